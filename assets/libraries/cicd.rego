@@ -1,2 +1,355 @@
 package generic.cicd
 
+# secret_name_pattern matches the names of the variables holding credentials
+secret_name_pattern := "(?i)(passw(or)?d|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)"
+
+# is_hardcoded_secret is true if the variable looks like a credential and its value is written in the pipeline
+# instead of referencing a secret variable
+is_hardcoded_secret(name, value) {
+	is_string(name)
+	regex.match(secret_name_pattern, name)
+	is_string(value)
+	trim_space(value) != ""
+	not regex.match(`^\$|\$\(|\$\{\{|\$\[`, trim_space(value))
+}
+
+# is_pinned_image is true if the container image is pinned to a digest
+is_pinned_image(image) {
+	regex.match(`@sha256:[a-f0-9]{64}$`, image)
+}
+
+# is_commit_sha is true if the git ref is a full length commit SHA
+is_commit_sha(ref) {
+	regex.match(`^[a-f0-9]{40}$`, ref)
+}
+
+# untrusted_references returns the untrusted variables referenced by a script, the patterns map the name of each
+# variable to the pattern matching its references
+untrusted_references(script, patterns) = matched {
+	matched := {variable |
+		pattern := patterns[variable]
+		regex.match(pattern, script)
+	}
+}
+
+# GitLab CI
+
+gitlab_keywords := {"after_script", "before_script", "cache", "image", "include", "services", "stages", "variables", "workflow"}
+
+gitlab_job_keys := {"script", "before_script", "after_script", "trigger", "extends", "run"}
+
+gitlab_script_keys := {"script", "before_script", "after_script"}
+
+# is_gitlab_ci is true if the document is a GitLab CI configuration, .gitlab-ci.yml or a file included by it
+is_gitlab_ci(document) {
+	regex.match(`(^|[/\\.])gitlab-ci\.ya?ml$`, lower(document.file))
+} else {
+	count(gitlab_jobs(document)) > 0
+}
+
+# gitlab_jobs returns the jobs of a GitLab CI configuration, including the hidden jobs and the default section,
+# whose settings are inherited by the jobs
+gitlab_jobs(document) = jobs {
+	jobs := {name: job |
+		job := document[name]
+		is_object(job)
+		not gitlab_keywords[name]
+		not startswith(name, "_kics")
+		is_gitlab_job(name, job)
+	}
+}
+
+is_gitlab_job("default", job) {
+	is_object(job)
+}
+
+is_gitlab_job(_, job) {
+	job[gitlab_job_keys[_]]
+}
+
+# gitlab_scripts returns the commands of the scripts of the jobs, with their path
+gitlab_scripts(document) = scripts {
+	jobs := gitlab_jobs(document)
+	scripts := {script |
+		job := jobs[name]
+		key := gitlab_script_keys[_]
+		script := script_lines(job[key], [name, key])[_]
+	}
+}
+
+# gitlab_images returns the images of the pipeline, of the jobs and of their services, with their path
+gitlab_images(document) = images {
+	jobs := gitlab_jobs(document)
+	jobImages := {image |
+		job := jobs[name]
+		image := gitlab_job_images(job, [name])[_]
+	}
+	images := jobImages | {image | image := gitlab_job_images(document, [])[_]}
+}
+
+# gitlab_variables returns the variables of the pipeline and of the jobs, whose value is a string or an object
+# with the value, with their path
+gitlab_variables(document) = variables {
+	scopes := array.concat([{"object": document, "path": []}], [{"object": job, "path": [name]} | job := gitlab_jobs(document)[name]])
+	variables := {variable |
+		scope := scopes[_]
+		value := scope.object.variables[name]
+		variable := gitlab_variable(name, value, array.concat(scope.path, ["variables", name]))
+	}
+}
+
+gitlab_variable(name, value, path) = variable {
+	is_object(value)
+	variable := {"name": name, "value": value.value, "path": array.concat(path, ["value"])}
+} else = variable {
+	variable := {"name": name, "value": value, "path": path}
+}
+
+gitlab_job_images(job, path) = images {
+	main := [image | image := image_reference(job.image, array.concat(path, ["image"]))]
+	services := [image |
+		service := job.services[s]
+		image := image_reference(service, array.concat(path, ["services", s]))
+	]
+	images := array.concat(main, services)
+}
+
+# gitlab_includes returns the included files of a GitLab CI configuration, with their path
+gitlab_includes(document) = includes {
+	is_array(document.include)
+	includes := {{"include": include, "path": ["include", i]} | include := document.include[i]}
+} else = includes {
+	includes := {{"include": document.include, "path": ["include"]}}
+}
+
+# Azure Pipelines
+
+azure_step_keys := {"script", "bash", "pwsh", "powershell", "task", "checkout", "template", "download", "downloadBuild", "publish", "getPackage", "restoreCache", "saveCache", "reviewApp"}
+
+azure_script_keys := {"script", "bash", "pwsh", "powershell"}
+
+azure_task_script_inputs := {"script", "inlineScript", "arguments", "scriptArguments"}
+
+# is_azure_pipelines is true if the document is an Azure Pipelines definition, azure-pipelines.yml or a template
+is_azure_pipelines(document) {
+	regex.match(`(^|[/\\])azure-pipelines[^/\\]*\.ya?ml$`, lower(document.file))
+} else {
+	not document.on
+	step := azure_steps(document)[_]
+	step.step[azure_step_keys[_]]
+}
+
+# azure_jobs returns the jobs of a pipeline, with their path
+azure_jobs(document) = jobs {
+	root := {{"job": job, "path": ["jobs", j]} |
+		is_array(document.jobs)
+		job := document.jobs[j]
+	}
+	staged := {{"job": job, "path": ["stages", s, "jobs", j]} |
+		is_array(document.stages)
+		is_array(document.stages[s].jobs)
+		job := document.stages[s].jobs[j]
+	}
+	jobs := root | staged
+}
+
+# azure_steps returns the steps of a pipeline, of its jobs and of the lifecycle hooks of its deployment jobs,
+# with their path
+azure_steps(document) = steps {
+	root := {{"step": step, "path": ["steps", i]} |
+		is_array(document.steps)
+		step := document.steps[i]
+	}
+	jobSteps := {{"step": step, "path": array.concat(job.path, ["steps", i])} |
+		job := azure_jobs(document)[_]
+		is_array(job.job.steps)
+		step := job.job.steps[i]
+	}
+	hookSteps := {{"step": step, "path": array.concat(job.path, ["strategy", strategy, hook, "steps", i])} |
+		job := azure_jobs(document)[_]
+		is_array(job.job.strategy[strategy][hook].steps)
+		step := job.job.strategy[strategy][hook].steps[i]
+	}
+	steps := (root | jobSteps) | hookSteps
+}
+
+# azure_scripts returns the scripts of the steps, including the inline scripts of the tasks, with their path
+azure_scripts(document) = scripts {
+	steps := azure_steps(document)
+	inline := {{"value": step.step[key], "path": array.concat(step.path, [key])} |
+		step := steps[_]
+		key := azure_script_keys[_]
+		is_string(step.step[key])
+	}
+	tasks := {{"value": step.step.inputs[key], "path": array.concat(step.path, ["inputs", key])} |
+		step := steps[_]
+		step.step.task
+		key := azure_task_script_inputs[_]
+		is_string(step.step.inputs[key])
+	}
+	scripts := inline | tasks
+}
+
+# azure_images returns the images of the container resources and of the containers of the jobs, which are the
+# alias of a container resource, an image or an object with the image, with their path
+azure_images(document) = images {
+	resources := {image |
+		container := document.resources.containers[c]
+		image := image_reference(container.image, ["resources", "containers", c, "image"])
+	}
+	aliases := {alias | alias := document.resources.containers[_].container}
+	jobs := {image |
+		job := azure_jobs(document)[_]
+		image := azure_job_image(job.job.container, array.concat(job.path, ["container"]), aliases)
+	}
+	images := resources | jobs
+}
+
+azure_job_image(container, path, aliases) = image {
+	is_string(container)
+	not aliases[container]
+	image := {"name": container, "path": path}
+} else = image {
+	is_string(container.image)
+	image := {"name": container.image, "path": array.concat(path, ["image"])}
+}
+
+# azure_variables returns the variables of a pipeline, of its stages and of its jobs, in the mapping or the list
+# syntax, with their path
+azure_variables(document) = variables {
+	scopes := array.concat([{"object": document, "path": []}], [scope |
+		stage := document.stages[s]
+		is_object(stage)
+		scope := {"object": stage, "path": ["stages", s]}
+	])
+	all := array.concat(scopes, [{"object": job.job, "path": job.path} | job := azure_jobs(document)[_]])
+	variables := {variable |
+		scope := all[_]
+		variable := azure_scope_variables(scope.object.variables, scope.path)[_]
+	}
+}
+
+azure_scope_variables(variables, path) = result {
+	is_array(variables)
+	result := [{"name": variable.name, "value": variable.value, "path": array.concat(path, ["variables", i, "value"])} |
+		variable := variables[i]
+		is_object(variable)
+	]
+} else = result {
+	is_object(variables)
+	result := [{"name": name, "value": value, "path": array.concat(path, ["variables", name])} |
+		value := variables[name]
+		not startswith(name, "_kics")
+	]
+}
+
+# Bitbucket Pipelines
+
+bitbucket_pipeline_groups := {"branches", "tags", "bookmarks", "custom", "pull-requests"}
+
+# is_bitbucket_pipelines is true if the document is a Bitbucket Pipelines configuration, bitbucket-pipelines.yml
+is_bitbucket_pipelines(document) {
+	regex.match(`(^|[/\\])bitbucket-pipelines\.ya?ml$`, lower(document.file))
+} else {
+	is_object(document.pipelines)
+}
+
+# bitbucket_steps returns the steps of the pipelines, including the parallel steps and the steps of the stages,
+# with their path
+bitbucket_steps(document) = steps {
+	is_bitbucket_pipelines(document)
+	defaults := {{"item": item, "path": ["pipelines", "default", i]} | item := document.pipelines["default"][i]}
+	grouped := {{"item": item, "path": ["pipelines", group, name, i]} |
+		group := bitbucket_pipeline_groups[_]
+		item := document.pipelines[group][name][i]
+	}
+	items := defaults | grouped
+	steps := {step | item := items[_]; step := bitbucket_item_steps(item.item, item.path)[_]}
+}
+
+bitbucket_item_steps(item, path) = steps {
+	single := [{"step": item.step, "path": array.concat(path, ["step"])} | is_object(item.step)]
+	parallel := [{"step": step.step, "path": array.concat(path, ["parallel", p, "step"])} |
+		step := item.parallel[p]
+		is_object(step.step)
+	]
+	parallelSteps := [{"step": step.step, "path": array.concat(path, ["parallel", "steps", p, "step"])} |
+		step := item.parallel.steps[p]
+		is_object(step.step)
+	]
+	stage := [{"step": step.step, "path": array.concat(path, ["stage", "steps", p, "step"])} |
+		step := item.stage.steps[p]
+		is_object(step.step)
+	]
+	steps := array.concat(array.concat(single, parallel), array.concat(parallelSteps, stage))
+}
+
+# bitbucket_scripts returns the commands of the scripts of the steps, with their path
+bitbucket_scripts(document) = scripts {
+	scripts := {script |
+		step := bitbucket_steps(document)[_]
+		key := {"script", "after-script"}[_]
+		script := script_lines(step.step[key], array.concat(step.path, [key]))[_]
+	}
+}
+
+# bitbucket_images returns the images of the pipelines, of their steps and of the services, with their path
+bitbucket_images(document) = images {
+	is_bitbucket_pipelines(document)
+	main := {image | image := image_reference(document.image, ["image"])}
+	steps := {image |
+		step := bitbucket_steps(document)[_]
+		image := image_reference(step.step.image, array.concat(step.path, ["image"]))
+	}
+	services := {image |
+		service := document.definitions.services[name]
+		is_object(service)
+		image := image_reference(service.image, ["definitions", "services", name, "image"])
+	}
+	images := (main | steps) | services
+}
+
+# bitbucket_pipes returns the pipes of the scripts of the steps, with their path
+bitbucket_pipes(document) = pipes {
+	pipes := {{"pipe": line.pipe, "variables": object.get(line, "variables", {}), "path": array.concat(step.path, ["script", i])} |
+		step := bitbucket_steps(document)[_]
+		line := step.step.script[i]
+		is_object(line)
+		is_string(line.pipe)
+	}
+}
+
+# bitbucket_variables returns the variables of the services and of the pipes, with their path
+bitbucket_variables(document) = variables {
+	services := {{"name": name, "value": value, "path": ["definitions", "services", service, "variables", name]} |
+		value := document.definitions.services[service].variables[name]
+	}
+	pipes := {{"name": name, "value": value, "path": array.concat(pipe.path, ["variables", name])} |
+		pipe := bitbucket_pipes(document)[_]
+		value := pipe.variables[name]
+	}
+	variables := services | pipes
+}
+
+# Scripts and images
+
+# script_lines returns the commands of a script, which is a command or a list of commands, with their path
+script_lines(script, path) = lines {
+	is_string(script)
+	lines := [{"value": script, "path": path}]
+} else = lines {
+	is_array(script)
+	lines := [{"value": line, "path": array.concat(path, [i])} |
+		line := script[i]
+		is_string(line)
+	]
+}
+
+# image_reference returns the name of an image, which is a string or an object with the name of the image
+image_reference(image, path) = reference {
+	is_string(image)
+	reference := {"name": image, "path": path}
+} else = reference {
+	is_string(image.name)
+	reference := {"name": image.name, "path": array.concat(path, ["name"])}
+}
@@ -70,3 +70,23 @@ check_multi_stage(imageName, images) {
     sortedIndex := sort(unsortedIndex)
     imageName == sortedIndex[minus(count(sortedIndex), 1)].Name
 } 
+
+# final_stage returns the final stage of a Dockerfile, which is the image built when no target is set
+final_stage(document) = stage {
+	stage := document.stages[_]
+	stage.Final
+}
+
+# is_final_stage is true if name is the key of the commands of the final stage of a Dockerfile,
+# the stages only used to build or copy files from are not part of the image built
+is_final_stage(document, name) {
+	final_stage(document).Command == name
+}
+
+# stage_commands returns the commands of the stage whose commands key is name, preceded by the commands of the
+# stages it is built from, whose instructions, such as USER and HEALTHCHECK, are inherited
+stage_commands(document, name) = commands {
+	stages := [stage | stage := document.stages[_]; stage.Command == name]
+	chain := stages[minus(count(stages), 1)].Chain
+	commands := [command | key := chain[_]; command := document.command[key][_]]
+}
@@ -583,3 +583,69 @@ check_key_empty(disk_encryption_key) = key {
 	common_lib.emptyOrNull(disk_encryption_key.kms_key_self_link)
 	key := "kms_key_self_link"
 }
+
+# Gets the concrete instances of a resource or data source repeated by count or for_each, a resource without
+# instances is its own single instance
+get_instances(resource) = instances {
+	common_lib.valid_key(resource, "_kics_instances")
+	instances := [instance | instance := resource._kics_instances[_]]
+} else = [resource] {
+	true
+}
+
+# Checks if the document was instantiated by a local module call
+is_module_instance(document) {
+	common_lib.valid_key(document, "_kics_module")
+}
+
+# Gets the module path of a document (e.g. module.network.module.vpc), empty for root documents
+get_module_path(document) = path {
+	path := document._kics_module.path
+} else = "" {
+	true
+}
+
+# Gets the directory of the file of a document
+get_document_directory(document) = directory {
+	parts := split(replace(document.file, "\\", "/"), "/")
+	directory := concat("/", array.slice(parts, 0, count(parts) - 1))
+}
+
+# Checks if two documents belong to the same Terraform configuration, which are the documents of the files of the same
+# directory instantiated by the same module call, or not instantiated by a module call
+same_configuration(document, other) {
+	get_module_path(document) == get_module_path(other)
+	get_document_directory(document) == get_document_directory(other)
+}
+
+# Gets the references of the Terraform reference graph to a resource, data source (e.g. data.aws_iam_policy_document)
+# or module (module) of a document, from the documents of the same configuration, each reference holds the source_type,
+# source_name and attribute of the resource referencing it
+get_references_to(document, resourceType, resourceName) = references {
+	references := [reference |
+		other := input.document[_]
+		same_configuration(document, other)
+		reference := other._kics_references[_]
+		reference.target_type == resourceType
+		reference.target_name == resourceName
+	]
+}
+
+# Gets the references of the Terraform reference graph from a resource or data source of a document to other resources,
+# data sources and modules, each reference holds the target_type, target_name and target_attribute referenced
+get_references_from(document, resourceType, resourceName) = references {
+	references := [reference |
+		other := input.document[_]
+		same_configuration(document, other)
+		reference := other._kics_references[_]
+		reference.source_type == resourceType
+		reference.source_name == resourceName
+	]
+}
+
+# Checks if a resource is referenced by a resource of the given type
+# (e.g. an aws_s3_bucket referenced by the bucket attribute of an aws_s3_bucket_public_access_block)
+is_referenced_by(document, resourceType, resourceName, sourceType) {
+	reference := get_references_to(document, resourceType, resourceName)[_]
+	reference.source_type == sourceType
+}
@@ -0,0 +1,21 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_azure_pipelines(document)
+	variable := cicd_lib.azure_variables(document)[_]
+
+	cicd_lib.is_hardcoded_secret(variable.name, variable.value)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("%s={{%s}}", [common_lib.concat_path(variable.path), variable.name]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("Variable '%s' should be a secret variable or be read from a variable group linked to a key vault", [variable.name]),
+		"keyActualValue": sprintf("Variable '%s' is hardcoded in the pipeline", [variable.name]),
+		"searchLine": common_lib.build_search_line(variable.path, []),
+	}
+}
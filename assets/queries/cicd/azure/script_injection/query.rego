@@ -0,0 +1,40 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+# untrusted are the predefined variables set from the branches, commits and pull requests of the users
+untrusted := [
+	"Build.RequestedFor",
+	"Build.RequestedForEmail",
+	"Build.SourceBranch",
+	"Build.SourceBranchName",
+	"Build.SourceVersionMessage",
+	"System.PullRequest.SourceBranch",
+]
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_azure_pipelines(document)
+	script := cicd_lib.azure_scripts(document)[_]
+
+	patterns := {variable: reference_pattern(variable) | variable := untrusted[_]}
+	matched := cicd_lib.untrusted_references(script.value, patterns)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("%s={{%s}}", [common_lib.concat_path(script.path), script.value]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": "Script does not use variables controlled by users",
+		"keyActualValue": "Script uses variables controlled by users",
+		"searchLine": common_lib.build_search_line(script.path, []),
+		"searchValue": matched[_],
+	}
+}
+
+# reference_pattern matches the macro syntax, $(Build.SourceBranch), and the expressions reading the variable,
+# variables['Build.SourceBranch']
+reference_pattern(variable) = pattern {
+	name := replace(variable, ".", `\.`)
+	pattern := sprintf(`(?i)\$\(\s*%s\s*\)|variables\[\s*['"]%s['"]\s*\]`, [name, name])
+}
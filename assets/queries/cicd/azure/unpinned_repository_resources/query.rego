@@ -0,0 +1,44 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_azure_pipelines(document)
+	repository := document.resources.repositories[r]
+
+	not repository.ref
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("resources.repositories.repository={{%s}}", [repository.repository]),
+		"issueType": "MissingAttribute",
+		"keyExpectedValue": sprintf("Repository '%s' should set 'ref' to a tag", [repository.repository]),
+		"keyActualValue": sprintf("Repository '%s' does not set 'ref', the default branch is used", [repository.repository]),
+		"searchLine": common_lib.build_search_line(["resources", "repositories", r, "repository"], []),
+	}
+}
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_azure_pipelines(document)
+	repository := document.resources.repositories[r]
+
+	is_branch(repository.ref)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("resources.repositories.ref={{%s}}", [repository.ref]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("Repository '%s' should set 'ref' to a tag", [repository.repository]),
+		"keyActualValue": sprintf("Repository '%s' sets 'ref' to a branch", [repository.repository]),
+		"searchLine": common_lib.build_search_line(["resources", "repositories", r, "ref"], []),
+	}
+}
+
+is_branch(ref) {
+	startswith(ref, "refs/heads/")
+} else {
+	not startswith(ref, "refs/")
+}
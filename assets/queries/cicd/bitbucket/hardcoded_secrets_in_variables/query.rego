@@ -0,0 +1,21 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_bitbucket_pipelines(document)
+	variable := cicd_lib.bitbucket_variables(document)[_]
+
+	cicd_lib.is_hardcoded_secret(variable.name, variable.value)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": common_lib.concat_path(variable.path),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("Variable '%s' should reference a secured repository or deployment variable", [variable.name]),
+		"keyActualValue": sprintf("Variable '%s' is hardcoded in the pipeline", [variable.name]),
+		"searchLine": common_lib.build_search_line(variable.path, []),
+	}
+}
@@ -0,0 +1,31 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+# untrusted are the default variables set from the branches, tags and bookmarks pushed by the users
+untrusted := [
+	"BITBUCKET_BOOKMARK",
+	"BITBUCKET_BRANCH",
+	"BITBUCKET_PR_DESTINATION_BRANCH",
+	"BITBUCKET_TAG",
+]
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_bitbucket_pipelines(document)
+	script := cicd_lib.bitbucket_scripts(document)[_]
+
+	patterns := {variable: sprintf(`\$\{?%s\b`, [variable]) | variable := untrusted[_]}
+	matched := cicd_lib.untrusted_references(script.value, patterns)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("%s={{%s}}", [common_lib.concat_path(script.path), script.value]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": "Script does not use variables controlled by users",
+		"keyActualValue": "Script uses variables controlled by users",
+		"searchLine": common_lib.build_search_line(script.path, []),
+		"searchValue": matched[_],
+	}
+}
@@ -0,0 +1,21 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_bitbucket_pipelines(document)
+	image := cicd_lib.bitbucket_images(document)[_]
+
+	not cicd_lib.is_pinned_image(image.name)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("%s={{%s}}", [common_lib.concat_path(image.path), image.name]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("Image '%s' should be pinned to a digest", [image.name]),
+		"keyActualValue": sprintf("Image '%s' is not pinned to a digest", [image.name]),
+		"searchLine": common_lib.build_search_line(image.path, []),
+	}
+}
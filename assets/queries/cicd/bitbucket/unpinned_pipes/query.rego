@@ -0,0 +1,27 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_bitbucket_pipelines(document)
+	pipe := cicd_lib.bitbucket_pipes(document)[_]
+
+	not is_pinned(pipe.pipe)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("%s.pipe={{%s}}", [common_lib.concat_path(pipe.path), pipe.pipe]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("Pipe '%s' should be pinned to a full version or to a digest", [pipe.pipe]),
+		"keyActualValue": sprintf("Pipe '%s' is pinned to a partial version or is not pinned", [pipe.pipe]),
+		"searchLine": common_lib.build_search_line(pipe.path, ["pipe"]),
+	}
+}
+
+is_pinned(pipe) {
+	regex.match(`:\d+\.\d+\.\d+$`, pipe)
+} else {
+	cicd_lib.is_pinned_image(trim_prefix(pipe, "docker://"))
+}
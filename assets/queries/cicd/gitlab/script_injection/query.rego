@@ -0,0 +1,38 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+# untrusted are the predefined variables set from the commits, branches, tags and merge requests of the users
+untrusted := [
+	"CI_COMMIT_AUTHOR",
+	"CI_COMMIT_BRANCH",
+	"CI_COMMIT_DESCRIPTION",
+	"CI_COMMIT_MESSAGE",
+	"CI_COMMIT_REF_NAME",
+	"CI_COMMIT_TAG_MESSAGE",
+	"CI_COMMIT_TITLE",
+	"CI_EXTERNAL_PULL_REQUEST_SOURCE_BRANCH_NAME",
+	"CI_MERGE_REQUEST_DESCRIPTION",
+	"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME",
+	"CI_MERGE_REQUEST_TITLE",
+]
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_gitlab_ci(document)
+	script := cicd_lib.gitlab_scripts(document)[_]
+
+	patterns := {variable: sprintf(`\$\{?%s\b`, [variable]) | variable := untrusted[_]}
+	matched := cicd_lib.untrusted_references(script.value, patterns)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": sprintf("%s={{%s}}", [common_lib.concat_path(script.path), script.value]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": "Script does not use variables controlled by users",
+		"keyActualValue": "Script uses variables controlled by users",
+		"searchLine": common_lib.build_search_line(script.path, []),
+		"searchValue": matched[_],
+	}
+}
@@ -0,0 +1,79 @@
+package Cx
+
+import data.generic.cicd as cicd_lib
+import data.generic.common as common_lib
+
+CxPolicy[result] {
+	document := input.document[i]
+	cicd_lib.is_gitlab_ci(document)
+	include := cicd_lib.gitlab_includes(document)[_]
+
+	details := unpinned(include.include, include.path)
+
+	result := {
+		"documentId": document.id,
+		"searchKey": common_lib.concat_path(details.path),
+		"issueType": details.issueType,
+		"keyExpectedValue": details.expected,
+		"keyActualValue": details.actual,
+		"searchLine": common_lib.build_search_line(details.path, []),
+	}
+}
+
+unpinned(include, path) = details {
+	is_string(include)
+	regex.match(`^https?://`, include)
+	details := {
+		"path": path,
+		"issueType": "IncorrectValue",
+		"expected": "Remote include should set 'integrity'",
+		"actual": "Remote include is not pinned to the hash of its content",
+	}
+}
+
+unpinned(include, path) = details {
+	is_string(include.remote)
+	not include.integrity
+	details := {
+		"path": array.concat(path, ["remote"]),
+		"issueType": "MissingAttribute",
+		"expected": "Remote include should set 'integrity'",
+		"actual": "Remote include does not set 'integrity'",
+	}
+}
+
+unpinned(include, path) = details {
+	is_string(include.project)
+	not include.ref
+	details := {
+		"path": array.concat(path, ["project"]),
+		"issueType": "MissingAttribute",
+		"expected": "Project include should set 'ref' to a full length commit SHA",
+		"actual": "Project include does not set 'ref'",
+	}
+}
+
+unpinned(include, path) = details {
+	is_string(include.project)
+	include.ref
+	not cicd_lib.is_commit_sha(include.ref)
+	details := {
+		"path": array.concat(path, ["ref"]),
+		"issueType": "IncorrectValue",
+		"expected": "Project include should set 'ref' to a full length commit SHA",
+		"actual": "Project include 'ref' is a branch or a tag",
+	}
+}
+
+unpinned(include, path) = details {
+	is_string(include.component)
+	version := split(include.component, "@")[1]
+	not cicd_lib.is_commit_sha(version)
+	not regex.match(`^\d+\.\d+\.\d+$`, version)
+	details := {
+		"path": array.concat(path, ["component"]),
+		"issueType": "IncorrectValue",
+		"expected": "Component should be pinned to a full length commit SHA or to a full version",
+		"actual": "Component is pinned to a partial version or to the latest release",
+	}
+}
@@ -3,10 +3,11 @@ package Cx
 import data.generic.dockerfile as dockerLib
 
 CxPolicy[result] {
-	resource := input.document[i].command[name]
-	dockerLib.check_multi_stage(name, input.document[i].command)
+	document := input.document[i]
+	document.command[name]
+	dockerLib.is_final_stage(document, name)
 
-	not contains(resource, "healthcheck")
+	not contains(dockerLib.stage_commands(document, name), "healthcheck")
 
 	result := {
 		"documentId": input.document[i].id,
@@ -3,15 +3,17 @@ package Cx
 import data.generic.dockerfile as dockerLib
 
 CxPolicy[result] {
-	resource := input.document[i].command[name]
-	dockerLib.check_multi_stage(name, input.document[i].command)
+	document := input.document[i]
+	stage := dockerLib.final_stage(document)
 
-	userCmd := [x | resource[j].Cmd == "user"; x := resource[j]]
-	userCmd[minus(count(userCmd), 1)].Value[0] == "root"
+	# the USER instructions of the stages the final stage is built from are inherited
+	userCmd := [x | key := stage.Chain[_]; cmd := document.command[key][_]; cmd.Cmd == "user"; x := {"name": key, "cmd": cmd}]
+	lastUser := userCmd[minus(count(userCmd), 1)]
+	lastUser.cmd.Value[0] == "root"
 
 	result := {
 		"documentId": input.document[i].id,
-		"searchKey": sprintf("FROM={{%s}}.{{%s}}", [name, userCmd[minus(count(userCmd), 1)].Original]),
+		"searchKey": sprintf("FROM={{%s}}.{{%s}}", [lastUser.name, lastUser.cmd.Original]),
 		"issueType": "IncorrectValue",
 		"keyExpectedValue": "Last User shouldn't be root",
 		"keyActualValue": "Last User is root",
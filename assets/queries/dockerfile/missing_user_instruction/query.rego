@@ -3,11 +3,12 @@ package Cx
 import data.generic.dockerfile as dockerLib
 
 CxPolicy[result] {
-	resource := input.document[i].command[name]
-	dockerLib.check_multi_stage(name, input.document[i].command)
+	document := input.document[i]
+	document.command[name]
+	dockerLib.is_final_stage(document, name)
 
 	not name == "scratch"
-	not has_user_instruction(resource)
+	not has_user_instruction(dockerLib.stage_commands(document, name))
 
 	result := {
 		"documentId": input.document[i].id,
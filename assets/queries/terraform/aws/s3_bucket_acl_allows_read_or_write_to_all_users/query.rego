@@ -7,16 +7,17 @@ import data.generic.terraform as tf_lib
 CxPolicy[result] {
 
 	resource := input.document[i].resource.aws_s3_bucket[name]
-	publicAccessACL(resource.acl)
+	instance := tf_lib.get_instances(resource)[_]
+	publicAccessACL(instance.acl)
 
 	result := {
 		"documentId": input.document[i].id,
 		"resourceType": "aws_s3_bucket",
 		"resourceName": tf_lib.get_specific_resource_name(resource, "aws_s3_bucket", name),
-		"searchKey": sprintf("aws_s3_bucket[%s].acl=%s", [name, resource.acl]),
+		"searchKey": sprintf("aws_s3_bucket[%s].acl=%s", [name, instance.acl]),
 		"issueType": "IncorrectValue",
 		"keyExpectedValue": "'acl' should equal to 'private'",
-		"keyActualValue": sprintf("'acl' is equal '%s'", [resource.acl]),
+		"keyActualValue": sprintf("'acl' is equal '%s'", [instance.acl]),
 		"searchLine": common_lib.build_search_line(["resource", "aws_s3_bucket", name, "acl"], []),
 	}
 }
@@ -0,0 +1,9 @@
+resource "aws_s3_bucket" "negative4" {
+  for_each = {
+    logs    = "private"
+    archive = "log-delivery-write"
+  }
+
+  bucket = "my-tf-${each.key}-bucket"
+  acl    = each.value
+}
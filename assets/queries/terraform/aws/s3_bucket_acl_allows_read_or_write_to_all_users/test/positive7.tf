@@ -0,0 +1,9 @@
+resource "aws_s3_bucket" "positive7" {
+  for_each = {
+    logs = "private"
+    site = "public-read"
+  }
+
+  bucket = "my-tf-${each.key}-bucket"
+  acl    = each.value
+}
@@ -2,7 +2,8 @@ package testcases
 
 // E2E-CLI-095 - KICS  scan and ignore references
 // should perform the scan successfully and return exit code 0
-// this test sample contains a circular loop. It will stop after 15 iterations, having parsed 6887 lines
+// this test sample contains a circular loop. It will stop after 15 iterations, having parsed 2812 lines, the
+// playbook includes are resolved by the Ansible resolver, which does not include the files being resolved again
 func init() { //nolint
 	testSample := TestCase{
 		Name: "should perform a valid scan and resolve references [E2E-CLI-095]",
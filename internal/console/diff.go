@@ -0,0 +1,140 @@
+package console
+
+import (
+	_ "embed" // Embed diff flags
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/v2/internal/console/flags"
+	consoleHelpers "github.com/Checkmarx/kics/v2/internal/console/helpers"
+	sentryReport "github.com/Checkmarx/kics/v2/internal/sentry"
+	"github.com/Checkmarx/kics/v2/pkg/diff"
+	"github.com/Checkmarx/kics/v2/pkg/engine/source"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	internalPrinter "github.com/Checkmarx/kics/v2/pkg/printer"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	//go:embed assets/diff-flags.json
+	diffFlagsListContent string
+)
+
+// NewDiffCmd creates a new instance of the diff Command
+func NewDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff",
+		Short: "Compares the JSON results of two scans",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return preDiff(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diffResults()
+		},
+	}
+}
+
+func initDiffCmd(diffCmd *cobra.Command) error {
+	if err := flags.InitJSONFlags(
+		diffCmd,
+		diffFlagsListContent,
+		false,
+		source.ListSupportedPlatforms(),
+		source.ListSupportedCloudProviders()); err != nil {
+		return err
+	}
+
+	for _, requiredFlag := range []string{flags.DiffOldResults, flags.DiffNewResults} {
+		if err := diffCmd.MarkFlagRequired(requiredFlag); err != nil {
+			sentryReport.ReportSentry(&sentryReport.Report{
+				Message:  "Failed to add command required flags",
+				Err:      err,
+				Location: "func initDiffCmd()",
+			}, true)
+			log.Err(err).Msg("Failed to add command required flags")
+		}
+	}
+	return nil
+}
+
+func preDiff(cmd *cobra.Command) error {
+	if err := flags.Validate(); err != nil {
+		return err
+	}
+	if err := internalPrinter.SetupPrinter(cmd.InheritedFlags()); err != nil {
+		return errors.New(initError + err.Error())
+	}
+	return nil
+}
+
+func diffResults() error {
+	return executeDiff(getDiffParameters())
+}
+
+func getDiffParameters() *diff.Parameters {
+	diffParams := diff.Parameters{
+		OldResults:    flags.GetStrFlag(flags.DiffOldResults),
+		NewResults:    flags.GetStrFlag(flags.DiffNewResults),
+		OutputPath:    flags.GetStrFlag(flags.DiffOutputPath),
+		OutputName:    flags.GetStrFlag(flags.DiffOutputName),
+		ReportFormats: flags.GetMultiStrFlag(flags.DiffReportFormats),
+	}
+
+	return &diffParams
+}
+
+func executeDiff(diffParams *diff.Parameters) error {
+	log.Debug().Msg("console.diff()")
+
+	oldSummary, err := readResults(diffParams.OldResults)
+	if err != nil {
+		return err
+	}
+	newSummary, err := readResults(diffParams.NewResults)
+	if err != nil {
+		return err
+	}
+
+	report := diff.Compare(oldSummary, newSummary, diffParams.OldResults, diffParams.NewResults)
+	printDiff(report)
+
+	if diffParams.OutputPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(diffParams.OutputPath, os.ModePerm); err != nil {
+		return err
+	}
+	formats := diffParams.ReportFormats
+	if len(formats) == 0 {
+		formats = []string{"json"}
+	}
+	return consoleHelpers.GenerateDiffReport(diffParams.OutputPath, diffParams.OutputName, report, formats)
+}
+
+func readResults(resultsPath string) (*model.Summary, error) {
+	content, err := os.ReadFile(filepath.Clean(resultsPath))
+	if err != nil {
+		log.Error().Msgf("failed to read file: %s", err)
+		return nil, err
+	}
+
+	var summary model.Summary
+	if err := json.Unmarshal(content, &summary); err != nil {
+		log.Error().Msgf("failed to unmarshal file: %s", err)
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+func printDiff(report *diff.Report) {
+	fmt.Printf("\nOld results: %s (%d)\n", report.OldResults, report.Counters.OldTotal)
+	fmt.Printf("New results: %s (%d)\n\n", report.NewResults, report.Counters.NewTotal)
+	fmt.Printf("Added: %d\n", report.Counters.Added)
+	fmt.Printf("Removed: %d\n", report.Counters.Removed)
+	fmt.Printf("Severity changed: %d\n\n", report.Counters.SeverityChanged)
+}
@@ -0,0 +1,10 @@
+package flags
+
+// Flags constants for diff
+const (
+	DiffOldResults    = "old-results"
+	DiffNewResults    = "new-results"
+	DiffOutputPath    = "diff-output-path"
+	DiffOutputName    = "diff-output-name"
+	DiffReportFormats = "diff-report-formats"
+)
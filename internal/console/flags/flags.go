@@ -35,14 +35,16 @@ type flagJSON struct {
 
 func evalUsage(usage string, supportedPlatforms, supportedCloudProviders []string) string {
 	variables := map[string]string{
-		"sliceInstructions":  "can be provided multiple times or as a comma separated string",
-		"supportedLogLevels": strings.Join(constants.AvailableLogLevels, ","),
-		"supportedPlatforms": strings.Join(supportedPlatforms, ", "),
-		"supportedProviders": strings.Join(supportedCloudProviders, ", "),
-		"supportedReports":   strings.Join(append([]string{"all"}, helpers.ListReportFormats()...), ", "),
-		"defaultLogFile":     constants.DefaultLogFile,
-		"logFormatPretty":    constants.LogFormatPretty,
-		"logFormatJSON":      constants.LogFormatJSON,
+		"sliceInstructions":          "can be provided multiple times or as a comma separated string",
+		"supportedLogLevels":         strings.Join(constants.AvailableLogLevels, ","),
+		"supportedPlatforms":         strings.Join(supportedPlatforms, ", "),
+		"supportedProviders":         strings.Join(supportedCloudProviders, ", "),
+		"supportedReports":           strings.Join(append([]string{"all"}, helpers.ListReportFormats()...), ", "),
+		"supportedDiffReports":       strings.Join(helpers.ListDiffReportFormats(), ", "),
+		"supportedQueryTestsReports": strings.Join(helpers.ListQueryTestsReportFormats(), ", "),
+		"defaultLogFile":             constants.DefaultLogFile,
+		"logFormatPretty":            constants.LogFormatPretty,
+		"logFormatJSON":              constants.LogFormatJSON,
 	}
 	variableRegex := regexp.MustCompile(`\$\{(\w+)\}`)
 	match := variableRegex.FindAllStringSubmatch(usage, -1)
@@ -70,6 +72,17 @@ func checkHiddenAndDeprecated(flagSet *pflag.FlagSet, flagName string, flagProps
 	return nil
 }
 
+// reference returns the variable holding the value of the flag, flags defined by several commands share
+// the same variable since only one command runs on each execution
+func reference[T any](references map[string]*T, flagName string) *T {
+	if flag, ok := references[flagName]; ok {
+		return flag
+	}
+	flag := new(T)
+	references[flagName] = flag
+	return flag
+}
+
 // InitJSONFlags initialize cobra flags
 func InitJSONFlags(
 	cmd *cobra.Command,
@@ -94,35 +107,31 @@ func InitJSONFlags(
 
 		switch flagProps.FlagType {
 		case "multiStr":
-			var flag []string
-			flagsMultiStrReferences[flagName] = &flag
+			flag := reference(flagsMultiStrReferences, flagName)
 			defaultValues := make([]string, 0)
 			if flagProps.DefaultValue != nil {
 				defaultValues = strings.Split(*flagProps.DefaultValue, ",")
 			}
-			flagSet.StringSliceVarP(flagsMultiStrReferences[flagName], flagName, flagProps.ShorthandFlag, defaultValues, flagProps.Usage)
+			flagSet.StringSliceVarP(flag, flagName, flagProps.ShorthandFlag, defaultValues, flagProps.Usage)
 		case "str":
-			var flag string
-			flagsStrReferences[flagName] = &flag
-			flagSet.StringVarP(flagsStrReferences[flagName], flagName, flagProps.ShorthandFlag, *flagProps.DefaultValue, flagProps.Usage)
+			flag := reference(flagsStrReferences, flagName)
+			flagSet.StringVarP(flag, flagName, flagProps.ShorthandFlag, *flagProps.DefaultValue, flagProps.Usage)
 		case "bool":
-			var flag bool
-			flagsBoolReferences[flagName] = &flag
+			flag := reference(flagsBoolReferences, flagName)
 			defaultValue, err := strconv.ParseBool(*flagProps.DefaultValue)
 			if err != nil {
 				log.Err(err).Msg("Loading flags: could not convert default values")
 				return err
 			}
-			flagSet.BoolVarP(flagsBoolReferences[flagName], flagName, flagProps.ShorthandFlag, defaultValue, flagProps.Usage)
+			flagSet.BoolVarP(flag, flagName, flagProps.ShorthandFlag, defaultValue, flagProps.Usage)
 		case "int":
-			var flag int
-			flagsIntReferences[flagName] = &flag
+			flag := reference(flagsIntReferences, flagName)
 			defaultValue, err := strconv.Atoi(*flagProps.DefaultValue)
 			if err != nil {
 				log.Err(err).Msg("Loading flags: could not convert default values")
 				return err
 			}
-			flagSet.IntVarP(flagsIntReferences[flagName], flagName, flagProps.ShorthandFlag, defaultValue, flagProps.Usage)
+			flagSet.IntVarP(flag, flagName, flagProps.ShorthandFlag, defaultValue, flagProps.Usage)
 		default:
 			log.Error().Msgf("Flag %s has unknown type %s", flagName, flagProps.FlagType)
 		}
@@ -150,6 +150,20 @@ func TestFlags_InitJSONFlags(t *testing.T) {
 	}
 }
 
+func TestFlags_InitJSONFlagsSharedBetweenCommands(t *testing.T) {
+	flagsListContent := `{"shared-flag": {"flagType": "str", "shorthandFlag": "", "defaultValue": "default", "usage": "shared"}}`
+	firstCmd := &cobra.Command{Use: "first"}
+	secondCmd := &cobra.Command{Use: "second"}
+
+	require.NoError(t, InitJSONFlags(firstCmd, flagsListContent, false, nil, nil))
+	require.NoError(t, InitJSONFlags(secondCmd, flagsListContent, false, nil, nil))
+
+	require.NoError(t, firstCmd.Flags().Set("shared-flag", "first"))
+	require.Equal(t, "first", GetStrFlag("shared-flag"))
+	require.NoError(t, secondCmd.Flags().Set("shared-flag", "second"))
+	require.Equal(t, "second", GetStrFlag("shared-flag"))
+}
+
 func TestFlags_GetStrFlag(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2,9 +2,12 @@ package flags
 
 // Flags constants for scan
 const (
+	BaselineFlag            = "baseline"
 	BomFlag                 = "bom"
+	CacheDirFlag            = "cache-dir"
 	CloudProviderFlag       = "cloud-provider"
 	ConfigFlag              = "config"
+	CoveragePathFlag        = "coverage-path"
 	DisableFullDescFlag     = "disable-full-descriptions"
 	ExcludeCategoriesFlag   = "exclude-categories"
 	ExcludePathsFlag        = "exclude-paths"
@@ -15,6 +18,10 @@ const (
 	IncludeQueriesFlag      = "include-queries"
 	InputDataFlag           = "input-data"
 	FailOnFlag              = "fail-on"
+	GitDiffBaseFlag         = "git-diff-base"
+	HelmEnvironmentsFlag    = "helm-environments"
+	HelmSetFlag             = "helm-set"
+	HelmValuesFlag          = "helm-values"
 	IgnoreOnExitFlag        = "ignore-on-exit"
 	MinimalUIFlag           = "minimal-ui"
 	NoProgressFlag          = "no-progress"
@@ -29,6 +36,7 @@ const (
 	TypeFlag                = "type"
 	ExcludeTypeFlag         = "exclude-type"
 	TerraformVarsPathFlag   = "terraform-vars-path"
+	CFNParametersPathFlag   = "cfn-parameters-path"
 	QueryExecTimeoutFlag    = "timeout"
 	LineInfoPayloadFlag     = "payload-lines"
 	DisableSecretsFlag      = "disable-secrets"
@@ -0,0 +1,7 @@
+package flags
+
+// Flags constants for serve
+const (
+	ServeAddressFlag        = "address"
+	ServeMaxRequestSizeFlag = "max-request-size"
+)
@@ -0,0 +1,8 @@
+package flags
+
+// Flags constants for test-queries
+const (
+	TestQueriesOutputPath    = "test-output-path"
+	TestQueriesOutputName    = "test-output-name"
+	TestQueriesReportFormats = "test-report-formats"
+)
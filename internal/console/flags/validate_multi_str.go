@@ -13,13 +13,15 @@ import (
 )
 
 var validMultiStrEnums = map[string]map[string]string{
-	CloudProviderFlag:     constants.AvailableCloudProviders,
-	ExcludeCategoriesFlag: constants.AvailableCategories,
-	ExcludeSeveritiesFlag: convertSliceToDummyMap(constants.AvailableSeverities),
-	FailOnFlag:            convertSliceToDummyMap(constants.AvailableSeverities),
-	ReportFormatsFlag:     convertSliceToDummyMap(append([]string{"all"}, helpers.ListReportFormats()...)),
-	TypeFlag:              constants.AvailablePlatforms,
-	ExcludeTypeFlag:       constants.AvailablePlatforms,
+	CloudProviderFlag:        constants.AvailableCloudProviders,
+	ExcludeCategoriesFlag:    constants.AvailableCategories,
+	ExcludeSeveritiesFlag:    convertSliceToDummyMap(constants.AvailableSeverities),
+	FailOnFlag:               convertSliceToDummyMap(constants.AvailableSeverities),
+	ReportFormatsFlag:        convertSliceToDummyMap(append([]string{"all"}, helpers.ListReportFormats()...)),
+	DiffReportFormats:        convertSliceToDummyMap(helpers.ListDiffReportFormats()),
+	TestQueriesReportFormats: convertSliceToDummyMap(helpers.ListQueryTestsReportFormats()),
+	TypeFlag:                 constants.AvailablePlatforms,
+	ExcludeTypeFlag:          constants.AvailablePlatforms,
 }
 
 func sliceFlagsShouldNotStartWithFlags(flagName string) error {
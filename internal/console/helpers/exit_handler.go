@@ -16,6 +16,10 @@ func ResultsExitCode(summary *model.Summary) int {
 	severityArr := []model.Severity{"CRITICAL", "HIGH", "MEDIUM", "LOW", "INFO", "TRACE"}
 	codeMap := map[model.Severity]int{"CRITICAL": 60, "HIGH": 50, "MEDIUM": 40, "LOW": 30, "INFO": 20, "TRACE": 0}
 	exitMap := summary.SeveritySummary.SeverityCounters
+	// when a baseline is provided only the new findings are considered
+	if summary.Baseline != nil {
+		exitMap = summary.Baseline.NewSeverityCounters
+	}
 	for _, severity := range severityArr {
 		if _, reportSeverity := shouldFail[strings.ToLower(string(severity))]; !reportSeverity {
 			continue
@@ -81,3 +85,13 @@ func RemediateExitCode(selectedRemediationNumber, actualRemediationDoneNumber in
 
 	return 0
 }
+
+// QueryTestsExitCode calculate exit code base on the number of queries whose tests failed
+func QueryTestsExitCode(failedQueries int) int {
+	statusCode := 80
+	if failedQueries > 0 {
+		return statusCode
+	}
+
+	return 0
+}
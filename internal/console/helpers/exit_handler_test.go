@@ -62,6 +62,40 @@ var resultsExitCodeTests = []struct {
 		},
 		expectedResult: 60,
 	},
+	{
+		caseTest: resultExitCode{
+			summary: baselineSummaryMock(map[model.Severity]int{model.SeverityMedium: 1}),
+			failOn: map[string]struct{}{
+				"critical": {},
+				"high":     {},
+				"medium":   {},
+				"low":      {},
+				"info":     {},
+			},
+		},
+		expectedResult: 40,
+	},
+	{
+		caseTest: resultExitCode{
+			summary: baselineSummaryMock(map[model.Severity]int{}),
+			failOn: map[string]struct{}{
+				"critical": {},
+				"high":     {},
+				"medium":   {},
+				"low":      {},
+				"info":     {},
+			},
+		},
+		expectedResult: 0,
+	},
+}
+
+func baselineSummaryMock(newSeverityCounters map[model.Severity]int) model.Summary {
+	summary := test.ComplexSummaryMock
+	summary.Baseline = &model.BaselineSummary{
+		NewSeverityCounters: newSeverityCounters,
+	}
+	return summary
 }
 
 func TestExitHandler_ResultsExitCode(t *testing.T) {
@@ -262,3 +296,12 @@ func Test_RemediateNotAll(t *testing.T) {
 		require.Equal(t, statusCode, 70)
 	})
 }
+
+func Test_QueryTestsExitCode(t *testing.T) {
+	t.Run("QueryTestsPassedExitCode", func(t *testing.T) {
+		require.Equal(t, 0, QueryTestsExitCode(0))
+	})
+	t.Run("QueryTestsFailedExitCode", func(t *testing.T) {
+		require.Equal(t, 80, QueryTestsExitCode(3))
+	})
+}
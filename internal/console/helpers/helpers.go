@@ -15,7 +15,10 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/Checkmarx/kics/v2/internal/metrics"
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
+	"github.com/Checkmarx/kics/v2/pkg/diff"
 	"github.com/Checkmarx/kics/v2/pkg/progress"
+	"github.com/Checkmarx/kics/v2/pkg/querytest"
 	"github.com/Checkmarx/kics/v2/pkg/report"
 	"github.com/hashicorp/hcl"
 	"github.com/rs/zerolog"
@@ -23,7 +26,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-const divisor = float32(100000)
+const (
+	divisor            = float32(100000)
+	coverageReportName = "coverage"
+)
 
 var reportGenerators = map[string]func(path, filename string, body interface{}) error{
 	"json":        report.PrintJSONReport,
@@ -39,6 +45,17 @@ var reportGenerators = map[string]func(path, filename string, body interface{})
 	"codeclimate": report.PrintCodeClimateReport,
 }
 
+var diffReportGenerators = map[string]func(path, filename string, body *diff.Report) error{
+	"json":     report.PrintDiffJSONReport,
+	"markdown": report.PrintDiffMarkdownReport,
+	"html":     report.PrintDiffHTMLReport,
+}
+
+var queryTestsReportGenerators = map[string]func(path, filename string, body *querytest.Report) error{
+	"json":  report.PrintQueryTestsJSONReport,
+	"junit": report.PrintQueryTestsJUnitReport,
+}
+
 // CustomConsoleWriter creates an output to print log in a files
 func CustomConsoleWriter(fileLogger *zerolog.ConsoleWriter) zerolog.ConsoleWriter {
 	fileLogger.FormatLevel = func(i interface{}) string {
@@ -119,6 +136,52 @@ func GenerateReport(path, filename string, body interface{}, formats []string, p
 	return err
 }
 
+// GenerateDiffReport generates the diff report in each of the formats provided
+func GenerateDiffReport(path, filename string, body *diff.Report, formats []string) error {
+	log.Debug().Msgf("helpers.GenerateDiffReport()")
+
+	for _, format := range formats {
+		format = strings.ToLower(format)
+		if err := diffReportGenerators[format](path, filename, body); err != nil {
+			log.Error().Msgf("Failed to generate %s diff report", format)
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateQueryTestsReport generates the query tests report in each of the formats provided
+func GenerateQueryTestsReport(path, filename string, body *querytest.Report, formats []string) error {
+	log.Debug().Msgf("helpers.GenerateQueryTestsReport()")
+
+	for _, format := range formats {
+		format = strings.ToLower(format)
+		if err := queryTestsReportGenerators[format](path, filename, body); err != nil {
+			log.Error().Msgf("Failed to generate %s query tests report", format)
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateCoverageReport generates the queries coverage report in JSON and HTML formats in the path provided
+func GenerateCoverageReport(path string, body *coverage.Report) error {
+	log.Debug().Msgf("helpers.GenerateCoverageReport()")
+
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return err
+	}
+	if err := report.PrintCoverageJSONReport(path, coverageReportName, body); err != nil {
+		log.Error().Msg("Failed to generate json coverage report")
+		return err
+	}
+	if err := report.PrintCoverageHTMLReport(path, coverageReportName, body); err != nil {
+		log.Error().Msg("Failed to generate html coverage report")
+		return err
+	}
+	return nil
+}
+
 // GetExecutableDirectory - returns the path to the directory containing KICS executable
 func GetExecutableDirectory() string {
 	log.Debug().Msg("helpers.GetExecutableDirectory()")
@@ -172,6 +235,26 @@ func ListReportFormats() []string {
 	return supportedFormats
 }
 
+// ListDiffReportFormats return a slice with all supported diff report formats
+func ListDiffReportFormats() []string {
+	supportedFormats := make([]string, 0, len(diffReportGenerators))
+	for reportFormats := range diffReportGenerators {
+		supportedFormats = append(supportedFormats, reportFormats)
+	}
+	sort.Strings(supportedFormats)
+	return supportedFormats
+}
+
+// ListQueryTestsReportFormats return a slice with all supported query tests report formats
+func ListQueryTestsReportFormats() []string {
+	supportedFormats := make([]string, 0, len(queryTestsReportGenerators))
+	for reportFormats := range queryTestsReportGenerators {
+		supportedFormats = append(supportedFormats, reportFormats)
+	}
+	sort.Strings(supportedFormats)
+	return supportedFormats
+}
+
 // GetNumCPU return the number of cpus available
 func GetNumCPU() float32 {
 	// Check if application is running inside docker
@@ -44,12 +44,20 @@ func initialize(rootCmd *cobra.Command) error {
 	scanCmd := NewScanCmd()
 	remediateCmd := NewRemediateCmd()
 	analyzeCmd := NewAnalyzeCmd()
+	diffCmd := NewDiffCmd()
+	serveCmd := NewServeCmd()
+	lspCmd := NewLSPCmd()
+	testQueriesCmd := NewTestQueriesCmd()
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewGenerateIDCmd())
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(NewListPlatformsCmd())
 	rootCmd.AddCommand(remediateCmd)
 	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(lspCmd)
+	rootCmd.AddCommand(testQueriesCmd)
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	if err := flags.InitJSONFlags(
@@ -73,6 +81,22 @@ func initialize(rootCmd *cobra.Command) error {
 		return err
 	}
 
+	if err := initDiffCmd(diffCmd); err != nil {
+		return err
+	}
+
+	if err := initServeCmd(serveCmd); err != nil {
+		return err
+	}
+
+	if err := initLSPCmd(lspCmd); err != nil {
+		return err
+	}
+
+	if err := initTestQueriesCmd(testQueriesCmd); err != nil {
+		return err
+	}
+
 	return initScanCmd(scanCmd)
 }
 
@@ -225,6 +225,23 @@ func TestConsole_Execute(t *testing.T) { //nolint
 			remove:                   "analyze-results.json",
 			rewriteRemediateTestFile: false,
 		},
+		{
+			name: "test_kics_diff",
+			args: []string{"kics", "diff", "--old-results", filepath.FromSlash("../../test/assets/results_for_ar.json"),
+				"--new-results", filepath.FromSlash("../../test/assets/results_for_ar.json"),
+				"--diff-output-path", "diff-results", "--diff-report-formats", "json,markdown,html"},
+			wantErr:                  false,
+			remove:                   "diff-results",
+			rewriteRemediateTestFile: false,
+		},
+		{
+			name: "test_kics_fail_diff_invalid_json",
+			args: []string{"kics", "diff", "--old-results", filepath.FromSlash("../../test/assets/invalid.json"),
+				"--new-results", filepath.FromSlash("../../test/assets/results_for_ar.json")},
+			wantErr:                  true,
+			remove:                   "",
+			rewriteRemediateTestFile: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,114 @@
+package console
+
+import (
+	"context"
+	_ "embed" // Embed lsp flags
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Checkmarx/kics/v2/internal/console/flags"
+	"github.com/Checkmarx/kics/v2/pkg/api"
+	"github.com/Checkmarx/kics/v2/pkg/engine/source"
+	"github.com/Checkmarx/kics/v2/pkg/lsp"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	internalPrinter "github.com/Checkmarx/kics/v2/pkg/printer"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	//go:embed assets/lsp-flags.json
+	lspFlagsListContent string
+)
+
+// NewLSPCmd creates a new instance of the lsp Command
+func NewLSPCmd() *cobra.Command {
+	var protocolOutput io.Writer
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "Starts a Language Server Protocol server over stdio publishing results as diagnostics",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// stdout carries the protocol, anything else printed there would corrupt it
+			protocolOutput = os.Stdout
+			os.Stdout = os.Stderr
+			return preLSP(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLSP(cmd, protocolOutput)
+		},
+	}
+}
+
+func initLSPCmd(lspCmd *cobra.Command) error {
+	return flags.InitJSONFlags(
+		lspCmd,
+		lspFlagsListContent,
+		false,
+		source.ListSupportedPlatforms(),
+		source.ListSupportedCloudProviders())
+}
+
+func preLSP(cmd *cobra.Command) error {
+	if err := flags.Validate(); err != nil {
+		return err
+	}
+	if err := internalPrinter.SetupPrinter(cmd.InheritedFlags()); err != nil {
+		return errors.New(initError + err.Error())
+	}
+	return nil
+}
+
+func runLSP(cmd *cobra.Command, protocolOutput io.Writer) error {
+	opts, err := getScannerOptions(cmd.Flags().Lookup(flags.QueriesPath).Changed)
+	if err != nil {
+		return err
+	}
+
+	signalCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// the queries are loaded while the client initializes, scans wait for them to be ready
+	scanner := newLoadingScanner(signalCtx, opts)
+	err = lsp.NewServer(scanner, lsp.DefaultDebounce).Serve(signalCtx, os.Stdin, protocolOutput)
+	if err != nil && signalCtx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// loadingScanner is an api.Scanner created in the background
+type loadingScanner struct {
+	ready   chan struct{}
+	scanner *api.Scanner
+	err     error
+}
+
+func newLoadingScanner(ctx context.Context, opts api.Options) *loadingScanner {
+	s := &loadingScanner{ready: make(chan struct{})}
+	go func() {
+		defer close(s.ready)
+		log.Info().Msg("Loading queries")
+		s.scanner, s.err = api.NewScanner(ctx, opts)
+		if s.err != nil {
+			log.Error().Msgf("Failed to load queries: %s", s.err)
+			return
+		}
+		log.Info().Msgf("Queries loaded, number of queries=%d", s.scanner.LoadedQueries())
+	}()
+	return s
+}
+
+func (s *loadingScanner) Scan(ctx context.Context, files []api.InMemoryFile) (model.Summary, error) {
+	select {
+	case <-ctx.Done():
+		return model.Summary{}, ctx.Err()
+	case <-s.ready:
+	}
+	if s.err != nil {
+		return model.Summary{}, s.err
+	}
+	return s.scanner.Scan(ctx, files)
+}
@@ -130,6 +130,7 @@ func getScanParameters(changedDefaultQueryPath, changedDefaultLibrariesPath bool
 		Platform:                    flags.GetMultiStrFlag(flags.TypeFlag),
 		ExcludePlatform:             flags.GetMultiStrFlag(flags.ExcludeTypeFlag),
 		TerraformVarsPath:           flags.GetStrFlag(flags.TerraformVarsPathFlag),
+		CloudFormationParamsPath:    flags.GetStrFlag(flags.CFNParametersPathFlag),
 		QueryExecTimeout:            flags.GetIntFlag(flags.QueryExecTimeoutFlag),
 		LineInfoPayload:             flags.GetBoolFlag(flags.LineInfoPayloadFlag),
 		DisableSecrets:              flags.GetBoolFlag(flags.DisableSecretsFlag),
@@ -145,6 +146,13 @@ func getScanParameters(changedDefaultQueryPath, changedDefaultLibrariesPath bool
 		UseOldSeverities:            flags.GetBoolFlag(flags.UseOldSeveritiesFlag),
 		MaxResolverDepth:            flags.GetIntFlag(flags.MaxResolverDepth),
 		KicsComputeNewSimID:         flags.GetBoolFlag(flags.KicsComputeNewSimIDFlag),
+		BaselinePath:                flags.GetStrFlag(flags.BaselineFlag),
+		GitDiffBase:                 flags.GetStrFlag(flags.GitDiffBaseFlag),
+		CacheDir:                    flags.GetStrFlag(flags.CacheDirFlag),
+		CoveragePath:                flags.GetStrFlag(flags.CoveragePathFlag),
+		HelmValues:                  flags.GetMultiStrFlag(flags.HelmValuesFlag),
+		HelmSet:                     flags.GetMultiStrFlag(flags.HelmSetFlag),
+		HelmEnvironments:            flags.GetMultiStrFlag(flags.HelmEnvironmentsFlag),
 	}
 
 	return &scanParams
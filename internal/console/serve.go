@@ -0,0 +1,167 @@
+package console
+
+import (
+	"context"
+	_ "embed" // Embed serve flags
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Checkmarx/kics/v2/internal/console/flags"
+	consoleHelpers "github.com/Checkmarx/kics/v2/internal/console/helpers"
+	"github.com/Checkmarx/kics/v2/pkg/api"
+	"github.com/Checkmarx/kics/v2/pkg/engine/source"
+	internalPrinter "github.com/Checkmarx/kics/v2/pkg/printer"
+	"github.com/Checkmarx/kics/v2/pkg/server"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	serveReadHeaderTimeout = 10 * time.Second
+	serveShutdownTimeout   = 30 * time.Second
+)
+
+var (
+	//go:embed assets/serve-flags.json
+	serveFlagsListContent string
+)
+
+// NewServeCmd creates a new instance of the serve Command
+func NewServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Starts an HTTP server scanning files with queries loaded once",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return preServe(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve(cmd)
+		},
+	}
+}
+
+func initServeCmd(serveCmd *cobra.Command) error {
+	return flags.InitJSONFlags(
+		serveCmd,
+		serveFlagsListContent,
+		false,
+		source.ListSupportedPlatforms(),
+		source.ListSupportedCloudProviders())
+}
+
+func preServe(cmd *cobra.Command) error {
+	if err := flags.Validate(); err != nil {
+		return err
+	}
+	if err := internalPrinter.SetupPrinter(cmd.InheritedFlags()); err != nil {
+		return errors.New(initError + err.Error())
+	}
+	return nil
+}
+
+func serve(cmd *cobra.Command) error {
+	opts, err := getScannerOptions(cmd.Flags().Lookup(flags.QueriesPath).Changed)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("Loading queries")
+	scanner, err := api.NewScanner(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Queries loaded, number of queries=%d", scanner.LoadedQueries())
+
+	return executeServe(
+		flags.GetStrFlag(flags.ServeAddressFlag),
+		server.New(scanner, int64(flags.GetIntFlag(flags.ServeMaxRequestSizeFlag))*1024*1024))
+}
+
+// getScannerOptions returns the options of the scanner kept by the serve and lsp commands
+func getScannerOptions(changedDefaultQueryPath bool) (api.Options, error) {
+	queriesPath := flags.GetMultiStrFlag(flags.QueriesPath)
+	if !changedDefaultQueryPath {
+		defaultQueryPath, err := consoleHelpers.GetDefaultQueryPath(queriesPath[0])
+		if err != nil {
+			return api.Options{}, err
+		}
+		queriesPath = []string{defaultQueryPath}
+	}
+
+	secretsRegexRules := ""
+	if secretsRegexesPath := flags.GetStrFlag(flags.SecretsRegexesPathFlag); secretsRegexesPath != "" {
+		content, err := os.ReadFile(filepath.Clean(secretsRegexesPath))
+		if err != nil {
+			return api.Options{}, err
+		}
+		secretsRegexRules = string(content)
+	}
+
+	return api.Options{
+		QueriesPath:              queriesPath,
+		LibrariesPath:            flags.GetStrFlag(flags.LibrariesPath),
+		Platforms:                flags.GetMultiStrFlag(flags.TypeFlag),
+		CloudProviders:           flags.GetMultiStrFlag(flags.CloudProviderFlag),
+		IncludeQueries:           flags.GetMultiStrFlag(flags.IncludeQueriesFlag),
+		ExcludeQueries:           flags.GetMultiStrFlag(flags.ExcludeQueriesFlag),
+		ExcludeCategories:        flags.GetMultiStrFlag(flags.ExcludeCategoriesFlag),
+		ExcludeSeverities:        flags.GetMultiStrFlag(flags.ExcludeSeveritiesFlag),
+		ExcludeResults:           flags.GetMultiStrFlag(flags.ExcludeResultsFlag),
+		ExperimentalQueries:      flags.GetBoolFlag(flags.ExperimentalQueriesFlag),
+		BillOfMaterials:          flags.GetBoolFlag(flags.BomFlag),
+		InputDataPath:            flags.GetStrFlag(flags.InputDataFlag),
+		DisableSecrets:           flags.GetBoolFlag(flags.DisableSecretsFlag),
+		SecretsRegexRules:        secretsRegexRules,
+		QueryExecTimeout:         flags.GetIntFlag(flags.QueryExecTimeoutFlag),
+		PreviewLines:             flags.GetIntFlag(flags.PreviewLinesFlag),
+		MaxFileSize:              flags.GetIntFlag(flags.MaxFileSizeFlag),
+		Workers:                  flags.GetIntFlag(flags.ParallelScanFile),
+		UseOldSeverities:         flags.GetBoolFlag(flags.UseOldSeveritiesFlag),
+		ComputeNewSimilarityID:   flags.GetBoolFlag(flags.KicsComputeNewSimIDFlag),
+		OpenAPIResolveReferences: flags.GetBoolFlag(flags.OpenAPIReferencesFlag),
+		MaxResolverDepth:         flags.GetIntFlag(flags.MaxResolverDepth),
+		TerraformVarsPath:        flags.GetStrFlag(flags.TerraformVarsPathFlag),
+		CloudFormationParamsPath: flags.GetStrFlag(flags.CFNParametersPathFlag),
+	}, nil
+}
+
+// executeServe serves the handler until an interrupt or termination signal is received, waiting for the
+// requests being handled to finish
+func executeServe(address string, handler http.Handler) error {
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+	}
+
+	signalCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info().Msgf("KICS server listening on %s", address)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-signalCtx.Done():
+	}
+
+	log.Info().Msg("Shutting down KICS server")
+	shutdownCtx, cancel := context.WithTimeout(ctx, serveShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package console
+
+import (
+	_ "embed" // Embed test-queries flags
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/internal/console/flags"
+	consoleHelpers "github.com/Checkmarx/kics/v2/internal/console/helpers"
+	"github.com/Checkmarx/kics/v2/pkg/engine/source"
+	internalPrinter "github.com/Checkmarx/kics/v2/pkg/printer"
+	"github.com/Checkmarx/kics/v2/pkg/querytest"
+	"github.com/Checkmarx/kics/v2/pkg/querytest/runner"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	//go:embed assets/test-queries-flags.json
+	testQueriesFlagsListContent string
+)
+
+// NewTestQueriesCmd creates a new instance of the test-queries Command
+func NewTestQueriesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "test-queries",
+		Short: "Tests queries against the positive and negative samples of their test directory",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return preTestQueries(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return testQueries(cmd)
+		},
+	}
+}
+
+func initTestQueriesCmd(testQueriesCmd *cobra.Command) error {
+	return flags.InitJSONFlags(
+		testQueriesCmd,
+		testQueriesFlagsListContent,
+		false,
+		source.ListSupportedPlatforms(),
+		source.ListSupportedCloudProviders())
+}
+
+func preTestQueries(cmd *cobra.Command) error {
+	if err := flags.Validate(); err != nil {
+		return err
+	}
+	if err := internalPrinter.SetupPrinter(cmd.InheritedFlags()); err != nil {
+		return errors.New(initError + err.Error())
+	}
+	return nil
+}
+
+func testQueries(cmd *cobra.Command) error {
+	testParams, err := getTestQueriesParameters(cmd.Flags().Lookup(flags.QueriesPath).Changed)
+	if err != nil {
+		return err
+	}
+	return executeTestQueries(testParams)
+}
+
+func getTestQueriesParameters(changedDefaultQueryPath bool) (*querytest.Parameters, error) {
+	queriesPath := flags.GetMultiStrFlag(flags.QueriesPath)
+	if !changedDefaultQueryPath {
+		defaultQueryPath, err := consoleHelpers.GetDefaultQueryPath(queriesPath[0])
+		if err != nil {
+			return nil, err
+		}
+		queriesPath = []string{defaultQueryPath}
+	}
+
+	return &querytest.Parameters{
+		QueriesPath:   queriesPath,
+		LibrariesPath: flags.GetStrFlag(flags.LibrariesPath),
+		OutputPath:    flags.GetStrFlag(flags.TestQueriesOutputPath),
+		OutputName:    flags.GetStrFlag(flags.TestQueriesOutputName),
+		ReportFormats: flags.GetMultiStrFlag(flags.TestQueriesReportFormats),
+		CoveragePath:  flags.GetStrFlag(flags.CoveragePathFlag),
+	}, nil
+}
+
+func executeTestQueries(testParams *querytest.Parameters) error {
+	log.Debug().Msg("console.testQueries()")
+
+	queryDirs, err := runner.FindQueries(testParams.QueriesPath)
+	if err != nil {
+		return err
+	}
+	if len(queryDirs) == 0 {
+		return fmt.Errorf("no queries found in %s", strings.Join(testParams.QueriesPath, ", "))
+	}
+
+	log.Info().Msgf("Testing %d queries", len(queryDirs))
+	report, coverageReport := runner.Run(ctx, queryDirs, testParams.LibrariesPath, testParams.CoveragePath != "")
+	report.QueriesPath = testParams.QueriesPath
+	printQueryTests(report)
+
+	if coverageReport != nil {
+		if err := consoleHelpers.GenerateCoverageReport(testParams.CoveragePath, coverageReport); err != nil {
+			return err
+		}
+	}
+
+	if testParams.OutputPath != "" {
+		if err := os.MkdirAll(testParams.OutputPath, os.ModePerm); err != nil {
+			return err
+		}
+		formats := testParams.ReportFormats
+		if len(formats) == 0 {
+			formats = []string{"json"}
+		}
+		if err := consoleHelpers.GenerateQueryTestsReport(testParams.OutputPath, testParams.OutputName, report,
+			formats); err != nil {
+			return err
+		}
+	}
+
+	exitCode := consoleHelpers.QueryTestsExitCode(report.Counters.Failed)
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+
+	return nil
+}
+
+func printQueryTests(report *querytest.Report) {
+	for i := range report.Queries {
+		query := &report.Queries[i]
+		if query.Passed {
+			continue
+		}
+		fmt.Printf("\nFAIL %s\n", query.Dir)
+		for j := range query.Cases {
+			testCase := &query.Cases[j]
+			if testCase.Passed {
+				continue
+			}
+			if testCase.Error != "" {
+				fmt.Printf("  %s: %s\n", testCase.Name, testCase.Error)
+				continue
+			}
+			for _, result := range testCase.Missing {
+				fmt.Printf("  %s: missing %s\n", testCase.Name, result.String())
+			}
+			for _, result := range testCase.Unexpected {
+				fmt.Printf("  %s: unexpected %s\n", testCase.Name, result.String())
+			}
+		}
+	}
+
+	fmt.Printf("\nQueries tested: %d\n", report.Counters.Total)
+	fmt.Printf("Passed: %d\n", report.Counters.Passed)
+	fmt.Printf("Failed: %d\n\n", report.Counters.Failed)
+}
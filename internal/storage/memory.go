@@ -46,13 +46,19 @@ func (m *MemoryStorage) GetVulnerabilities(_ context.Context, _ string) ([]model
 func (m *MemoryStorage) getUniqueVulnerabilities() []model.Vulnerability {
 	vulnDictionary := make(map[string]model.Vulnerability)
 	for i := range m.vulnerabilities {
-		key := fmt.Sprintf("%s:%s:%d:%s:%s:%s",
+		modulePath := ""
+		if m.vulnerabilities[i].ModuleCall != nil {
+			modulePath = m.vulnerabilities[i].ModuleCall.Path
+		}
+		key := fmt.Sprintf("%s:%s:%d:%s:%s:%s:%s:%s",
 			m.vulnerabilities[i].QueryID,
 			m.vulnerabilities[i].FileName,
 			m.vulnerabilities[i].Line,
 			m.vulnerabilities[i].SimilarityID,
 			m.vulnerabilities[i].SearchKey,
 			m.vulnerabilities[i].KeyActualValue,
+			modulePath,
+			m.vulnerabilities[i].Environment,
 		)
 		vulnDictionary[key] = m.vulnerabilities[i]
 	}
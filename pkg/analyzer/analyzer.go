@@ -66,6 +66,7 @@ var (
 	cicdOnRegex                                     = regexp.MustCompile(`\s*on:\s*`)
 	cicdJobsRegex                                   = regexp.MustCompile(`\s*jobs:\s*`)
 	cicdStepsRegex                                  = regexp.MustCompile(`\s*steps:\s*`)
+	cicdFileRegex                                   = regexp.MustCompile(`(^|\.)gitlab-ci\.ya?ml$|^azure-pipelines.*\.ya?ml$|^bitbucket-pipelines\.ya?ml$`)
 	queryRegexPathsAnsible                          = regexp.MustCompile(fmt.Sprintf(`^.*?%s(?:group|host)_vars%s.*$`, regexp.QuoteMeta(string(os.PathSeparator)), regexp.QuoteMeta(string(os.PathSeparator)))) //nolint:lll
 )
 
@@ -127,6 +128,7 @@ const (
 	dockerfile = "dockerfile"
 	crossplane = "crossplane"
 	knative    = "knative"
+	cicd       = "cicd"
 	sizeMb     = 1048576
 )
 
@@ -512,6 +514,10 @@ func (a *analyzerInfo) checkContent(results, unwanted chan<- string, locCount ch
 }
 
 func checkReturnType(path, returnType, ext string, content []byte) string {
+	// GitLab CI, Azure Pipelines and Bitbucket Pipelines files are identified by their name
+	if cicdFileRegex.MatchString(filepath.Base(path)) {
+		return cicd
+	}
 	if returnType != "" {
 		if returnType == "cdkTf" {
 			return terraform
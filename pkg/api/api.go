@@ -0,0 +1,390 @@
+// Package api exposes a stable API to embed KICS in Go programs. Scans run over files held in memory,
+// without reading the scanned files or the files they reference from disk, printing to the console or
+// writing reports. Helm charts and Kustomize directories are not rendered
+package api
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Checkmarx/kics/v2/assets"
+	"github.com/Checkmarx/kics/v2/internal/constants"
+	"github.com/Checkmarx/kics/v2/internal/storage"
+	"github.com/Checkmarx/kics/v2/internal/tracker"
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
+	"github.com/Checkmarx/kics/v2/pkg/engine"
+	"github.com/Checkmarx/kics/v2/pkg/engine/provider"
+	"github.com/Checkmarx/kics/v2/pkg/engine/secrets"
+	"github.com/Checkmarx/kics/v2/pkg/engine/source"
+	"github.com/Checkmarx/kics/v2/pkg/kics"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser"
+	ansibleConfigParser "github.com/Checkmarx/kics/v2/pkg/parser/ansible/ini/config"
+	ansibleHostsParser "github.com/Checkmarx/kics/v2/pkg/parser/ansible/ini/hosts"
+	bicepParser "github.com/Checkmarx/kics/v2/pkg/parser/bicep"
+	buildahParser "github.com/Checkmarx/kics/v2/pkg/parser/buildah"
+	"github.com/Checkmarx/kics/v2/pkg/parser/cloudformation"
+	dockerParser "github.com/Checkmarx/kics/v2/pkg/parser/docker"
+	protoParser "github.com/Checkmarx/kics/v2/pkg/parser/grpc"
+	jsonParser "github.com/Checkmarx/kics/v2/pkg/parser/json"
+	terraformParser "github.com/Checkmarx/kics/v2/pkg/parser/terraform"
+	yamlParser "github.com/Checkmarx/kics/v2/pkg/parser/yaml"
+	"github.com/Checkmarx/kics/v2/pkg/progress"
+	"github.com/Checkmarx/kics/v2/pkg/scanner"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultQueryExecTimeout = 60
+	defaultPreviewLines     = 3
+	defaultMaxFileSize      = 5
+	defaultMaxResolverDepth = 15
+)
+
+// ErrNoQueries is returned when neither a queries path nor a queries source is provided
+var ErrNoQueries = errors.New("queries path or queries source must be provided")
+
+// InMemoryFile is a file to be scanned. The path is only used to detect the file type, to find the files
+// it references, such as Terraform variables and local modules, and to report the results
+type InMemoryFile struct {
+	Path    string
+	Content []byte
+}
+
+// Options are the settings of a scan, zero values fall back to the defaults of the scan command
+type Options struct {
+	// QueriesPath are the directories the queries are loaded from, ignored when QueriesSource is set
+	QueriesPath []string
+	// QueriesSource loads the queries and libraries, when queries are not read from QueriesPath
+	QueriesSource source.QueriesSource
+	// LibrariesPath is the directory with custom libraries, the embedded libraries are used when empty
+	LibrariesPath string
+	// Platforms restricts the queries and parsers to the given platforms, loading fewer queries
+	Platforms []string
+	// CloudProviders restricts the queries to the given cloud providers
+	CloudProviders []string
+
+	IncludeQueries      []string
+	ExcludeQueries      []string
+	ExcludeCategories   []string
+	ExcludeSeverities   []string
+	ExcludeResults      []string
+	ExperimentalQueries bool
+	BillOfMaterials     bool
+	InputDataPath       string
+
+	// DisableSecrets disables secrets scanning, unlike the scan command previews are not masked
+	DisableSecrets bool
+	// SecretsRegexRules is the content of a secrets regex rules file, the embedded rules are used when empty
+	SecretsRegexRules string
+
+	// QueryExecTimeout is the number of seconds a query has to execute
+	QueryExecTimeout int
+	// PreviewLines is the number of lines included in the results
+	PreviewLines int
+	// MaxFileSize is the max file size permitted for scanning, in MB
+	MaxFileSize int
+	// Workers is the number of workers per platform, 0 auto-detects the optimal number
+	Workers int
+
+	UseOldSeverities         bool
+	ComputeNewSimilarityID   bool
+	OpenAPIResolveReferences bool
+	MaxResolverDepth         int
+	// TerraformVarsPath and CloudFormationParamsPath are paths of scanned files, they are not read from disk
+	TerraformVarsPath        string
+	CloudFormationParamsPath string
+
+	// CoverageReport records the lines of the queries exercised by the scans, see Scanner.CoverageReport
+	CoverageReport bool
+
+	// ScanID identifies the scans, a random one is generated for each scan when empty
+	ScanID string
+}
+
+// Scanner scans files held in memory with queries loaded and compiled once, its scans are isolated from
+// each other and it is safe for concurrent use
+type Scanner struct {
+	opts           Options
+	inspector      *engine.Inspector
+	queryFilter    *source.QueryInspectorParameters
+	excludeResults map[string]bool
+	loadedQueries  int
+}
+
+// NewScanner loads and compiles the queries, which dominates the time taken by scans of few files
+func NewScanner(ctx context.Context, opts Options) (*Scanner, error) { //nolint:gocritic
+	setDefaults(&opts)
+
+	t, err := tracker.NewTracker(opts.PreviewLines)
+	if err != nil {
+		return nil, err
+	}
+
+	querySource, err := getQueriesSource(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	queryFilter := &source.QueryInspectorParameters{
+		IncludeQueries: source.IncludeQueries{
+			ByIDs: opts.IncludeQueries,
+		},
+		ExcludeQueries: source.ExcludeQueries{
+			ByIDs:        opts.ExcludeQueries,
+			ByCategories: opts.ExcludeCategories,
+			BySeverities: opts.ExcludeSeverities,
+		},
+		ExperimentalQueries: opts.ExperimentalQueries,
+		InputDataPath:       opts.InputDataPath,
+		BomQueries:          opts.BillOfMaterials,
+	}
+
+	excludeResults := make(map[string]bool, len(opts.ExcludeResults))
+	for _, result := range opts.ExcludeResults {
+		excludeResults[result] = true
+	}
+
+	inspector, err := engine.NewInspector(ctx,
+		querySource,
+		engine.DefaultVulnerabilityBuilder,
+		t,
+		queryFilter,
+		excludeResults,
+		opts.QueryExecTimeout,
+		opts.UseOldSeverities,
+		false,
+		opts.Workers,
+		opts.ComputeNewSimilarityID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := inspector.KeepPreparedQueries(ctx); err != nil {
+		return nil, err
+	}
+	if opts.CoverageReport {
+		inspector.EnableCoverageReport()
+	}
+
+	return &Scanner{
+		opts:           opts,
+		inspector:      inspector,
+		queryFilter:    queryFilter,
+		excludeResults: excludeResults,
+		loadedQueries:  t.LoadedQueries,
+	}, nil
+}
+
+// LoadedQueries returns the number of queries loaded by the scanner
+func (s *Scanner) LoadedQueries() int {
+	return s.loadedQueries
+}
+
+// CoverageReport returns the coverage of the queries evaluated by all the scans of the scanner, it is empty
+// unless the CoverageReport option is set
+func (s *Scanner) CoverageReport() *coverage.Report {
+	return s.inspector.GetQueriesCoverageReport()
+}
+
+// Scan scans the given files and returns the summary of the results
+func (s *Scanner) Scan(ctx context.Context, files []InMemoryFile) (model.Summary, error) {
+	start := time.Now()
+	scanID := s.opts.ScanID
+	if scanID == "" {
+		scanID = uuid.New().String()
+	}
+
+	t, err := tracker.NewTracker(s.opts.PreviewLines)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	secretsInspector, err := secrets.NewInspector(
+		ctx,
+		s.excludeResults,
+		t,
+		s.queryFilter,
+		s.opts.DisableSecrets,
+		s.opts.QueryExecTimeout,
+		s.opts.SecretsRegexRules,
+		s.opts.SecretsRegexRules != assets.SecretsQueryRegexRulesJSON,
+	)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	filesSource := newFilesSource(files)
+	store := storage.NewMemoryStorage()
+
+	services, err := createServices(&s.opts, filesSource, s.inspector.WithTracker(t), secretsInspector, t, store)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	if err := scanner.PrepareAndScan(ctx, scanID, s.opts.OpenAPIResolveReferences, s.opts.MaxResolverDepth,
+		*progress.InitializePbBuilder(true, false, true), services); err != nil {
+		return model.Summary{}, err
+	}
+
+	results, err := store.GetVulnerabilities(ctx, scanID)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	return getSummary(scanID, t, results, files, start), nil
+}
+
+// Scan scans the given files and returns the summary of the results. Each call loads the queries again,
+// so restricting the Platforms reduces the time taken by scans of few files, a Scanner should be used
+// instead to run several scans with the same options
+func Scan(ctx context.Context, opts Options, files []InMemoryFile) (model.Summary, error) { //nolint:gocritic
+	s, err := NewScanner(ctx, opts)
+	if err != nil {
+		return model.Summary{}, err
+	}
+	return s.Scan(ctx, files)
+}
+
+func setDefaults(opts *Options) {
+	if opts.QueryExecTimeout <= 0 {
+		opts.QueryExecTimeout = defaultQueryExecTimeout
+	}
+	if opts.PreviewLines == 0 {
+		opts.PreviewLines = defaultPreviewLines
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = defaultMaxFileSize
+	}
+	if opts.MaxResolverDepth <= 0 {
+		opts.MaxResolverDepth = defaultMaxResolverDepth
+	}
+	if opts.LibrariesPath == "" {
+		opts.LibrariesPath = source.LibrariesDefaultBasePath
+	}
+	if opts.SecretsRegexRules == "" {
+		opts.SecretsRegexRules = assets.SecretsQueryRegexRulesJSON
+	}
+	opts.Platforms = orAll(opts.Platforms)
+	// bicep files are scanned with the azure resource manager queries
+	if containsFold(opts.Platforms, "bicep") && !containsFold(opts.Platforms, "azureresourcemanager") {
+		opts.Platforms = append(append([]string{}, opts.Platforms...), "azureresourcemanager")
+	}
+	opts.CloudProviders = orAll(opts.CloudProviders)
+}
+
+// orAll returns the values or, when empty, the value the query source and parsers take as all of them
+func orAll(values []string) []string {
+	if len(values) == 0 {
+		return []string{""}
+	}
+	return values
+}
+
+func getQueriesSource(opts *Options) (source.QueriesSource, error) {
+	if opts.QueriesSource != nil {
+		return opts.QueriesSource, nil
+	}
+	if len(opts.QueriesPath) == 0 {
+		return nil, ErrNoQueries
+	}
+
+	return source.NewFilesystemSource(
+		opts.QueriesPath,
+		opts.Platforms,
+		opts.CloudProviders,
+		opts.LibrariesPath,
+		opts.ExperimentalQueries), nil
+}
+
+func newFilesSource(files []InMemoryFile) *provider.MemorySourceProvider {
+	contents := make(map[string][]byte, len(files))
+	for i := range files {
+		contents[files[i].Path] = files[i].Content
+	}
+	return provider.NewMemorySourceProvider(contents)
+}
+
+func createServices(
+	opts *Options,
+	filesSource *provider.MemorySourceProvider,
+	inspector *engine.Inspector,
+	secretsInspector *secrets.Inspector,
+	t kics.Tracker,
+	store kics.Storage) ([]*kics.Service, error) {
+	cfnParameters, err := cloudformation.LoadParameters(filesSource, opts.CloudFormationParamsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	combinedParser, err := parser.NewBuilder().
+		WithFileSystem(filesSource).
+		Add(jsonParser.NewWithCloudFormationParameters(cfnParameters)).
+		Add(yamlParser.NewWithCloudFormationParameters(cfnParameters)).
+		Add(terraformParser.NewDefaultWithFileSystem(opts.TerraformVarsPath, filesSource)).
+		Add(&bicepParser.Parser{}).
+		Add(&dockerParser.Parser{}).
+		Add(&protoParser.Parser{}).
+		Add(&buildahParser.Parser{}).
+		Add(&ansibleConfigParser.Parser{}).
+		Add(&ansibleHostsParser.Parser{}).
+		Build(opts.Platforms, opts.CloudProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*kics.Service, 0, len(combinedParser))
+	for _, parser := range combinedParser {
+		services = append(services, &kics.Service{
+			SourceProvider:   filesSource,
+			Storage:          store,
+			Parser:           parser,
+			Inspector:        inspector,
+			SecretsInspector: secretsInspector,
+			Tracker:          t,
+			MaxFileSize:      opts.MaxFileSize,
+		})
+	}
+	return services, nil
+}
+
+func getSummary(scanID string, t *tracker.CITracker, results []model.Vulnerability, files []InMemoryFile,
+	start time.Time) model.Summary {
+	counters := model.Counters{
+		ScannedFiles:           t.FoundFiles,
+		ScannedFilesLines:      t.FoundCountLines,
+		ParsedFilesLines:       t.ParsedCountLines,
+		ParsedFiles:            t.ParsedFiles,
+		IgnoredFilesLines:      t.IgnoreCountLines,
+		TotalQueries:           t.LoadedQueries,
+		FailedToExecuteQueries: t.ExecutingQueries - t.ExecutedQueries,
+		FailedSimilarityID:     t.FailedSimilarityID,
+	}
+
+	summary := model.CreateSummary(counters, results, scanID, nil, t.Version)
+	summary.Version = constants.Version
+	summary.Times = model.Times{
+		Start: start,
+		End:   time.Now(),
+	}
+
+	summary.ScannedPaths = make([]string, 0, len(files))
+	for i := range files {
+		summary.ScannedPaths = append(summary.ScannedPaths, files[i].Path)
+	}
+	sort.Strings(summary.ScannedPaths)
+
+	return summary
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	queriesPath       = "../../assets/queries"
+	s3BucketACLQuery  = "38c5ee0d-7f22-4260-ab72-5073048df100"
+	missingUserQuery  = "fd54f200-402c-4333-a5a4-36ef6709af2f"
+	privilegedCompose = "ae5b6871-7f45-42e0-bb4c-ab300c4d2026"
+	websiteHTTPSQuery = "488847ff-6031-487c-bf42-98fd6ac5c9a0"
+	privilegedPod     = "dd29336b-fe57-445b-a26e-e6aa867ae609"
+	s3BucketVariables = `
+variable "acl" {
+  type = string
+}
+
+resource "aws_s3_bucket" "b" {
+  bucket = "my-tf-test-bucket"
+  acl    = var.acl
+}
+`
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		files       []InMemoryFile
+		wantFiles   map[string]int
+		wantResults int
+		wantScanned int
+	}{
+		{
+			name: "should resolve terraform variables from in memory tfvars",
+			opts: Options{
+				Platforms:      []string{"Terraform"},
+				IncludeQueries: []string{s3BucketACLQuery},
+			},
+			files: []InMemoryFile{
+				{Path: "infra/main.tf", Content: []byte(s3BucketVariables)},
+				{Path: "infra/terraform.tfvars", Content: []byte(`acl = "public-read"`)},
+			},
+			wantFiles:   map[string]int{"infra/main.tf": 8},
+			wantResults: 1,
+			wantScanned: 2,
+		},
+		{
+			name: "should resolve terraform local modules from in memory files",
+			opts: Options{
+				Platforms:      []string{"Terraform"},
+				IncludeQueries: []string{s3BucketACLQuery},
+			},
+			files: []InMemoryFile{
+				{Path: "infra/main.tf", Content: []byte(`
+module "bucket" {
+  source = "./modules/bucket"
+  acl    = "public-read"
+}
+`)},
+				{Path: "infra/modules/bucket/main.tf", Content: []byte(s3BucketVariables)},
+			},
+			wantFiles:   map[string]int{"infra/modules/bucket/main.tf": 8},
+			wantResults: 1,
+			wantScanned: 2,
+		},
+		{
+			name: "should report the results of a called module file once per module call",
+			opts: Options{
+				Platforms:      []string{"Terraform"},
+				IncludeQueries: []string{s3BucketACLQuery},
+			},
+			files: []InMemoryFile{
+				{Path: "infra/main.tf", Content: []byte(`
+module "bucket" {
+  source = "./modules/bucket"
+}
+`)},
+				{Path: "infra/modules/bucket/main.tf", Content: []byte(`
+variable "acl" {
+  default = "public-read"
+}
+
+resource "aws_s3_bucket" "b" {
+  bucket = "my-tf-test-bucket"
+  acl    = var.acl
+}
+`)},
+			},
+			wantFiles:   map[string]int{"infra/modules/bucket/main.tf": 8},
+			wantResults: 1,
+			wantScanned: 2,
+		},
+		{
+			name: "should detect dockerfiles without extension from their content",
+			opts: Options{
+				Platforms:      []string{"Dockerfile"},
+				IncludeQueries: []string{missingUserQuery},
+			},
+			files: []InMemoryFile{
+				{Path: "images/base", Content: []byte("FROM alpine:3.18\nRUN apk add --no-cache curl\n")},
+				{Path: "images/notes", Content: []byte("not a dockerfile\n")},
+			},
+			wantFiles:   map[string]int{"images/base": 1},
+			wantResults: 1,
+			wantScanned: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.QueriesPath = []string{queriesPath}
+			summary, err := Scan(context.Background(), tt.opts, tt.files)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantScanned, summary.ScannedFiles)
+			require.Equal(t, tt.wantScanned, summary.ParsedFiles)
+
+			got := make(map[string]int)
+			results := 0
+			for i := range summary.Queries {
+				for j := range summary.Queries[i].Files {
+					got[summary.Queries[i].Files[j].FileName] = summary.Queries[i].Files[j].Line
+					results++
+				}
+			}
+			require.Equal(t, tt.wantFiles, got)
+			require.Equal(t, tt.wantResults, results)
+		})
+	}
+}
+
+// diskFiles are the files referenced by the scanned files that TestScan_NoDiskReads writes to the working
+// directory, any of them read during the scan would add a result
+var diskFiles = map[string]string{
+	"compose/common.yml": "services:\n  base:\n    image: nginx\n    privileged: true\n",
+	"arm/linked.json": `{"contentVersion": "1.0.0.0", "resources": [{"type": "Microsoft.Web/sites",
+		"apiVersion": "2020-12-01", "name": "site", "properties": {}}]}`,
+	"chart/templates/pod.yaml": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\nspec:\n  containers:\n" +
+		"    - name: app\n      image: nginx\n      securityContext:\n        privileged: true\n",
+}
+
+func TestScan_NoDiskReads(t *testing.T) {
+	queries, err := filepath.Abs(queriesPath)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	for name, content := range diskFiles {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+	}()
+
+	scanned := []InMemoryFile{
+		{Path: "compose/docker-compose.yml", Content: []byte(
+			"services:\n  web:\n    extends:\n      file: common.yml\n      service: base\n")},
+		{Path: "arm/azuredeploy.json", Content: []byte(`{"contentVersion": "1.0.0.0", "resources": [{
+			"type": "Microsoft.Resources/deployments", "apiVersion": "2021-04-01", "name": "linked",
+			"properties": {"templateLink": {"relativePath": "linked.json"}}}]}`)},
+		{Path: "chart/Chart.yaml", Content: []byte("apiVersion: v2\nname: chart\nversion: 0.1.0\n")},
+		{Path: "chart/values.yaml", Content: []byte("replicas: 1\n")},
+	}
+	referenced := make([]InMemoryFile, 0, len(diskFiles))
+	for name, content := range diskFiles {
+		referenced = append(referenced, InMemoryFile{Path: name, Content: []byte(content)})
+	}
+
+	tests := []struct {
+		name  string
+		files []InMemoryFile
+		want  map[string]int
+	}{
+		{
+			name:  "should not read the referenced files from disk",
+			files: scanned,
+			want:  map[string]int{},
+		},
+		{
+			name:  "should read the referenced files from memory",
+			files: append(append([]InMemoryFile{}, scanned...), referenced...),
+			// the service extending base is reported in common.yml, the file its privileged attribute comes from
+			want: map[string]int{
+				"compose/common.yml":       2,
+				"arm/azuredeploy.json":     1,
+				"arm/linked.json":          1,
+				"chart/templates/pod.yaml": 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := Scan(context.Background(), Options{
+				QueriesPath:    []string{queries},
+				Platforms:      []string{"DockerCompose", "AzureResourceManager", "Kubernetes"},
+				IncludeQueries: []string{privilegedCompose, websiteHTTPSQuery, privilegedPod},
+			}, tt.files)
+			require.NoError(t, err)
+
+			got := make(map[string]int)
+			for i := range summary.Queries {
+				for j := range summary.Queries[i].Files {
+					got[summary.Queries[i].Files[j].FileName]++
+				}
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScan_NoQueries(t *testing.T) {
+	_, err := Scan(context.Background(), Options{}, []InMemoryFile{{Path: "main.tf"}})
+	require.ErrorIs(t, err, ErrNoQueries)
+}
+
+func TestScanner_ConcurrentScans(t *testing.T) {
+	s, err := NewScanner(context.Background(), Options{
+		QueriesPath:    []string{queriesPath},
+		Platforms:      []string{"Terraform"},
+		IncludeQueries: []string{s3BucketACLQuery},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, s.LoadedQueries())
+
+	acls := []string{"public-read", "private", "public-read-write", "private"}
+	summaries := make([]int, len(acls))
+	var wg sync.WaitGroup
+	for i := range acls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			summary, err := s.Scan(context.Background(), []InMemoryFile{
+				{Path: "main.tf", Content: []byte(s3BucketVariables)},
+				{Path: "terraform.tfvars", Content: []byte(`acl = "` + acls[i] + `"`)},
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, summary.TotalQueries)
+			summaries[i] = summary.TotalCounter
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, []int{1, 0, 1, 0}, summaries)
+}
+
+func TestScanner_CoverageReport(t *testing.T) {
+	s, err := NewScanner(context.Background(), Options{
+		QueriesPath:    []string{queriesPath},
+		Platforms:      []string{"Terraform"},
+		IncludeQueries: []string{s3BucketACLQuery},
+		CoverageReport: true,
+	})
+	require.NoError(t, err)
+	require.Empty(t, s.CoverageReport().Queries)
+
+	for _, acl := range []string{"public-read", "private"} {
+		_, err := s.Scan(context.Background(), []InMemoryFile{
+			{Path: "main.tf", Content: []byte(s3BucketVariables)},
+			{Path: "terraform.tfvars", Content: []byte(`acl = "` + acl + `"`)},
+		})
+		require.NoError(t, err)
+	}
+
+	report := s.CoverageReport()
+	require.Len(t, report.Queries, 1)
+	require.Equal(t, s3BucketACLQuery, report.Queries[0].QueryID)
+	require.Positive(t, report.Queries[0].CoveredLines)
+}
@@ -0,0 +1,240 @@
+// Package cache implements a persistent on-disk cache of the results of scanned files
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// Cache stores the results of scanned files in a directory. Entries are keyed by the content of the file,
+// its path, the scanned path holding it, the parser kind and a namespace built from the KICS version, the loaded queries and libraries and
+// every other setting affecting the results, so changing any of them invalidates the cache
+type Cache struct {
+	dir       string
+	namespace string
+	mu        sync.Mutex
+	hits      int
+	misses    int
+}
+
+// Entry contains the results of a scanned file and the files it depends on. The queries look across every
+// document of a platform, so the results also depend on the files scanned along with it, its peers
+type Entry struct {
+	FileName        string            `json:"file_name"`
+	Peers           string            `json:"peers"`
+	Failed          bool              `json:"failed,omitempty"`
+	Dependencies    map[string]string `json:"dependencies"`
+	Patterns        map[string]string `json:"patterns"`
+	ResolvedLines   int               `json:"resolved_lines"`
+	ParsedLines     int               `json:"parsed_lines"`
+	IgnoredLines    int               `json:"ignored_lines"`
+	Vulnerabilities []Vulnerability   `json:"vulnerabilities"`
+}
+
+// Vulnerability is a cached vulnerability, keeping the fields omitted from the JSON representation of
+// model.Vulnerability that are needed by the reports
+type Vulnerability struct {
+	model.Vulnerability
+	QueryURI string `json:"queryURI"`
+}
+
+// NewCache creates the cache directory and initializes a Cache for the given namespace parts
+func NewCache(dir string, namespaceParts ...string) (*Cache, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+
+	hash := sha256.New()
+	for _, part := range namespaceParts {
+		fmt.Fprintf(hash, "%s\x00", part)
+	}
+
+	return &Cache{
+		dir:       dir,
+		namespace: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// Key returns the cache key of a file content parsed by the given parser kind. The files it depends on and the
+// similarity IDs of its results depend on its path and on the scanned path, so they are part of the key
+func (c *Cache) Key(content []byte, kind, basePath, path string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00", c.namespace, kind, absPath(basePath), absPath(path))
+	hash.Write(content)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the entry stored for the key, entries whose dependencies changed are ignored
+func (c *Cache) Get(key string) (*Entry, bool) {
+	entry, err := c.read(key)
+	if err != nil || !dependenciesMatch(entry.Dependencies) || !patternsMatch(entry.Patterns) {
+		if err != nil && !os.IsNotExist(err) {
+			log.Debug().Msgf("Ignoring cache entry %s: %s", key, err)
+		}
+		return nil, false
+	}
+	return entry, true
+}
+
+// Count counts a file whose cached results were used as a hit and a file that was scanned as a miss, an entry
+// found for a file is not used when its peers changed
+func (c *Cache) Count(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+}
+
+// Put stores the entry for the key
+func (c *Cache) Put(key string, entry *Entry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path := c.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so concurrent scans never read partial entries
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := os.Remove(tmp.Name()); removeErr != nil {
+			log.Debug().Msgf("Failed to remove temporary cache file %s: %s", tmp.Name(), removeErr)
+		}
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Stats returns the number of cache hits and misses
+func (c *Cache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// HashPeers returns a hash of the keys of the files scanned together, regardless of their order
+func HashPeers(keys []string) string {
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	hash := sha256.New()
+	for _, key := range sorted {
+		fmt.Fprintf(hash, "%s\x00", key)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// HashFiles returns the content hash of each file, files that can not be read are ignored
+func HashFiles(paths []string) map[string]string {
+	hashes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		if hash, err := hashFile(path); err == nil {
+			hashes[path] = hash
+		}
+	}
+	return hashes
+}
+
+// HashPatterns returns, for each glob pattern, a hash of the matching files and their contents
+func HashPatterns(patterns []string) map[string]string {
+	hashes := make(map[string]string, len(patterns))
+	for _, pattern := range patterns {
+		if hash, err := hashPattern(pattern); err == nil {
+			hashes[pattern] = hash
+		}
+	}
+	return hashes
+}
+
+func (c *Cache) read(key string) (*Entry, error) {
+	content, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (c *Cache) entryPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+func dependenciesMatch(dependencies map[string]string) bool {
+	for path, hash := range dependencies {
+		current, err := hashFile(path)
+		if err != nil || current != hash {
+			return false
+		}
+	}
+	return true
+}
+
+func patternsMatch(patterns map[string]string) bool {
+	for pattern, hash := range patterns {
+		current, err := hashPattern(pattern)
+		if err != nil || current != hash {
+			return false
+		}
+	}
+	return true
+}
+
+func hashPattern(pattern string) (string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(matches)
+
+	hash := sha256.New()
+	for _, match := range matches {
+		fileHash, err := hashFile(match)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00", match, fileHash)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(path)
+}
+
+func hashFile(path string) (string, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCache_Key tests the function [Key()] and all the methods called by it
+func TestCache_Key(t *testing.T) {
+	c, err := NewCache(t.TempDir(), "version", "queries")
+	require.NoError(t, err)
+	other, err := NewCache(t.TempDir(), "version", "changed queries")
+	require.NoError(t, err)
+
+	key := c.Key([]byte("content"), "terraform", "project", filepath.Join("project", "a", "main.tf"))
+	require.Equal(t, key, c.Key([]byte("content"), "terraform", "project", filepath.Join("project", "a", "main.tf")))
+	require.NotEqual(t, key, c.Key([]byte("changed content"), "terraform", "project", filepath.Join("project", "a", "main.tf")))
+	require.NotEqual(t, key, c.Key([]byte("content"), "kubernetes", "project", filepath.Join("project", "a", "main.tf")))
+	require.NotEqual(t, key, c.Key([]byte("content"), "terraform", "project", filepath.Join("project", "b", "main.tf")))
+	require.NotEqual(t, key, c.Key([]byte("content"), "terraform", filepath.Join("project", "a"), filepath.Join("project", "a", "main.tf")))
+	require.NotEqual(t, key, other.Key([]byte("content"), "terraform", "project", filepath.Join("project", "a", "main.tf")))
+}
+
+// TestCache_GetPut tests the functions [Get()] and [Put()] and all the methods called by them
+func TestCache_GetPut(t *testing.T) {
+	dir := t.TempDir()
+	dependency := filepath.Join(dir, "variables.tf")
+	require.NoError(t, os.WriteFile(dependency, []byte("variable \"a\" {}"), 0600))
+
+	c, err := NewCache(filepath.Join(dir, "cache"), "version")
+	require.NoError(t, err)
+	key := c.Key([]byte("content"), "terraform", dir, filepath.Join(dir, "main.tf"))
+
+	_, ok := c.Get(key)
+	require.False(t, ok)
+
+	entry := &Entry{
+		FileName:     "main.tf",
+		Dependencies: HashFiles([]string{dependency, filepath.Join(dir, "missing.tf")}),
+		Patterns:     HashPatterns([]string{filepath.Join(dir, "*.tf")}),
+		ParsedLines:  10,
+		Vulnerabilities: []Vulnerability{
+			{
+				Vulnerability: model.Vulnerability{QueryID: "query-1", FileName: "main.tf", Line: 3},
+				QueryURI:      "https://docs.kics.io",
+			},
+		},
+	}
+	require.Len(t, entry.Dependencies, 1)
+	require.NoError(t, c.Put(key, entry))
+
+	cached, ok := c.Get(key)
+	require.True(t, ok)
+	require.Equal(t, 10, cached.ParsedLines)
+	require.Len(t, cached.Vulnerabilities, 1)
+	require.Equal(t, "query-1", cached.Vulnerabilities[0].QueryID)
+	require.Equal(t, 3, cached.Vulnerabilities[0].Line)
+	require.Equal(t, "https://docs.kics.io", cached.Vulnerabilities[0].QueryURI)
+
+	// a new file matching the patterns invalidates the entry
+	newFile := filepath.Join(dir, "outputs.tf")
+	require.NoError(t, os.WriteFile(newFile, []byte(""), 0600))
+	_, ok = c.Get(key)
+	require.False(t, ok)
+	require.NoError(t, os.Remove(newFile))
+
+	// a changed dependency invalidates the entry
+	require.NoError(t, os.WriteFile(dependency, []byte("variable \"b\" {}"), 0600))
+	_, ok = c.Get(key)
+	require.False(t, ok)
+
+	// only the counted files are reported as hits and misses
+	c.Count(true)
+	c.Count(false)
+	c.Count(false)
+	hits, misses := c.Stats()
+	require.Equal(t, 1, hits)
+	require.Equal(t, 2, misses)
+}
+
+// TestCache_HashPeers tests the function [HashPeers()]
+func TestCache_HashPeers(t *testing.T) {
+	peers := HashPeers([]string{"key-1", "key-2"})
+	require.Equal(t, peers, HashPeers([]string{"key-2", "key-1"}))
+	require.NotEqual(t, peers, HashPeers([]string{"key-1"}))
+	require.NotEqual(t, peers, HashPeers([]string{"key-1", "key-2", "key-3"}))
+}
@@ -0,0 +1,123 @@
+// Package coverage holds the lines of the Rego queries exercised by the scanned files
+package coverage
+
+import (
+	"sort"
+)
+
+// Line statuses, lines without status, such as comments, are not considered by the coverage
+const (
+	LineCovered    = "covered"
+	LineNotCovered = "not-covered"
+)
+
+// Range is a range of lines of a query, both included
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Rule is a rule of a query
+type Rule struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+// Line is a line of the source of a query along with its status
+type Line struct {
+	Number  int
+	Content string
+	Status  string
+}
+
+// Query contains the coverage of a single query
+type Query struct {
+	QueryName       string  `json:"query_name"`
+	QueryID         string  `json:"query_id"`
+	Platform        string  `json:"platform"`
+	Query           string  `json:"query"`
+	Coverage        float64 `json:"coverage"`
+	CoveredLines    int     `json:"covered_lines"`
+	NotCoveredLines int     `json:"not_covered_lines"`
+	Covered         []Range `json:"covered"`
+	NotCovered      []Range `json:"not_covered"`
+	NotCoveredRules []Rule  `json:"not_covered_rules"`
+	Lines           []Line  `json:"-"`
+}
+
+// Report contains the coverage of the evaluated queries
+type Report struct {
+	Coverage        float64 `json:"coverage"`
+	CoveredLines    int     `json:"covered_lines"`
+	NotCoveredLines int     `json:"not_covered_lines"`
+	Queries         []Query `json:"queries"`
+}
+
+// NewReport returns an empty coverage report
+func NewReport() *Report {
+	return &Report{
+		Queries: make([]Query, 0),
+	}
+}
+
+// Add adds the coverage of a query to the report, keeping the queries sorted by platform and name
+func (r *Report) Add(query *Query) {
+	query.CoveredLines = countLines(query.Covered)
+	query.NotCoveredLines = countLines(query.NotCovered)
+	query.Coverage = percentage(query.CoveredLines, query.NotCoveredLines)
+
+	r.Queries = append(r.Queries, *query)
+	sort.SliceStable(r.Queries, func(i, j int) bool {
+		if r.Queries[i].Platform != r.Queries[j].Platform {
+			return r.Queries[i].Platform < r.Queries[j].Platform
+		}
+		return r.Queries[i].Query < r.Queries[j].Query
+	})
+
+	r.CoveredLines += query.CoveredLines
+	r.NotCoveredLines += query.NotCoveredLines
+	r.Coverage = percentage(r.CoveredLines, r.NotCoveredLines)
+}
+
+// Merge adds the coverage of the queries of another report
+func (r *Report) Merge(other *Report) {
+	for i := range other.Queries {
+		r.Add(&other.Queries[i])
+	}
+}
+
+// LineStatus returns the status of a line of the query
+func (q *Query) LineStatus(line int) string {
+	switch {
+	case inRanges(q.Covered, line):
+		return LineCovered
+	case inRanges(q.NotCovered, line):
+		return LineNotCovered
+	default:
+		return ""
+	}
+}
+
+func inRanges(ranges []Range, line int) bool {
+	for _, r := range ranges {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+func countLines(ranges []Range) int {
+	count := 0
+	for _, r := range ranges {
+		count += r.End - r.Start + 1
+	}
+	return count
+}
+
+func percentage(covered, notCovered int) float64 {
+	if covered+notCovered == 0 {
+		return 0
+	}
+	return 100.0 * float64(covered) / float64(covered+notCovered)
+}
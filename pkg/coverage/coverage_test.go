@@ -0,0 +1,42 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Add(t *testing.T) {
+	report := NewReport()
+	report.Add(&Query{
+		Platform:   "Terraform",
+		Query:      "b",
+		Covered:    []Range{{Start: 3, End: 5}},
+		NotCovered: []Range{{Start: 8, End: 8}},
+	})
+	other := NewReport()
+	other.Add(&Query{
+		Platform: "Terraform",
+		Query:    "a",
+		Covered:  []Range{{Start: 3, End: 6}},
+	})
+	report.Merge(other)
+
+	require.Equal(t, 7, report.CoveredLines)
+	require.Equal(t, 1, report.NotCoveredLines)
+	require.InDelta(t, 87.5, report.Coverage, 0.001)
+	require.Len(t, report.Queries, 2)
+	require.Equal(t, "a", report.Queries[0].Query)
+	require.InDelta(t, 100.0, report.Queries[0].Coverage, 0.001)
+	require.InDelta(t, 75.0, report.Queries[1].Coverage, 0.001)
+}
+
+func TestQuery_LineStatus(t *testing.T) {
+	query := &Query{
+		Covered:    []Range{{Start: 3, End: 5}},
+		NotCovered: []Range{{Start: 8, End: 9}},
+	}
+	require.Equal(t, LineCovered, query.LineStatus(4))
+	require.Equal(t, LineNotCovered, query.LineStatus(9))
+	require.Equal(t, "", query.LineStatus(1))
+}
@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -12,6 +13,9 @@ const (
 	undetectedVulnerabilityLine = -1
 )
 
+// ansibleTaskNameRegex matches the name of the task of the search key of an Ansible result, e.g. name={{task}}
+var ansibleTaskNameRegex = regexp.MustCompile(`^name=\{\{(.+?)\}\}`)
+
 type defaultDetectLine struct {
 }
 
@@ -26,6 +30,26 @@ func (d defaultDetectLine) DetectLine(file *model.FileMetadata, searchKey string
 		ResolvedFiles:   d.prepareResolvedFiles(file.ResolvedFiles),
 	}
 
+	// tweak in order to find the values generated by the parsers, such as the resources generated by the SAM
+	// transform, which are not written in the file, in their line information, which points to their source
+	if line, ok := generatedValueLine(file, searchKey); ok {
+		return model.VulnerabilityLines{
+			Line:         line,
+			VulnLines:    GetAdjacentVulnLines(line-1, outputLines, *file.LinesOriginalData),
+			ResolvedFile: file.FilePath,
+		}
+	}
+
+	// ANSIBLE-specific tweak in order to find the tasks loaded from roles and included files, which are not
+	// written in the file, in their line information, which points to the role or include loading them
+	if line, ok := ansibleLoadedTaskLine(file, searchKey); ok {
+		return model.VulnerabilityLines{
+			Line:         line,
+			VulnLines:    GetAdjacentVulnLines(line-1, outputLines, *file.LinesOriginalData),
+			ResolvedFile: file.FilePath,
+		}
+	}
+
 	var extractedString [][]string
 	extractedString = GetBracketValues(searchKey, extractedString, "")
 	sanitizedSubstring := searchKey
@@ -78,6 +102,102 @@ func (d defaultDetectLine) DetectLine(file *model.FileMetadata, searchKey string
 	}
 }
 
+// generatedValueLine returns the line of the deepest key of the search key of a value generated by a parser, such
+// as the resources generated by the SAM transform, found in the line information of the document
+func generatedValueLine(file *model.FileMetadata, searchKey string) (int, bool) {
+	keys := strings.Split(searchKey, ".")
+	if !isGenerated(file.LineInfoDocument, keys) {
+		return 0, false
+	}
+	for i := len(keys); i > 1; i-- {
+		if line, err := GetLineBySearchLine(keys[:i], file); err == nil && line > 0 {
+			return line, true
+		}
+	}
+	return 0, false
+}
+
+// isGenerated returns true if a value along the keys is marked as generated in its line information
+func isGenerated(document map[string]interface{}, keys []string) bool {
+	value := interface{}(document)
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = m[key]; !ok {
+			return false
+		}
+		if child, ok := value.(map[string]interface{}); ok && isGeneratedLine(child["_kics_lines"]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGeneratedLine returns true if the default line of the line information of a value is marked as generated
+func isGeneratedLine(lines interface{}) bool {
+	switch l := lines.(type) {
+	case map[string]*model.LineObject:
+		return l["_kics__default"] != nil && l["_kics__default"].Generated
+	case map[string]interface{}:
+		switch d := l["_kics__default"].(type) {
+		case *model.LineObject:
+			return d != nil && d.Generated
+		case map[string]interface{}:
+			generated, _ := d["_kics_generated"].(bool)
+			return generated
+		}
+	}
+	return false
+}
+
+// ansibleLoadedTaskLine returns the line of a task of an Ansible playbook loaded from a role or an included file,
+// whose name is not written in the file, found in the line information of the document
+func ansibleLoadedTaskLine(file *model.FileMetadata, searchKey string) (int, bool) {
+	if file.Kind != model.KindYAML {
+		return 0, false
+	}
+	match := ansibleTaskNameRegex.FindStringSubmatch(searchKey)
+	if match == nil || strings.Contains(file.OriginalData, match[1]) {
+		return 0, false
+	}
+	lines, _ := file.LineInfoDocument["_kics_lines"].(map[string]interface{})
+	return taskLine(file.LineInfoDocument["playbooks"], lines["_kics__default"], match[1])
+}
+
+// taskLine looks for the task named name in a list of plays or tasks and returns its line
+func taskLine(value, lines interface{}, name string) (int, bool) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return 0, false
+	}
+	linesMap, _ := lines.(map[string]interface{})
+	arr, _ := linesMap["_kics_arr"].([]interface{})
+	for i, element := range list {
+		task, ok := element.(map[string]interface{})
+		if !ok || i >= len(arr) {
+			continue
+		}
+		taskLines, _ := arr[i].(map[string]interface{})
+		if task["name"] == name {
+			defaultLines, _ := taskLines["_kics__default"].(map[string]interface{})
+			if line, ok := defaultLines["_kics_line"].(float64); ok {
+				return int(line), true
+			}
+			if line, ok := defaultLines["_kics_line"].(int); ok {
+				return line, true
+			}
+		}
+		for key, child := range task {
+			if line, found := taskLine(child, taskLines["_kics_"+key], name); found {
+				return line, true
+			}
+		}
+	}
+	return 0, false
+}
+
 func (d defaultDetectLine) prepareResolvedFiles(resFiles map[string]model.ResolvedFile) map[string]model.ResolvedFileSplit {
 	resolvedFiles := make(map[string]model.ResolvedFileSplit)
 	for f, res := range resFiles {
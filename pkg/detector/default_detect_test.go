@@ -1,10 +1,13 @@
 package detector
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/Checkmarx/kics/v2/pkg/model"
+	yamlParser "github.com/Checkmarx/kics/v2/pkg/parser/yaml"
 	"github.com/Checkmarx/kics/v2/pkg/utils"
 	"github.com/Checkmarx/kics/v2/test"
 	"github.com/rs/zerolog"
@@ -183,3 +186,106 @@ func Test_defaultDetectLine_prepareResolvedFiles(t *testing.T) {
 		})
 	}
 }
+
+// Test_generatedValueLine tests that the lines of the resources generated by the SAM transform, which are marked as
+// generated, are found in the line information of their document
+func Test_generatedValueLine(t *testing.T) {
+	path := filepath.FromSlash("../../test/fixtures/test_cloudformation_sam/template.yaml")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	documents, _, err := (&yamlParser.Parser{}).Parse(path, content)
+	require.NoError(t, err)
+	require.Len(t, documents, 2)
+
+	tests := []struct {
+		name      string
+		document  model.Document
+		searchKey string
+		want      int
+		found     bool
+	}{
+		{
+			name:      "generated resource with a property of its SAM resource",
+			document:  documents[1],
+			searchKey: "Resources.OrdersFunctionRole.Properties.Policies.PolicyDocument",
+			want:      20,
+			found:     true,
+		},
+		{
+			name:      "generated resource with a property created by the transform",
+			document:  documents[1],
+			searchKey: "Resources.OrdersFunction.Properties.TracingConfig.Mode",
+			want:      16,
+			found:     true,
+		},
+		{
+			name:      "SAM resource",
+			document:  documents[0],
+			searchKey: "Resources.OrdersFunction.Properties.Tracing",
+			found:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &model.FileMetadata{
+				Kind:              model.KindYAML,
+				Document:          tt.document,
+				LineInfoDocument:  tt.document,
+				LinesOriginalData: utils.SplitLines(string(content)),
+			}
+			got, found := generatedValueLine(file, tt.searchKey)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Test_ansibleLoadedTaskLine tests that the lines of the tasks loaded from roles and included files are found in
+// the line information of their playbook
+func Test_ansibleLoadedTaskLine(t *testing.T) {
+	path := filepath.FromSlash("../../test/fixtures/test_ansible_roles/site.yml")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	documents, _, err := (&yamlParser.Parser{}).Parse(path, content)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+
+	tests := []struct {
+		name      string
+		searchKey string
+		want      int
+		found     bool
+	}{
+		{
+			name:      "task of a role",
+			searchKey: "name={{Create data bucket}}.{{amazon.aws.s3_bucket}}.versioning",
+			want:      7,
+			found:     true,
+		},
+		{
+			name:      "task of an included file",
+			searchKey: "name={{Create backup bucket}}.{{amazon.aws.s3_bucket}}",
+			want:      14,
+			found:     true,
+		},
+		{
+			name:      "task of the playbook",
+			searchKey: "name={{Create logs bucket}}.{{amazon.aws.s3_bucket}}",
+			found:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &model.FileMetadata{
+				Kind:              model.KindYAML,
+				Document:          documents[0],
+				LineInfoDocument:  documents[0],
+				OriginalData:      string(content),
+				LinesOriginalData: utils.SplitLines(string(content)),
+			}
+			got, found := ansibleLoadedTaskLine(file, tt.searchKey)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
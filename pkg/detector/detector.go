@@ -47,6 +47,13 @@ func (d *DetectLine) DetectLine(file *model.FileMetadata, searchKey string, logW
 	return d.defaultDetector.DetectLine(file, searchKey, d.outputLines, logWithFields)
 }
 
+// DefaultDetectLine searches the vulnerability line with the default detect line, it is used by the kindDetectLine
+// that only handle some of the files of their kind
+func DefaultDetectLine(file *model.FileMetadata, searchKey string, outputLines int,
+	logWithFields *zerolog.Logger) model.VulnerabilityLines {
+	return defaultDetectLine{}.DetectLine(file, searchKey, outputLines, logWithFields)
+}
+
 // GetAdjacent finds and returns the lines adjacent to the line containing the vulnerability
 func (d *DetectLine) GetAdjacent(file *model.FileMetadata, line int) model.VulnerabilityLines {
 	return model.VulnerabilityLines{
@@ -0,0 +1,80 @@
+package dockercompose
+
+import (
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/detector"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	composeParser "github.com/Checkmarx/kics/v2/pkg/parser/dockercompose"
+	"github.com/rs/zerolog"
+)
+
+// DetectKindLine defines a kindDetectLine type
+type DetectKindLine struct {
+}
+
+// DetectLine searches the vulnerability line of a Compose project model resolved from several files, such as its
+// override file, in its line information, which points to the file each attribute comes from, the lines of the
+// other YAML files are searched with the default detect line
+func (d DetectKindLine) DetectLine(file *model.FileMetadata, searchKey string,
+	outputLines int, logWithFields *zerolog.Logger) model.VulnerabilityLines {
+	if lines, ok := projectLines(file, searchKey, outputLines); ok {
+		return lines
+	}
+	return detector.DefaultDetectLine(file, searchKey, outputLines, logWithFields)
+}
+
+// isProject returns true if the file is a Compose file whose project model is resolved from several files
+func isProject(file *model.FileMetadata) bool {
+	return len(file.ResolvedFiles) > 0 && composeParser.IsCompose(file.LineInfoDocument, file.FilePath)
+}
+
+// projectLines returns the line of the deepest key of the search key of a Compose project model found in its
+// line information, along with the file the key comes from. The results of a project depend on their line, as
+// the results of its override and included files scanned on their own, so they are not reported twice
+func projectLines(file *model.FileMetadata, searchKey string, outputLines int) (model.VulnerabilityLines, bool) {
+	if !isProject(file) {
+		return model.VulnerabilityLines{}, false
+	}
+	var entry map[string]interface{}
+	value := interface{}(file.LineInfoDocument)
+	for _, key := range strings.Split(searchKey, ".") {
+		key = strings.TrimSuffix(strings.TrimPrefix(key, "{{"), "}}")
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		child, ok := m[key]
+		if !ok {
+			break
+		}
+		lines, _ := m["_kics_lines"].(map[string]interface{})
+		entry, _ = lines["_kics_"+key].(map[string]interface{})
+		value = child
+	}
+
+	line := 0
+	switch l := entry["_kics_line"].(type) {
+	case float64:
+		line = int(l)
+	case int:
+		line = l
+	}
+	if line <= 0 {
+		return model.VulnerabilityLines{}, false
+	}
+	path, lines := file.FilePath, *file.LinesOriginalData
+	if resolvedPath, ok := entry[composeParser.FileKey].(string); ok {
+		resolvedFile, found := file.ResolvedFiles[resolvedPath]
+		if !found {
+			return model.VulnerabilityLines{}, false
+		}
+		path, lines = resolvedPath, *resolvedFile.LinesContent
+	}
+	return model.VulnerabilityLines{
+		Line:           line,
+		VulnLines:      detector.GetAdjacentVulnLines(line-1, outputLines, lines),
+		ResolvedFile:   path,
+		LineSimilarity: true,
+	}, true
+}
@@ -0,0 +1,86 @@
+package dockercompose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	yamlParser "github.com/Checkmarx/kics/v2/pkg/parser/yaml"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetectLine tests that the lines of a Compose project model are found in the file each attribute comes from
+func TestDetectLine(t *testing.T) {
+	dir := filepath.FromSlash("../../../test/fixtures/test_compose_project")
+	path := filepath.Join(dir, "docker-compose.yml")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	parser := &yamlParser.Parser{}
+	documents, _, err := parser.Parse(path, content)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+
+	tests := []struct {
+		name      string
+		searchKey string
+		wantLine  int
+		wantFile  string
+		found     bool
+	}{
+		{
+			name:      "attribute of the compose file",
+			searchKey: "services.web.ports",
+			wantLine:  6,
+			wantFile:  path,
+			found:     true,
+		},
+		{
+			name:      "attribute of the override file",
+			searchKey: "services.web.privileged",
+			wantLine:  3,
+			wantFile:  filepath.Join(dir, "docker-compose.override.yml"),
+			found:     true,
+		},
+		{
+			name:      "attribute of an extended service",
+			searchKey: "services.worker.cap_add",
+			wantLine:  4,
+			wantFile:  filepath.Join(dir, "common.yml"),
+			found:     true,
+		},
+		{
+			name:      "service of an included file",
+			searchKey: "services.exporter.healthcheck",
+			wantLine:  2,
+			wantFile:  filepath.Join(dir, "monitoring", "compose.yaml"),
+			found:     true,
+		},
+		{
+			name:      "unknown attribute",
+			searchKey: "networks.default",
+			wantLine:  -1,
+			wantFile:  path,
+			found:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &model.FileMetadata{
+				FilePath:          path,
+				Kind:              model.KindYAML,
+				Document:          documents[0],
+				LineInfoDocument:  documents[0],
+				OriginalData:      string(content),
+				LinesOriginalData: utils.SplitLines(string(content)),
+				ResolvedFiles:     parser.GetResolvedFiles(),
+			}
+			got := DetectKindLine{}.DetectLine(file, tt.searchKey, 3, &zerolog.Logger{})
+			require.Equal(t, tt.found, got.LineSimilarity)
+			require.Equal(t, tt.wantLine, got.Line)
+			require.Equal(t, tt.wantFile, got.ResolvedFile)
+		})
+	}
+}
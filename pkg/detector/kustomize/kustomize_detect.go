@@ -0,0 +1,76 @@
+package kustomize
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/detector"
+	"github.com/Checkmarx/kics/v2/pkg/detector/helm"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog"
+)
+
+// DetectKindLine defines a kindDetectLine type
+type DetectKindLine struct {
+}
+
+const (
+	undetectedVulnerabilityLine = -1
+)
+
+// DetectLine is used to detect line on the files a kustomize resource was rendered from,
+// it looks for the keys in the file the resource was loaded from, the same way as the helm
+// templates are looked at, and in the patches of the resource when the last key is not there
+func (d DetectKindLine) DetectLine(file *model.FileMetadata, searchKey string,
+	outputLines int, logWithFields *zerolog.Logger) model.VulnerabilityLines {
+	nopLogger := zerolog.Nop()
+	lastKey := getLastKey(searchKey)
+
+	lines := helm.DetectKindLine{}.DetectLine(file, searchKey, outputLines, &nopLogger)
+	if isKeyLine(lines, lastKey) {
+		return lines
+	}
+
+	for i := range file.Patches {
+		patchFile := *file
+		patchFile.FilePath = file.Patches[i].FileName
+		patchFile.OriginalData = string(file.Patches[i].OriginalData)
+		patchFile.LinesOriginalData = utils.SplitLines(patchFile.OriginalData)
+		patchFile.HelmID = file.Patches[i].SplitID
+		patchFile.IDInfo = file.Patches[i].IDInfo
+
+		patchLines := helm.DetectKindLine{}.DetectLine(&patchFile, searchKey, outputLines, &nopLogger)
+		if isKeyLine(patchLines, lastKey) {
+			return patchLines
+		}
+	}
+
+	if lines.Line == undetectedVulnerabilityLine {
+		var filePathSplit = strings.Split(file.FilePath, "/")
+		logWithFields.Warn().Msgf("Failed to detect line associated with identified result in file %s\n", filePathSplit[len(filePathSplit)-1])
+	}
+	return lines
+}
+
+// getLastKey returns the last key of the search key, without its value
+func getLastKey(searchKey string) string {
+	var extractedString [][]string
+	extractedString = detector.GetBracketValues(searchKey, extractedString, "")
+	sanitizedSubstring := searchKey
+	for idx, str := range extractedString {
+		sanitizedSubstring = strings.Replace(sanitizedSubstring, str[0], `{{`+strconv.Itoa(idx)+`}}`, -1)
+	}
+	keys := strings.Split(sanitizedSubstring, ".")
+	lastKey, _ := detector.GenerateSubstrings(keys[len(keys)-1], extractedString)
+	return lastKey
+}
+
+// isKeyLine checks if the line detected is the line of the key
+func isKeyLine(lines model.VulnerabilityLines, key string) bool {
+	if lines.Line == undetectedVulnerabilityLine {
+		return false
+	}
+	line := strings.TrimPrefix(strings.TrimSpace(lines.LineWithVulnerability), "- ")
+	return strings.Trim(strings.TrimSpace(line), `"'`) == key
+}
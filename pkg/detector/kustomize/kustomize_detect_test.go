@@ -0,0 +1,92 @@
+package kustomize
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+var baseOriginalData = `# KICS_HELM_ID_0:
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx
+`
+
+var patchOriginalData = `# KICS_HELM_ID_0:
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          securityContext:
+            privileged: true
+`
+
+// TestDetectKindLine_DetectLine tests the functions [DetectLine()] and all the methods called by them
+func TestDetectKindLine_DetectLine(t *testing.T) {
+	file := &model.FileMetadata{
+		FilePath:          "base/deployment.yaml",
+		OriginalData:      baseOriginalData,
+		LinesOriginalData: utils.SplitLines(baseOriginalData),
+		HelmID:            "# KICS_HELM_ID_0:",
+		IDInfo: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4, 5: 5,
+			6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11}},
+		Patches: []model.ResolvedHelm{
+			{
+				FileName:     "overlays/prod/patch.yaml",
+				OriginalData: []byte(patchOriginalData),
+				SplitID:      "# KICS_HELM_ID_0:",
+				IDInfo: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4, 5: 5,
+					6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		searchKey string
+		wantFile  string
+		wantLine  int
+	}{
+		{
+			name:      "detect_line_base",
+			searchKey: "metadata.name={{prod-web}}.spec.template.spec.containers.name={{web}}.image",
+			wantFile:  "base/deployment.yaml",
+			wantLine:  10,
+		},
+		{
+			name:      "detect_line_patch",
+			searchKey: "metadata.name={{prod-web}}.spec.template.spec.containers.name={{web}}.securityContext.privileged",
+			wantFile:  "overlays/prod/patch.yaml",
+			wantLine:  11,
+		},
+		{
+			name:      "detect_line_missing_key",
+			searchKey: "metadata.name={{prod-web}}.spec.template.spec.containers.name={{web}}.resources",
+			wantFile:  "base/deployment.yaml",
+			wantLine:  9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectKindLine{}.DetectLine(file, tt.searchKey, 3, &zerolog.Logger{})
+			require.Equal(t, tt.wantFile, got.ResolvedFile)
+			require.Equal(t, tt.wantLine, got.Line)
+		})
+	}
+}
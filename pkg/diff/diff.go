@@ -0,0 +1,256 @@
+// Package diff compares the results of two KICS scans
+package diff
+
+import (
+	"sort"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+)
+
+// Parameters represents all available diff parameters
+type Parameters struct {
+	OldResults    string
+	NewResults    string
+	OutputPath    string
+	OutputName    string
+	ReportFormats []string
+}
+
+// Counters contains the totals of the delta between two scans
+type Counters struct {
+	OldTotal        int `json:"old_total"`
+	NewTotal        int `json:"new_total"`
+	Added           int `json:"added"`
+	Removed         int `json:"removed"`
+	SeverityChanged int `json:"severity_changed"`
+}
+
+// QueryCounter contains the delta of a single query between two scans
+type QueryCounter struct {
+	QueryName string         `json:"query_name"`
+	QueryID   string         `json:"query_id"`
+	Platform  string         `json:"platform"`
+	Severity  model.Severity `json:"severity"`
+	Old       int            `json:"old"`
+	New       int            `json:"new"`
+	Added     int            `json:"added"`
+	Removed   int            `json:"removed"`
+}
+
+// FileCounter contains the delta of a single file between two scans
+type FileCounter struct {
+	FileName string `json:"file_name"`
+	Old      int    `json:"old"`
+	New      int    `json:"new"`
+	Added    int    `json:"added"`
+	Removed  int    `json:"removed"`
+}
+
+// SeverityChange contains the findings of a query whose severity changed between two scans
+type SeverityChange struct {
+	QueryName   string                 `json:"query_name"`
+	QueryID     string                 `json:"query_id"`
+	Platform    string                 `json:"platform"`
+	OldSeverity model.Severity         `json:"old_severity"`
+	NewSeverity model.Severity         `json:"new_severity"`
+	Files       []model.VulnerableFile `json:"files"`
+}
+
+// Report is the structured delta between the results of two scans
+type Report struct {
+	OldResults              string                 `json:"old_results"`
+	NewResults              string                 `json:"new_results"`
+	OldVersion              string                 `json:"old_kics_version,omitempty"`
+	NewVersion              string                 `json:"new_kics_version,omitempty"`
+	Counters                Counters               `json:"counters"`
+	AddedSeverityCounters   map[model.Severity]int `json:"added_severity_counters"`
+	RemovedSeverityCounters map[model.Severity]int `json:"removed_severity_counters"`
+	Added                   model.QueryResultSlice `json:"added"`
+	Removed                 model.QueryResultSlice `json:"removed"`
+	SeverityChanged         []SeverityChange       `json:"severity_changed"`
+	Queries                 []QueryCounter         `json:"queries"`
+	Files                   []FileCounter          `json:"files"`
+}
+
+// finding identifies a single finding of a scan
+type finding struct {
+	query *model.QueryResult
+	file  *model.VulnerableFile
+}
+
+// Compare computes the delta between the old and the new results. Findings are matched by their
+// SimilarityID or OldSimilarityID
+func Compare(oldSummary, newSummary *model.Summary, oldResults, newResults string) *Report {
+	report := &Report{
+		OldResults:              oldResults,
+		NewResults:              newResults,
+		OldVersion:              oldSummary.Version,
+		NewVersion:              newSummary.Version,
+		AddedSeverityCounters:   make(map[model.Severity]int),
+		RemovedSeverityCounters: make(map[model.Severity]int),
+		Added:                   make(model.QueryResultSlice, 0),
+		Removed:                 make(model.QueryResultSlice, 0),
+		SeverityChanged:         make([]SeverityChange, 0),
+	}
+
+	oldFindings := indexFindings(oldSummary.Queries)
+	newFindings := indexFindings(newSummary.Queries)
+
+	queries := make(map[string]*QueryCounter)
+	files := make(map[string]*FileCounter)
+	severityChanges := make(map[string]*SeverityChange)
+
+	for i := range oldSummary.Queries {
+		query := &oldSummary.Queries[i]
+		removedFiles := make([]model.VulnerableFile, 0)
+		for j := range query.Files {
+			file := &query.Files[j]
+			getQueryCounter(queries, query).Old++
+			getFileCounter(files, file.FileName).Old++
+			report.Counters.OldTotal++
+			if _, ok := matchFinding(file, newFindings); ok {
+				continue
+			}
+			removedFiles = append(removedFiles, *file)
+			getQueryCounter(queries, query).Removed++
+			getFileCounter(files, file.FileName).Removed++
+			report.RemovedSeverityCounters[query.Severity]++
+		}
+		report.Removed = appendQueryFiles(report.Removed, query, removedFiles)
+		report.Counters.Removed += len(removedFiles)
+	}
+
+	for i := range newSummary.Queries {
+		query := &newSummary.Queries[i]
+		addedFiles := make([]model.VulnerableFile, 0)
+		for j := range query.Files {
+			file := &query.Files[j]
+			getQueryCounter(queries, query).New++
+			getFileCounter(files, file.FileName).New++
+			report.Counters.NewTotal++
+			old, ok := matchFinding(file, oldFindings)
+			if !ok {
+				addedFiles = append(addedFiles, *file)
+				getQueryCounter(queries, query).Added++
+				getFileCounter(files, file.FileName).Added++
+				report.AddedSeverityCounters[query.Severity]++
+				continue
+			}
+			if old.query.Severity != query.Severity {
+				addSeverityChange(severityChanges, old.query, query, file)
+				report.Counters.SeverityChanged++
+			}
+		}
+		report.Added = appendQueryFiles(report.Added, query, addedFiles)
+		report.Counters.Added += len(addedFiles)
+	}
+
+	for _, change := range severityChanges {
+		report.SeverityChanged = append(report.SeverityChanged, *change)
+	}
+	sort.Slice(report.SeverityChanged, func(i, j int) bool {
+		return report.SeverityChanged[i].QueryName < report.SeverityChanged[j].QueryName
+	})
+
+	report.Queries = make([]QueryCounter, 0, len(queries))
+	for _, counter := range queries {
+		report.Queries = append(report.Queries, *counter)
+	}
+	sort.Slice(report.Queries, func(i, j int) bool {
+		if report.Queries[i].QueryName == report.Queries[j].QueryName {
+			return report.Queries[i].QueryID < report.Queries[j].QueryID
+		}
+		return report.Queries[i].QueryName < report.Queries[j].QueryName
+	})
+
+	report.Files = make([]FileCounter, 0, len(files))
+	for _, counter := range files {
+		report.Files = append(report.Files, *counter)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].FileName < report.Files[j].FileName
+	})
+
+	return report
+}
+
+// indexFindings maps the similarity IDs, current and old, to the findings of the queries
+func indexFindings(queries model.QueryResultSlice) map[string]finding {
+	findings := make(map[string]finding)
+	for i := range queries {
+		for j := range queries[i].Files {
+			f := finding{query: &queries[i], file: &queries[i].Files[j]}
+			if queries[i].Files[j].SimilarityID != "" {
+				findings[queries[i].Files[j].SimilarityID] = f
+			}
+			if queries[i].Files[j].OldSimilarityID != "" {
+				findings[queries[i].Files[j].OldSimilarityID] = f
+			}
+		}
+	}
+	return findings
+}
+
+func matchFinding(file *model.VulnerableFile, findings map[string]finding) (finding, bool) {
+	if file.SimilarityID != "" {
+		if f, ok := findings[file.SimilarityID]; ok {
+			return f, true
+		}
+	}
+	if file.OldSimilarityID != "" {
+		if f, ok := findings[file.OldSimilarityID]; ok {
+			return f, true
+		}
+	}
+	return finding{}, false
+}
+
+func getQueryCounter(queries map[string]*QueryCounter, query *model.QueryResult) *QueryCounter {
+	if counter, ok := queries[query.QueryID]; ok {
+		// the severity of the latest results prevails
+		counter.Severity = query.Severity
+		return counter
+	}
+	counter := &QueryCounter{
+		QueryName: query.QueryName,
+		QueryID:   query.QueryID,
+		Platform:  query.Platform,
+		Severity:  query.Severity,
+	}
+	queries[query.QueryID] = counter
+	return counter
+}
+
+func getFileCounter(files map[string]*FileCounter, fileName string) *FileCounter {
+	if counter, ok := files[fileName]; ok {
+		return counter
+	}
+	counter := &FileCounter{FileName: fileName}
+	files[fileName] = counter
+	return counter
+}
+
+func addSeverityChange(changes map[string]*SeverityChange, oldQuery, newQuery *model.QueryResult, file *model.VulnerableFile) {
+	change, ok := changes[newQuery.QueryID]
+	if !ok {
+		change = &SeverityChange{
+			QueryName:   newQuery.QueryName,
+			QueryID:     newQuery.QueryID,
+			Platform:    newQuery.Platform,
+			OldSeverity: oldQuery.Severity,
+			NewSeverity: newQuery.Severity,
+			Files:       make([]model.VulnerableFile, 0),
+		}
+		changes[newQuery.QueryID] = change
+	}
+	change.Files = append(change.Files, *file)
+}
+
+func appendQueryFiles(queries model.QueryResultSlice, query *model.QueryResult, files []model.VulnerableFile) model.QueryResultSlice {
+	if len(files) == 0 {
+		return queries
+	}
+	queryCopy := *query
+	queryCopy.Files = files
+	return append(queries, queryCopy)
+}
@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompare tests the function [Compare()] and all the methods called by it
+func TestCompare(t *testing.T) {
+	oldSummary := &model.Summary{
+		Version: "v1.0.0",
+		Queries: model.QueryResultSlice{
+			{
+				QueryName: "Query A",
+				QueryID:   "query-a",
+				Severity:  model.SeverityHigh,
+				Files: []model.VulnerableFile{
+					{FileName: "main.tf", SimilarityID: "a-1"},
+					{FileName: "main.tf", SimilarityID: "a-removed"},
+				},
+			},
+			{
+				QueryName: "Query B",
+				QueryID:   "query-b",
+				Severity:  model.SeverityLow,
+				Files: []model.VulnerableFile{
+					{FileName: "other.tf", SimilarityID: "b-old"},
+				},
+			},
+		},
+	}
+	newSummary := &model.Summary{
+		Version: "v1.1.0",
+		Queries: model.QueryResultSlice{
+			{
+				QueryName: "Query A",
+				QueryID:   "query-a",
+				Severity:  model.SeverityHigh,
+				Files: []model.VulnerableFile{
+					{FileName: "main.tf", SimilarityID: "a-1"},
+					{FileName: "new.tf", SimilarityID: "a-added"},
+				},
+			},
+			{
+				QueryName: "Query B",
+				QueryID:   "query-b",
+				Severity:  model.SeverityMedium,
+				Files: []model.VulnerableFile{
+					{FileName: "other.tf", SimilarityID: "b-new", OldSimilarityID: "b-old"},
+				},
+			},
+		},
+	}
+
+	report := Compare(oldSummary, newSummary, "old.json", "new.json")
+
+	require.Equal(t, "old.json", report.OldResults)
+	require.Equal(t, "new.json", report.NewResults)
+	require.Equal(t, "v1.0.0", report.OldVersion)
+	require.Equal(t, "v1.1.0", report.NewVersion)
+	require.Equal(t, Counters{OldTotal: 3, NewTotal: 3, Added: 1, Removed: 1, SeverityChanged: 1}, report.Counters)
+	require.Equal(t, 1, report.AddedSeverityCounters[model.SeverityHigh])
+	require.Equal(t, 1, report.RemovedSeverityCounters[model.SeverityHigh])
+
+	require.Len(t, report.Added, 1)
+	require.Equal(t, "a-added", report.Added[0].Files[0].SimilarityID)
+	require.Len(t, report.Removed, 1)
+	require.Equal(t, "a-removed", report.Removed[0].Files[0].SimilarityID)
+
+	require.Len(t, report.SeverityChanged, 1)
+	require.Equal(t, model.Severity(model.SeverityLow), report.SeverityChanged[0].OldSeverity)
+	require.Equal(t, model.Severity(model.SeverityMedium), report.SeverityChanged[0].NewSeverity)
+	require.Len(t, report.SeverityChanged[0].Files, 1)
+
+	require.Equal(t, []QueryCounter{
+		{QueryName: "Query A", QueryID: "query-a", Severity: model.SeverityHigh, Old: 2, New: 2, Added: 1, Removed: 1},
+		{QueryName: "Query B", QueryID: "query-b", Severity: model.SeverityMedium, Old: 1, New: 1},
+	}, report.Queries)
+
+	require.Equal(t, []FileCounter{
+		{FileName: "main.tf", Old: 2, New: 1, Removed: 1},
+		{FileName: "new.tf", New: 1, Added: 1},
+		{FileName: "other.tf", Old: 1, New: 1},
+	}, report.Files)
+}
+
+// TestCompare_NoChanges tests the function [Compare()] with the same results
+func TestCompare_NoChanges(t *testing.T) {
+	summary := &model.Summary{
+		Queries: model.QueryResultSlice{
+			{
+				QueryID:  "query-a",
+				Severity: model.SeverityHigh,
+				Files:    []model.VulnerableFile{{FileName: "main.tf", SimilarityID: "a-1"}},
+			},
+		},
+	}
+
+	report := Compare(summary, summary, "old.json", "new.json")
+
+	require.Equal(t, Counters{OldTotal: 1, NewTotal: 1}, report.Counters)
+	require.Empty(t, report.Added)
+	require.Empty(t, report.Removed)
+	require.Empty(t, report.SeverityChanged)
+}
+
+// TestCompare_Environments tests the function [Compare()] with the results of a file rendered with two environments
+func TestCompare_Environments(t *testing.T) {
+	query := func(files ...model.VulnerableFile) model.QueryResultSlice {
+		return model.QueryResultSlice{
+			{QueryName: "Query A", QueryID: "query-a", Severity: model.SeverityHigh, Files: files},
+		}
+	}
+	dev := model.VulnerableFile{FileName: "chart/templates/deployment.yaml", Line: 10, SimilarityID: "a-dev",
+		Environment: "values-dev.yaml"}
+	prod := model.VulnerableFile{FileName: "chart/templates/deployment.yaml", Line: 10, SimilarityID: "a-prod",
+		Environment: "values-prod.yaml"}
+
+	report := Compare(&model.Summary{Queries: query(dev)}, &model.Summary{Queries: query(dev, prod)}, "old.json", "new.json")
+
+	require.Equal(t, Counters{OldTotal: 1, NewTotal: 2, Added: 1}, report.Counters)
+	require.Len(t, report.Added, 1)
+	require.Equal(t, []model.VulnerableFile{prod}, report.Added[0].Files)
+	require.Empty(t, report.Removed)
+}
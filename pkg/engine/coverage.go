@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/cover"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/rs/zerolog/log"
+)
+
+// queriesCoverage keeps the coverage of each evaluated query, it is shared by the copies of an inspector so the
+// coverage of all their scans is merged
+type queriesCoverage struct {
+	mu      sync.Mutex
+	queries map[string]*queryCoverage
+}
+
+// queryCoverage is the tracer of the evaluations of a query, accumulating the lines hit by all of them
+type queryCoverage struct {
+	mu       sync.Mutex
+	cover    *cover.Cover
+	metadata model.QueryMetadata
+}
+
+func newQueriesCoverage() *queriesCoverage {
+	return &queriesCoverage{
+		queries: make(map[string]*queryCoverage),
+	}
+}
+
+// get returns the tracer of the query, creating it on its first evaluation
+func (c *queriesCoverage) get(metadata *model.QueryMetadata) *queryCoverage {
+	key := fmt.Sprintf("%s\x00%s\x00%v", metadata.Platform, metadata.Query, metadata.Metadata["id"])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	query, ok := c.queries[key]
+	if !ok {
+		query = &queryCoverage{
+			cover:    cover.New(),
+			metadata: *metadata,
+		}
+		c.queries[key] = query
+	}
+	return query
+}
+
+// report returns the coverage of the evaluated queries
+func (c *queriesCoverage) report() *coverage.Report {
+	report := coverage.NewReport()
+	if c == nil {
+		return report
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, query := range c.queries {
+		queryReport, err := query.report()
+		if err != nil {
+			log.Debug().Msgf("Failed to get coverage of query %s: %s", query.metadata.Query, err)
+			continue
+		}
+		report.Add(queryReport)
+	}
+	return report
+}
+
+// coverReport returns the OPA coverage report of the evaluated queries, with the report of each query keyed by its name
+func (c *queriesCoverage) coverReport() cover.Report {
+	report := cover.Report{
+		Files: make(map[string]*cover.FileReport),
+	}
+	if c == nil {
+		return report
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, query := range c.queries {
+		_, fileReport, err := query.fileReport()
+		if err != nil {
+			log.Debug().Msgf("Failed to get coverage of query %s: %s", query.metadata.Query, err)
+			continue
+		}
+		report.Files[query.metadata.Query] = fileReport
+		report.CoveredLines += fileReport.CoveredLines
+		report.NotCoveredLines += fileReport.NotCoveredLines
+	}
+	if total := report.CoveredLines + report.NotCoveredLines; total != 0 {
+		report.Coverage = 100.0 * float64(report.CoveredLines) / float64(total)
+	}
+	return report
+}
+
+// Enabled is part of the topdown.QueryTracer interface
+func (q *queryCoverage) Enabled() bool {
+	return true
+}
+
+// Config is part of the topdown.QueryTracer interface
+func (q *queryCoverage) Config() topdown.TraceConfig {
+	return q.cover.Config()
+}
+
+// TraceEvent records the lines of the query hit by an evaluation, the same query can be evaluated by
+// concurrent scans
+func (q *queryCoverage) TraceEvent(event topdown.Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.cover.TraceEvent(event)
+}
+
+// fileReport returns the parsed query along with the OPA coverage report of its lines
+func (q *queryCoverage) fileReport() (*ast.Module, *cover.FileReport, error) {
+	module, err := ast.ParseModule(q.metadata.Query, q.metadata.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return module, q.cover.Report(map[string]*ast.Module{q.metadata.Query: module}).Files[q.metadata.Query], nil
+}
+
+func (q *queryCoverage) report() (*coverage.Query, error) {
+	module, fileReport, err := q.fileReport()
+	if err != nil {
+		return nil, err
+	}
+
+	queryReport := &coverage.Query{
+		QueryName:       fmt.Sprint(q.metadata.Metadata["queryName"]),
+		QueryID:         fmt.Sprint(q.metadata.Metadata["id"]),
+		Platform:        q.metadata.Platform,
+		Query:           q.metadata.Query,
+		Covered:         make([]coverage.Range, 0),
+		NotCovered:      make([]coverage.Range, 0),
+		NotCoveredRules: make([]coverage.Rule, 0),
+	}
+	if fileReport != nil {
+		queryReport.Covered = toCoverageRanges(fileReport.Covered)
+		queryReport.NotCovered = toCoverageRanges(fileReport.NotCovered)
+	}
+
+	ast.WalkRules(module, func(rule *ast.Rule) bool {
+		if fileReport.IsNotCovered(rule.Head.Location.Row) {
+			queryReport.NotCoveredRules = append(queryReport.NotCoveredRules, coverage.Rule{
+				Name: rule.Head.Ref().String(),
+				Line: rule.Head.Location.Row,
+			})
+		}
+		return false
+	})
+
+	lines := strings.Split(q.metadata.Content, "\n")
+	queryReport.Lines = make([]coverage.Line, 0, len(lines))
+	for i, line := range lines {
+		queryReport.Lines = append(queryReport.Lines, coverage.Line{
+			Number:  i + 1,
+			Content: line,
+			Status:  queryReport.LineStatus(i + 1),
+		})
+	}
+
+	return queryReport, nil
+}
+
+func toCoverageRanges(ranges []cover.Range) []coverage.Range {
+	coverageRanges := make([]coverage.Range, 0, len(ranges))
+	for _, r := range ranges {
+		coverageRanges = append(coverageRanges, coverage.Range{Start: r.Start.Row, End: r.End.Row})
+	}
+	return coverageRanges
+}
@@ -3,19 +3,25 @@ package engine
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Checkmarx/kics/v2/internal/metrics"
 	sentryReport "github.com/Checkmarx/kics/v2/internal/sentry"
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
 	"github.com/Checkmarx/kics/v2/pkg/detector"
 	"github.com/Checkmarx/kics/v2/pkg/detector/docker"
+	"github.com/Checkmarx/kics/v2/pkg/detector/dockercompose"
 	"github.com/Checkmarx/kics/v2/pkg/detector/helm"
+	"github.com/Checkmarx/kics/v2/pkg/detector/kustomize"
 	"github.com/Checkmarx/kics/v2/pkg/engine/source"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/open-policy-agent/opa/ast"
@@ -54,6 +60,13 @@ type QueryLoader struct {
 	platformLibraries map[string]source.RegoLibraries
 	querySum          int
 	QueriesMetadata   []model.QueryMetadata
+	prepared          *preparedQueries
+}
+
+// preparedQueries keeps the queries prepared for evaluation, so each query is compiled only once
+type preparedQueries struct {
+	mu      sync.Mutex
+	queries map[string]*rego.PreparedEvalQuery
 }
 
 // VulnerabilityBuilder represents a function that will build a vulnerability
@@ -77,7 +90,7 @@ type Inspector struct {
 	detector       *detector.DetectLine
 
 	enableCoverageReport bool
-	coverageReport       cover.Report
+	coverage             *queriesCoverage
 	queryExecTimeout     time.Duration
 	useOldSeverities     bool
 	numWorkers           int
@@ -155,10 +168,7 @@ func NewInspector(
 			Msgf("Inspector initialized, number of queries=%d", queryLoader.querySum)
 	}
 
-	lineDetector := detector.NewDetectLine(tracker.GetOutputLines()).
-		Add(helm.DetectKindLine{}, model.KindHELM).
-		Add(docker.DetectKindLine{}, model.KindDOCKER).
-		Add(docker.DetectKindLine{}, model.KindBUILDAH)
+	lineDetector := newLineDetector(tracker.GetOutputLines())
 
 	queryExecTimeout := time.Duration(queryTimeout) * time.Second
 
@@ -180,6 +190,15 @@ func NewInspector(
 	}, nil
 }
 
+func newLineDetector(outputLines int) *detector.DetectLine {
+	return detector.NewDetectLine(outputLines).
+		Add(helm.DetectKindLine{}, model.KindHELM).
+		Add(kustomize.DetectKindLine{}, model.KindKUSTOMIZE).
+		Add(docker.DetectKindLine{}, model.KindDOCKER).
+		Add(docker.DetectKindLine{}, model.KindBUILDAH).
+		Add(dockercompose.DetectKindLine{}, model.KindYAML)
+}
+
 func getPlatformLibraries(queriesSource source.QueriesSource, queries []model.QueryMetadata) map[string]source.RegoLibraries {
 	supportedPlatforms := make(map[string]string)
 	for _, query := range queries {
@@ -364,11 +383,63 @@ func (c *Inspector) getQueriesByPlat(platforms []string) []model.QueryMetadata {
 // EnableCoverageReport enables the flag to create a coverage report
 func (c *Inspector) EnableCoverageReport() {
 	c.enableCoverageReport = true
+	c.coverage = newQueriesCoverage()
 }
 
 // GetCoverageReport returns the scan coverage report
 func (c *Inspector) GetCoverageReport() cover.Report {
-	return c.coverageReport
+	return c.coverage.coverReport()
+}
+
+// GetQueriesCoverageReport returns the covered lines and rules of the queries evaluated since the coverage report
+// was enabled, including the evaluations of the copies of the inspector
+func (c *Inspector) GetQueriesCoverageReport() *coverage.Report {
+	return c.coverage.report()
+}
+
+// KeepPreparedQueries prepares the queries for evaluation and keeps them in memory, so later inspections
+// reuse them instead of compiling them again
+func (c *Inspector) KeepPreparedQueries(ctx context.Context) error {
+	c.QueryLoader.prepared = &preparedQueries{
+		queries: make(map[string]*rego.PreparedEvalQuery),
+	}
+
+	queries := c.QueryLoader.QueriesMetadata
+	jobs := make(chan int, len(queries))
+	for i := range queries {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < c.numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// queries failing to be prepared are reported and skipped, as they are on inspections
+				if _, err := c.QueryLoader.LoadQuery(ctx, &queries[i]); err != nil {
+					log.Debug().Msgf("Failed to prepare query %s: %s", queries[i].Query, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// WithTracker returns a copy of the inspector sharing its loaded queries but tracking the scan with the given
+// tracker and keeping its own failed queries, so concurrent scans can use the same queries
+func (c *Inspector) WithTracker(tracker Tracker) *Inspector {
+	inspector := *c
+	inspector.tracker = tracker
+	inspector.failedQueries = make(map[string]error)
+	inspector.detector = newLineDetector(tracker.GetOutputLines())
+	for _, metadata := range c.QueryLoader.QueriesMetadata {
+		tracker.TrackQueryLoad(metadata.Aggregation)
+	}
+	return &inspector
 }
 
 // GetFailedQueries returns a map of failed queries and the associated error
@@ -376,6 +447,41 @@ func (c *Inspector) GetFailedQueries() map[string]error {
 	return c.failedQueries
 }
 
+// QueriesHash returns a hash of the loaded queries, their input data and the libraries, which changes whenever
+// any of them changes
+func (c *Inspector) QueriesHash() string {
+	queries := make([]model.QueryMetadata, len(c.QueryLoader.QueriesMetadata))
+	copy(queries, c.QueryLoader.QueriesMetadata)
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].Query < queries[j].Query
+	})
+
+	hash := sha256.New()
+	for i := range queries {
+		metadata, err := json.Marshal(queries[i].Metadata)
+		if err != nil {
+			log.Debug().Msgf("failed to marshal metadata of query %s: %s", queries[i].Query, err)
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00%s\x00%d\x00%t\x00",
+			queries[i].Query, queries[i].Content, queries[i].InputData, metadata,
+			queries[i].Platform, queries[i].Aggregation, queries[i].Experimental)
+	}
+
+	fmt.Fprintf(hash, "common\x00%s\x00%s\x00",
+		c.QueryLoader.commonLibrary.LibraryCode, c.QueryLoader.commonLibrary.LibraryInputData)
+	platforms := make([]string, 0, len(c.QueryLoader.platformLibraries))
+	for platform := range c.QueryLoader.platformLibraries {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		library := c.QueryLoader.platformLibraries[platform]
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", platform, library.LibraryCode, library.LibraryInputData)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 func (c *Inspector) doRun(ctx *QueryContext) (vulns []model.Vulnerability, err error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx.Ctx, c.queryExecTimeout)
 	defer cancel()
@@ -389,10 +495,8 @@ func (c *Inspector) doRun(ctx *QueryContext) (vulns []model.Vulnerability, err e
 	}()
 	options := []rego.EvalOption{rego.EvalParsedInput(*ctx.payload)}
 
-	var cov *cover.Cover
 	if c.enableCoverageReport {
-		cov = cover.New()
-		options = append(options, rego.EvalQueryTracer(cov))
+		options = append(options, rego.EvalQueryTracer(c.coverage.get(&ctx.Query.Metadata)))
 	}
 
 	results, err := ctx.Query.OpaQuery.Eval(timeoutCtx, options...)
@@ -404,17 +508,6 @@ func (c *Inspector) doRun(ctx *QueryContext) (vulns []model.Vulnerability, err e
 
 		return nil, errors.Wrap(err, "failed to evaluate query")
 	}
-	if c.enableCoverageReport && cov != nil {
-		module, parseErr := ast.ParseModule(ctx.Query.Metadata.Query, ctx.Query.Metadata.Content)
-		if parseErr != nil {
-			return nil, errors.Wrap(parseErr, "failed to parse coverage module")
-		}
-
-		c.coverageReport = cov.Report(map[string]*ast.Module{
-			ctx.Query.Metadata.Query: module,
-		})
-	}
-
 	log.Trace().
 		Str("scanID", ctx.scanID).
 		Msgf("Inspector executed with result %+v, query=%s", results, ctx.Query.Metadata.Query)
@@ -588,8 +681,31 @@ func prepareQueries(queries []model.QueryMetadata, commonLibrary source.RegoLibr
 	}
 }
 
-// LoadQuery loads the query into memory so it can be freed when not used anymore
+// LoadQuery loads the query into memory so it can be freed when not used anymore, unless prepared queries are kept
 func (q QueryLoader) LoadQuery(ctx context.Context, query *model.QueryMetadata) (*rego.PreparedEvalQuery, error) {
+	if q.prepared == nil {
+		return q.prepareQuery(ctx, query)
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%v", query.Platform, query.Query, query.Metadata["id"])
+	q.prepared.mu.Lock()
+	opaQuery, ok := q.prepared.queries[key]
+	q.prepared.mu.Unlock()
+	if ok {
+		return opaQuery, nil
+	}
+
+	opaQuery, err := q.prepareQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	q.prepared.mu.Lock()
+	q.prepared.queries[key] = opaQuery
+	q.prepared.mu.Unlock()
+	return opaQuery, nil
+}
+
+func (q QueryLoader) prepareQuery(ctx context.Context, query *model.QueryMetadata) (*rego.PreparedEvalQuery, error) {
 	opaQuery := rego.PreparedEvalQuery{}
 
 	platformGeneralQuery, ok := q.platformLibraries[query.Platform]
@@ -12,11 +12,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/open-policy-agent/opa/cover"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/Checkmarx/kics/v2/assets"
 	"github.com/Checkmarx/kics/v2/internal/tracker"
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
 	"github.com/Checkmarx/kics/v2/pkg/detector"
 	"github.com/Checkmarx/kics/v2/pkg/detector/docker"
 	"github.com/Checkmarx/kics/v2/pkg/detector/helm"
@@ -28,8 +30,6 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
-
-	"github.com/open-policy-agent/opa/cover"
 )
 
 // TestInspector_EnableCoverageReport tests the functions [EnableCoverageReport()] and all the methods called by them
@@ -41,7 +41,7 @@ func TestInspector_EnableCoverageReport(t *testing.T) {
 		vb                   VulnerabilityBuilder
 		tracker              Tracker
 		enableCoverageReport bool
-		coverageReport       cover.Report
+		coverage             *queriesCoverage
 	}
 	tests := []struct {
 		name   string
@@ -55,7 +55,18 @@ func TestInspector_EnableCoverageReport(t *testing.T) {
 				vb:                   DefaultVulnerabilityBuilder,
 				tracker:              &tracker.CITracker{},
 				enableCoverageReport: false,
-				coverageReport:       cover.Report{},
+				coverage:             nil,
+			},
+			want: true,
+		},
+		{
+			name: "enable_coverage_report_2",
+			fields: fields{
+				queryLoader:          &QueryLoader{},
+				vb:                   DefaultVulnerabilityBuilder,
+				tracker:              &tracker.CITracker{},
+				enableCoverageReport: true,
+				coverage:             newQueriesCoverage(),
 			},
 			want: true,
 		},
@@ -67,59 +78,199 @@ func TestInspector_EnableCoverageReport(t *testing.T) {
 				vb:                   tt.fields.vb,
 				tracker:              tt.fields.tracker,
 				enableCoverageReport: tt.fields.enableCoverageReport,
-				coverageReport:       tt.fields.coverageReport,
+				coverage:             tt.fields.coverage,
 			}
 			c.EnableCoverageReport()
 			if !reflect.DeepEqual(c.enableCoverageReport, tt.want) {
 				t.Errorf("Inspector.enableCoverageReport() = %v, want %v", c.enableCoverageReport, tt.want)
 			}
+			require.NotNil(t, c.coverage)
+			require.Same(t, c.coverage, c.WithTracker(&tracker.CITracker{}).coverage)
 		})
 	}
 }
 
-// TestInspector_GetCoverageReport tests the functions [GetCoverageReport()] and all the methods called by them
-func TestInspector_GetCoverageReport(t *testing.T) {
-	coverageReports := cover.Report{
-		Coverage: 75.5,
-		Files:    map[string]*cover.FileReport{},
+// coverageTestQuery is a query with two rules, only the first one matching the documents of kind "covered"
+var coverageTestQuery = model.QueryMetadata{
+	Query:    "coverage_query",
+	Platform: "Dockerfile",
+	Metadata: map[string]interface{}{"id": "a-b-c", "queryName": "Coverage Query"},
+	Content: `package Cx
+
+CxPolicy[result] {
+	input.document[_].kind == "covered"
+	result := {}
+}
+
+CxPolicy[result] {
+	input.document[_].kind == "not_covered"
+	result := {}
+}`,
+}
+
+// evalCoverageTestQuery evaluates the coverage test query over the documents, tracing it as the inspector does
+// when the coverage report is enabled
+func evalCoverageTestQuery(t *testing.T, c *Inspector, enableCoverageReport bool, documents []interface{}) {
+	options := []rego.EvalOption{rego.EvalInput(map[string]interface{}{"document": documents})}
+	if enableCoverageReport {
+		c.EnableCoverageReport()
+		options = append(options, rego.EvalQueryTracer(c.coverage.get(&coverageTestQuery)))
 	}
 
-	type fields struct {
-		queryLoader          *QueryLoader
-		vb                   VulnerabilityBuilder
-		tracker              Tracker
+	prepared, err := rego.New(
+		rego.Query(regoQuery),
+		rego.Module(coverageTestQuery.Query, coverageTestQuery.Content),
+	).PrepareForEval(context.Background())
+	require.NoError(t, err)
+	_, err = prepared.Eval(context.Background(), options...)
+	require.NoError(t, err)
+}
+
+// TestInspector_GetCoverageReport tests the functions [GetCoverageReport()] and all the methods called by them
+func TestInspector_GetCoverageReport(t *testing.T) {
+	tests := []struct {
+		name                 string
 		enableCoverageReport bool
-		coverageReport       cover.Report
+		documents            []interface{}
+		want                 cover.Report
+	}{
+		{
+			name:                 "get_coverage_report_disabled",
+			enableCoverageReport: false,
+			documents:            []interface{}{map[string]interface{}{"kind": "covered"}},
+			want: cover.Report{
+				Files: map[string]*cover.FileReport{},
+			},
+		},
+		{
+			name:                 "get_coverage_report_not_evaluated_rule",
+			enableCoverageReport: true,
+			documents:            []interface{}{map[string]interface{}{"kind": "covered"}},
+			want: cover.Report{
+				Files: map[string]*cover.FileReport{
+					"coverage_query": {
+						Covered:         []cover.Range{coverRange(3, 5), coverRange(9, 9)},
+						NotCovered:      []cover.Range{coverRange(8, 8), coverRange(10, 10)},
+						CoveredLines:    4,
+						NotCoveredLines: 2,
+						Coverage:        100.0 * 4 / 6,
+					},
+				},
+				CoveredLines:    4,
+				NotCoveredLines: 2,
+				Coverage:        100.0 * 4 / 6,
+			},
+		},
+		{
+			name:                 "get_coverage_report_all_rules_evaluated",
+			enableCoverageReport: true,
+			documents:            []interface{}{map[string]interface{}{"kind": "covered"}, map[string]interface{}{"kind": "not_covered"}},
+			want: cover.Report{
+				Files: map[string]*cover.FileReport{
+					"coverage_query": {
+						Covered:      []cover.Range{coverRange(3, 5), coverRange(8, 10)},
+						CoveredLines: 6,
+						Coverage:     100,
+					},
+				},
+				CoveredLines: 6,
+				Coverage:     100,
+			},
+		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Inspector{
+				QueryLoader: &QueryLoader{},
+				vb:          DefaultVulnerabilityBuilder,
+				tracker:     &tracker.CITracker{},
+			}
+			evalCoverageTestQuery(t, c, tt.enableCoverageReport, tt.documents)
+			got := c.GetCoverageReport()
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func coverRange(start, end int) cover.Range {
+	return cover.Range{Start: cover.Position{Row: start}, End: cover.Position{Row: end}}
+}
+
+// TestInspector_GetQueriesCoverageReport tests the functions [GetQueriesCoverageReport()] and all the methods called by them
+func TestInspector_GetQueriesCoverageReport(t *testing.T) {
 	tests := []struct {
-		name   string
-		fields fields
-		want   cover.Report
+		name                 string
+		enableCoverageReport bool
+		documents            []interface{}
+		want                 *coverage.Report
 	}{
 		{
-			name: "get_coverage_report_1",
-			fields: fields{
-				queryLoader:          &QueryLoader{},
-				vb:                   DefaultVulnerabilityBuilder,
-				tracker:              &tracker.CITracker{},
-				enableCoverageReport: false,
-				coverageReport:       coverageReports,
+			name:                 "get_queries_coverage_report_disabled",
+			enableCoverageReport: false,
+			documents:            []interface{}{map[string]interface{}{"kind": "covered"}},
+			want:                 coverage.NewReport(),
+		},
+		{
+			name:                 "get_queries_coverage_report_not_evaluated_rule",
+			enableCoverageReport: true,
+			documents:            []interface{}{map[string]interface{}{"kind": "covered"}},
+			want: &coverage.Report{
+				Coverage:        100.0 * 4 / 6,
+				CoveredLines:    4,
+				NotCoveredLines: 2,
+				Queries: []coverage.Query{
+					{
+						QueryName:       "Coverage Query",
+						QueryID:         "a-b-c",
+						Platform:        "Dockerfile",
+						Query:           "coverage_query",
+						Coverage:        100.0 * 4 / 6,
+						CoveredLines:    4,
+						NotCoveredLines: 2,
+						Covered:         []coverage.Range{{Start: 3, End: 5}, {Start: 9, End: 9}},
+						NotCovered:      []coverage.Range{{Start: 8, End: 8}, {Start: 10, End: 10}},
+						NotCoveredRules: []coverage.Rule{{Name: "CxPolicy", Line: 8}},
+					},
+				},
+			},
+		},
+		{
+			name:                 "get_queries_coverage_report_all_rules_evaluated",
+			enableCoverageReport: true,
+			documents:            []interface{}{map[string]interface{}{"kind": "covered"}, map[string]interface{}{"kind": "not_covered"}},
+			want: &coverage.Report{
+				Coverage:     100,
+				CoveredLines: 6,
+				Queries: []coverage.Query{
+					{
+						QueryName:       "Coverage Query",
+						QueryID:         "a-b-c",
+						Platform:        "Dockerfile",
+						Query:           "coverage_query",
+						Coverage:        100,
+						CoveredLines:    6,
+						Covered:         []coverage.Range{{Start: 3, End: 5}, {Start: 8, End: 10}},
+						NotCovered:      []coverage.Range{},
+						NotCoveredRules: []coverage.Rule{},
+					},
+				},
 			},
-			want: coverageReports,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Inspector{
-				QueryLoader:          tt.fields.queryLoader,
-				vb:                   tt.fields.vb,
-				tracker:              tt.fields.tracker,
-				enableCoverageReport: tt.fields.enableCoverageReport,
-				coverageReport:       tt.fields.coverageReport,
+				QueryLoader: &QueryLoader{},
+				vb:          DefaultVulnerabilityBuilder,
+				tracker:     &tracker.CITracker{},
 			}
-			if got := c.GetCoverageReport(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Inspector.GetCoverageReport() = %v, want %v", got, tt.want)
+			evalCoverageTestQuery(t, c, tt.enableCoverageReport, tt.documents)
+			got := c.GetQueriesCoverageReport()
+			// the source lines of the queries are only used by the HTML report
+			for i := range got.Queries {
+				got.Queries[i].Lines = nil
 			}
+			require.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -182,7 +333,7 @@ func TestInspect(t *testing.T) { //nolint
 		vb                   VulnerabilityBuilder
 		tracker              Tracker
 		enableCoverageReport bool
-		coverageReport       cover.Report
+		coverage             *queriesCoverage
 		excludeResults       map[string]bool
 	}
 	type args struct {
@@ -217,7 +368,7 @@ func TestInspect(t *testing.T) { //nolint
 				vb:                   DefaultVulnerabilityBuilder,
 				tracker:              &tracker.CITracker{},
 				enableCoverageReport: true,
-				coverageReport:       cover.Report{},
+				coverage:             newQueriesCoverage(),
 				excludeResults:       map[string]bool{},
 			},
 			args: args{
@@ -282,7 +433,7 @@ func TestInspect(t *testing.T) { //nolint
 				vb:                   DefaultVulnerabilityBuilder,
 				tracker:              &tracker.CITracker{},
 				enableCoverageReport: true,
-				coverageReport:       cover.Report{},
+				coverage:             newQueriesCoverage(),
 				excludeResults:       map[string]bool{"fec62a97d569662093dbb9739360942fc2a0c47bedec0bfcae05dc9d899d3ebe": true},
 			},
 			args: args{
@@ -320,7 +471,7 @@ func TestInspect(t *testing.T) { //nolint
 				vb:                   tt.fields.vb,
 				tracker:              tt.fields.tracker,
 				enableCoverageReport: tt.fields.enableCoverageReport,
-				coverageReport:       tt.fields.coverageReport,
+				coverage:             tt.fields.coverage,
 				excludeResults:       tt.fields.excludeResults,
 				detector:             inspDetector,
 				queryExecTimeout:     time.Duration(60) * time.Second,
@@ -631,6 +782,89 @@ func TestEngine_GetFailedQueries(t *testing.T) {
 	}
 }
 
+func TestEngine_QueriesHash(t *testing.T) {
+	newInspector := func(queryContent, libraryCode string) *Inspector {
+		return &Inspector{
+			QueryLoader: &QueryLoader{
+				commonLibrary: source.RegoLibraries{LibraryCode: "common"},
+				platformLibraries: map[string]source.RegoLibraries{
+					"terraform": {LibraryCode: libraryCode, LibraryInputData: "{}"},
+				},
+				QueriesMetadata: []model.QueryMetadata{
+					{Query: "query-b", Content: "content-b", Platform: "terraform"},
+					{Query: "query-a", Content: queryContent, Platform: "terraform", InputData: "{}"},
+				},
+			},
+		}
+	}
+
+	hash := newInspector("content-a", "library").QueriesHash()
+	require.Equal(t, hash, newInspector("content-a", "library").QueriesHash())
+	require.NotEqual(t, hash, newInspector("changed", "library").QueriesHash())
+	require.NotEqual(t, hash, newInspector("content-a", "changed").QueriesHash())
+
+	// the order of the loaded queries does not change the hash
+	reordered := newInspector("content-a", "library")
+	queries := reordered.QueryLoader.QueriesMetadata
+	queries[0], queries[1] = queries[1], queries[0]
+	require.Equal(t, hash, reordered.QueriesHash())
+}
+
+func TestEngine_KeepPreparedQueries(t *testing.T) {
+	query := model.QueryMetadata{
+		Query:    "query",
+		Content:  "package Cx\n\nCxPolicy[result] {\n\tresult := {}\n}\n",
+		Platform: "terraform",
+		Metadata: map[string]interface{}{"id": "ffdf4b37-7703-4dfe-a682-9d2e99bc6c09"},
+	}
+	ins := &Inspector{
+		numWorkers: 2,
+		QueryLoader: &QueryLoader{
+			commonLibrary: source.RegoLibraries{LibraryCode: "package generic.common", LibraryInputData: "{}"},
+			platformLibraries: map[string]source.RegoLibraries{
+				"terraform": {LibraryCode: "package generic.terraform", LibraryInputData: "{}"},
+			},
+			QueriesMetadata: []model.QueryMetadata{query},
+		},
+	}
+
+	first, err := ins.QueryLoader.LoadQuery(context.Background(), &query)
+	require.NoError(t, err)
+	second, err := ins.QueryLoader.LoadQuery(context.Background(), &query)
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+
+	require.NoError(t, ins.KeepPreparedQueries(context.Background()))
+	require.Len(t, ins.QueryLoader.prepared.queries, 1)
+	first, err = ins.QueryLoader.LoadQuery(context.Background(), &query)
+	require.NoError(t, err)
+	second, err = ins.QueryLoader.LoadQuery(context.Background(), &query)
+	require.NoError(t, err)
+	require.Same(t, first, second)
+}
+
+func TestEngine_WithTracker(t *testing.T) {
+	ins := &Inspector{
+		QueryLoader: &QueryLoader{
+			QueriesMetadata: []model.QueryMetadata{
+				{Query: "query-a", Aggregation: 1},
+				{Query: "query-b", Aggregation: 2},
+			},
+		},
+		tracker:       &tracker.CITracker{},
+		failedQueries: map[string]error{"query-a": ErrNoResult},
+	}
+
+	scanTracker := &tracker.CITracker{}
+	got := ins.WithTracker(scanTracker)
+
+	require.Same(t, ins.QueryLoader, got.QueryLoader)
+	require.Equal(t, 3, scanTracker.LoadedQueries)
+	require.Empty(t, got.GetFailedQueries())
+	require.Len(t, ins.GetFailedQueries(), 1)
+	require.NotSame(t, ins.tracker, got.tracker)
+}
+
 func TestShouldSkipFile(t *testing.T) {
 	type args struct {
 		commands model.CommentsCommands
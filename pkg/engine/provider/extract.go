@@ -13,6 +13,7 @@ import (
 
 	"github.com/Checkmarx/kics/v2/pkg/kuberneter"
 	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/oci"
 	"github.com/Checkmarx/kics/v2/pkg/utils"
 	"github.com/rs/zerolog/log"
 
@@ -68,6 +69,37 @@ func GetKuberneterSources(ctx context.Context, source []string, destinationPath
 	return extrStruct, nil
 }
 
+// GetOCISources imports the container images of OCI or docker save tarballs
+// After imported the config and files of the images kics scan them as normal local files
+func GetOCISources(ctx context.Context, source []string) (ExtractedPath, error) {
+	extrStruct := ExtractedPath{
+		Path:          []string{},
+		ExtractionMap: make(map[string]model.ExtractedPathObject),
+	}
+
+	for _, path := range source {
+		destination := filepath.Join(os.TempDir(), "kics-extract-oci-"+utils.NextRandom())
+
+		importedPath, err := oci.Import(ctx, path, destination)
+		if err != nil {
+			log.Error().Msgf("failed to import %s: %s", path, err)
+			if errRemove := os.RemoveAll(destination); errRemove != nil {
+				log.Err(errRemove).Msgf("failed to remove %s", destination)
+			}
+			return ExtractedPath{}, err
+		}
+
+		extrStruct.ExtractionMap[importedPath] = model.ExtractedPathObject{
+			Path:      path,
+			LocalPath: true,
+		}
+
+		extrStruct.Path = append(extrStruct.Path, importedPath)
+	}
+
+	return extrStruct, nil
+}
+
 // GetSources goes through the source slice, and determines the of source type (ex: zip, git, local).
 // It than extracts the files to be scanned. If the source given is not local, a temp dir
 // will be created where the files will be stored.
@@ -17,6 +17,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/yargevad/filepathx"
+	"sigs.k8s.io/kustomize/api/konfig"
 )
 
 // FileSystemSourceProvider provides a path to be scanned
@@ -24,6 +25,7 @@ import (
 type FileSystemSourceProvider struct {
 	paths    []string
 	excludes map[string][]os.FileInfo
+	includes map[string]bool
 	mu       sync.RWMutex
 }
 
@@ -83,6 +85,46 @@ func (s *FileSystemSourceProvider) AddExcluded(excludePaths []string) error {
 	return nil
 }
 
+// SetIncludedFiles restricts the File System Source Provider to the given files,
+// directories not containing any of them are skipped
+func (s *FileSystemSourceProvider) SetIncludedFiles(files []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.includes = make(map[string]bool, len(files))
+	for _, file := range files {
+		s.includes[resolvePath(file)] = true
+	}
+}
+
+// isIncluded checks if a file, or a directory containing one, was included in the scan
+func (s *FileSystemSourceProvider) isIncluded(path string, isDir bool) bool {
+	if s.includes == nil {
+		return true
+	}
+	resolved := resolvePath(path)
+	if !isDir {
+		return s.includes[resolved]
+	}
+	for file := range s.includes {
+		if strings.HasPrefix(file, resolved+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path with symbolic links evaluated
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(filepath.FromSlash(path))
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
 // GetExcludePaths gets all the files that should be excluded
 func GetExcludePaths(pathExpressions string) ([]string, error) {
 	if strings.ContainsAny(pathExpressions, "*?[") {
@@ -101,6 +143,16 @@ func (s *FileSystemSourceProvider) GetBasePaths() []string {
 	return s.paths
 }
 
+// isKustomizationDir checks whether a directory has a kustomization file to be rendered
+func isKustomizationDir(path string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if info, err := os.Stat(filepath.Join(path, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
 // ignoreDamagedFiles checks whether we should ignore a damaged file from a scan or not.
 func ignoreDamagedFiles(path string) bool {
 	shouldIgnoreFile := false
@@ -130,6 +182,12 @@ func (s *FileSystemSourceProvider) GetSources(ctx context.Context,
 		}
 
 		if !fileInfo.IsDir() {
+			s.mu.RLock()
+			included := s.isIncluded(scanPath, false)
+			s.mu.RUnlock()
+			if !included {
+				continue
+			}
 			c, openFileErr := openScanFile(scanPath, extensions)
 			if openFileErr != nil {
 				if openFileErr == ErrNotSupportedFile || ignoreDamagedFiles(scanPath) {
@@ -163,7 +221,7 @@ func (s *FileSystemSourceProvider) walkDir(ctx context.Context, scanPath string,
 			return skipFolder
 		}
 
-		// ------------------ Helm resolver --------------------------------
+		// ------------------ Helm and Kustomize resolvers -----------------
 		if info.IsDir() {
 			excluded, errRes := resolverSink(ctx, strings.ReplaceAll(path, "\\", "/"))
 			if errRes != nil {
@@ -178,7 +236,10 @@ func (s *FileSystemSourceProvider) walkDir(ctx context.Context, scanPath string,
 			if errAdd := s.AddExcluded(excluded); errAdd != nil {
 				log.Err(errAdd).Msgf("Filesystem files provider couldn't exclude rendered Chart files, Chart=%s", info.Name())
 			}
-			resolved = true
+			// kustomizations do not prevent the charts in their subdirectories from being resolved
+			if !isKustomizationDir(path) {
+				resolved = true
+			}
 			return nil
 		}
 		// -----------------------------------------------------------------
@@ -250,6 +311,13 @@ func (s *FileSystemSourceProvider) checkConditions(info os.FileInfo, extensions
 			log.Info().Msgf("Directory ignored: %s", path)
 			return true, filepath.SkipDir
 		}
+		if !s.isIncluded(path, true) {
+			log.Trace().Msgf("Directory ignored: %s", path)
+			return true, filepath.SkipDir
+		}
+		if isKustomizationDir(path) {
+			return false, nil
+		}
 		_, err := os.Stat(filepath.Join(path, "Chart.yaml"))
 		if err != nil || resolved {
 			return true, nil
@@ -261,6 +329,10 @@ func (s *FileSystemSourceProvider) checkConditions(info os.FileInfo, extensions
 		log.Trace().Msgf("File ignored: %s", path)
 		return true, nil
 	}
+	if !s.isIncluded(path, false) {
+		log.Trace().Msgf("File ignored: %s", path)
+		return true, nil
+	}
 	ext, _ := utils.GetExtension(path)
 	if !extensions.Include(ext) {
 		log.Trace().Msgf("File ignored: %s", path)
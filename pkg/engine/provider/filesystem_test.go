@@ -771,3 +771,38 @@ func TestProvider_getExcludePaths(t *testing.T) {
 		})
 	}
 }
+
+// TestFileSystemSourceProvider_SetIncludedFiles tests the functions [SetIncludedFiles()] and all the methods called by them
+func TestFileSystemSourceProvider_SetIncludedFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"changed/Dockerfile", "changed/other.dockerfile", "unchanged/Dockerfile"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, os.WriteFile(path, []byte("FROM alpine\n"), 0600))
+	}
+	changedFile := filepath.Join(dir, "changed", "Dockerfile")
+
+	fs, err := NewFileSystemSourceProvider([]string{dir}, []string{})
+	require.NoError(t, err)
+	fs.SetIncludedFiles([]string{changedFile})
+
+	scanned := make([]string, 0)
+	sink := func(ctx context.Context, filename string, content io.ReadCloser) error {
+		scanned = append(scanned, filepath.FromSlash(filename))
+		return nil
+	}
+	extensions := model.Extensions{
+		"Dockerfile":  dockerParser.Parser{},
+		".dockerfile": dockerParser.Parser{},
+	}
+
+	require.NoError(t, fs.GetSources(context.Background(), extensions, sink, mockResolverSink))
+	require.Equal(t, []string{changedFile}, scanned)
+
+	scanned = make([]string, 0)
+	fileFs, err := NewFileSystemSourceProvider([]string{filepath.Join(dir, "unchanged", "Dockerfile")}, []string{})
+	require.NoError(t, err)
+	fileFs.SetIncludedFiles([]string{changedFile})
+	require.NoError(t, fileFs.GetSources(context.Background(), extensions, sink, mockResolverSink))
+	require.Empty(t, scanned)
+}
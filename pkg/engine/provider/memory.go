@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// MemorySourceProvider provides the sources of files held in memory, keyed by their path.
+// It never touches the filesystem, and can be used by parsers as the filesystem surrounding the parsed files,
+// the relative paths leading outside of the files, ex: ../etc/passwd, are rejected.
+// Helm charts and Kustomize directories are not rendered, since their resolvers read the files from disk
+type MemorySourceProvider struct {
+	files map[string][]byte
+	paths []string
+}
+
+// NewMemorySourceProvider initializes a MemorySourceProvider with the content of the given files
+func NewMemorySourceProvider(files map[string][]byte) *MemorySourceProvider {
+	provider := &MemorySourceProvider{
+		files: make(map[string][]byte, len(files)),
+		paths: make([]string, 0, len(files)),
+	}
+	for path, content := range files {
+		path = cleanMemoryPath(path)
+		if _, ok := provider.files[path]; !ok {
+			provider.paths = append(provider.paths, path)
+		}
+		provider.files[path] = content
+	}
+	sort.Strings(provider.paths)
+	return provider
+}
+
+// GetBasePaths returns no base paths, the paths of the files are already relative
+func (s *MemorySourceProvider) GetBasePaths() []string {
+	return []string{}
+}
+
+// GetSources executes the sink function on each file with a supported extension
+func (s *MemorySourceProvider) GetSources(ctx context.Context,
+	extensions model.Extensions, sink Sink, _ ResolverSink) error {
+	for _, path := range s.paths {
+		content := s.files[path]
+		if !extensions.Include(utils.GetExtensionFromContent(path, content)) {
+			continue
+		}
+		if err := sink(ctx, filepath.ToSlash(path), io.NopCloser(bytes.NewReader(content))); err != nil {
+			log.Err(err).Msgf("Memory files provider couldn't parse file, file=%s", path)
+		}
+	}
+	return nil
+}
+
+// Glob returns the paths of the files matching the pattern
+func (s *MemorySourceProvider) Glob(pattern string) ([]string, error) {
+	pattern = cleanMemoryPath(pattern)
+	matches := make([]string, 0)
+	for _, path := range s.paths {
+		matched, err := filepath.Match(pattern, path)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, path)
+		}
+	}
+	return matches, nil
+}
+
+// ReadFile returns the content of a file
+func (s *MemorySourceProvider) ReadFile(name string) ([]byte, error) {
+	if isOutside(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: filesystem.ErrOutsideRoots}
+	}
+	content, ok := s.files[cleanMemoryPath(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return content, nil
+}
+
+// Stat returns the information of a file, or of a directory holding some files
+func (s *MemorySourceProvider) Stat(name string) (fs.FileInfo, error) {
+	if isOutside(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: filesystem.ErrOutsideRoots}
+	}
+	name = cleanMemoryPath(name)
+	if content, ok := s.files[name]; ok {
+		return &memoryFileInfo{name: filepath.Base(name), size: int64(len(content))}, nil
+	}
+	prefix := name + string(filepath.Separator)
+	for _, path := range s.paths {
+		if name == "." || strings.HasPrefix(path, prefix) {
+			return &memoryFileInfo{name: filepath.Base(name), dir: true}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// memoryFileInfo is the information of a file or directory held in memory
+type memoryFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i *memoryFileInfo) Name() string       { return i.name }
+func (i *memoryFileInfo) Size() int64        { return i.size }
+func (i *memoryFileInfo) ModTime() time.Time { return time.Time{} }
+func (i *memoryFileInfo) IsDir() bool        { return i.dir }
+func (i *memoryFileInfo) Sys() interface{}   { return nil }
+
+func (i *memoryFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func cleanMemoryPath(path string) string {
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
+// isOutside returns true if a relative path leads outside of the root of the files, the absolute paths are
+// only looked up among the files
+func isOutside(path string) bool {
+	path = cleanMemoryPath(path)
+	return !filepath.IsAbs(path) && !filesystem.Contains(".", path)
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySourceProvider_GetSources(t *testing.T) {
+	provider := NewMemorySourceProvider(map[string][]byte{
+		"infra/main.tf":   []byte(`resource "aws_s3_bucket" "b" {}`),
+		"infra/README.md": []byte("# infra"),
+		"images/base":     []byte("FROM alpine:3.18\n"),
+	})
+
+	got := make(map[string]string)
+	err := provider.GetSources(context.Background(),
+		model.Extensions{".tf": {}, "possibleDockerfile": {}},
+		func(ctx context.Context, filename string, rc io.ReadCloser) error {
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			got[filename] = string(content)
+			return nil
+		}, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"infra/main.tf": `resource "aws_s3_bucket" "b" {}`,
+		"images/base":   "FROM alpine:3.18\n",
+	}, got)
+	require.Empty(t, provider.GetBasePaths())
+}
+
+func TestMemorySourceProvider_FileSystem(t *testing.T) {
+	provider := NewMemorySourceProvider(map[string][]byte{
+		"./infra/main.tf":           []byte("main"),
+		"infra/variables.tf":        []byte("variables"),
+		"infra/modules/vpc/main.tf": []byte("vpc"),
+	})
+
+	matches, err := provider.Glob(filepath.Join("infra", "*.tf"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join("infra", "main.tf"), filepath.Join("infra", "variables.tf")}, matches)
+
+	content, err := provider.ReadFile(filepath.Join("infra", "modules", "..", "main.tf"))
+	require.NoError(t, err)
+	require.Equal(t, "main", string(content))
+
+	_, err = provider.ReadFile("infra/terraform.tfvars")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = provider.ReadFile(filepath.Join("infra", "..", "..", "etc", "passwd"))
+	require.ErrorIs(t, err, filesystem.ErrOutsideRoots)
+}
+
+func TestMemorySourceProvider_Stat(t *testing.T) {
+	provider := NewMemorySourceProvider(map[string][]byte{
+		"infra/main.tf":             []byte("main"),
+		"infra/modules/vpc/main.tf": []byte("vpc"),
+	})
+
+	info, err := provider.Stat("infra/main.tf")
+	require.NoError(t, err)
+	require.False(t, info.IsDir())
+	require.Equal(t, int64(4), info.Size())
+
+	info, err = provider.Stat(filepath.Join("infra", "modules"))
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+
+	_, err = provider.Stat("infra/mod")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = provider.Stat("../etc/passwd")
+	require.ErrorIs(t, err, filesystem.ErrOutsideRoots)
+}
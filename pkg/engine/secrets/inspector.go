@@ -14,7 +14,9 @@ import (
 	"github.com/Checkmarx/kics/v2/assets"
 	"github.com/Checkmarx/kics/v2/pkg/detector"
 	"github.com/Checkmarx/kics/v2/pkg/detector/docker"
+	"github.com/Checkmarx/kics/v2/pkg/detector/dockercompose"
 	"github.com/Checkmarx/kics/v2/pkg/detector/helm"
+	"github.com/Checkmarx/kics/v2/pkg/detector/kustomize"
 	engine "github.com/Checkmarx/kics/v2/pkg/engine"
 	"github.com/Checkmarx/kics/v2/pkg/engine/similarity"
 	"github.com/Checkmarx/kics/v2/pkg/engine/source"
@@ -29,6 +31,11 @@ const (
 
 var (
 	SecretsQueryMetadata map[string]string
+
+	// secretsQueryMetadataMu guards the decoding of the metadata, as inspectors may be created by concurrent scans
+	secretsQueryMetadataMu sync.Mutex
+	// secretsQueryMetadataJSON is the content SecretsQueryMetadata was last decoded from
+	secretsQueryMetadataJSON string
 )
 
 // SecretTracker is Struct created to keep track of the secrets found in the inspector
@@ -130,9 +137,11 @@ func NewInspector(
 
 	lineDetector := detector.NewDetectLine(tracker.GetOutputLines()).
 		Add(helm.DetectKindLine{}, model.KindHELM).
-		Add(docker.DetectKindLine{}, model.KindDOCKER)
+		Add(kustomize.DetectKindLine{}, model.KindKUSTOMIZE).
+		Add(docker.DetectKindLine{}, model.KindDOCKER).
+		Add(dockercompose.DetectKindLine{}, model.KindYAML)
 
-	err = json.Unmarshal([]byte(assets.SecretsQueryMetadataJSON), &SecretsQueryMetadata)
+	err = loadSecretsQueryMetadata()
 	if err != nil {
 		return nil, err
 	}
@@ -209,6 +218,21 @@ func (c *Inspector) Inspect(ctx context.Context, basePaths []string,
 	return c.vulnerabilities, nil
 }
 
+// loadSecretsQueryMetadata decodes the secrets query metadata, unless it was already decoded from the same content,
+// so scans running while another inspector is created do not read the metadata while it is written
+func loadSecretsQueryMetadata() error {
+	secretsQueryMetadataMu.Lock()
+	defer secretsQueryMetadataMu.Unlock()
+	if SecretsQueryMetadata != nil && secretsQueryMetadataJSON == assets.SecretsQueryMetadataJSON {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(assets.SecretsQueryMetadataJSON), &SecretsQueryMetadata); err != nil {
+		return err
+	}
+	secretsQueryMetadataJSON = assets.SecretsQueryMetadataJSON
+	return nil
+}
+
 func compileRegexQueries(
 	queryFilter *source.QueryInspectorParameters,
 	allRegexQueries []RegexQuery,
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	dec "github.com/Checkmarx/kics/v2/pkg/detector"
@@ -125,7 +126,7 @@ var DefaultVulnerabilityBuilder = func(ctx *QueryContext,
 
 	lineNumber := 0
 	var similarityIDLineInfoOld = similarityIDLineInfo
-	if file.Kind != model.KindHELM && len(file.ResolvedFiles) == 0 {
+	if file.Kind != model.KindHELM && file.Kind != model.KindKUSTOMIZE && len(file.ResolvedFiles) == 0 {
 		searchLineCalc := &searchLineCalculator{
 			lineNr:               -1,
 			vObj:                 vObj,
@@ -136,6 +137,10 @@ var DefaultVulnerabilityBuilder = func(ctx *QueryContext,
 		}
 		// calculate search Line if possible (default uses values of search key)
 		lineNumber, similarityIDLineInfoOld, linesVulne = calculeSearchLine(searchLineCalc)
+	} else if _, ok := vObj["searchLine"]; ok && linesVulne.Line > 0 && linesVulne.LineSimilarity {
+		// the results found in files also scanned on their own depend on their line as the results of these files,
+		// so they are not reported twice
+		similarityIDLineInfoOld = strconv.Itoa(linesVulne.Line)
 	}
 
 	if linesVulne.Line == -1 {
@@ -162,8 +167,8 @@ var DefaultVulnerabilityBuilder = func(ctx *QueryContext,
 		issueType = model.IssueType(*v)
 	}
 
-	similarityID, oldSimilarityID := generateSimilaritiesID(ctx, linesVulne.ResolvedFile, queryID, similarityIDLineInfo, searchValue,
-		searchKey, similarityIDLineInfoOld, kicsComputeNewSimID, &logWithFields, tracker)
+	similarityID, oldSimilarityID := generateSimilaritiesID(ctx, linesVulne.ResolvedFile, queryID, similarityIDLineInfo,
+		searchValue+similarityScope(&file), searchKey, similarityIDLineInfoOld, kicsComputeNewSimID, &logWithFields, tracker)
 
 	return &model.Vulnerability{
 		ID:               0,
@@ -197,10 +202,27 @@ var DefaultVulnerabilityBuilder = func(ctx *QueryContext,
 		CloudProvider:    getCloudProvider(overrideKey, vObj, &logWithFields),
 		Remediation:      PtrStringToString(mustMapKeyToString(vObj, "remediation")),
 		RemediationType:  PtrStringToString(mustMapKeyToString(vObj, "remediationType")),
+		ModuleCall:       file.ModuleCall,
+		Environment:      file.Environment,
 	}, nil
 }
 
 // <editor-fold desc="similarity id">
+
+// similarityScope returns what tells apart the results of the same lines of a file scanned more than once,
+// which is the path of the module call for the documents of a Terraform module instance and the environment
+// for the files rendered with several values files
+func similarityScope(file *model.FileMetadata) string {
+	scope := ""
+	if file.ModuleCall != nil {
+		scope = file.ModuleCall.Path
+	}
+	if file.Environment != "" {
+		scope += "environment:" + file.Environment
+	}
+	return scope
+}
+
 func generateSimilaritiesID(ctx *QueryContext,
 	resolvedFile, queryID, similarityIDLineInfo, searchValue, searchKey, similarityIDLineInfoOld string,
 	kicsComputeNewSimID bool,
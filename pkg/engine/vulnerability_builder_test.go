@@ -444,6 +444,41 @@ func TestDefaultVulnerabilityBuilder(t *testing.T) {
 	}
 }
 
+// TestDefaultVulnerabilityBuilder_SimilarityScope tests that the results of the same lines of a file rendered with
+// different environments, or instantiated by different module calls, have different similarity IDs
+func TestDefaultVulnerabilityBuilder_SimilarityScope(t *testing.T) {
+	files := map[string]model.FileMetadata{
+		"plain": {FilePath: "chart/templates/deployment.yaml", LinesOriginalData: &[]string{}},
+		"dev": {FilePath: "chart/templates/deployment.yaml", LinesOriginalData: &[]string{},
+			Environment: "values-dev.yaml"},
+		"prod": {FilePath: "chart/templates/deployment.yaml", LinesOriginalData: &[]string{},
+			Environment: "values-prod.yaml"},
+		"module": {FilePath: "chart/templates/deployment.yaml", LinesOriginalData: &[]string{},
+			ModuleCall: &model.ModuleCall{Path: "module.a"}},
+	}
+	ctx := &QueryContext{
+		scanID: "ScanID",
+		Query: &PreparedQuery{
+			Metadata: model.QueryMetadata{
+				Metadata: map[string]interface{}{"id": "query-id", "severity": model.SeverityHigh},
+				Query:    "TestQuery",
+			},
+		},
+		Files: files,
+	}
+
+	similarityIDs := make(map[string]string, len(files))
+	for _, id := range []string{"plain", "dev", "prod", "module"} {
+		got, err := DefaultVulnerabilityBuilder(ctx, &tracker.CITracker{}, map[string]interface{}{
+			"documentId": id,
+			"searchKey":  "spec.containers",
+		}, detector.NewDetectLine(3), false, true)
+		require.NoError(t, err)
+		require.NotContains(t, similarityIDs, got.SimilarityID, "%s has the similarity ID of %s", id, similarityIDs[got.SimilarityID])
+		similarityIDs[got.SimilarityID] = id
+	}
+}
+
 var OriginalData = `{
 	"father": {
 		"son": {
@@ -0,0 +1,119 @@
+// Package filesystem gives the parsers and resolvers access to the files surrounding the parsed ones, read from disk
+// or, when the scanned files are held in memory, from the sources of the scan
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutsideRoots is returned when reading a file outside of the scanned paths
+var ErrOutsideRoots = errors.New("path outside of the scanned paths")
+
+// FileSystem gives access to the files surrounding a parsed file, such as the variables files, linked templates,
+// parameters files and modules it loads
+type FileSystem interface {
+	Glob(pattern string) ([]string, error)
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// OS is the FileSystem reading the files from disk, an OS with roots only reads the files inside them
+type OS struct {
+	roots []string
+}
+
+// NewOS returns an OS reading only the files inside the roots, the roots that are files are replaced by
+// their directory
+func NewOS(roots []string) *OS {
+	dirs := make([]string, 0, len(roots))
+	for _, root := range roots {
+		dir, err := realPath(root)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		dirs = append(dirs, dir)
+	}
+	return &OS{roots: dirs}
+}
+
+// Get returns the FileSystem, or an OS without roots when it is nil
+func Get(fileSystem FileSystem) FileSystem {
+	if fileSystem == nil {
+		return &OS{}
+	}
+	return fileSystem
+}
+
+// Glob returns the paths of the files inside the roots matching the pattern
+func (o *OS) Glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil || len(o.roots) == 0 {
+		return matches, err
+	}
+	contained := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if o.contains(match) {
+			contained = append(contained, match)
+		}
+	}
+	return contained, nil
+}
+
+// ReadFile returns the content of a file inside the roots
+func (o *OS) ReadFile(name string) ([]byte, error) {
+	if !o.contains(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: ErrOutsideRoots}
+	}
+	return os.ReadFile(filepath.Clean(name))
+}
+
+// Stat returns the information of a file inside the roots
+func (o *OS) Stat(name string) (fs.FileInfo, error) {
+	if !o.contains(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: ErrOutsideRoots}
+	}
+	return os.Stat(name)
+}
+
+// contains returns true if the path, with its symbolic links resolved, is inside one of the roots
+func (o *OS) contains(name string) bool {
+	if len(o.roots) == 0 {
+		return true
+	}
+	path, err := realPath(name)
+	if err != nil {
+		return false
+	}
+	for _, root := range o.roots {
+		if Contains(root, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if the path is the root or a path inside it
+func Contains(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// realPath returns the absolute path with its symbolic links resolved, a path that does not exist is only
+// made absolute
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestOS tests the functions [ReadFile()], [Stat()] and [Glob()] and all the methods called by them
+func TestOS(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+	inside := filepath.Join(root, "app", "docker-compose.yaml")
+	outside := filepath.Join(dir, "secret.env")
+	require.NoError(t, os.MkdirAll(filepath.Dir(inside), 0700))
+	require.NoError(t, os.WriteFile(inside, []byte("services: {}"), 0600))
+	require.NoError(t, os.WriteFile(outside, []byte("PASSWORD=secret"), 0600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "app", "linked.env")))
+
+	tests := []struct {
+		name       string
+		fileSystem FileSystem
+		path       string
+		wantErr    bool
+	}{
+		{
+			name:       "should read a file inside the root",
+			fileSystem: NewOS([]string{root}),
+			path:       inside,
+		},
+		{
+			name:       "should read a file inside the directory of a root file",
+			fileSystem: NewOS([]string{inside}),
+			path:       filepath.Join(root, "app", "docker-compose.yaml"),
+		},
+		{
+			name:       "should not read a file outside the root",
+			fileSystem: NewOS([]string{root}),
+			path:       filepath.Join(root, "app", "..", "..", "secret.env"),
+			wantErr:    true,
+		},
+		{
+			name:       "should not read a file linked from inside the root",
+			fileSystem: NewOS([]string{root}),
+			path:       filepath.Join(root, "app", "linked.env"),
+			wantErr:    true,
+		},
+		{
+			name:       "should read any file without roots",
+			fileSystem: Get(nil),
+			path:       outside,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.fileSystem.ReadFile(tt.path)
+			require.Equal(t, tt.wantErr, err != nil)
+			_, err = tt.fileSystem.Stat(tt.path)
+			require.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				require.True(t, errors.Is(err, ErrOutsideRoots))
+			}
+			matches, err := tt.fileSystem.Glob(filepath.Join(filepath.Dir(tt.path), "*"))
+			require.NoError(t, err)
+			require.Equal(t, !tt.wantErr, contains(matches, filepath.Clean(tt.path)))
+		})
+	}
+}
+
+// TestContains tests the function [Contains()]
+func TestContains(t *testing.T) {
+	root := filepath.Join("project", "app")
+	require.True(t, Contains(root, root))
+	require.True(t, Contains(root, filepath.Join(root, "base.yaml")))
+	require.False(t, Contains(root, filepath.Join("project", "application", "base.yaml")))
+	require.False(t, Contains(root, filepath.Join(root, "..", "base.yaml")))
+	require.False(t, Contains(root, filepath.Join("..", "etc", "passwd")))
+}
+
+func contains(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
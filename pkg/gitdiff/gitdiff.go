@@ -0,0 +1,254 @@
+// Package gitdiff computes the files and lines of a local git repository changed since a base ref
+package gitdiff
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// LineRange is an inclusive range of changed lines of a file
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes contains the files, and their changed lines, touched since a base ref
+type Changes struct {
+	BaseRef string
+	Files   map[string][]LineRange
+}
+
+// wholeFile is the range used for files that are entirely new to the repository
+var wholeFile = LineRange{Start: 1, End: math.MaxInt32}
+
+// GetChanges computes the changes made since baseRef in the git repositories containing the given paths.
+// Only the local .git directory is used: baseRef must already be available in the local clone.
+// Committed and uncommitted changes since the merge base of baseRef and HEAD are considered, as well as
+// untracked files that are not ignored
+func GetChanges(ctx context.Context, baseRef string, paths []string) (*Changes, error) {
+	changes := &Changes{
+		BaseRef: baseRef,
+		Files:   make(map[string][]LineRange),
+	}
+
+	roots := make(map[string]bool)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Debug().Msgf("Skipping git diff of path %s: %s", path, err)
+			continue
+		}
+		dir := path
+		if !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+
+		root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+		if err != nil {
+			return nil, fmt.Errorf("path %s is not inside a git repository: %w", path, err)
+		}
+		root = strings.TrimSpace(root)
+		if roots[root] {
+			continue
+		}
+		roots[root] = true
+
+		if err := changes.addRepository(ctx, root); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no local git repository found for paths %s", strings.Join(paths, ", "))
+	}
+
+	return changes, nil
+}
+
+func (c *Changes) addRepository(ctx context.Context, root string) error {
+	if _, err := runGit(ctx, root, "rev-parse", "--verify", "--quiet", c.BaseRef+"^{commit}"); err != nil {
+		return fmt.Errorf("git base ref %s not found in repository %s", c.BaseRef, root)
+	}
+
+	base := c.BaseRef
+	if mergeBase, err := runGit(ctx, root, "merge-base", c.BaseRef, "HEAD"); err == nil {
+		base = strings.TrimSpace(mergeBase)
+	}
+
+	diff, err := runGit(ctx, root,
+		"-c", "core.quotePath=false",
+		"diff", "--unified=0", "--no-color", "--no-ext-diff", "--no-textconv", "--find-renames",
+		"--diff-filter=d", "--src-prefix=a/", "--dst-prefix=b/", base, "--")
+	if err != nil {
+		return fmt.Errorf("failed to compute git diff against %s: %w", c.BaseRef, err)
+	}
+
+	files, err := ParseDiff(strings.NewReader(diff))
+	if err != nil {
+		return err
+	}
+	for file, ranges := range files {
+		c.Files[filepath.Join(root, filepath.FromSlash(file))] = ranges
+	}
+
+	untracked, err := runGit(ctx, root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	for _, file := range strings.Split(untracked, "\x00") {
+		if file == "" {
+			continue
+		}
+		c.Files[filepath.Join(root, filepath.FromSlash(file))] = []LineRange{wholeFile}
+	}
+
+	log.Info().Msgf("Files changed since %s in %s: %d", c.BaseRef, root, len(c.Files))
+	return nil
+}
+
+// ParseDiff extracts the changed line ranges of each file from a unified diff with zero context lines.
+// Paths are relative to the repository root. Deletions mark the lines surrounding them as changed
+func ParseDiff(reader io.Reader) (map[string][]LineRange, error) {
+	files := make(map[string][]LineRange)
+	current := ""
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = ""
+		case strings.HasPrefix(line, "+++ "):
+			current = parseDiffPath(strings.TrimPrefix(line, "+++ "))
+			if current != "" {
+				if _, ok := files[current]; !ok {
+					files[current] = make([]LineRange, 0)
+				}
+			}
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			lineRange, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			files[current] = append(files[current], lineRange)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func parseDiffPath(path string) string {
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+	}
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, "b/")
+}
+
+func parseHunkHeader(header string) (LineRange, error) {
+	matches := hunkHeaderRegex.FindStringSubmatch(header)
+	if matches == nil {
+		return LineRange{}, fmt.Errorf("invalid hunk header: %s", header)
+	}
+	start, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return LineRange{}, err
+	}
+	count := 1
+	if matches[2] != "" {
+		if count, err = strconv.Atoi(matches[2]); err != nil {
+			return LineRange{}, err
+		}
+	}
+
+	// a pure deletion reports the line preceding the removed lines
+	if count == 0 {
+		return LineRange{Start: start, End: start + 1}, nil
+	}
+	return LineRange{Start: start, End: start + count - 1}, nil
+}
+
+// Paths returns the sorted list of changed files
+func (c *Changes) Paths() []string {
+	paths := make([]string, 0, len(c.Files))
+	for path := range c.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// HasFile checks if the given file was changed
+func (c *Changes) HasFile(path string) bool {
+	_, ok := c.Files[absPath(path)]
+	return ok
+}
+
+// Contains checks if the given line of a file was changed
+func (c *Changes) Contains(path string, line int) bool {
+	return c.Overlaps(path, line, line)
+}
+
+// Overlaps checks if any line of a file between start and end, both included, was changed
+func (c *Changes) Overlaps(path string, start, end int) bool {
+	ranges, ok := c.Files[absPath(path)]
+	if !ok {
+		return false
+	}
+	for _, lineRange := range ranges {
+		if start <= lineRange.End && end >= lineRange.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// absPath returns the absolute path with symbolic links evaluated, since git reports the real repository root
+func absPath(path string) string {
+	abs, err := filepath.Abs(filepath.FromSlash(path))
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...) //nolint:gosec
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
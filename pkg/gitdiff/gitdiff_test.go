@@ -0,0 +1,106 @@
+package gitdiff
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseDiff tests the function [ParseDiff()] and all the methods called by it
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.tf b/main.tf
+index 3b18e51..a2c4f3e 100644
+--- a/main.tf
++++ b/main.tf
+@@ -3 +3 @@ resource "aws_s3_bucket" "b" {
+-  acl    = "private"
++  acl    = "public-read"
+@@ -10,0 +11,3 @@ resource "aws_s3_bucket" "b" {
++  tags = {
++    Name = "bucket"
++  }
+@@ -20,2 +23,0 @@ resource "aws_s3_bucket" "b" {
+-  versioning {
+-  }
+diff --git a/old.yaml b/dir/new file.yaml
+similarity index 90%
+rename from old.yaml
+rename to dir/new file.yaml
+--- a/old.yaml
++++ b/dir/new file.yaml
+@@ -1 +1 @@
+-a: 1
++a: 2
+diff --git a/image.png b/image.png
+Binary files a/image.png and b/image.png differ
+`
+
+	files, err := ParseDiff(strings.NewReader(diff))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]LineRange{
+		"main.tf": {
+			{Start: 3, End: 3},
+			{Start: 11, End: 13},
+			{Start: 23, End: 24},
+		},
+		"dir/new file.yaml": {
+			{Start: 1, End: 1},
+		},
+	}, files)
+
+	_, err = ParseDiff(strings.NewReader("+++ b/main.tf\n@@ invalid @@\n"))
+	require.Error(t, err)
+}
+
+// TestGetChanges tests the function [GetChanges()] and all the methods called by it
+func TestGetChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=kics", "-c", "user.email=kics@kics.io"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	git("init", "-q")
+	write("main.tf", "line1\nline2\nline3\nline4\n")
+	write("unchanged.tf", "line1\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("tag", "base")
+
+	write("main.tf", "line1\nchanged\nline3\nline4\nadded\n")
+	git("commit", "-q", "-am", "change")
+	write("new.tf", "line1\nline2\n")
+
+	changes, err := GetChanges(context.Background(), "base", []string{dir})
+	require.NoError(t, err)
+
+	mainFile := filepath.Join(dir, "main.tf")
+	newFile := filepath.Join(dir, "new.tf")
+	require.Equal(t, []string{absPath(mainFile), absPath(newFile)}, changes.Paths())
+	require.True(t, changes.HasFile(mainFile))
+	require.False(t, changes.HasFile(filepath.Join(dir, "unchanged.tf")))
+	require.True(t, changes.Contains(mainFile, 2))
+	require.False(t, changes.Contains(mainFile, 3))
+	require.True(t, changes.Contains(mainFile, 5))
+	require.True(t, changes.Contains(newFile, 1))
+
+	_, err = GetChanges(context.Background(), "missing-ref", []string{dir})
+	require.Error(t, err)
+
+	_, err = GetChanges(context.Background(), "base", []string{filepath.Join(dir, "missing")})
+	require.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package kics
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/cache"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser"
+	"github.com/Checkmarx/kics/v2/pkg/parser/ansible"
+	"github.com/Checkmarx/kics/v2/pkg/parser/azureresourcemanager"
+	"github.com/Checkmarx/kics/v2/pkg/parser/bicep"
+	"github.com/Checkmarx/kics/v2/pkg/parser/dockercompose"
+	"github.com/Checkmarx/kics/v2/pkg/parser/githubactions"
+	"github.com/rs/zerolog/log"
+)
+
+// cacheEntry is an entry of a parsed file waiting for its results to be stored in the cache
+type cacheEntry struct {
+	key   string
+	entry *cache.Entry
+}
+
+// cachedFile is a file whose results were found in the cache, waiting for every file of the service to be found
+type cachedFile struct {
+	filename string
+	key      string
+	content  *Content
+	entry    *cache.Entry
+}
+
+func (s *Service) cacheKind() string {
+	platforms := make([]string, len(s.Parser.Platform))
+	copy(platforms, s.Parser.Platform)
+	sort.Strings(platforms)
+	return strings.Join(platforms, ",")
+}
+
+// cacheBasePath returns the scanned path holding a file, which the similarity IDs of its results are relative to
+func (s *Service) cacheBasePath(filename string) string {
+	for _, path := range s.SourceProvider.GetBasePaths() {
+		if path == filename {
+			return path
+		}
+		rel, err := filepath.Rel(path, filename)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return path
+		}
+	}
+	return ""
+}
+
+// deferCachedFile looks for the results of the file in the cache, when found the file is kept until every
+// file of the service is found, since its results also depend on the documents of the other files
+func (s *Service) deferCachedFile(filename, key string, content *Content) bool {
+	entry, ok := s.Cache.Get(key)
+	if !ok {
+		return false
+	}
+	s.cachedFiles = append(s.cachedFiles, &cachedFile{
+		filename: filename,
+		key:      key,
+		content:  content,
+		entry:    entry,
+	})
+	return true
+}
+
+// resolveCachedFiles uses the cached results of the files found in the cache when no file of the service was
+// parsed and the files are the ones scanned together when the results were cached. Otherwise the queries
+// looking across the documents of the service could find other results, so the files are parsed again
+func (s *Service) resolveCachedFiles(ctx context.Context, scanID string, openAPIResolveReferences bool,
+	maxResolverDepth int) error {
+	keys := make([]string, 0, len(s.cachedFiles))
+	for _, cached := range s.cachedFiles {
+		keys = append(keys, cached.key)
+	}
+	peers := cache.HashPeers(keys)
+
+	unchanged := len(s.files) == 0 && len(s.cacheEntries) == 0
+	for _, cached := range s.cachedFiles {
+		unchanged = unchanged && cached.entry.Peers == peers
+	}
+
+	cachedFiles := s.cachedFiles
+	s.cachedFiles = nil
+	for _, cached := range cachedFiles {
+		s.Cache.Count(unchanged)
+		if unchanged {
+			s.useCachedResults(cached.filename, scanID, cached.entry)
+			continue
+		}
+		log.Debug().Msgf("Ignoring cached results of file %s, the files scanned with it changed", cached.filename)
+		if err := s.parseFile(ctx, cached.filename, scanID, cached.content, cached.key,
+			openAPIResolveReferences, maxResolverDepth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// useCachedResults keeps the cached results of the file to be saved together with the results of the scan
+func (s *Service) useCachedResults(filename, scanID string, entry *cache.Entry) {
+	log.Debug().Msgf("Using cached results of file %s", filename)
+
+	for i := range entry.Vulnerabilities {
+		vulnerability := entry.Vulnerabilities[i].Vulnerability
+		vulnerability.QueryURI = entry.Vulnerabilities[i].QueryURI
+		vulnerability.ScanID = scanID
+		s.cachedVulnerabilities = append(s.cachedVulnerabilities, vulnerability)
+	}
+
+	if entry.Failed {
+		return
+	}
+	s.Tracker.TrackFileFoundCountLines(entry.ResolvedLines)
+	s.Tracker.TrackFileParse(filename)
+	s.Tracker.TrackFileParseCountLines(entry.ParsedLines)
+	s.Tracker.TrackFileIgnoreCountLines(entry.IgnoredLines)
+}
+
+// linkedFilePatterns returns the patterns of the files a parsed file depends on that are not resolved while
+// parsing, which may not exist yet
+func linkedFilePatterns(filename string, documents *parser.ParsedDocument) []string {
+	patterns := make([]string, 0)
+	// the files of the directories from where the variables and modules are loaded
+	if documents.Kind == model.KindTerraform {
+		patterns = append(patterns, terraformPatterns(filepath.Dir(filename))...)
+	}
+	// the parameters files and linked templates of the ARM templates
+	if documents.Kind == model.KindJSON {
+		for _, document := range documents.Docs {
+			patterns = append(patterns, azureresourcemanager.LinkedFiles(document, filename)...)
+		}
+	}
+	// the parameters files and modules of the Bicep files
+	if documents.Kind == model.KindBICEP {
+		patterns = append(patterns, bicep.LinkedFiles(filename)...)
+	}
+	// the roles, includes and variables files of the Ansible playbooks
+	if documents.Kind == model.KindYAML && len(documents.Docs) > 0 && ansible.IsAnsible(documents.Docs[0], filename) {
+		patterns = append(patterns, ansible.LinkedFiles(filename, 0)...)
+	}
+	// the .env file and override file of the Compose files
+	if documents.Kind == model.KindYAML && len(documents.Docs) > 0 && dockercompose.IsCompose(documents.Docs[0], filename) {
+		patterns = append(patterns, dockercompose.LinkedFiles(filename)...)
+	}
+	// the local reusable workflows and composite actions of the GitHub Actions workflows
+	if documents.Kind == model.KindYAML && len(documents.Docs) > 0 && githubactions.IsWorkflow(documents.Docs[0]) {
+		patterns = append(patterns, githubactions.LinkedFiles(filename, 0)...)
+	}
+	return patterns
+}
+
+// addCacheEntry prepares the cache entry of a parsed file and its documents, the entry depends on the files
+// resolved while parsing, on its linked files and on the Terraform modules it calls
+func (s *Service) addCacheEntry(filename, key string, documents *parser.ParsedDocument, files []model.FileMetadata,
+	resolvedLines int) {
+	dependencies := make([]string, 0)
+	for path := range documents.ResolvedFiles {
+		if path != filename {
+			dependencies = append(dependencies, path)
+		}
+	}
+
+	patterns := linkedFilePatterns(filename, documents)
+	for i := range files {
+		if files[i].ModuleCall != nil {
+			dependencies = append(dependencies, files[i].ModuleCall.ModuleFile)
+			patterns = append(patterns, terraformPatterns(filepath.Dir(files[i].ModuleCall.ModuleFile))...)
+		}
+	}
+
+	pending := &cacheEntry{
+		key: key,
+		entry: &cache.Entry{
+			FileName:        filename,
+			Dependencies:    cache.HashFiles(dependencies),
+			Patterns:        cache.HashPatterns(patterns),
+			ResolvedLines:   resolvedLines,
+			ParsedLines:     documents.CountLines - len(documents.IgnoreLines),
+			IgnoredLines:    len(documents.IgnoreLines),
+			Vulnerabilities: make([]cache.Vulnerability, 0),
+		},
+	}
+	s.cacheEntries = append(s.cacheEntries, pending)
+	if s.cacheFiles == nil {
+		s.cacheFiles = make(map[string]*cacheEntry)
+	}
+	for i := range files {
+		s.cacheFiles[files[i].ID] = pending
+	}
+}
+
+// addFailedCacheEntry prepares the cache entry of a file that failed to be parsed, so it is not parsed again
+// while it does not change
+func (s *Service) addFailedCacheEntry(filename, key string) {
+	s.cacheEntries = append(s.cacheEntries, &cacheEntry{
+		key: key,
+		entry: &cache.Entry{
+			FileName:        filename,
+			Failed:          true,
+			Vulnerabilities: make([]cache.Vulnerability, 0),
+		},
+	})
+}
+
+func terraformPatterns(dir string) []string {
+	return []string{filepath.Join(dir, "*.tf"), filepath.Join(dir, "*.tfvars")}
+}
+
+// assignCacheResults assigns the vulnerabilities found to the cache entries of the files they belong to
+func (s *Service) assignCacheResults(vulnerabilities []model.Vulnerability) {
+	for i := range vulnerabilities {
+		pending, ok := s.cacheFiles[vulnerabilities[i].FileID]
+		if !ok {
+			continue
+		}
+		pending.entry.Vulnerabilities = append(pending.entry.Vulnerabilities, cache.Vulnerability{
+			Vulnerability: vulnerabilities[i],
+			QueryURI:      vulnerabilities[i].QueryURI,
+		})
+	}
+}
+
+// SaveCache stores the results of the parsed files in the cache, along with the files scanned with them
+func (s *Service) SaveCache() {
+	if s.Cache == nil {
+		return
+	}
+	keys := make([]string, 0, len(s.cacheEntries))
+	for _, pending := range s.cacheEntries {
+		keys = append(keys, pending.key)
+	}
+	peers := cache.HashPeers(keys)
+	for _, pending := range s.cacheEntries {
+		pending.entry.Peers = peers
+		if err := s.Cache.Put(pending.key, pending.entry); err != nil {
+			log.Warn().Msgf("Failed to store cached results of file %s: %s", pending.entry.FileName, err)
+		}
+	}
+}
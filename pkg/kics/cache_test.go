@@ -0,0 +1,112 @@
+package kics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/cache"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SinkCache(t *testing.T) {
+	ctx := context.Background()
+	content := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\n"
+	peerContent := "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: account\n"
+	dir := t.TempDir()
+
+	resultsCache, err := cache.NewCache(filepath.Join(dir, "cache"), "version")
+	require.NoError(t, err)
+
+	paths := []string{dir}
+	newService := func(paths []string) *Service {
+		s := MockService(paths, []string{"Kubernetes"}, []string{}, 10, 60, 100, ctx)
+		s.Cache = resultsCache
+		return &s
+	}
+	filename := writeCacheTestFile(t, filepath.Join(dir, "a", "pod.yaml"), content)
+	peerFilename := writeCacheTestFile(t, filepath.Join(dir, "a", "account.yaml"), peerContent)
+
+	s := newService(paths)
+	sinkCacheTestFiles(t, s, "scan-1", map[string]string{filename: content, peerFilename: peerContent})
+	require.Len(t, s.files, 2)
+	require.Len(t, s.cacheEntries, 2)
+	require.Empty(t, s.cachedVulnerabilities)
+
+	fileID := s.files[0].ID
+	if s.files[0].FilePath != filename {
+		fileID = s.files[1].ID
+	}
+	s.assignCacheResults([]model.Vulnerability{
+		{FileID: fileID, FileName: filename, SimilarityID: "similarity-1", QueryID: "query-1", Line: 2,
+			QueryURI: "https://docs.kics.io"},
+		{FileID: "other-file", FileName: "other.yaml", QueryID: "query-1", Line: 2},
+	})
+	s.SaveCache()
+
+	// the same files are not parsed again
+	cached := newService(paths)
+	sinkCacheTestFiles(t, cached, "scan-2", map[string]string{filename: content, peerFilename: peerContent})
+	require.Empty(t, cached.files)
+	require.Len(t, cached.cachedVulnerabilities, 1)
+	require.Equal(t, filename, cached.cachedVulnerabilities[0].FileName)
+	require.Equal(t, "similarity-1", cached.cachedVulnerabilities[0].SimilarityID)
+	require.Equal(t, "scan-2", cached.cachedVulnerabilities[0].ScanID)
+	require.Equal(t, "https://docs.kics.io", cached.cachedVulnerabilities[0].QueryURI)
+	hits, misses := resultsCache.Stats()
+	require.Equal(t, 2, hits)
+	require.Equal(t, 2, misses)
+
+	// an unchanged file is parsed along with a changed file, since the queries look across their documents
+	changed := newService(paths)
+	sinkCacheTestFiles(t, changed, "scan-3", map[string]string{filename: content, peerFilename: peerContent + "secrets: []\n"})
+	require.Len(t, changed.files, 2)
+	require.Empty(t, changed.cachedVulnerabilities)
+
+	// an unchanged file is parsed when the files scanned with it are not the same
+	alone := newService(paths)
+	sinkCacheTestFiles(t, alone, "scan-4", map[string]string{filename: content})
+	require.Len(t, alone.files, 1)
+	require.Empty(t, alone.cachedVulnerabilities)
+
+	// the same content in another directory, which may depend on other files, is parsed
+	otherFilename := writeCacheTestFile(t, filepath.Join(dir, "b", "pod.yaml"), content)
+	other := newService(paths)
+	sinkCacheTestFiles(t, other, "scan-5", map[string]string{otherFilename: content})
+	require.Len(t, other.files, 1)
+	require.Empty(t, other.cachedVulnerabilities)
+
+	// the same file scanned from another path, which the similarity IDs are relative to, is parsed
+	rescanned := newService([]string{filepath.Join(dir, "a")})
+	sinkCacheTestFiles(t, rescanned, "scan-6", map[string]string{filename: content, peerFilename: peerContent})
+	require.Len(t, rescanned.files, 2)
+	require.Empty(t, rescanned.cachedVulnerabilities)
+}
+
+func Test_CacheBasePath(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := MockService([]string{filepath.Join(dir, "a")}, []string{"Kubernetes"}, []string{}, 10, 60, 100, ctx)
+
+	require.Equal(t, filepath.Join(dir, "a"), s.cacheBasePath(filepath.Join(dir, "a", "pod.yaml")))
+	require.Empty(t, s.cacheBasePath(filepath.Join(dir, "ab", "pod.yaml")))
+	require.Empty(t, s.cacheBasePath(filepath.Join(dir, "b", "a", "pod.yaml")))
+}
+
+func writeCacheTestFile(t *testing.T, filename, content string) string {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0700))
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+	return filename
+}
+
+// sinkCacheTestFiles sinks the files of a scan and resolves the ones found in the cache, as PrepareSources does
+func sinkCacheTestFiles(t *testing.T, s *Service, scanID string, files map[string]string) {
+	ctx := context.Background()
+	for filename, content := range files {
+		require.NoError(t, s.sink(ctx, filename, scanID, strings.NewReader(content), make([]byte, mbConst), false, 15))
+	}
+	require.NoError(t, s.resolveCachedFiles(ctx, scanID, false, 15))
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+var kustomizeIDRegex = regexp.MustCompile(`(?m)^# KICS_HELM_ID_\d+:\n`)
+
 func (s *Service) resolverSink(
 	ctx context.Context,
 	filename, scanID string,
@@ -44,7 +46,8 @@ func (s *Service) resolverSink(
 			return []string{}, nil
 		}
 
-		if kind == model.KindHELM {
+		switch kind {
+		case model.KindHELM:
 			ignoreList, errorIL := s.getOriginalIgnoreLines(
 				rfile.FileName, rfile.OriginalData,
 				openAPIResolveReferences, isMinified, maxResolverDepth)
@@ -54,7 +57,17 @@ func (s *Service) resolverSink(
 				// Need to ignore #KICS_HELM_ID Line
 				documents.CountLines = bytes.Count(rfile.OriginalData, []byte{'\n'})
 			}
-		} else {
+		case model.KindKUSTOMIZE:
+			// the auxiliary lines are added at the start of the documents of the original file
+			original := kustomizeIDRegex.ReplaceAll(rfile.OriginalData, []uint8{})
+			ignoreList, errorIL := s.getOriginalIgnoreLines(
+				rfile.FileName, original,
+				openAPIResolveReferences, isMinified, maxResolverDepth)
+			if errorIL == nil {
+				documents.IgnoreLines = ignoreList
+			}
+			documents.CountLines = bytes.Count(original, []byte{'\n'}) + 1
+		default:
 			documents.CountLines = bytes.Count(rfile.OriginalData, []byte{'\n'}) + 1
 		}
 
@@ -93,6 +106,8 @@ func (s *Service) resolverSink(
 				ResolvedFiles:     documents.ResolvedFiles,
 				LinesOriginalData: utils.SplitLines(string(rfile.OriginalData)),
 				IsMinified:        documents.IsMinified,
+				Patches:           rfile.Patches,
+				Environment:       rfile.Environment,
 			}
 			s.saveToFile(ctx, &file)
 		}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"sync"
 
+	"github.com/Checkmarx/kics/v2/pkg/cache"
 	"github.com/Checkmarx/kics/v2/pkg/engine"
 	"github.com/Checkmarx/kics/v2/pkg/engine/provider"
 	"github.com/Checkmarx/kics/v2/pkg/engine/secrets"
@@ -58,8 +59,14 @@ type Service struct {
 	SecretsInspector *secrets.Inspector
 	Tracker          Tracker
 	Resolver         *resolver.Resolver
+	Cache            *cache.Cache
 	files            model.FileMetadatas
 	MaxFileSize      int
+
+	cachedVulnerabilities []model.Vulnerability
+	cachedFiles           []*cachedFile
+	cacheEntries          []*cacheEntry
+	cacheFiles            map[string]*cacheEntry
 }
 
 // PrepareSources will prepare the sources to be scanned
@@ -82,6 +89,10 @@ func (s *Service) PrepareSources(ctx context.Context,
 		},
 	); err != nil {
 		errCh <- errors.Wrap(err, "failed to read sources")
+		return
+	}
+	if err := s.resolveCachedFiles(ctx, scanID, openAPIResolveReferences, maxResolverDepth); err != nil {
+		errCh <- errors.Wrap(err, "failed to read sources")
 	}
 }
 
@@ -120,6 +131,12 @@ func (s *Service) StartScan(
 
 	updateMaskedSecrets(&vulnerabilities, s.SecretsInspector.SecretTracker)
 
+	if s.Cache != nil {
+		s.assignCacheResults(vulnerabilities)
+		vulnerabilities = append(vulnerabilities, s.cachedVulnerabilities...)
+	}
+	vulnerabilities = removeStandaloneModuleResults(vulnerabilities, s.files)
+
 	err = s.Storage.SaveVulnerabilities(ctx, vulnerabilities)
 	if err != nil {
 		errCh <- errors.Wrap(err, "failed to save vulnerabilities")
@@ -231,6 +248,34 @@ func prepareScanDocumentValue(bodyType map[string]interface{}, kind model.FileKi
 	}
 }
 
+// removeStandaloneModuleResults removes the results of the files of the local Terraform modules called by the
+// scanned files found when the files were scanned on their own, since they are reported for each module call
+func removeStandaloneModuleResults(results []model.Vulnerability, files model.FileMetadatas) []model.Vulnerability {
+	moduleFiles := make(map[string]bool)
+	for i := range files {
+		if files[i].ModuleCall != nil {
+			moduleFiles[files[i].ModuleCall.ModuleFile] = true
+		}
+	}
+	for i := range results {
+		if results[i].ModuleCall != nil {
+			moduleFiles[results[i].ModuleCall.ModuleFile] = true
+		}
+	}
+	if len(moduleFiles) == 0 {
+		return results
+	}
+
+	filtered := make([]model.Vulnerability, 0, len(results))
+	for i := range results {
+		if results[i].ModuleCall == nil && moduleFiles[results[i].FileName] {
+			continue
+		}
+		filtered = append(filtered, results[i])
+	}
+	return filtered
+}
+
 func updateMaskedSecrets(vulnerabilities *[]model.Vulnerability, maskedSecretsTracked []secrets.SecretTracker) {
 	for idx := range *vulnerabilities {
 		for _, secretT := range maskedSecretsTracked {
@@ -3,6 +3,7 @@ package kics
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"testing"
@@ -20,6 +21,7 @@ import (
 	yamlParser "github.com/Checkmarx/kics/v2/pkg/parser/yaml"
 	"github.com/Checkmarx/kics/v2/pkg/resolver"
 	"github.com/Checkmarx/kics/v2/pkg/resolver/helm"
+	"github.com/stretchr/testify/require"
 )
 
 // TestService tests the functions [GetVulnerabilities(), GetScanSummary(),StartScan()] and all the methods called by them
@@ -158,3 +160,54 @@ func createParserSourceProvider(path string) ([]*parser.Parser,
 
 	return mockParser, mockFilesSource, mockResolver
 }
+
+func Test_RemoveStandaloneModuleResults(t *testing.T) {
+	moduleFile := filepath.Join("modules", "bucket", "main.tf")
+	callA := &model.ModuleCall{Path: "module.a", ModuleFile: moduleFile, FileName: "main.tf"}
+	callB := &model.ModuleCall{Path: "module.b", ModuleFile: moduleFile, FileName: "main.tf"}
+
+	tests := []struct {
+		name    string
+		results []model.Vulnerability
+		files   model.FileMetadatas
+		want    []model.Vulnerability
+	}{
+		{
+			name: "should remove the results of a module file scanned on its own",
+			results: []model.Vulnerability{
+				{FileName: moduleFile, SimilarityID: "standalone"},
+				{FileName: moduleFile, SimilarityID: "a", ModuleCall: callA},
+				{FileName: moduleFile, SimilarityID: "b", ModuleCall: callB},
+				{FileName: "main.tf", SimilarityID: "root"},
+			},
+			files: model.FileMetadatas{
+				{FilePath: moduleFile},
+				{FilePath: moduleFile, ModuleCall: callA},
+				{FilePath: moduleFile, ModuleCall: callB},
+			},
+			want: []model.Vulnerability{
+				{FileName: moduleFile, SimilarityID: "a", ModuleCall: callA},
+				{FileName: moduleFile, SimilarityID: "b", ModuleCall: callB},
+				{FileName: "main.tf", SimilarityID: "root"},
+			},
+		},
+		{
+			name: "should keep the results of a module file that is not called",
+			results: []model.Vulnerability{
+				{FileName: moduleFile, SimilarityID: "standalone"},
+			},
+			files: model.FileMetadatas{
+				{FilePath: moduleFile},
+			},
+			want: []model.Vulnerability{
+				{FileName: moduleFile, SimilarityID: "standalone"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, removeStandaloneModuleResults(tt.results, tt.files))
+		})
+	}
+}
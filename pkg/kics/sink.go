@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 
@@ -43,9 +45,30 @@ func (s *Service) sink(ctx context.Context, filename, scanID string,
 	if err != nil {
 		return errors.Wrapf(err, "failed to get file content: %s", filename)
 	}
+
+	cacheKey := ""
+	if s.Cache != nil {
+		cacheKey = s.Cache.Key(*content, s.cacheKind(), s.cacheBasePath(filename), filename)
+		if s.deferCachedFile(filename, cacheKey, c) {
+			return nil
+		}
+		s.Cache.Count(false)
+	}
+
+	return s.parseFile(ctx, filename, scanID, c, cacheKey, openAPIResolveReferences, maxResolverDepth)
+}
+
+// parseFile parses the content of a file and saves its documents to be scanned
+func (s *Service) parseFile(ctx context.Context, filename, scanID string, c *Content, cacheKey string,
+	openAPIResolveReferences bool,
+	maxResolverDepth int) error {
+	content := c.Content
 	documents, err := s.Parser.Parse(filename, *content, openAPIResolveReferences, c.IsMinified, maxResolverDepth)
 	if err != nil {
 		log.Err(err).Msgf("failed to parse file content: %s", filename)
+		if s.Cache != nil {
+			s.addFailedCacheEntry(filename, cacheKey)
+		}
 		return nil
 	}
 
@@ -58,6 +81,7 @@ func (s *Service) sink(ctx context.Context, filename, scanID string,
 	s.Tracker.TrackFileFoundCountLines(linesResolved)
 
 	fileCommands := s.Parser.CommentsCommands(filename, *content)
+	savedFiles := make([]model.FileMetadata, 0, len(documents.Docs))
 
 	for _, document := range documents.Docs {
 		_, err = json.Marshal(document)
@@ -91,7 +115,19 @@ func (s *Service) sink(ctx context.Context, filename, scanID string,
 			IsMinified:        documents.IsMinified,
 		}
 
+		if moduleCall, ok := document[model.ModuleCallKey].(*model.ModuleCall); ok {
+			if err = s.setModuleFile(&file, moduleCall); err != nil {
+				log.Err(err).Msgf("failed to read module file: %s", moduleCall.ModuleFile)
+				continue
+			}
+		}
+
 		s.saveToFile(ctx, &file)
+		savedFiles = append(savedFiles, file)
+	}
+
+	if s.Cache != nil {
+		s.addCacheEntry(filename, cacheKey, &documents, savedFiles, linesResolved)
 	}
 	s.Tracker.TrackFileParse(filename)
 	log.Debug().Msgf("Finished to process file %s", filename)
@@ -102,6 +138,37 @@ func (s *Service) sink(ctx context.Context, filename, scanID string,
 	return errors.Wrap(err, "failed to save file content")
 }
 
+// setModuleFile points the file metadata of a module instance document to the module file,
+// keeping the module call that instantiated it
+func (s *Service) setModuleFile(file *model.FileMetadata, moduleCall *model.ModuleCall) error {
+	content, err := s.readFile(moduleCall.ModuleFile)
+	if err != nil {
+		return err
+	}
+	content = resolveCRLFFile(content)
+
+	file.FilePath = moduleCall.ModuleFile
+	file.OriginalData = string(content)
+	file.LinesOriginalData = utils.SplitLines(string(content))
+	file.Commands = s.Parser.CommentsCommands(moduleCall.ModuleFile, content)
+	file.LinesIgnore = moduleCall.LinesIgnore
+	file.ModuleCall = moduleCall
+	return nil
+}
+
+// fileReader is implemented by the source providers whose files are not read from the filesystem
+type fileReader interface {
+	ReadFile(name string) ([]byte, error)
+}
+
+// readFile reads a file other than the one being processed from the source provider
+func (s *Service) readFile(path string) ([]byte, error) {
+	if reader, ok := s.SourceProvider.(fileReader); ok {
+		return reader.ReadFile(path)
+	}
+	return os.ReadFile(filepath.Clean(path))
+}
+
 func resolveCRLFFile(fileContent []byte) []byte {
 	regex := regexp.MustCompile(`\r\n`)
 	contentSTR := regex.ReplaceAllString(string(fileContent), "\n")
@@ -0,0 +1,86 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentLengthHeader = "Content-Length"
+
+// errInvalidMessage is returned for messages that can not be decoded, the connection can still be used
+var errInvalidMessage = errors.New("invalid message")
+
+// conn reads and writes JSON-RPC messages framed by Content-Length headers, as defined by the base protocol
+type conn struct {
+	reader *bufio.Reader
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func newConn(reader io.Reader, writer io.Writer) *conn {
+	return &conn{
+		reader: bufio.NewReader(reader),
+		writer: writer,
+	}
+}
+
+// read returns the next message, io.EOF is returned when the client closes the connection
+func (c *conn) read() (*message, error) {
+	length := -1
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if length < 0 {
+				// stray line breaks between messages are ignored
+				continue
+			}
+			break
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), contentLengthHeader) {
+			continue
+		}
+		length, err = strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("invalid %s header %q", contentLengthHeader, value)
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidMessage, err)
+	}
+	return &msg, nil
+}
+
+// write sends a message, it is safe for concurrent use
+func (c *conn) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.writer, "%s: %d\r\n\r\n", contentLengthHeader, len(body)); err != nil {
+		return err
+	}
+	_, err = c.writer.Write(body)
+	return err
+}
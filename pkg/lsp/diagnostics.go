@@ -0,0 +1,156 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/remediation"
+)
+
+const diagnosticSource = "KICS"
+
+// finding is a result reported on a document, together with the remediation used by its quick fix
+type finding struct {
+	diagnostic      Diagnostic
+	queryName       string
+	remediation     remediation.Remediation
+	remediationType string
+}
+
+var severities = map[model.Severity]int{
+	model.SeverityCritical: severityError,
+	model.SeverityHigh:     severityError,
+	model.SeverityMedium:   severityWarning,
+	model.SeverityLow:      severityInformation,
+	model.SeverityInfo:     severityHint,
+	model.SeverityTrace:    severityHint,
+}
+
+// getFindings returns the results of the summary located in the given file, the diagnostics span the
+// line detected for each result, without its indentation
+func getFindings(summary *model.Summary, filePath string, lines []string) []finding {
+	findings := make([]finding, 0)
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		for j := range query.Files {
+			file := &query.Files[j]
+			if !samePath(file.FileName, filePath) {
+				continue
+			}
+
+			diagnostic := Diagnostic{
+				Range:    lineRange(file.Line, lines),
+				Severity: severities[query.Severity],
+				Code:     query.QueryID,
+				Source:   diagnosticSource,
+				Message:  diagnosticMessage(query, file),
+			}
+			if query.QueryURI != "" {
+				diagnostic.CodeDescription = &CodeDescription{Href: query.QueryURI}
+			}
+
+			findings = append(findings, finding{
+				diagnostic: diagnostic,
+				queryName:  query.QueryName,
+				remediation: remediation.Remediation{
+					Line:          file.Line,
+					Remediation:   file.Remediation,
+					SimilarityID:  file.SimilarityID,
+					QueryID:       query.QueryID,
+					SearchKey:     file.SearchKey,
+					ExpectedValue: file.KeyExpectedValue,
+					ActualValue:   file.KeyActualValue,
+				},
+				remediationType: file.RemediationType,
+			})
+		}
+	}
+	return findings
+}
+
+// samePath reports whether both paths are the same file, summaries hold paths relative to the working directory
+func samePath(summaryPath, filePath string) bool {
+	absPath, err := filepath.Abs(summaryPath)
+	if err != nil {
+		return filepath.Clean(summaryPath) == filepath.Clean(filePath)
+	}
+	return absPath == filepath.Clean(filePath)
+}
+
+func diagnosticMessage(query *model.QueryResult, file *model.VulnerableFile) string {
+	if file.KeyExpectedValue == "" {
+		return fmt.Sprintf("%s: %s", query.QueryName, query.Description)
+	}
+	return fmt.Sprintf("%s: %s", query.QueryName, file.KeyExpectedValue)
+}
+
+// lineRange returns the range of the one based line, results without a line are reported on the first line
+func lineRange(line int, lines []string) Range {
+	idx := line - 1
+	if idx < 0 || idx >= len(lines) {
+		idx = 0
+	}
+	if len(lines) == 0 {
+		return Range{}
+	}
+
+	content := strings.TrimSuffix(lines[idx], "\r")
+	indentation := content[:len(content)-len(strings.TrimLeft(content, " \t"))]
+	return Range{
+		Start: Position{Line: idx, Character: utf16Len(indentation)},
+		End:   Position{Line: idx, Character: utf16Len(content)},
+	}
+}
+
+// codeAction returns the quick fix of the finding, false when it has no remediation or it can not be applied
+func (f *finding) codeAction(uri string, lines []string) (CodeAction, bool) {
+	if f.remediation.Remediation == "" {
+		return CodeAction{}, false
+	}
+	remediated := remediation.RemediateLines(&f.remediation, f.remediationType, lines)
+	if len(remediated) == 0 {
+		return CodeAction{}, false
+	}
+
+	idx := f.remediation.Line - 1
+	var edit TextEdit
+	switch f.remediationType {
+	case "replacement":
+		original := strings.TrimSuffix(lines[idx], "\r")
+		edit = TextEdit{
+			Range: Range{
+				Start: Position{Line: idx},
+				End:   Position{Line: idx, Character: utf16Len(original)},
+			},
+			NewText: strings.TrimSuffix(remediated[idx], "\r"),
+		}
+	case "addition":
+		lineBreak := "\n"
+		if strings.HasSuffix(lines[idx], "\r") {
+			lineBreak = "\r\n"
+		}
+		position := Position{Line: idx + 1}
+		edit = TextEdit{
+			Range:   Range{Start: position, End: position},
+			NewText: remediated[idx+1] + lineBreak,
+		}
+	}
+
+	return CodeAction{
+		Title:       "Fix: " + f.queryName,
+		Kind:        codeActionQuickFix,
+		Diagnostics: []Diagnostic{f.diagnostic},
+		IsPreferred: true,
+		Edit: &WorkspaceEdit{
+			Changes: map[string][]TextEdit{uri: {edit}},
+		},
+	}, true
+}
+
+// utf16Len returns the length of the string in UTF-16 code units, the unit of the character offsets
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
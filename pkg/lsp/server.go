@@ -0,0 +1,437 @@
+/*
+Package lsp implements a Language Server Protocol server publishing the results of KICS scans as diagnostics of
+the documents open in an editor, with quick fixes built from the remediation of each result
+*/
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Checkmarx/kics/v2/internal/constants"
+	"github.com/Checkmarx/kics/v2/pkg/api"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultDebounce is the time waited after the last change of a document before scanning it
+const DefaultDebounce = 300 * time.Millisecond
+
+// ErrExitWithoutShutdown is returned by Serve when the client exits without requesting a shutdown first
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown request")
+
+// terraformExtensions are the extensions of the files read from disk along with a Terraform document, since its
+// resources may reference variables and locals declared in the other files of the module
+var terraformExtensions = map[string]bool{".tf": true, ".tfvars": true}
+
+// Scanner scans files held in memory, api.Scanner keeps the queries loaded across scans
+type Scanner interface {
+	Scan(ctx context.Context, files []api.InMemoryFile) (model.Summary, error)
+}
+
+// Server is a language server scanning the documents open in the client
+type Server struct {
+	scanner  Scanner
+	debounce time.Duration
+
+	conn        *conn
+	ctx         context.Context
+	initialized bool
+	shutdown    bool
+
+	mu        sync.Mutex
+	documents map[string]*document
+	scans     sync.WaitGroup
+}
+
+// document is a text document open in the client
+type document struct {
+	path     string
+	version  int
+	text     string
+	findings []finding
+	timer    *time.Timer
+}
+
+// NewServer creates a new language server using the scanner to scan the documents
+func NewServer(scanner Scanner, debounce time.Duration) *Server {
+	return &Server{
+		scanner:   scanner,
+		debounce:  debounce,
+		documents: make(map[string]*document),
+	}
+}
+
+// Serve reads the messages of the client from the reader and writes the responses and notifications to the
+// writer until the client exits, the reader is closed or the context is canceled
+func (s *Server) Serve(ctx context.Context, reader io.Reader, writer io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer s.stopScans(cancel)
+
+	s.ctx = ctx
+	s.conn = newConn(reader, writer)
+
+	messages := make(chan *message)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := s.conn.read()
+			if errors.Is(err, errInvalidMessage) {
+				log.Debug().Msgf("Failed to decode LSP message: %s", err)
+				s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+				continue
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case msg := <-messages:
+			if msg.Method == "exit" {
+				if !s.shutdown {
+					return ErrExitWithoutShutdown
+				}
+				return nil
+			}
+			s.handle(msg)
+		}
+	}
+}
+
+// stopScans cancels the pending scans and waits for the running ones to finish
+func (s *Server) stopScans(cancel context.CancelFunc) {
+	s.mu.Lock()
+	for _, doc := range s.documents {
+		s.stopTimer(doc)
+	}
+	s.mu.Unlock()
+	cancel()
+	s.scans.Wait()
+}
+
+func (s *Server) handle(msg *message) {
+	isRequest := msg.ID != nil
+	switch {
+	case msg.Method == "initialize":
+		s.initialized = true
+		s.reply(msg.ID, initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncOptions{
+					OpenClose: true,
+					Change:    syncFull,
+					Save:      saveOptions{IncludeText: true},
+				},
+				CodeActionProvider: codeActionOptions{CodeActionKinds: []string{codeActionQuickFix}},
+			},
+			ServerInfo: serverInfo{Name: diagnosticSource, Version: constants.Version},
+		}, nil)
+		return
+	case !s.initialized:
+		if isRequest {
+			s.reply(msg.ID, nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"})
+		}
+		return
+	case s.shutdown:
+		if isRequest {
+			s.reply(msg.ID, nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"})
+		}
+		return
+	}
+
+	var err error
+	var result interface{}
+	switch msg.Method {
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		err = s.didOpen(msg.Params)
+	case "textDocument/didChange":
+		err = s.didChange(msg.Params)
+	case "textDocument/didSave":
+		err = s.didSave(msg.Params)
+	case "textDocument/didClose":
+		err = s.didClose(msg.Params)
+	case "textDocument/codeAction":
+		result, err = s.codeAction(msg.Params)
+	default:
+		if isRequest {
+			s.reply(msg.ID, nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method})
+		}
+		return
+	}
+
+	if err != nil {
+		log.Debug().Msgf("Invalid params of LSP method %s: %s", msg.Method, err)
+	}
+	if !isRequest {
+		return
+	}
+	if err != nil {
+		s.reply(msg.ID, nil, &responseError{Code: codeInvalidParams, Message: err.Error()})
+		return
+	}
+	s.reply(msg.ID, result, nil)
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, respErr *responseError) {
+	s.send(response{JSONRPC: "2.0", ID: id, Result: result, Error: respErr})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.send(struct {
+		JSONRPC string      `json:"jsonrpc"`
+		Method  string      `json:"method"`
+		Params  interface{} `json:"params"`
+	}{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) send(msg interface{}) {
+	if err := s.conn.write(msg); err != nil {
+		log.Error().Msgf("Failed to write LSP message: %s", err)
+	}
+}
+
+func (s *Server) didOpen(params json.RawMessage) error {
+	var p didOpenParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := &document{
+		path:    uriToPath(p.TextDocument.URI),
+		version: p.TextDocument.Version,
+		text:    p.TextDocument.Text,
+	}
+	if old, ok := s.documents[p.TextDocument.URI]; ok {
+		s.stopTimer(old)
+	}
+	s.documents[p.TextDocument.URI] = doc
+	s.scheduleScan(p.TextDocument.URI, doc, 0)
+	return nil
+}
+
+func (s *Server) didChange(params json.RawMessage) error {
+	var p didChangeParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.documents[p.TextDocument.URI]
+	if !ok || len(p.ContentChanges) == 0 {
+		return nil
+	}
+	// the server asks for full synchronization, so the last change holds the whole document
+	doc.text = p.ContentChanges[len(p.ContentChanges)-1].Text
+	doc.version = p.TextDocument.Version
+	s.scheduleScan(p.TextDocument.URI, doc, s.debounce)
+	return nil
+}
+
+func (s *Server) didSave(params json.RawMessage) error {
+	var p didSaveParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	if p.Text != nil {
+		doc.text = *p.Text
+	}
+	s.scheduleScan(p.TextDocument.URI, doc, 0)
+	return nil
+}
+
+func (s *Server) didClose(params json.RawMessage) error {
+	var p didCloseParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	doc, ok := s.documents[p.TextDocument.URI]
+	if ok {
+		s.stopTimer(doc)
+		delete(s.documents, p.TextDocument.URI)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         p.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	}
+	return nil
+}
+
+func (s *Server) codeAction(params json.RawMessage) ([]CodeAction, error) {
+	var p codeActionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	actions := make([]CodeAction, 0)
+	doc, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return actions, nil
+	}
+
+	lines := strings.Split(doc.text, "\n")
+	for i := range doc.findings {
+		f := &doc.findings[i]
+		if f.diagnostic.Range.Start.Line < p.Range.Start.Line || f.diagnostic.Range.Start.Line > p.Range.End.Line {
+			continue
+		}
+		if action, ok := f.codeAction(p.TextDocument.URI, lines); ok {
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+// scheduleScan scans the document once the delay elapses, replacing the scan already scheduled, s.mu must be held
+func (s *Server) scheduleScan(uri string, doc *document, delay time.Duration) {
+	s.stopTimer(doc)
+	if doc.path == "" {
+		return
+	}
+	s.scans.Add(1)
+	doc.timer = time.AfterFunc(delay, func() {
+		defer s.scans.Done()
+		s.scan(uri)
+	})
+}
+
+// stopTimer cancels the scan scheduled for the document, s.mu must be held
+func (s *Server) stopTimer(doc *document) {
+	if doc.timer != nil && doc.timer.Stop() {
+		s.scans.Done()
+	}
+	doc.timer = nil
+}
+
+// scan scans the document and publishes its diagnostics, unless it changed or was closed in the meantime
+func (s *Server) scan(uri string) {
+	s.mu.Lock()
+	doc, ok := s.documents[uri]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	version, text, path := doc.version, doc.text, doc.path
+	files := s.scanFiles(path, text)
+	s.mu.Unlock()
+
+	summary, err := s.scanner.Scan(s.ctx, files)
+	if err != nil {
+		if s.ctx.Err() == nil {
+			log.Error().Msgf("Failed to scan %s: %s", path, err)
+		}
+		return
+	}
+	findings := getFindings(&summary, path, strings.Split(text, "\n"))
+
+	s.mu.Lock()
+	doc, ok = s.documents[uri]
+	if !ok || doc.version != version || doc.text != text {
+		s.mu.Unlock()
+		return
+	}
+	doc.findings = findings
+	s.mu.Unlock()
+
+	diagnostics := make([]Diagnostic, 0, len(findings))
+	for i := range findings {
+		diagnostics = append(diagnostics, findings[i].diagnostic)
+	}
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Version:     version,
+		Diagnostics: diagnostics,
+	})
+}
+
+// scanFiles returns the files scanned along with the document, the other documents open in its directory and,
+// for Terraform, the files of its module saved on disk, s.mu must be held
+func (s *Server) scanFiles(path, text string) []api.InMemoryFile {
+	dir := filepath.Dir(path)
+	files := []api.InMemoryFile{{Path: path, Content: []byte(text)}}
+	included := map[string]bool{path: true}
+	for _, doc := range s.documents {
+		if doc.path != "" && !included[doc.path] && filepath.Dir(doc.path) == dir {
+			files = append(files, api.InMemoryFile{Path: doc.path, Content: []byte(doc.text)})
+			included[doc.path] = true
+		}
+	}
+
+	if !terraformExtensions[filepath.Ext(path)] {
+		return files
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return files
+	}
+	for _, entry := range entries {
+		filePath := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || !terraformExtensions[filepath.Ext(filePath)] || included[filePath] {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Clean(filePath))
+		if err != nil {
+			log.Debug().Msgf("Failed to read %s: %s", filePath, err)
+			continue
+		}
+		files = append(files, api.InMemoryFile{Path: filePath, Content: content})
+	}
+	return files
+}
+
+// uriToPath returns the path of a file URI, documents with other schemes are not scanned and have an empty path
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	path := u.Path
+	// file:///c:/dir/file.tf on Windows
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(path))
+}
@@ -0,0 +1,235 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Checkmarx/kics/v2/pkg/api"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const testDocument = `resource "aws_s3_bucket" "b" {
+  bucket = "my-tf-test-bucket"
+  acl    = "public-read"
+}
+`
+
+type fakeScanner struct {
+	mu    sync.Mutex
+	files [][]api.InMemoryFile
+}
+
+func (f *fakeScanner) Scan(ctx context.Context, files []api.InMemoryFile) (model.Summary, error) {
+	f.mu.Lock()
+	f.files = append(f.files, files)
+	f.mu.Unlock()
+
+	return model.Summary{
+		Queries: []model.QueryResult{
+			{
+				QueryName: "S3 Bucket ACL Allows Read Or Write to All Users",
+				QueryID:   "38c5ee0d-7f22-4260-ab72-5073048df100",
+				QueryURI:  "https://docs.aws.amazon.com/AmazonS3/latest/dev/acl-overview.html",
+				Severity:  model.SeverityCritical,
+				Files: []model.VulnerableFile{
+					{
+						FileName:         files[0].Path,
+						Line:             3,
+						SimilarityID:     "similarity",
+						SearchKey:        "aws_s3_bucket[b].acl=public-read",
+						KeyExpectedValue: "'acl' should equal to 'private'",
+						KeyActualValue:   "'acl' is equal 'public-read'",
+						Remediation:      `{"before":"public-read","after":"private"}`,
+						RemediationType:  "replacement",
+					},
+					{
+						FileName: filepath.Join("other", "main.tf"),
+						Line:     1,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+type testClient struct {
+	t      *testing.T
+	conn   *conn
+	served chan error
+	nextID int
+}
+
+func newTestClient(t *testing.T, scanner Scanner) *testClient {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	client := &testClient{
+		t:      t,
+		conn:   newConn(outReader, inWriter),
+		served: make(chan error, 1),
+	}
+	go func() {
+		client.served <- NewServer(scanner, time.Millisecond).Serve(context.Background(), inReader, outWriter)
+		outWriter.Close()
+	}()
+	return client
+}
+
+func (c *testClient) request(method string, params interface{}) *message {
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	require.NoError(c.t, c.conn.write(map[string]interface{}{"jsonrpc": "2.0", "id": &id, "method": method, "params": params}))
+	for {
+		msg, err := c.conn.read()
+		require.NoError(c.t, err)
+		if msg.ID != nil && string(*msg.ID) == string(id) {
+			return msg
+		}
+	}
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	require.NoError(c.t, c.conn.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}))
+}
+
+// diagnostics waits for the next diagnostics published by the server
+func (c *testClient) diagnostics() publishDiagnosticsParams {
+	for {
+		msg, err := c.conn.read()
+		require.NoError(c.t, err)
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params publishDiagnosticsParams
+		require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+		return params
+	}
+}
+
+func (c *testClient) exit() error {
+	c.request("shutdown", nil)
+	c.notify("exit", nil)
+	return <-c.served
+}
+
+func decodeResult(t *testing.T, msg *message, v interface{}) {
+	require.Nil(t, msg.Error)
+	b, err := json.Marshal(msg.Result)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, v))
+}
+
+func TestServer_Initialize(t *testing.T) {
+	client := newTestClient(t, &fakeScanner{})
+
+	msg := client.request("textDocument/codeAction", codeActionParams{})
+	require.NotNil(t, msg.Error)
+	require.Equal(t, codeServerNotInitialized, msg.Error.Code)
+
+	var result initializeResult
+	decodeResult(t, client.request("initialize", map[string]interface{}{}), &result)
+	require.Equal(t, syncFull, result.Capabilities.TextDocumentSync.Change)
+	require.True(t, result.Capabilities.TextDocumentSync.OpenClose)
+	require.Equal(t, []string{codeActionQuickFix}, result.Capabilities.CodeActionProvider.CodeActionKinds)
+	require.Equal(t, "KICS", result.ServerInfo.Name)
+
+	msg = client.request("textDocument/hover", map[string]interface{}{})
+	require.NotNil(t, msg.Error)
+	require.Equal(t, codeMethodNotFound, msg.Error.Code)
+
+	require.NoError(t, client.exit())
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	client := newTestClient(t, &fakeScanner{})
+	client.request("initialize", map[string]interface{}{})
+	client.notify("exit", nil)
+	require.ErrorIs(t, <-client.served, ErrExitWithoutShutdown)
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.tf")
+	uri := "file://" + filepath.ToSlash(path)
+	scanner := &fakeScanner{}
+	client := newTestClient(t, scanner)
+	client.request("initialize", map[string]interface{}{})
+	client.notify("initialized", map[string]interface{}{})
+
+	client.notify("textDocument/didOpen", didOpenParams{
+		TextDocument: textDocumentItem{URI: uri, Version: 1, Text: testDocument},
+	})
+	published := client.diagnostics()
+	require.Equal(t, uri, published.URI)
+	require.Equal(t, 1, published.Version)
+	require.Equal(t, []Diagnostic{
+		{
+			Range: Range{
+				Start: Position{Line: 2, Character: 2},
+				End:   Position{Line: 2, Character: 24},
+			},
+			Severity:        severityError,
+			Code:            "38c5ee0d-7f22-4260-ab72-5073048df100",
+			CodeDescription: &CodeDescription{Href: "https://docs.aws.amazon.com/AmazonS3/latest/dev/acl-overview.html"},
+			Source:          "KICS",
+			Message:         "S3 Bucket ACL Allows Read Or Write to All Users: 'acl' should equal to 'private'",
+		},
+	}, published.Diagnostics)
+
+	var actions []CodeAction
+	decodeResult(t, client.request("textDocument/codeAction", codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Range:        published.Diagnostics[0].Range,
+	}), &actions)
+	require.Len(t, actions, 1)
+	require.Equal(t, "Fix: S3 Bucket ACL Allows Read Or Write to All Users", actions[0].Title)
+	require.Equal(t, codeActionQuickFix, actions[0].Kind)
+	require.Equal(t, []TextEdit{
+		{
+			Range: Range{
+				Start: Position{Line: 2, Character: 0},
+				End:   Position{Line: 2, Character: 24},
+			},
+			NewText: `  acl    = "private"`,
+		},
+	}, actions[0].Edit.Changes[uri])
+
+	decodeResult(t, client.request("textDocument/codeAction", codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Range:        Range{Start: Position{Line: 0}, End: Position{Line: 1}},
+	}), &actions)
+	require.Empty(t, actions)
+
+	client.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   versionedTextDocumentIdentifier{URI: uri, Version: 2},
+		"contentChanges": []map[string]string{{"text": testDocument + "\n"}},
+	})
+	published = client.diagnostics()
+	require.Equal(t, 2, published.Version)
+	require.Len(t, published.Diagnostics, 1)
+
+	client.notify("textDocument/didClose", didCloseParams{TextDocument: textDocumentIdentifier{URI: uri}})
+	published = client.diagnostics()
+	require.Empty(t, published.Diagnostics)
+
+	require.NoError(t, client.exit())
+
+	scanner.mu.Lock()
+	defer scanner.mu.Unlock()
+	require.Len(t, scanner.files, 2)
+	require.Equal(t, path, scanner.files[0][0].Path)
+	require.Equal(t, testDocument+"\n", string(scanner.files[1][0].Content))
+}
+
+func TestLineRange(t *testing.T) {
+	lines := []string{"a:", "  b: \"é😀\"\r"}
+	require.Equal(t, Range{Start: Position{Line: 1, Character: 2}, End: Position{Line: 1, Character: 10}}, lineRange(2, lines))
+	require.Equal(t, Range{Start: Position{Line: 0}, End: Position{Line: 0, Character: 2}}, lineRange(0, lines))
+	require.Equal(t, Range{}, lineRange(1, nil))
+}
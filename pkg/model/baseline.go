@@ -0,0 +1,88 @@
+package model
+
+// Baseline states of a finding when comparing the scan results with a baseline
+const (
+	BaselineStateNew       = "new"
+	BaselineStateUnchanged = "unchanged"
+	BaselineStateFixed     = "fixed"
+)
+
+// BaselineSummary contains the result of comparing the scan results with a previous scan
+type BaselineSummary struct {
+	Path                string           `json:"path"`
+	NewCounter          int              `json:"new_counter"`
+	UnchangedCounter    int              `json:"unchanged_counter"`
+	FixedCounter        int              `json:"fixed_counter"`
+	NewSeverityCounters map[Severity]int `json:"new_severity_counters"`
+	Fixed               QueryResultSlice `json:"fixed,omitempty"`
+}
+
+// ApplyBaseline classifies each finding of the summary as new or unchanged, based on the findings
+// of the baseline summary, and collects the baseline findings that are no longer present as fixed.
+// Findings are matched by their SimilarityID or OldSimilarityID
+func (s *Summary) ApplyBaseline(baseline *Summary, baselinePath string) {
+	baselineIDs := getSimilarityIDs(baseline.Queries)
+	currentIDs := getSimilarityIDs(s.Queries)
+
+	baselineSummary := &BaselineSummary{
+		Path:                baselinePath,
+		NewSeverityCounters: initSeverityCounter(),
+		Fixed:               make(QueryResultSlice, 0),
+	}
+
+	for i := range s.Queries {
+		for j := range s.Queries[i].Files {
+			file := &s.Queries[i].Files[j]
+			if matchesSimilarityIDs(file, baselineIDs) {
+				file.BaselineState = BaselineStateUnchanged
+				baselineSummary.UnchangedCounter++
+				continue
+			}
+			file.BaselineState = BaselineStateNew
+			baselineSummary.NewCounter++
+			baselineSummary.NewSeverityCounters[s.Queries[i].Severity]++
+		}
+	}
+
+	for i := range baseline.Queries {
+		fixedFiles := make([]VulnerableFile, 0)
+		for j := range baseline.Queries[i].Files {
+			file := baseline.Queries[i].Files[j]
+			if matchesSimilarityIDs(&file, currentIDs) {
+				continue
+			}
+			file.BaselineState = BaselineStateFixed
+			fixedFiles = append(fixedFiles, file)
+		}
+		if len(fixedFiles) == 0 {
+			continue
+		}
+		fixedQuery := baseline.Queries[i]
+		fixedQuery.Files = fixedFiles
+		baselineSummary.Fixed = append(baselineSummary.Fixed, fixedQuery)
+		baselineSummary.FixedCounter += len(fixedFiles)
+	}
+
+	s.Baseline = baselineSummary
+}
+
+// getSimilarityIDs returns the set of similarity IDs, current and old, of the findings
+func getSimilarityIDs(queries QueryResultSlice) map[string]bool {
+	ids := make(map[string]bool)
+	for i := range queries {
+		for j := range queries[i].Files {
+			if queries[i].Files[j].SimilarityID != "" {
+				ids[queries[i].Files[j].SimilarityID] = true
+			}
+			if queries[i].Files[j].OldSimilarityID != "" {
+				ids[queries[i].Files[j].OldSimilarityID] = true
+			}
+		}
+	}
+	return ids
+}
+
+func matchesSimilarityIDs(file *VulnerableFile, ids map[string]bool) bool {
+	return (file.SimilarityID != "" && ids[file.SimilarityID]) ||
+		(file.OldSimilarityID != "" && ids[file.OldSimilarityID])
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSummary_ApplyBaseline tests the function [ApplyBaseline()] and all the methods called by it
+func TestSummary_ApplyBaseline(t *testing.T) {
+	baseline := Summary{
+		Queries: QueryResultSlice{
+			{
+				QueryID:  "query-1",
+				Severity: SeverityHigh,
+				Files: []VulnerableFile{
+					{FileName: "main.tf", SimilarityID: "unchanged"},
+					{FileName: "main.tf", SimilarityID: "fixed"},
+				},
+			},
+			{
+				QueryID:  "query-2",
+				Severity: SeverityLow,
+				Files: []VulnerableFile{
+					{FileName: "main.tf", SimilarityID: "renamed-old"},
+				},
+			},
+		},
+	}
+
+	summary := Summary{
+		Queries: QueryResultSlice{
+			{
+				QueryID:  "query-1",
+				Severity: SeverityHigh,
+				Files: []VulnerableFile{
+					{FileName: "main.tf", SimilarityID: "unchanged"},
+					{FileName: "main.tf", SimilarityID: "new"},
+				},
+			},
+			{
+				QueryID:  "query-2",
+				Severity: SeverityLow,
+				Files: []VulnerableFile{
+					{FileName: "main.tf", SimilarityID: "renamed-new", OldSimilarityID: "renamed-old"},
+				},
+			},
+		},
+	}
+
+	summary.ApplyBaseline(&baseline, "baseline.json")
+
+	require.Equal(t, BaselineStateUnchanged, summary.Queries[0].Files[0].BaselineState)
+	require.Equal(t, BaselineStateNew, summary.Queries[0].Files[1].BaselineState)
+	require.Equal(t, BaselineStateUnchanged, summary.Queries[1].Files[0].BaselineState)
+
+	require.NotNil(t, summary.Baseline)
+	require.Equal(t, "baseline.json", summary.Baseline.Path)
+	require.Equal(t, 1, summary.Baseline.NewCounter)
+	require.Equal(t, 2, summary.Baseline.UnchangedCounter)
+	require.Equal(t, 1, summary.Baseline.FixedCounter)
+	require.Equal(t, 1, summary.Baseline.NewSeverityCounters[SeverityHigh])
+	require.Equal(t, 0, summary.Baseline.NewSeverityCounters[SeverityLow])
+
+	require.Len(t, summary.Baseline.Fixed, 1)
+	require.Equal(t, "query-1", summary.Baseline.Fixed[0].QueryID)
+	require.Len(t, summary.Baseline.Fixed[0].Files, 1)
+	require.Equal(t, "fixed", summary.Baseline.Fixed[0].Files[0].SimilarityID)
+	require.Equal(t, BaselineStateFixed, summary.Baseline.Fixed[0].Files[0].BaselineState)
+
+	// the baseline findings are not changed
+	require.Empty(t, baseline.Queries[0].Files[1].BaselineState)
+}
@@ -19,11 +19,18 @@ const (
 	KindPROTO     FileKind = "PROTO"
 	KindCOMMON    FileKind = "*"
 	KindHELM      FileKind = "HELM"
+	KindKUSTOMIZE FileKind = "KUSTOMIZE"
 	KindBUILDAH   FileKind = "SH"
 	KindCFG       FileKind = "CFG"
 	KindINI       FileKind = "INI"
 )
 
+// ModuleCallKey is the document key that holds the module call of a Terraform module instance document
+const ModuleCallKey = "_kics_module"
+
+// ReferencesKey is the document key that holds the references between the resources of a Terraform document
+const ReferencesKey = "_kics_references"
+
 // Constants to describe commands given from comments
 const (
 	IgnoreLine    CommentCommand = "ignore-line"
@@ -87,6 +94,9 @@ type VulnerabilityLines struct {
 	VulnLines             *[]CodeLine
 	LineWithVulnerability string
 	ResolvedFile          string
+	// LineSimilarity is true when the similarity of the result depends on its line, as the results of the file
+	// the line is found in when that file is also scanned on its own
+	LineSimilarity bool
 }
 
 // CommentCommand represents a command given from a comment
@@ -114,6 +124,31 @@ type ExtractedPathObject struct {
 	LocalPath bool
 }
 
+// ModuleCall is the module block that instantiated the resources of a Terraform module document
+type ModuleCall struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Path        string `json:"path"`
+	ModuleFile  string `json:"file"`
+	FileName    string `json:"call_file"`
+	Line        int    `json:"call_line"`
+	EndLine     int    `json:"call_end_line"`
+	LinesIgnore []int  `json:"-"`
+}
+
+// Reference is an edge of the Terraform reference graph, an attribute of a resource or data source referencing
+// another resource, data source or module, e.g. the bucket attribute of an aws_s3_bucket_public_access_block
+// referencing aws_s3_bucket.example.id
+type Reference struct {
+	SourceType      string `json:"source_type"`
+	SourceName      string `json:"source_name"`
+	Attribute       string `json:"attribute"`
+	TargetType      string `json:"target_type"`
+	TargetName      string `json:"target_name"`
+	TargetAttribute string `json:"target_attribute"`
+	Line            int    `json:"line"`
+}
+
 // CommentsCommands list of commands on a file that will be parsed
 type CommentsCommands map[string]string
 
@@ -134,6 +169,9 @@ type FileMetadata struct {
 	ResolvedFiles     map[string]ResolvedFile
 	LinesOriginalData *[]string
 	IsMinified        bool
+	ModuleCall        *ModuleCall
+	Patches           []ResolvedHelm
+	Environment       string
 }
 
 // QueryMetadata is a representation of general information about a query
@@ -184,6 +222,8 @@ type Vulnerability struct {
 	CloudProvider    string      `json:"cloud_provider"`
 	Remediation      string      `db:"remediation" json:"remediation"`
 	RemediationType  string      `db:"remediation_type" json:"remediation_type"`
+	ModuleCall       *ModuleCall `json:"module_call,omitempty"`
+	Environment      string      `json:"environment,omitempty"`
 }
 
 // QueryConfig is a struct that contains the fileKind and platform of the rego query
@@ -205,6 +245,10 @@ type ResolvedHelm struct {
 	OriginalData []byte
 	SplitID      string
 	IDInfo       map[int]interface{}
+	// Patches are the files that patch the resolved content (ex: kustomize patches)
+	Patches []ResolvedHelm
+	// Environment is the values file the content was rendered with (ex: helm environments)
+	Environment string
 }
 
 // Extensions represents a list of supported extensions
@@ -222,6 +266,9 @@ func (e Extensions) Include(ext string) bool {
 type LineObject struct {
 	Line int                      `json:"_kics_line"`
 	Arr  []map[string]*LineObject `json:"_kics_arr,omitempty"`
+	// Generated is true for the values generated by a parser, which are not written in the file and are found
+	// in their line information
+	Generated bool `json:"_kics_generated,omitempty"`
 }
 
 // MatchedFilesRegex returns the regex rule to identify if an extension is supported or not
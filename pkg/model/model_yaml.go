@@ -11,6 +11,35 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// ShortFormKey marks the maps of the CloudFormation intrinsic functions written in their short form, which are
+// replaced by their arguments when they are not evaluated, see cloudformation.RestoreShortForm
+const ShortFormKey = "_kics_short_form"
+
+// cloudFormationTags are the YAML tags of the short form of the CloudFormation intrinsic functions
+// and the names of their long form
+var cloudFormationTags = map[string]string{
+	"!And":          "Fn::And",
+	"!Base64":       "Fn::Base64",
+	"!Cidr":         "Fn::Cidr",
+	"!Condition":    "Condition",
+	"!Equals":       "Fn::Equals",
+	"!FindInMap":    "Fn::FindInMap",
+	"!GetAtt":       "Fn::GetAtt",
+	"!GetAZs":       "Fn::GetAZs",
+	"!If":           "Fn::If",
+	"!ImportValue":  "Fn::ImportValue",
+	"!Join":         "Fn::Join",
+	"!Length":       "Fn::Length",
+	"!Not":          "Fn::Not",
+	"!Or":           "Fn::Or",
+	"!Ref":          "Ref",
+	"!Select":       "Fn::Select",
+	"!Split":        "Fn::Split",
+	"!Sub":          "Fn::Sub",
+	"!ToJsonString": "Fn::ToJsonString",
+	"!Transform":    "Fn::Transform",
+}
+
 // UnmarshalYAML is a custom yaml parser that places line information in the payload
 func (m *Document) UnmarshalYAML(value *yaml.Node) error {
 	dpc := unmarshal(value)
@@ -62,6 +91,10 @@ func GetIgnoreLines(file *FileMetadata) []int {
 // to place their line information in the payload
 func unmarshal(val *yaml.Node) interface{} {
 	tmp := make(map[string]interface{})
+	if long := longFormNode(val); long != val {
+		tmp[ShortFormKey] = true
+		val = long
+	}
 	ignoreCommentsYAML(val)
 
 	// if Yaml Node is an Array than we are working with ansible
@@ -79,20 +112,21 @@ func unmarshal(val *yaml.Node) interface{} {
 		// iterate two by two, since first iteration is the key and the second is the value
 		for i := 0; i < len(val.Content); i += 2 {
 			if val.Content[i].Kind == yaml.ScalarNode {
-				switch val.Content[i+1].Kind {
+				value := longFormNode(val.Content[i+1])
+				switch value.Kind {
 				case yaml.ScalarNode:
-					tmp[val.Content[i].Value] = scalarNodeResolver(val.Content[i+1])
+					tmp[val.Content[i].Value] = scalarNodeResolver(value)
 				// in case value iteration is a map
 				case yaml.MappingNode:
 					// unmarshall map value and get its line information
 					tt := unmarshal(val.Content[i+1]).(map[string]interface{})
-					tt["_kics_lines"] = getLines(val.Content[i+1], val.Content[i].Line)
+					tt["_kics_lines"] = getLines(value, val.Content[i].Line)
 					tmp[val.Content[i].Value] = tt
 				// in case value iteration is an array
 				case yaml.SequenceNode:
 					contentArray := make([]interface{}, 0)
 					// unmarshall each iteration of the array
-					for _, contentEntry := range val.Content[i+1].Content {
+					for _, contentEntry := range value.Content {
 						contentArray = append(contentArray, unmarshal(contentEntry))
 					}
 					tmp[val.Content[i].Value] = contentArray
@@ -114,6 +148,7 @@ func unmarshal(val *yaml.Node) interface{} {
 // getLines creates the map containing the line information for the yaml Node
 // def is the line to be used as "_kics__default"
 func getLines(val *yaml.Node, def int) map[string]*LineObject {
+	val = longFormNode(val)
 	lineMap := make(map[string]*LineObject)
 
 	// line information map
@@ -131,8 +166,8 @@ func getLines(val *yaml.Node, def int) map[string]*LineObject {
 	for i := 0; i < len(val.Content); i += 2 {
 		lineArr := make([]map[string]*LineObject, 0)
 		// in case the value iteration is an array call getLines for each iteration of the array
-		if val.Content[i+1].Kind == yaml.SequenceNode {
-			for _, contentEntry := range val.Content[i+1].Content {
+		if value := longFormNode(val.Content[i+1]); value.Kind == yaml.SequenceNode {
+			for _, contentEntry := range value.Content {
 				defaultLine := val.Content[i].Line
 				if contentEntry.Kind == yaml.ScalarNode {
 					defaultLine = contentEntry.Line
@@ -172,6 +207,31 @@ func getSeqLines(val *yaml.Node, def int) map[string]*LineObject {
 	return lineMap
 }
 
+// longFormNode returns the node of the long form of a CloudFormation intrinsic function written in its short
+// form, ex: "!Ref Bucket" is returned as "Ref: Bucket", other nodes are returned as they are. The functions
+// are marked with the ShortFormKey, see unmarshal
+func longFormNode(val *yaml.Node) *yaml.Node {
+	name, ok := cloudFormationTags[val.Tag]
+	if !ok {
+		return val
+	}
+	value := *val
+	value.Tag = ""
+	if value.Kind == yaml.ScalarNode {
+		value.Tag = "!!str"
+	}
+	return &yaml.Node{
+		Kind:   yaml.MappingNode,
+		Tag:    "!!map",
+		Line:   val.Line,
+		Column: val.Column,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: name, Line: val.Line, Column: val.Column},
+			&value,
+		},
+	}
+}
+
 // scalarNodeResolver transforms a ScalarNode value in its correct type
 func scalarNodeResolver(val *yaml.Node) interface{} {
 	var transformed interface{} = val.Value
@@ -513,3 +513,32 @@ func Test_GetIgnoreLines(t *testing.T) {
 		})
 	}
 }
+
+// TestDocument_UnmarshalYAML_ShortForm tests that the CloudFormation intrinsic functions written in their short form
+// are unmarshalled as their long form, marked with the ShortFormKey
+func TestDocument_UnmarshalYAML_ShortForm(t *testing.T) {
+	sample := `
+Bucket: !Ref BucketName
+Tags:
+  - !If [IsProd, prod, dev]
+Other: !Custom value
+`
+	var document Document
+	require.NoError(t, yaml.Unmarshal([]byte(sample), &document))
+
+	bucket, ok := document["Bucket"].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "BucketName", bucket["Ref"])
+	require.Equal(t, true, bucket[ShortFormKey])
+	require.Contains(t, bucket, "_kics_lines")
+
+	tags, ok := document["Tags"].([]interface{})
+	require.True(t, ok)
+	require.Equal(t, map[string]interface{}{
+		"Fn::If":     []interface{}{"IsProd", "prod", "dev"},
+		ShortFormKey: true,
+	}, tags[0])
+
+	// tags that are not intrinsic functions are ignored
+	require.Equal(t, "value", document["Other"])
+}
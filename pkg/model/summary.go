@@ -37,6 +37,9 @@ type VulnerableFile struct {
 	Value            *string     `json:"value,omitempty"`
 	Remediation      string      `json:"remediation,omitempty"`
 	RemediationType  string      `json:"remediation_type,omitempty"`
+	ModuleCall       *ModuleCall `json:"module_call,omitempty"`
+	Environment      string      `json:"environment,omitempty"`
+	BaselineState    string      `json:"baseline_state,omitempty"`
 }
 
 // QueryResult contains a query that tested positive ID, name, severity and a list of files that tested vulnerable
@@ -102,6 +105,7 @@ type Summary struct {
 	ScannedPaths []string          `json:"paths"`
 	Queries      QueryResultSlice  `json:"queries"`
 	Bom          QueryResultSlice  `json:"bill_of_materials,omitempty"`
+	Baseline     *BaselineSummary  `json:"baseline,omitempty"`
 	FilePaths    map[string]string `json:"-"`
 }
 
@@ -183,6 +187,16 @@ func resolvePath(filePath string, pathExtractionMap map[string]ExtractedPathObje
 	return returnPath
 }
 
+func resolveModuleCall(moduleCall *ModuleCall, pathExtractionMap map[string]ExtractedPathObject) *ModuleCall {
+	if moduleCall == nil {
+		return nil
+	}
+	resolved := *moduleCall
+	resolved.ModuleFile = resolvePath(moduleCall.ModuleFile, pathExtractionMap)
+	resolved.FileName = resolvePath(moduleCall.FileName, pathExtractionMap)
+	return &resolved
+}
+
 // CreateSummary creates a report for a single scan, based on its scanID
 func CreateSummary(counters Counters, vulnerabilities []Vulnerability,
 	scanID string, pathExtractionMap map[string]ExtractedPathObject, version Version) Summary {
@@ -231,6 +245,8 @@ func CreateSummary(counters Counters, vulnerabilities []Vulnerability,
 			Value:            item.Value,
 			Remediation:      item.Remediation,
 			RemediationType:  item.RemediationType,
+			ModuleCall:       resolveModuleCall(item.ModuleCall, pathExtractionMap),
+			Environment:      item.Environment,
 		})
 
 		filePaths[resolvedPath] = item.FileName
@@ -239,7 +255,7 @@ func CreateSummary(counters Counters, vulnerabilities []Vulnerability,
 	}
 
 	queries := make([]QueryResult, 0, len(q))
-	sevs := map[Severity]int{SeverityTrace: 0, SeverityInfo: 0, SeverityLow: 0, SeverityMedium: 0, SeverityHigh: 0, SeverityCritical: 0}
+	sevs := initSeverityCounter()
 	for idx := range q {
 		sevs[q[idx].Severity] += len(q[idx].Files)
 
@@ -286,3 +302,7 @@ func CreateSummary(counters Counters, vulnerabilities []Vulnerability,
 		FilePaths:       filePaths,
 	}
 }
+
+func initSeverityCounter() map[Severity]int {
+	return map[Severity]int{SeverityTrace: 0, SeverityInfo: 0, SeverityLow: 0, SeverityMedium: 0, SeverityHigh: 0, SeverityCritical: 0}
+}
@@ -0,0 +1,180 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	shellPrefix    = "/bin/sh -c "
+	nopPrefix      = shellPrefix + "#(nop) "
+	buildkitSuffix = " # buildkit"
+)
+
+// buildArgsRegex matches the build arguments written before the commands of RUN instructions, ex: |2 A=1 B=2
+var buildArgsRegex = regexp.MustCompile(`^\|(\d+)\s+`)
+
+// imageConfig is the config of an image, with the settings of the containers created from it and the history
+// of the instructions it was built with
+type imageConfig struct {
+	Config struct {
+		User         string              `json:"User"`
+		ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+		Env          []string            `json:"Env"`
+		Entrypoint   []string            `json:"Entrypoint"`
+		Cmd          []string            `json:"Cmd"`
+		Volumes      map[string]struct{} `json:"Volumes"`
+		WorkingDir   string              `json:"WorkingDir"`
+		Labels       map[string]string   `json:"Labels"`
+		StopSignal   string              `json:"StopSignal"`
+		Healthcheck  *healthcheck        `json:"Healthcheck"`
+	} `json:"config"`
+	History []struct {
+		CreatedBy string `json:"created_by"`
+	} `json:"history"`
+}
+
+type healthcheck struct {
+	Test        []string      `json:"Test"`
+	Interval    time.Duration `json:"Interval"`
+	Timeout     time.Duration `json:"Timeout"`
+	StartPeriod time.Duration `json:"StartPeriod"`
+	Retries     int           `json:"Retries"`
+}
+
+// dockerfile returns the Dockerfile of the image, with the RUN instructions of its history followed by the
+// instructions of its config, so the Dockerfile queries analyze the image as if it was built from it
+func (c *imageConfig) dockerfile(reference string) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "# generated by KICS from the config of the image %s\n", reference)
+	fmt.Fprintf(sb, "FROM %s\n", reference)
+
+	for i := range c.History {
+		if command, ok := runCommand(c.History[i].CreatedBy); ok {
+			fmt.Fprintf(sb, "RUN %s\n", command)
+		}
+	}
+
+	config := &c.Config
+	for _, env := range config.Env {
+		if key, value, ok := strings.Cut(env, "="); ok {
+			fmt.Fprintf(sb, "ENV %s=%s\n", key, strconv.Quote(value))
+		}
+	}
+	for _, key := range sortedKeys(config.Labels) {
+		fmt.Fprintf(sb, "LABEL %s=%s\n", strconv.Quote(key), strconv.Quote(config.Labels[key]))
+	}
+	for _, port := range sortedKeys(config.ExposedPorts) {
+		// tcp is the default protocol of the ports
+		fmt.Fprintf(sb, "EXPOSE %s\n", strings.TrimSuffix(port, "/tcp"))
+	}
+	if volumes := sortedKeys(config.Volumes); len(volumes) > 0 {
+		fmt.Fprintf(sb, "VOLUME %s\n", jsonArray(volumes))
+	}
+	if config.WorkingDir != "" {
+		fmt.Fprintf(sb, "WORKDIR %s\n", config.WorkingDir)
+	}
+	if config.User != "" {
+		fmt.Fprintf(sb, "USER %s\n", config.User)
+	}
+	if config.StopSignal != "" {
+		fmt.Fprintf(sb, "STOPSIGNAL %s\n", config.StopSignal)
+	}
+	if config.Healthcheck != nil && len(config.Healthcheck.Test) > 0 {
+		fmt.Fprintf(sb, "HEALTHCHECK %s\n", config.Healthcheck.instruction())
+	}
+	if len(config.Entrypoint) > 0 {
+		fmt.Fprintf(sb, "ENTRYPOINT %s\n", jsonArray(config.Entrypoint))
+	}
+	if len(config.Cmd) > 0 {
+		fmt.Fprintf(sb, "CMD %s\n", jsonArray(config.Cmd))
+	}
+
+	return sb.String()
+}
+
+// runCommand returns the command of a RUN instruction of the history, the other instructions are set in the config
+func runCommand(createdBy string) (string, bool) {
+	command := strings.TrimSpace(createdBy)
+	if strings.HasSuffix(command, buildkitSuffix) {
+		if !strings.HasPrefix(command, "RUN ") {
+			return "", false
+		}
+		command = strings.TrimSuffix(strings.TrimPrefix(command, "RUN "), buildkitSuffix)
+	} else if strings.HasPrefix(command, nopPrefix) {
+		return "", false
+	}
+
+	if match := buildArgsRegex.FindStringSubmatch(command); match != nil {
+		count, _ := strconv.Atoi(match[1])
+		command = strings.TrimPrefix(command, match[0])
+		for i := 0; i < count; i++ {
+			if _, rest, ok := strings.Cut(command, " "); ok {
+				command = strings.TrimLeft(rest, " ")
+			}
+		}
+	}
+
+	if !strings.HasPrefix(command, shellPrefix) {
+		return "", false
+	}
+	command = strings.TrimSpace(strings.TrimPrefix(command, shellPrefix))
+	if command == "" {
+		return "", false
+	}
+	if !strings.Contains(command, "<<") {
+		command = strings.ReplaceAll(command, "\n", " \\\n")
+	}
+	return command, true
+}
+
+// instruction returns the arguments of the HEALTHCHECK instruction
+func (h *healthcheck) instruction() string {
+	switch h.Test[0] {
+	case "NONE":
+		return "NONE"
+	case "CMD-SHELL":
+		return h.options() + "CMD " + strings.Join(h.Test[1:], " ")
+	default:
+		return h.options() + "CMD " + jsonArray(h.Test[1:])
+	}
+}
+
+func (h *healthcheck) options() string {
+	options := ""
+	if h.Interval > 0 {
+		options += fmt.Sprintf("--interval=%s ", h.Interval)
+	}
+	if h.Timeout > 0 {
+		options += fmt.Sprintf("--timeout=%s ", h.Timeout)
+	}
+	if h.StartPeriod > 0 {
+		options += fmt.Sprintf("--start-period=%s ", h.StartPeriod)
+	}
+	if h.Retries > 0 {
+		options += fmt.Sprintf("--retries=%d ", h.Retries)
+	}
+	return options
+}
+
+func jsonArray(values []string) string {
+	content, err := json.Marshal(values)
+	if err != nil {
+		return "[]"
+	}
+	return string(content)
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,179 @@
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+	// maxFileSize is the size of the largest file extracted from the layers, 5MB
+	maxFileSize = 5 << 20
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+	// extractedExtensions are the extensions of the files KICS can scan, the configuration files of the
+	// operating system, such as .conf and .ini, are not extracted
+	extractedExtensions = map[string]bool{
+		".yaml":       true,
+		".yml":        true,
+		".json":       true,
+		".tf":         true,
+		".tfvars":     true,
+		".bicep":      true,
+		".proto":      true,
+		".dockerfile": true,
+	}
+
+	// ignoredFolders are the folders of the packages of the operating system and of the languages,
+	// which hold the files of the dependencies of the image
+	ignoredFolders = []string{
+		"proc", "sys", "dev", "lib", "lib64", "usr/lib", "usr/lib64", "usr/libexec", "usr/share", "usr/include",
+		"usr/local/lib", "usr/local/share", "var/lib", "var/cache", "var/log",
+	}
+	ignoredSegments = map[string]bool{
+		"node_modules":  true,
+		"site-packages": true,
+		"dist-packages": true,
+		"vendor":        true,
+		".git":          true,
+	}
+)
+
+// extractLayers extracts the files KICS can scan from the layers of an image, in order, the files deleted
+// by the whiteouts of an upper layer are removed, it returns the number of files of the image
+func (t *tarball) extractLayers(layers []string, destination string) (int, error) {
+	// files are the extracted files with the index of their layer
+	files := make(map[string]int)
+	for i, layer := range layers {
+		reader, err := t.open(layer)
+		if err != nil {
+			return 0, err
+		}
+		if err := extractLayer(reader, destination, i, files); err != nil {
+			return 0, errors.Wrapf(err, "failed to extract layer %s", layer)
+		}
+	}
+	return len(files), nil
+}
+
+func extractLayer(layer io.Reader, destination string, index int, files map[string]int) error {
+	uncompressed, closeReader, err := decompress(layer)
+	if err != nil {
+		return err
+	}
+	defer closeReader()
+
+	reader := tar.NewReader(uncompressed)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := cleanName(header.Name)
+		base := path.Base(name)
+		switch {
+		case base == opaqueWhiteout:
+			removeFiles(path.Dir(name), destination, index, files)
+		case strings.HasPrefix(base, whiteoutPrefix):
+			removeFiles(path.Join(path.Dir(name), strings.TrimPrefix(base, whiteoutPrefix)), destination, index, files)
+		case header.Typeflag == tar.TypeReg && header.Size <= maxFileSize && isExtracted(name):
+			if err := extractFile(reader, filepath.Join(destination, filepath.FromSlash(name))); err != nil {
+				log.Warn().Msgf("failed to extract file %s: %s", name, err)
+				continue
+			}
+			files[name] = index
+		}
+	}
+}
+
+// decompress returns the content of a layer, which is a tar archive compressed with gzip or zstd or not compressed
+func decompress(layer io.Reader) (io.Reader, func(), error) {
+	buffered := bufio.NewReader(layer)
+	magic, err := buffered.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		reader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		return reader, func() { _ = reader.Close() }, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		reader, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		return reader, reader.Close, nil
+	default:
+		return buffered, func() {}, nil
+	}
+}
+
+// isExtracted returns true if the file can be scanned by KICS and is not a file of a package
+func isExtracted(name string) bool {
+	base := path.Base(name)
+	if !extractedExtensions[path.Ext(base)] && !strings.HasPrefix(base, "Dockerfile") {
+		return false
+	}
+	for _, folder := range ignoredFolders {
+		if strings.HasPrefix(name, folder+"/") {
+			return false
+		}
+	}
+	for _, segment := range strings.Split(path.Dir(name), "/") {
+		if ignoredSegments[segment] {
+			return false
+		}
+	}
+	return true
+}
+
+func extractFile(reader io.Reader, destination string) error {
+	if err := os.MkdirAll(filepath.Dir(destination), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Clean(destination))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil { //nolint:gosec
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// removeFiles removes the files of the lower layers deleted by a whiteout, the name is a file or a folder
+func removeFiles(name, destination string, index int, files map[string]int) {
+	for file, layer := range files {
+		if layer >= index || (file != name && !strings.HasPrefix(file, name+"/") && name != ".") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(destination, filepath.FromSlash(file))); err != nil {
+			log.Err(err).Msgf("failed to remove file %s", file)
+		}
+		delete(files, file)
+	}
+}
@@ -0,0 +1,291 @@
+/*
+Package oci imports the config and the files of the container images of an OCI or docker save tarball
+in order to scan the images built without a Dockerfile at hand
+*/
+package oci
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dockerManifestFile = "manifest.json"
+	ociIndexFile       = "index.json"
+	// dockerfileName is the name of the Dockerfile generated from the config of an image
+	dockerfileName = "Dockerfile"
+	// filesystemFolder is the folder where the files of the layers of an image are extracted
+	filesystemFolder = "filesystem"
+	maxIndexDepth    = 5
+)
+
+var referenceRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// entry is the position of a file in the tarball
+type entry struct {
+	offset int64
+	size   int64
+}
+
+// tarball is an opened OCI or docker save tarball, with the position of its files
+type tarball struct {
+	file    *os.File
+	entries map[string]entry
+}
+
+// image is a container image of the tarball
+type image struct {
+	reference string
+	config    string
+	layers    []string
+}
+
+type dockerManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS string `json:"os"`
+	} `json:"platform"`
+}
+
+type ociManifest struct {
+	Manifests []descriptor `json:"manifests"`
+	Config    *descriptor  `json:"config"`
+	Layers    []descriptor `json:"layers"`
+}
+
+// Import imports the images of the tarball into the destination, the config of each image is saved as a
+// Dockerfile and the files of its layers KICS can scan are extracted in the filesystem folder
+func Import(ctx context.Context, tarballPath, destinationPath string) (string, error) {
+	log.Info().Msgf("importing container images from %s", tarballPath)
+
+	t, err := openTarball(tarballPath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := t.file.Close(); err != nil {
+			log.Err(err).Msgf("failed to close file: %s", tarballPath)
+		}
+	}()
+
+	images, err := t.images()
+	if err != nil {
+		return "", err
+	}
+	if len(images) == 0 {
+		return "", errors.Errorf("no container image found in %s", tarballPath)
+	}
+
+	for i := range images {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		destination := destinationPath
+		if len(images) > 1 {
+			destination = filepath.Join(destinationPath, images[i].folderName())
+		}
+		if err := t.importImage(&images[i], destination); err != nil {
+			return "", err
+		}
+	}
+
+	return destinationPath, nil
+}
+
+// openTarball opens the tarball and records the position of its files
+func openTarball(tarballPath string) (*tarball, error) {
+	file, err := os.Open(filepath.Clean(tarballPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open tarball")
+	}
+
+	t := &tarball{
+		file:    file,
+		entries: make(map[string]entry),
+	}
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, errors.Wrapf(err, "failed to read tarball %s", tarballPath)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// the reader does not buffer, the file is at the beginning of the content of the entry
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		t.entries[cleanName(header.Name)] = entry{offset: offset, size: header.Size}
+	}
+
+	return t, nil
+}
+
+// open returns a reader of the file of the tarball
+func (t *tarball) open(name string) (*io.SectionReader, error) {
+	e, ok := t.entries[cleanName(name)]
+	if !ok {
+		return nil, errors.Errorf("file %s not found in tarball", name)
+	}
+	return io.NewSectionReader(t.file, e.offset, e.size), nil
+}
+
+func (t *tarball) decode(name string, value interface{}) error {
+	reader, err := t.open(name)
+	if err != nil {
+		return err
+	}
+	if err := json.NewDecoder(reader).Decode(value); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", name)
+	}
+	return nil
+}
+
+// images returns the images of the tarball, from the manifest of docker save or from the OCI index
+func (t *tarball) images() ([]image, error) {
+	if _, ok := t.entries[dockerManifestFile]; ok {
+		manifests := make([]dockerManifest, 0)
+		if err := t.decode(dockerManifestFile, &manifests); err != nil {
+			return nil, err
+		}
+		images := make([]image, 0, len(manifests))
+		for _, manifest := range manifests {
+			img := image{config: manifest.Config, layers: manifest.Layers}
+			if len(manifest.RepoTags) > 0 {
+				img.reference = manifest.RepoTags[0]
+			}
+			images = append(images, img)
+		}
+		return images, nil
+	}
+
+	if _, ok := t.entries[ociIndexFile]; ok {
+		index := ociManifest{}
+		if err := t.decode(ociIndexFile, &index); err != nil {
+			return nil, err
+		}
+		return t.indexImages(index.Manifests, "", 0), nil
+	}
+
+	return nil, errors.New("tarball is neither an OCI image layout nor a docker save archive")
+}
+
+// indexImages returns the images of the manifests of an OCI index, the nested indexes are followed and the
+// manifests of other platforms whose blobs are not in the tarball are ignored
+func (t *tarball) indexImages(manifests []descriptor, reference string, depth int) []image {
+	images := make([]image, 0)
+	if depth > maxIndexDepth {
+		return images
+	}
+	for i := range manifests {
+		if manifests[i].Platform != nil && manifests[i].Platform.OS == "unknown" {
+			// attestation manifests
+			continue
+		}
+		name := blobName(manifests[i].Digest)
+		if _, ok := t.entries[name]; !ok {
+			continue
+		}
+		manifest := ociManifest{}
+		if err := t.decode(name, &manifest); err != nil {
+			log.Warn().Msgf("%s", err)
+			continue
+		}
+		ref := imageReference(manifests[i].Annotations, reference)
+		if manifest.Config == nil {
+			images = append(images, t.indexImages(manifest.Manifests, ref, depth+1)...)
+			continue
+		}
+		img := image{reference: ref, config: blobName(manifest.Config.Digest)}
+		for _, layer := range manifest.Layers {
+			img.layers = append(img.layers, blobName(layer.Digest))
+		}
+		images = append(images, img)
+	}
+	return images
+}
+
+// importImage saves the config of the image as a Dockerfile and extracts the files of its layers
+func (t *tarball) importImage(img *image, destination string) error {
+	config := imageConfig{}
+	if err := t.decode(img.config, &config); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(destination, os.ModePerm); err != nil {
+		return err
+	}
+	dockerfile := filepath.Join(destination, dockerfileName)
+	if err := os.WriteFile(dockerfile, []byte(config.dockerfile(img.name())), os.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to write %s", dockerfile)
+	}
+
+	files, err := t.extractLayers(img.layers, filepath.Join(destination, filesystemFolder))
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("KICS found %d file(s) to scan in the image %s", files, img.name())
+	return nil
+}
+
+// name returns the reference of the image, or the digest of its config when it is not tagged
+func (img *image) name() string {
+	if img.reference != "" {
+		return img.reference
+	}
+	return "sha256:" + strings.TrimSuffix(path.Base(img.config), ".json")
+}
+
+// folderName returns the name of the folder of the image, when the tarball has several images
+func (img *image) folderName() string {
+	return referenceRegex.ReplaceAllString(img.name(), "_")
+}
+
+// imageReference returns the name of the image from the annotations of its OCI descriptor
+func imageReference(annotations map[string]string, reference string) string {
+	if name, ok := annotations["io.containerd.image.name"]; ok {
+		return name
+	}
+	if name, ok := annotations["org.opencontainers.image.ref.name"]; ok {
+		if strings.ContainsAny(name, "/:") {
+			return name
+		}
+		return fmt.Sprintf("image:%s", name)
+	}
+	return reference
+}
+
+// blobName returns the name of the file of a blob in an OCI image layout, ex: blobs/sha256/<hex>
+func blobName(digest string) string {
+	return path.Join("blobs", strings.Replace(digest, ":", "/", 1))
+}
+
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
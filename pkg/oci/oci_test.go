@@ -0,0 +1,199 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{
+	"config": {
+		"User": "app",
+		"ExposedPorts": {"8080/tcp": {}},
+		"Env": ["PATH=/usr/local/bin:/usr/bin", "MODE=prod"],
+		"Cmd": ["/app/server"],
+		"Healthcheck": {"Test": ["CMD-SHELL", "curl -f http://localhost:8080/"], "Interval": 30000000000, "Retries": 3}
+	},
+	"history": [
+		{"created_by": "/bin/sh -c #(nop) ADD file:4b03b5f551e3fbdf47ec609712007327828f7530cc3455c43bbcdcaf449a75a9 in / "},
+		{"created_by": "RUN /bin/sh -c apt-get update && apt-get install -y curl # buildkit"},
+		{"created_by": "|1 VERSION=1.0 /bin/sh -c echo $VERSION > /version"},
+		{"created_by": "COPY server /app/server # buildkit"},
+		{"created_by": "USER app", "empty_layer": true}
+	]
+}`
+
+type testFile struct {
+	name    string
+	content []byte
+}
+
+func writeTar(t *testing.T, w *tar.Writer, files []testFile) {
+	for _, file := range files {
+		require.NoError(t, w.WriteHeader(&tar.Header{
+			Name:     file.name,
+			Mode:     0600,
+			Size:     int64(len(file.content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := w.Write(file.content)
+		require.NoError(t, err)
+	}
+}
+
+func layer(t *testing.T, compressed bool, files []testFile) []byte {
+	buffer := &bytes.Buffer{}
+	var gz *gzip.Writer
+	w := tar.NewWriter(buffer)
+	if compressed {
+		gz = gzip.NewWriter(buffer)
+		w = tar.NewWriter(gz)
+	}
+	writeTar(t, w, files)
+	require.NoError(t, w.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+	return buffer.Bytes()
+}
+
+func createTarball(t *testing.T, files []testFile) string {
+	tarballPath := filepath.Join(t.TempDir(), "image.tar")
+	file, err := os.Create(tarballPath)
+	require.NoError(t, err)
+	w := tar.NewWriter(file)
+	writeTar(t, w, files)
+	require.NoError(t, w.Close())
+	require.NoError(t, file.Close())
+	return tarballPath
+}
+
+func imageLayers(t *testing.T) (lower, upper []byte) {
+	lower = layer(t, true, []testFile{
+		{name: "etc/app/deployment.yaml", content: []byte("apiVersion: apps/v1\nkind: Deployment\n")},
+		{name: "etc/app/old.yaml", content: []byte("apiVersion: v1\nkind: Pod\n")},
+		{name: "usr/share/doc/package.json", content: []byte("{}")},
+		{name: "app/node_modules/lib/package.json", content: []byte("{}")},
+		{name: "etc/passwd", content: []byte("root:x:0:0:root:/root:/bin/sh\n")},
+	})
+	upper = layer(t, false, []testFile{
+		{name: "etc/app/.wh.old.yaml"},
+		{name: "manifests/../../main.tf", content: []byte("resource \"aws_s3_bucket\" \"b\" {}\n")},
+	})
+	return lower, upper
+}
+
+func TestImport_DockerSave(t *testing.T) {
+	lower, upper := imageLayers(t)
+	manifest, err := json.Marshal([]dockerManifest{{
+		Config:   "4f3a.json",
+		RepoTags: []string{"registry.example.com/app:1.0"},
+		Layers:   []string{"a1/layer.tar", "b2/layer.tar"},
+	}})
+	require.NoError(t, err)
+	tarballPath := createTarball(t, []testFile{
+		{name: "4f3a.json", content: []byte(testConfig)},
+		{name: "a1/layer.tar", content: lower},
+		{name: "b2/layer.tar", content: upper},
+		{name: "manifest.json", content: manifest},
+	})
+
+	destination := filepath.Join(t.TempDir(), "kics-extract-oci")
+	imported, err := Import(context.Background(), tarballPath, destination)
+	require.NoError(t, err)
+	require.Equal(t, destination, imported)
+
+	dockerfile, err := os.ReadFile(filepath.Join(destination, dockerfileName))
+	require.NoError(t, err)
+	require.Equal(t, `# generated by KICS from the config of the image registry.example.com/app:1.0
+FROM registry.example.com/app:1.0
+RUN apt-get update && apt-get install -y curl
+RUN echo $VERSION > /version
+ENV PATH="/usr/local/bin:/usr/bin"
+ENV MODE="prod"
+EXPOSE 8080
+USER app
+HEALTHCHECK --interval=30s --retries=3 CMD curl -f http://localhost:8080/
+CMD ["/app/server"]
+`, string(dockerfile))
+
+	filesystem := filepath.Join(destination, filesystemFolder)
+	require.FileExists(t, filepath.Join(filesystem, "etc", "app", "deployment.yaml"))
+	require.FileExists(t, filepath.Join(filesystem, "main.tf"))
+	require.NoFileExists(t, filepath.Join(filesystem, "etc", "app", "old.yaml"))
+	require.NoFileExists(t, filepath.Join(filesystem, "etc", "passwd"))
+	require.NoDirExists(t, filepath.Join(filesystem, "usr"))
+	require.NoDirExists(t, filepath.Join(filesystem, "app"))
+}
+
+func TestImport_OCILayout(t *testing.T) {
+	lower, upper := imageLayers(t)
+	manifest := []byte(`{
+		"config": {"digest": "sha256:c0"},
+		"layers": [{"digest": "sha256:l1"}, {"digest": "sha256:l2"}]
+	}`)
+	index := []byte(`{
+		"manifests": [
+			{"digest": "sha256:m1", "annotations": {"io.containerd.image.name": "docker.io/library/app:2.0"}},
+			{"digest": "sha256:m2", "platform": {"os": "unknown"}},
+			{"digest": "sha256:m3"}
+		]
+	}`)
+	tarballPath := createTarball(t, []testFile{
+		{name: "oci-layout", content: []byte(`{"imageLayoutVersion": "1.0.0"}`)},
+		{name: "index.json", content: index},
+		{name: "blobs/sha256/m1", content: manifest},
+		{name: "blobs/sha256/m2", content: manifest},
+		{name: "blobs/sha256/c0", content: []byte(testConfig)},
+		{name: "blobs/sha256/l1", content: lower},
+		{name: "blobs/sha256/l2", content: upper},
+	})
+
+	destination := filepath.Join(t.TempDir(), "kics-extract-oci")
+	_, err := Import(context.Background(), tarballPath, destination)
+	require.NoError(t, err)
+
+	dockerfile, err := os.ReadFile(filepath.Join(destination, dockerfileName))
+	require.NoError(t, err)
+	require.Contains(t, string(dockerfile), "FROM docker.io/library/app:2.0\n")
+	require.FileExists(t, filepath.Join(destination, filesystemFolder, "etc", "app", "deployment.yaml"))
+}
+
+func TestImport_InvalidTarball(t *testing.T) {
+	tarballPath := createTarball(t, []testFile{{name: "main.tf", content: []byte("")}})
+	_, err := Import(context.Background(), tarballPath, t.TempDir())
+	require.Error(t, err)
+
+	_, err = Import(context.Background(), filepath.Join(t.TempDir(), "missing.tar"), t.TempDir())
+	require.Error(t, err)
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		createdBy string
+		want      string
+		ok        bool
+	}{
+		{name: "legacy", createdBy: "/bin/sh -c apk add curl", want: "apk add curl", ok: true},
+		{name: "legacy nop", createdBy: "/bin/sh -c #(nop)  USER app", ok: false},
+		{name: "buildkit", createdBy: "RUN /bin/sh -c apk add curl # buildkit", want: "apk add curl", ok: true},
+		{name: "buildkit copy", createdBy: "COPY . /app # buildkit", ok: false},
+		{name: "build arguments", createdBy: "|2 A=1 B=2 /bin/sh -c make", want: "make", ok: true},
+		{name: "buildkit instruction", createdBy: "USER app", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := runCommand(tt.createdBy)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,555 @@
+package ansible
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	linesKey     = "_kics_lines"
+	linesPrefix  = "_kics_"
+	defaultLines = "_kics__default"
+	lineKey      = "_kics_line"
+	arrayKey     = "_kics_arr"
+	playbooksKey = "playbooks"
+	// defaultMaxDepth is the maximum depth of the files included when no maximum resolver depth is set
+	defaultMaxDepth = 15
+)
+
+var (
+	// includeTasksKeys are the keywords of the tasks that include or import the tasks of a file
+	includeTasksKeys = []string{"include_tasks", "import_tasks", "include",
+		"ansible.builtin.include_tasks", "ansible.builtin.import_tasks", "ansible.builtin.include"}
+	// includeRoleKeys are the keywords of the tasks that include or import a role
+	includeRoleKeys = []string{"include_role", "import_role", "ansible.builtin.include_role", "ansible.builtin.import_role"}
+	// includeVarsKeys are the keywords of the tasks that load the variables of a file
+	includeVarsKeys = []string{"include_vars", "ansible.builtin.include_vars"}
+	// importPlaybookKeys are the keywords of the plays that import the plays of another playbook
+	importPlaybookKeys = []string{"import_playbook", "ansible.builtin.import_playbook"}
+	// taskListKeys are the keywords of a play and of a block that hold a list of tasks
+	taskListKeys = []string{"pre_tasks", "tasks", "post_tasks", "handlers", "block", "rescue", "always"}
+	// becomeKeys are the privilege escalation keywords of a play inherited by its tasks when it enables become
+	becomeKeys = []string{"become", "become_user", "become_method", "become_flags"}
+	// unrenderedKeys are the keywords of a task whose value is kept as written, the name of the tasks identifies
+	// them in the results and the conditions are Jinja2 expressions without delimiters
+	unrenderedKeys = []string{"name", "vars", "when", "changed_when", "failed_when", "until", "tags", linesKey}
+	// roleKeys are the keywords of a role entry of a play that are not role parameters
+	roleKeys = []string{"role", "name", "vars", "when", "tags", "become", "become_user", "become_method", "become_flags",
+		"delegate_to", "tasks_from", "vars_from", "defaults_from", "handlers_from", "apply", "public", "allow_duplicates",
+		"rolespec_validate", linesKey}
+)
+
+// Resolver resolves the roles, includes and imports of Ansible playbooks and renders the variables of their
+// tasks, so tasks are analyzed with the variables and privilege escalation of the play running them. The roles,
+// included files and variables files are read from its FileSystem, which does not read the files outside the
+// scanned paths
+type Resolver struct {
+	maxDepth   int
+	files      map[string]bool
+	patterns   []string
+	fileSystem filesystem.FileSystem
+}
+
+// scope is the context of the tasks of a file, which are run by a play or by a role
+type scope struct {
+	// dir is the directory the files included by the tasks are relative to
+	dir string
+	// roleDir is the directory of the role running the tasks, if any
+	roleDir string
+	// playDir is the directory of the playbook, where the roles are looked for
+	playDir string
+	vars    *variables
+	become  map[string]interface{}
+	// stack are the files being resolved, which are not included again
+	stack []string
+}
+
+// NewResolver creates a Resolver following the includes up to maxDepth files deep
+func NewResolver(maxDepth int) *Resolver {
+	return NewResolverWithFileSystem(maxDepth, nil)
+}
+
+// NewResolverWithFileSystem creates a Resolver following the includes up to maxDepth files deep, reading them
+// from the given FileSystem
+func NewResolverWithFileSystem(maxDepth int, fileSystem filesystem.FileSystem) *Resolver {
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	return &Resolver{
+		maxDepth:   maxDepth,
+		files:      make(map[string]bool),
+		patterns:   make([]string, 0),
+		fileSystem: filesystem.Get(fileSystem),
+	}
+}
+
+// IsAnsible returns true if the document is an Ansible playbook, which is a list of plays, or the tasks of a role
+func IsAnsible(document map[string]interface{}, path string) bool {
+	plays, ok := document[playbooksKey].([]interface{})
+	if !ok {
+		return false
+	}
+	if roleDirOf(path) != "" {
+		return true
+	}
+	for _, play := range plays {
+		if isPlay(play) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve replaces the roles of the plays of a playbook, its included tasks and its imported playbooks by their
+// tasks and plays, and renders the variables of the tasks with the variables in their scope, which are the role
+// defaults, the group_vars and host_vars next to the playbook, the play vars and vars_files, the role vars and
+// the variables of the includes, blocks and tasks. The tasks also inherit the privilege escalation of their play.
+// The tasks of a role are rendered with the defaults and vars of the role when the document is one of its task
+// files. The tasks and plays loaded from other files are placed at the line of the role or include
+func (r *Resolver) Resolve(document model.Document, path string) model.Document {
+	// handle panic during resolve process
+	defer func() {
+		if err := recover(); err != nil {
+			log.Warn().Msgf("Recovered from panic during resolve of Ansible file %s: %v", path, err)
+		}
+	}()
+
+	plays, ok := document[playbooksKey].([]interface{})
+	if !ok {
+		return document
+	}
+	lines := getMap(getMap(document[linesKey])[defaultLines])
+	arr, _ := lines[arrayKey].([]interface{})
+
+	var resolved, resolvedLines []interface{}
+	if roleDir := roleDirOf(path); roleDir != "" {
+		s := &scope{
+			dir:     filepath.Dir(path),
+			roleDir: roleDir,
+			playDir: filepath.Dir(filepath.Dir(roleDir)),
+			vars:    newVariables(r.roleVars(roleDir)...),
+			become:  map[string]interface{}{},
+			stack:   []string{path},
+		}
+		resolved, resolvedLines = r.resolveTasks(plays, arr, s)
+	} else {
+		resolved, resolvedLines = r.resolvePlays(plays, arr, path, []string{path})
+	}
+
+	document[playbooksKey] = resolved
+	setArray(lines, resolvedLines)
+	return document
+}
+
+// LinkedFiles returns the files an Ansible playbook or role task file depends on, which are the files of the
+// roles, includes, imports and variables resolved, as patterns since they may not exist yet
+func LinkedFiles(path string, maxDepth int) []string {
+	document, err := loadDocument(filesystem.Get(nil), path)
+	if err != nil || !IsAnsible(document, path) {
+		return []string{}
+	}
+	r := NewResolver(maxDepth)
+	r.Resolve(document, path)
+	for _, pattern := range r.patterns {
+		r.files[pattern] = true
+	}
+	delete(r.files, path)
+	files := make([]string, 0, len(r.files))
+	for file := range r.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// resolvePlays resolves the plays of a playbook, the imported playbooks are replaced by their plays
+func (r *Resolver) resolvePlays(plays, lines []interface{}, path string, stack []string) (resolved, resolvedLines []interface{}) {
+	resolved = make([]interface{}, 0, len(plays))
+	resolvedLines = make([]interface{}, 0, len(plays))
+	for i, element := range plays {
+		play, ok := element.(map[string]interface{})
+		playLines := lineAt(lines, i)
+		if !ok {
+			resolved = append(resolved, element)
+			resolvedLines = append(resolvedLines, playLines)
+			continue
+		}
+		if file, ok := firstString(play, importPlaybookKeys); ok {
+			importPath := filepath.Join(filepath.Dir(path), filepath.FromSlash(file))
+			if imported, ok := r.loadList(importPath, stack); ok {
+				importedPlays, _ := r.resolvePlays(imported, nil, importPath, append(stack, importPath))
+				resolved, resolvedLines = appendGenerated(resolved, resolvedLines, importedPlays, lineOf(playLines))
+				continue
+			}
+		}
+		r.resolvePlay(play, playLines, path, stack)
+		resolved = append(resolved, play)
+		resolvedLines = append(resolvedLines, playLines)
+	}
+	return resolved, resolvedLines
+}
+
+// resolvePlay resolves the roles and tasks of a play, the tasks of the roles are placed before the tasks of the
+// play, as they are run
+func (r *Resolver) resolvePlay(play, lines map[string]interface{}, path string, stack []string) {
+	dir := filepath.Dir(path)
+	vars := r.hostsVars(dir, play["hosts"])
+	mergeVars(vars, getVars(play["vars"]))
+	for _, file := range toList(play["vars_files"]) {
+		if name, ok := file.(string); ok {
+			mergeVars(vars, r.loadVars(filepath.Join(dir, filepath.FromSlash(name))))
+		}
+	}
+	s := &scope{
+		dir:     dir,
+		playDir: dir,
+		vars:    newVariables(vars),
+		become:  escalation(play),
+		stack:   stack,
+	}
+
+	roleTasks, roleLines := make([]interface{}, 0), make([]interface{}, 0)
+	rolesLines := getMap(lines[linesPrefix+"roles"])
+	rolesArr, _ := rolesLines[arrayKey].([]interface{})
+	for i, role := range toList(play["roles"]) {
+		line := lineOf(lineAt(rolesArr, i))
+		if line == 0 {
+			line = lineOf(rolesLines)
+		}
+		tasks := r.roleEntryTasks(role, s)
+		roleTasks, roleLines = appendGenerated(roleTasks, roleLines, tasks, line)
+	}
+
+	for _, key := range taskListKeys[:4] {
+		tasks, ok := play[key].([]interface{})
+		if !ok {
+			continue
+		}
+		keyLines := getMap(lines[linesPrefix+key])
+		arr, _ := keyLines[arrayKey].([]interface{})
+		resolved, resolvedLines := r.resolveTasks(tasks, arr, s)
+		play[key] = resolved
+		setArray(keyLines, resolvedLines)
+	}
+
+	if len(roleTasks) == 0 {
+		return
+	}
+	tasks, _ := play["tasks"].([]interface{})
+	keyLines, ok := lines[linesPrefix+"tasks"].(map[string]interface{})
+	if !ok {
+		keyLines = map[string]interface{}{lineKey: lineOf(rolesLines)}
+		if lines != nil {
+			lines[linesPrefix+"tasks"] = keyLines
+		}
+	}
+	arr, _ := keyLines[arrayKey].([]interface{})
+	play["tasks"] = append(roleTasks, tasks...)
+	setArray(keyLines, append(roleLines, arr...))
+}
+
+// resolveTasks resolves a list of tasks, the included tasks and roles are replaced by their tasks, the variables
+// included are in the scope of the following tasks and the other tasks are rendered
+func (r *Resolver) resolveTasks(tasks, lines []interface{}, s *scope) (resolved, resolvedLines []interface{}) {
+	resolved = make([]interface{}, 0, len(tasks))
+	resolvedLines = make([]interface{}, 0, len(tasks))
+	for i, element := range tasks {
+		task, ok := element.(map[string]interface{})
+		taskLines := lineAt(lines, i)
+		if !ok {
+			resolved = append(resolved, element)
+			resolvedLines = append(resolvedLines, taskLines)
+			continue
+		}
+		if included, ok := r.includedTasks(task, s); ok {
+			resolved, resolvedLines = appendGenerated(resolved, resolvedLines, included, lineOf(taskLines))
+			continue
+		}
+		if file, ok := includeFile(task, includeVarsKeys); ok {
+			s = s.withVars(r.includedVars(file, s))
+		}
+		r.resolveTask(task, taskLines, s)
+		resolved = append(resolved, task)
+		resolvedLines = append(resolvedLines, taskLines)
+	}
+	return resolved, resolvedLines
+}
+
+// resolveTask renders a task with the variables in its scope, the tasks of its blocks are also resolved
+func (r *Resolver) resolveTask(task, lines map[string]interface{}, s *scope) {
+	s = s.withVars(getVars(task["vars"]))
+	for _, key := range taskListKeys[4:] {
+		tasks, ok := task[key].([]interface{})
+		if !ok {
+			continue
+		}
+		keyLines := getMap(lines[linesPrefix+key])
+		arr, _ := keyLines[arrayKey].([]interface{})
+		resolved, resolvedLines := r.resolveTasks(tasks, arr, s)
+		task[key] = resolved
+		setArray(keyLines, resolvedLines)
+	}
+	for key, value := range task {
+		if utils.Contains(key, unrenderedKeys) || utils.Contains(key, taskListKeys) {
+			continue
+		}
+		task[key], _ = s.vars.render(value)
+	}
+	for key, value := range s.become {
+		if _, ok := task[key]; !ok {
+			task[key] = value
+		}
+	}
+}
+
+// includedTasks returns the tasks of the file or role included by a task, with the variables of the task
+func (r *Resolver) includedTasks(task map[string]interface{}, s *scope) ([]interface{}, bool) {
+	if role, ok := task[firstKey(task, includeRoleKeys)].(map[string]interface{}); ok {
+		entry := map[string]interface{}{}
+		mergeVars(entry, role)
+		entry["vars"] = task["vars"]
+		tasks := r.roleTasks(entry, s)
+		return tasks, tasks != nil
+	}
+	file, ok := includeFile(task, includeTasksKeys)
+	if !ok {
+		return nil, false
+	}
+	path := filepath.Join(s.dir, filepath.FromSlash(file))
+	tasks, ok := r.loadList(path, s.stack)
+	if !ok {
+		return nil, false
+	}
+	child := s.withVars(getVars(task["vars"]))
+	child.stack = append(child.stack, path)
+	included, _ := r.resolveTasks(tasks, nil, child)
+	return included, true
+}
+
+// includedVars loads the variables of a file included by include_vars, which is relative to the vars
+// directory of the role or to the directory of the tasks
+func (r *Resolver) includedVars(file string, s *scope) map[string]interface{} {
+	rendered, known := s.vars.render(file)
+	name, ok := rendered.(string)
+	if !known || !ok {
+		return map[string]interface{}{}
+	}
+	candidates := []string{filepath.Join(s.dir, "vars", name), filepath.Join(s.dir, name)}
+	if s.roleDir != "" {
+		candidates = append([]string{filepath.Join(s.roleDir, "vars", name)}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if info, err := r.fileSystem.Stat(candidate); err == nil && !info.IsDir() && !utils.Contains(candidate, s.stack) {
+			return r.loadVars(candidate)
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// withVars returns the scope of nested tasks with their variables
+func (s *scope) withVars(vars map[string]interface{}) *scope {
+	child := *s
+	child.vars = s.vars.with(vars)
+	child.stack = append([]string{}, s.stack...)
+	return &child
+}
+
+// escalation returns the privilege escalation keywords of a play or role inherited by its tasks, which are
+// only inherited when become is enabled, since a become_user alone does not change the user running the tasks
+func escalation(keywords map[string]interface{}) map[string]interface{} {
+	become := make(map[string]interface{})
+	if enabled, _ := toBool(keywords["become"]); enabled != true {
+		return become
+	}
+	for _, key := range becomeKeys {
+		if value, ok := keywords[key]; ok {
+			become[key] = value
+		}
+	}
+	return become
+}
+
+// isPlay returns true if the element of a playbook is a play or the import of a playbook
+func isPlay(element interface{}) bool {
+	play, ok := element.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok := play["hosts"]; ok {
+		return true
+	}
+	_, ok = firstString(play, importPlaybookKeys)
+	return ok
+}
+
+// includeFile returns the file of an include task, which is its value or its file argument
+func includeFile(task map[string]interface{}, keys []string) (string, bool) {
+	switch value := task[firstKey(task, keys)].(type) {
+	case string:
+		return value, value != ""
+	case map[string]interface{}:
+		file, ok := value["file"].(string)
+		return file, ok && file != ""
+	default:
+		return "", false
+	}
+}
+
+// firstKey returns the first of the keys present in a map
+func firstKey(m map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if _, ok := m[key]; ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// firstString returns the value of the first of the keys present in a map when it is a string
+func firstString(m map[string]interface{}, keys []string) (string, bool) {
+	value, ok := m[firstKey(m, keys)].(string)
+	return value, ok && value != ""
+}
+
+// loadList loads the list of tasks or plays of a file, the files being resolved and the files beyond the
+// maximum depth are not loaded
+func (r *Resolver) loadList(path string, stack []string) ([]interface{}, bool) {
+	r.files[path] = true
+	if utils.Contains(path, stack) || len(stack) >= r.maxDepth {
+		return nil, false
+	}
+	document, err := loadDocument(r.fileSystem, path)
+	if err != nil {
+		return nil, false
+	}
+	list, ok := document[playbooksKey].([]interface{})
+	return list, ok
+}
+
+// loadDocument loads the first YAML document of a file of fileSystem
+func loadDocument(fileSystem filesystem.FileSystem, path string) (model.Document, error) {
+	content, err := fileSystem.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	document := model.Document{}
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// appendGenerated appends values loaded from another file, along with their line information, which is the line
+// of the role or include loading them
+func appendGenerated(values, lines, loaded []interface{}, line int) (resultValues, resultLines []interface{}) {
+	for _, value := range loaded {
+		elementLines := map[string]interface{}{defaultLines: map[string]interface{}{lineKey: line}}
+		if m, ok := value.(map[string]interface{}); ok {
+			elementLines = generatedLines(m, line)
+		}
+		values = append(values, value)
+		lines = append(lines, elementLines)
+	}
+	return values, lines
+}
+
+// generatedLines returns the line information of a map loaded from another file, whose keys are all at the
+// line given, the maps it contains get their line information
+func generatedLines(value map[string]interface{}, line int) map[string]interface{} {
+	lines := map[string]interface{}{
+		defaultLines: map[string]interface{}{lineKey: line},
+	}
+	for key, child := range value {
+		if key == linesKey {
+			continue
+		}
+		arr := make([]interface{}, 0)
+		if list, ok := child.([]interface{}); ok {
+			_, arr = appendGenerated(nil, nil, list, line)
+		}
+		lines[linesPrefix+key] = map[string]interface{}{lineKey: line, arrayKey: arr}
+		if m, ok := child.(map[string]interface{}); ok {
+			m[linesKey] = generatedLines(m, line)
+		}
+	}
+	return lines
+}
+
+// lineAt returns the line information of the element of a list
+func lineAt(lines []interface{}, index int) map[string]interface{} {
+	if index < len(lines) {
+		if m, ok := lines[index].(map[string]interface{}); ok {
+			return m
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// lineOf returns the line of an element from its line information
+func lineOf(lines map[string]interface{}) int {
+	if line, ok := lines[lineKey].(float64); ok {
+		return int(line)
+	}
+	if line, ok := lines[lineKey].(int); ok {
+		return line
+	}
+	if defaults, ok := lines[defaultLines].(map[string]interface{}); ok {
+		return lineOf(defaults)
+	}
+	return 0
+}
+
+// getMap returns value as a map, or nil if it is not a map
+func getMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	if m, ok := value.(model.Document); ok {
+		return m
+	}
+	return nil
+}
+
+// toList returns value as a list, or an empty list if it is not a list
+func toList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{}
+}
+
+// withoutLines returns a deep copy of a value without its line information
+func withoutLines(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if key != linesKey {
+				result[key] = withoutLines(child)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			result = append(result, withoutLines(element))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// setArray sets the line information of the elements of a list, when the line information of the list exists
+func setArray(lines map[string]interface{}, arr []interface{}) {
+	if lines != nil {
+		lines[arrayKey] = arr
+	}
+}
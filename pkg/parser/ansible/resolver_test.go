@@ -0,0 +1,190 @@
+package ansible
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var fixtureDir = filepath.Join("..", "..", "..", "test", "fixtures", "test_ansible_roles")
+
+func TestResolve(t *testing.T) {
+	path := filepath.Join(fixtureDir, "site.yml")
+	document, err := loadDocument(&filesystem.OS{}, path)
+	require.NoError(t, err)
+
+	resolved := withoutLines(map[string]interface{}(NewResolver(0).Resolve(document, path))).(map[string]interface{})
+	plays := resolved["playbooks"].([]interface{})
+	require.Len(t, plays, 2)
+
+	tasks := plays[0].(map[string]interface{})["tasks"].([]interface{})
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"name":   "Create data bucket",
+			"become": true,
+			"amazon.aws.s3_bucket": map[string]interface{}{
+				"name":       "prod-data",
+				"versioning": false,
+				"acl":        "private",
+			},
+		},
+		map[string]interface{}{
+			"name":   "Create logs bucket",
+			"become": true,
+			"amazon.aws.s3_bucket": map[string]interface{}{
+				"name":       "prod-logs",
+				"versioning": true,
+			},
+		},
+		map[string]interface{}{
+			"name":   "Create backup bucket",
+			"become": true,
+			"amazon.aws.s3_bucket": map[string]interface{}{
+				"name":          "prod-backups",
+				"encryption":    "{{ 'AES256' if backup_encrypt else 'none' }}",
+				"public_access": map[string]interface{}{"block_public_acls": false},
+			},
+		},
+	}, tasks)
+
+	monitoring := plays[1].(map[string]interface{})
+	require.Equal(t, "Monitoring", monitoring["name"])
+	topic := monitoring["tasks"].([]interface{})[0].(map[string]interface{})["community.aws.sns_topic"]
+	require.Equal(t, map[string]interface{}{"name": "prod-alarms"}, topic)
+}
+
+func TestResolve_Lines(t *testing.T) {
+	path := filepath.Join(fixtureDir, "site.yml")
+	document, err := loadDocument(&filesystem.OS{}, path)
+	require.NoError(t, err)
+	document = NewResolver(0).Resolve(document, path)
+
+	playsLines := getMap(getMap(document[linesKey])[defaultLines])[arrayKey].([]interface{})
+	require.Len(t, playsLines, 2)
+	require.Equal(t, 19, lineOf(playsLines[1].(map[string]interface{})))
+
+	tasksLines := getMap(playsLines[0].(map[string]interface{})[linesPrefix+"tasks"])[arrayKey].([]interface{})
+	require.Len(t, tasksLines, 3)
+	// the role tasks are at the line of the role and the included tasks at the line of the include
+	require.Equal(t, 7, lineOf(tasksLines[0].(map[string]interface{})))
+	require.Equal(t, 10, lineOf(tasksLines[1].(map[string]interface{})))
+	require.Equal(t, 14, lineOf(tasksLines[2].(map[string]interface{})))
+}
+
+func TestResolve_RoleTasks(t *testing.T) {
+	path := filepath.Join(fixtureDir, "roles", "storage", "tasks", "main.yml")
+	document, err := loadDocument(&filesystem.OS{}, path)
+	require.NoError(t, err)
+	require.True(t, IsAnsible(document, path))
+
+	resolved := withoutLines(map[string]interface{}(NewResolver(0).Resolve(document, path))).(map[string]interface{})
+	task := resolved["playbooks"].([]interface{})[0].(map[string]interface{})
+	// the role variables are rendered, the variables depending on the playbook are kept
+	require.Equal(t, map[string]interface{}{
+		"name":       "{{ bucket_name }}",
+		"versioning": true,
+		"acl":        "public-read",
+	}, task["amazon.aws.s3_bucket"])
+}
+
+func TestIsAnsible(t *testing.T) {
+	tests := []struct {
+		name     string
+		document model.Document
+		path     string
+		want     bool
+	}{
+		{
+			name:     "playbook",
+			document: model.Document{"playbooks": []interface{}{map[string]interface{}{"hosts": "all"}}},
+			path:     "site.yml",
+			want:     true,
+		},
+		{
+			name:     "role tasks",
+			document: model.Document{"playbooks": []interface{}{map[string]interface{}{"name": "task"}}},
+			path:     filepath.Join("roles", "common", "tasks", "main.yml"),
+			want:     true,
+		},
+		{
+			name:     "tasks file outside a role",
+			document: model.Document{"playbooks": []interface{}{map[string]interface{}{"name": "task"}}},
+			path:     filepath.Join("tasks", "main.yml"),
+			want:     false,
+		},
+		{
+			name:     "not a list",
+			document: model.Document{"hosts": "all"},
+			path:     "site.yml",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsAnsible(tt.document, tt.path))
+		})
+	}
+}
+
+func TestLinkedFiles(t *testing.T) {
+	files := LinkedFiles(filepath.Join(fixtureDir, "site.yml"), 0)
+	require.Contains(t, files, filepath.Join(fixtureDir, "monitoring.yml"))
+	require.Contains(t, files, filepath.Join(fixtureDir, "tasks", "backup.yml"))
+	require.Contains(t, files, filepath.Join(fixtureDir, "roles", "storage", "tasks", "main.yml"))
+	require.Contains(t, files, filepath.Join(fixtureDir, "roles", "storage", "defaults", "main*"))
+	require.Contains(t, files, filepath.Join(fixtureDir, "group_vars", "production*"))
+	require.NotContains(t, files, filepath.Join(fixtureDir, "site.yml"))
+}
+
+func TestResolve_OutsideRoots(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		filepath.Join("outside", "vars.yml"):  "bucket_name: outside\n",
+		filepath.Join("outside", "tasks.yml"): "- name: Create outside bucket\n  amazon.aws.s3_bucket:\n    name: outside\n",
+		filepath.Join("project", "site.yml"): "- hosts: all\n  tasks:\n    - include_vars: ../outside/vars.yml\n" +
+			"    - name: Create bucket\n      amazon.aws.s3_bucket:\n        name: \"{{ bucket_name }}\"\n" +
+			"    - include_tasks: ../outside/tasks.yml\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	path := filepath.Join(dir, "project", "site.yml")
+
+	tests := []struct {
+		name       string
+		fileSystem filesystem.FileSystem
+		want       []interface{}
+	}{
+		{
+			name:       "unrestricted",
+			fileSystem: &filesystem.OS{},
+			want:       []interface{}{"outside", "outside"},
+		},
+		{
+			name:       "outside the roots",
+			fileSystem: filesystem.NewOS([]string{filepath.Join(dir, "project")}),
+			want:       []interface{}{"{{ bucket_name }}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := loadDocument(tt.fileSystem, path)
+			require.NoError(t, err)
+			resolver := NewResolverWithFileSystem(0, tt.fileSystem)
+			resolved := withoutLines(map[string]interface{}(resolver.Resolve(document, path))).(map[string]interface{})
+
+			buckets := make([]interface{}, 0)
+			for _, task := range resolved["playbooks"].([]interface{})[0].(map[string]interface{})["tasks"].([]interface{}) {
+				if bucket, ok := task.(map[string]interface{})["amazon.aws.s3_bucket"]; ok {
+					buckets = append(buckets, bucket.(map[string]interface{})["name"])
+				}
+			}
+			require.Equal(t, tt.want, buckets)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package ansible
+
+import (
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+)
+
+// roleDirOf returns the directory of the role of a tasks or handlers file of the standard role layout,
+// ex: roles/common for roles/common/tasks/main.yml, or an empty string if the file is not part of a role
+func roleDirOf(path string) string {
+	dir := filepath.Dir(path)
+	if base := filepath.Base(dir); base != "tasks" && base != "handlers" {
+		return ""
+	}
+	roleDir := filepath.Dir(dir)
+	if filepath.Base(filepath.Dir(roleDir)) != "roles" {
+		return ""
+	}
+	return roleDir
+}
+
+// roleEntryTasks returns the tasks of a role of a play, which is its name or a map with its name, variables and
+// parameters
+func (r *Resolver) roleEntryTasks(role interface{}, s *scope) []interface{} {
+	switch entry := role.(type) {
+	case string:
+		return r.roleTasks(map[string]interface{}{"role": entry}, s)
+	case map[string]interface{}:
+		return r.roleTasks(entry, s)
+	default:
+		return []interface{}{}
+	}
+}
+
+// roleTasks returns the tasks of a role, preceded by the tasks of its dependencies, rendered with the defaults
+// of the role, the variables of the scope running it, the vars of the role and the variables and parameters
+// of the role entry, in increasing precedence
+func (r *Resolver) roleTasks(entry map[string]interface{}, s *scope) []interface{} {
+	name, ok := firstString(entry, []string{"role", "name"})
+	if !ok {
+		return nil
+	}
+	if rendered, known := s.vars.render(name); known {
+		name, _ = rendered.(string)
+	}
+	dir := r.findRole(name, s)
+	if dir == "" || len(s.stack) >= r.maxDepth {
+		return nil
+	}
+
+	params := make(map[string]interface{})
+	for key, value := range entry {
+		if !utils.Contains(key, roleKeys) {
+			params[key] = withoutLines(value)
+		}
+	}
+	scopes := append([]map[string]interface{}{}, r.roleVars(dir)...)
+	child := &scope{
+		dir:     filepath.Join(dir, "tasks"),
+		roleDir: dir,
+		playDir: s.playDir,
+		vars:    newVariables(scopes[0], s.vars.values, scopes[1], getVars(entry["vars"]), params),
+		become:  make(map[string]interface{}),
+		stack:   append([]string{}, s.stack...),
+	}
+	mergeVars(child.become, s.become)
+	mergeVars(child.become, escalation(entry))
+
+	tasks := make([]interface{}, 0)
+	meta := r.loadVars(filepath.Join(dir, "meta", "main.yml"))
+	for _, dependency := range toList(meta["dependencies"]) {
+		tasks = append(tasks, r.roleEntryTasks(dependency, s.withStack(dir))...)
+	}
+
+	tasksFrom, ok := entry["tasks_from"].(string)
+	if !ok {
+		tasksFrom = "main"
+	}
+	for _, extension := range []string{".yml", ".yaml", ""} {
+		path := filepath.Join(dir, "tasks", filepath.FromSlash(tasksFrom)+extension)
+		if _, err := r.fileSystem.Stat(path); err != nil {
+			r.files[path] = true
+			continue
+		}
+		if list, ok := r.loadList(path, child.stack); ok {
+			child.stack = append(child.stack, path)
+			resolved, _ := r.resolveTasks(list, nil, child)
+			tasks = append(tasks, resolved...)
+		}
+		break
+	}
+	return tasks
+}
+
+// roleVars returns the variables of the defaults and vars directories of a role
+func (r *Resolver) roleVars(dir string) []map[string]interface{} {
+	return []map[string]interface{}{
+		r.loadVarsFiles(filepath.Join(dir, "defaults"), "main"),
+		r.loadVarsFiles(filepath.Join(dir, "vars"), "main"),
+	}
+}
+
+// findRole returns the directory of a role, which is looked for next to the role running it, in the roles
+// directory next to the playbook and next to the playbook, or an empty string if it is not found
+func (r *Resolver) findRole(name string, s *scope) string {
+	candidates := make([]string, 0, 3)
+	if s.roleDir != "" {
+		candidates = append(candidates, filepath.Join(filepath.Dir(s.roleDir), name))
+	}
+	candidates = append(candidates, filepath.Join(s.playDir, "roles", name), filepath.Join(s.playDir, name))
+	for _, candidate := range candidates {
+		if info, err := r.fileSystem.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		r.patterns = append(r.patterns, filepath.Join(candidate, "*", "*"))
+	}
+	return ""
+}
+
+// withStack returns the scope with a role being resolved, so the roles depending on each other are not resolved
+// more than once
+func (s *scope) withStack(dir string) *scope {
+	child := *s
+	child.stack = append(append([]string{}, s.stack...), dir)
+	return &child
+}
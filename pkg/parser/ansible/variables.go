@@ -0,0 +1,363 @@
+package ansible
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// templateRegex matches the Jinja2 expressions of a string, ex: {{ bucket_name }}
+	templateRegex = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+	// pathRegex matches a variable reference with its attribute and element accesses, ex: bucket.tags['owner']
+	pathRegex = regexp.MustCompile(`^([A-Za-z_]\w*)((?:\.\w+|\[\s*(?:\d+|'[^']*'|"[^"]*")\s*\])*)$`)
+	// accessRegex matches each attribute or element access of a variable reference
+	accessRegex = regexp.MustCompile(`\.(\w+)|\[\s*(\d+)\s*\]|\[\s*'([^']*)'\s*\]|\[\s*"([^"]*)"\s*\]`)
+	// defaultRegex matches the default filter and its argument, ex: default('private')
+	defaultRegex = regexp.MustCompile(`^(?:default|d)\s*\((.*)\)$`)
+)
+
+// varsExtensions are the extensions of the variables files, variables files without extension are also loaded
+var varsExtensions = []string{".yml", ".yaml", ".json", ""}
+
+// variables are the variables in scope of a task, their values are rendered when they are referenced
+type variables struct {
+	values     map[string]interface{}
+	rendered   map[string]interface{}
+	evaluating map[string]bool
+}
+
+// newVariables creates the variables of a scope, the variables of the later scopes take precedence
+func newVariables(scopes ...map[string]interface{}) *variables {
+	v := &variables{
+		values:     make(map[string]interface{}),
+		rendered:   make(map[string]interface{}),
+		evaluating: make(map[string]bool),
+	}
+	for _, scope := range scopes {
+		for name, value := range scope {
+			v.values[name] = value
+		}
+	}
+	return v
+}
+
+// with returns the variables of a nested scope, such as the variables of a block or of an included file
+func (v *variables) with(scope map[string]interface{}) *variables {
+	if len(scope) == 0 {
+		return v
+	}
+	return newVariables(v.values, scope)
+}
+
+// get returns the rendered value of a variable, the variables whose value can not be rendered are not known
+func (v *variables) get(name string) (interface{}, bool) {
+	if value, ok := v.rendered[name]; ok {
+		return value, true
+	}
+	value, ok := v.values[name]
+	if !ok || v.evaluating[name] {
+		return nil, false
+	}
+	v.evaluating[name] = true
+	defer delete(v.evaluating, name)
+	rendered, known := v.render(value)
+	if !known {
+		return nil, false
+	}
+	v.rendered[name] = rendered
+	return rendered, true
+}
+
+// render returns a value with the Jinja2 expressions of its strings replaced by their value, the expressions
+// that can not be evaluated are kept, in which case the value is not known
+func (v *variables) render(value interface{}) (interface{}, bool) {
+	switch val := value.(type) {
+	case string:
+		return v.renderString(val)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		known := true
+		for key, child := range val {
+			if key == linesKey {
+				result[key] = child
+				continue
+			}
+			rendered, childKnown := v.render(child)
+			result[key] = rendered
+			known = known && childKnown
+		}
+		return result, known
+	case []interface{}:
+		result := make([]interface{}, 0, len(val))
+		known := true
+		for _, element := range val {
+			rendered, elementKnown := v.render(element)
+			result = append(result, rendered)
+			known = known && elementKnown
+		}
+		return result, known
+	default:
+		return value, true
+	}
+}
+
+// renderString returns the value of a string with Jinja2 expressions, a string that is a single expression
+// gets the value of the expression, ex: a boolean, otherwise the values are written into the string
+func (v *variables) renderString(value string) (interface{}, bool) {
+	matches := templateRegex.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value, true
+	}
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) {
+		result, known := v.evaluate(value[matches[0][2]:matches[0][3]])
+		if !known {
+			return value, false
+		}
+		return result, true
+	}
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		result, known := v.evaluate(value[match[2]:match[3]])
+		text, isScalar := toString(result)
+		if !known || !isScalar {
+			return value, false
+		}
+		builder.WriteString(value[last:match[0]])
+		builder.WriteString(text)
+		last = match[1]
+	}
+	builder.WriteString(value[last:])
+	return builder.String(), true
+}
+
+// evaluate returns the value of a Jinja2 expression, only literals and variable references are evaluated,
+// along with the default, bool, int, string, lower, upper and trim filters
+func (v *variables) evaluate(expression string) (interface{}, bool) {
+	parts := strings.Split(expression, "|")
+	value, known := v.operand(strings.TrimSpace(parts[0]))
+	for _, filter := range parts[1:] {
+		value, known = v.filter(strings.TrimSpace(filter), value, known)
+	}
+	return value, known
+}
+
+// filter applies a Jinja2 filter to a value, the default filter is the only one applied to unknown values
+func (v *variables) filter(filter string, value interface{}, known bool) (interface{}, bool) {
+	if match := defaultRegex.FindStringSubmatch(filter); match != nil {
+		if known {
+			return value, true
+		}
+		return v.operand(strings.TrimSpace(match[1]))
+	}
+	if !known {
+		return nil, false
+	}
+	text, isScalar := toString(value)
+	switch filter {
+	case "bool":
+		return toBool(value)
+	case "int":
+		number, err := strconv.ParseFloat(text, 64)
+		return float64(int(number)), isScalar && err == nil
+	case "string":
+		return text, isScalar
+	case "lower":
+		return strings.ToLower(text), isScalar
+	case "upper":
+		return strings.ToUpper(text), isScalar
+	case "trim":
+		return strings.TrimSpace(text), isScalar
+	default:
+		return nil, false
+	}
+}
+
+// operand returns the value of a literal or of a variable reference
+func (v *variables) operand(operand string) (interface{}, bool) {
+	if len(operand) >= 2 && (operand[0] == '\'' || operand[0] == '"') && operand[len(operand)-1] == operand[0] {
+		text := operand[1 : len(operand)-1]
+		if strings.ContainsRune(text, rune(operand[0])) {
+			return nil, false
+		}
+		return text, true
+	}
+	switch operand {
+	case "true", "True":
+		return true, true
+	case "false", "False":
+		return false, true
+	}
+	if number, err := strconv.ParseFloat(operand, 64); err == nil {
+		return number, true
+	}
+	match := pathRegex.FindStringSubmatch(operand)
+	if match == nil {
+		return nil, false
+	}
+	value, known := v.get(match[1])
+	for _, access := range accessRegex.FindAllStringSubmatch(match[2], -1) {
+		if !known {
+			return nil, false
+		}
+		value, known = accessValue(value, access)
+	}
+	return value, known
+}
+
+// accessValue returns the attribute or element of a value accessed
+func accessValue(value interface{}, access []string) (interface{}, bool) {
+	if index, err := strconv.Atoi(access[2]); err == nil {
+		list, ok := value.([]interface{})
+		if !ok || index >= len(list) {
+			return nil, false
+		}
+		return list[index], true
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	result, ok := m[access[1]+access[3]+access[4]]
+	return result, ok
+}
+
+// toString returns the text of a scalar value as written by Jinja2, booleans are capitalized as in Python
+func toString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		if v {
+			return "True", true
+		}
+		return "False", true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case nil:
+		return "", true
+	default:
+		return fmt.Sprint(v), false
+	}
+}
+
+// toBool returns the boolean value of a value, as converted by the bool filter
+func toBool(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case float64:
+		return v == 1, true
+	case int:
+		return v == 1, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "on", "1", "true", "y":
+			return true, true
+		default:
+			return false, true
+		}
+	default:
+		return false, true
+	}
+}
+
+// loadVars loads the variables of a variables file, which is a YAML or JSON map
+func (r *Resolver) loadVars(path string) map[string]interface{} {
+	r.files[path] = true
+	document, err := loadDocument(r.fileSystem, path)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	vars, _ := withoutLines(map[string]interface{}(document)).(map[string]interface{})
+	return vars
+}
+
+// loadVarsFiles loads the variables of the files of a variables directory, ex: group_vars/all.yml,
+// group_vars/all/main.yml or host_vars/web
+func (r *Resolver) loadVarsFiles(dir, name string) map[string]interface{} {
+	vars := make(map[string]interface{})
+	base := filepath.Join(dir, name)
+	r.patterns = append(r.patterns, base+"*", filepath.Join(base, "*"))
+	for _, extension := range varsExtensions {
+		if info, err := r.fileSystem.Stat(base + extension); err == nil && !info.IsDir() {
+			mergeVars(vars, r.loadVars(base+extension))
+		}
+	}
+	files, _ := r.fileSystem.Glob(filepath.Join(base, "*"))
+	for _, file := range files {
+		if info, err := r.fileSystem.Stat(file); err == nil && !info.IsDir() && isVarsFile(file) {
+			mergeVars(vars, r.loadVars(file))
+		}
+	}
+	return vars
+}
+
+// hostsVars loads the group_vars and host_vars next to a playbook for the hosts of a play, the group_vars of
+// the all group are loaded first, followed by the group_vars and host_vars named as the hosts
+func (r *Resolver) hostsVars(dir string, hosts interface{}) map[string]interface{} {
+	vars := r.loadVarsFiles(filepath.Join(dir, "group_vars"), "all")
+	names := hostNames(hosts)
+	for _, name := range names {
+		mergeVars(vars, r.loadVarsFiles(filepath.Join(dir, "group_vars"), name))
+	}
+	for _, name := range names {
+		mergeVars(vars, r.loadVarsFiles(filepath.Join(dir, "host_vars"), name))
+	}
+	return vars
+}
+
+// hostNames returns the groups and hosts of the hosts pattern of a play, ex: "webservers:&production"
+func hostNames(hosts interface{}) []string {
+	patterns := make([]string, 0)
+	switch h := hosts.(type) {
+	case string:
+		patterns = append(patterns, strings.FieldsFunc(h, func(r rune) bool { return r == ':' || r == ',' })...)
+	case []interface{}:
+		for _, host := range h {
+			if name, ok := host.(string); ok {
+				patterns = append(patterns, name)
+			}
+		}
+	}
+	names := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		name := strings.TrimLeft(strings.TrimSpace(pattern), "!&")
+		if name != "" && name != "all" && !strings.ContainsAny(name, "*?[{") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isVarsFile returns true if the file of a variables directory is a YAML or JSON file, or has no extension
+func isVarsFile(path string) bool {
+	extension := filepath.Ext(path)
+	for _, varsExtension := range varsExtensions {
+		if extension == varsExtension {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeVars merges the variables of a scope into vars, overriding the variables with the same name
+func mergeVars(vars, scope map[string]interface{}) {
+	for name, value := range scope {
+		vars[name] = value
+	}
+}
+
+// getVars returns the variables declared by a vars keyword, which are a map
+func getVars(value interface{}) map[string]interface{} {
+	vars, ok := withoutLines(value).(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return vars
+}
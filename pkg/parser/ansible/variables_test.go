@@ -0,0 +1,52 @@
+package ansible
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	vars := newVariables(map[string]interface{}{
+		"environment": "prod",
+		"encrypted":   "yes",
+		"size":        float64(20),
+		"bucket":      map[string]interface{}{"name": "{{ environment }}-data", "tags": []interface{}{"backup"}},
+		"loop":        "{{ loop }}",
+	})
+	tests := []struct {
+		name      string
+		value     interface{}
+		want      interface{}
+		wantKnown bool
+	}{
+		{name: "literal", value: "private", want: "private", wantKnown: true},
+		{name: "variable", value: "{{ environment }}", want: "prod", wantKnown: true},
+		{name: "interpolation", value: "{{ environment }}-{{ size }}", want: "prod-20", wantKnown: true},
+		{name: "attribute", value: "{{ bucket.name }}", want: "prod-data", wantKnown: true},
+		{name: "element", value: "{{ bucket['tags'][0] }}", want: "backup", wantKnown: true},
+		{name: "bool filter", value: "{{ encrypted | bool }}", want: true, wantKnown: true},
+		{name: "default filter", value: "{{ acl | default('private') }}", want: "private", wantKnown: true},
+		{name: "undefined", value: "{{ acl }}", want: "{{ acl }}", wantKnown: false},
+		{name: "unsupported filter", value: "{{ environment | hash('sha1') }}", want: "{{ environment | hash('sha1') }}"},
+		{name: "self reference", value: "{{ loop }}", want: "{{ loop }}", wantKnown: false},
+		{
+			name:      "map",
+			value:     map[string]interface{}{"size": "{{ size }}", "acl": "{{ acl }}"},
+			want:      map[string]interface{}{"size": float64(20), "acl": "{{ acl }}"},
+			wantKnown: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, known := vars.render(tt.value)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantKnown, known)
+		})
+	}
+}
+
+func TestHostNames(t *testing.T) {
+	require.Equal(t, []string{"webservers", "production"}, hostNames("webservers:&production:all"))
+	require.Equal(t, []string{"db"}, hostNames([]interface{}{"db", "web*"}))
+}
@@ -0,0 +1,224 @@
+package azureresourcemanager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// node is a node of the syntax tree of a template expression
+type node interface{}
+
+// literal is a string or number written in an expression
+type literal struct {
+	value interface{}
+}
+
+// call is the call of a template function, its name is lower case since function names are case insensitive
+type call struct {
+	name string
+	args []node
+}
+
+// access is the access to a property of an object or to an element of an array, ex: parameters('tags').owner
+type access struct {
+	target   node
+	property node
+}
+
+// expressionParser parses the template expressions, ex: [concat(parameters('prefix'), '-storage')]
+type expressionParser struct {
+	input string
+	pos   int
+}
+
+// isExpression returns true if value is a template expression, which is enclosed in square brackets,
+// strings starting with two brackets are escaped literals
+func isExpression(value string) bool {
+	return strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") && !strings.HasPrefix(value, "[[")
+}
+
+// isEscaped returns true if value is a literal starting with a bracket, which is escaped with another bracket
+func isEscaped(value string) bool {
+	return strings.HasPrefix(value, "[[") && strings.HasSuffix(value, "]")
+}
+
+// parseExpression parses a template expression, the expression must be enclosed in square brackets
+func parseExpression(expression string) (node, error) {
+	if !isExpression(expression) {
+		return nil, fmt.Errorf("%s is not a template expression", expression)
+	}
+	p := &expressionParser{
+		input: expression[1 : len(expression)-1],
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected character %q at position %d of expression %s", p.input[p.pos], p.pos, expression)
+	}
+	return value, nil
+}
+
+// parseValue parses a literal or a function call followed by its property and element accesses
+func (p *expressionParser) parseValue() (node, error) {
+	p.skipSpaces()
+	var value node
+	var err error
+	switch c := p.peek(); {
+	case c == '\'':
+		value, err = p.parseString()
+	case c == '-' || isDigit(c):
+		value, err = p.parseNumber()
+	case isLetter(c):
+		value, err = p.parseCall()
+	default:
+		err = fmt.Errorf("unexpected character %q at position %d", c, p.pos)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.parseAccesses(value)
+}
+
+// parseAccesses parses the property accesses, ex: .name, and element accesses, ex: [0], of a value
+func (p *expressionParser) parseAccesses(value node) (node, error) {
+	for {
+		p.skipSpaces()
+		switch p.peek() {
+		case '.':
+			p.pos++
+			p.skipSpaces()
+			name := p.parseIdentifier()
+			if name == "" {
+				return nil, fmt.Errorf("property name expected at position %d", p.pos)
+			}
+			value = access{target: value, property: literal{value: name}}
+		case '[':
+			p.pos++
+			index, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(']'); err != nil {
+				return nil, err
+			}
+			value = access{target: value, property: index}
+		default:
+			return value, nil
+		}
+	}
+}
+
+// parseCall parses a function call and its arguments
+func (p *expressionParser) parseCall() (node, error) {
+	name := p.parseIdentifier()
+	p.skipSpaces()
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	args := make([]node, 0)
+	p.skipSpaces()
+	if p.peek() == ')' {
+		p.pos++
+		return call{name: strings.ToLower(name), args: args}, nil
+	}
+	for {
+		arg, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return call{name: strings.ToLower(name), args: args}, nil
+		default:
+			return nil, fmt.Errorf("',' or ')' expected at position %d", p.pos)
+		}
+	}
+}
+
+// parseString parses a string literal, which is enclosed in single quotes and escapes them by doubling them
+func (p *expressionParser) parseString() (node, error) {
+	p.pos++
+	var builder strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		if c != '\'' {
+			builder.WriteByte(c)
+			continue
+		}
+		if p.peek() != '\'' {
+			return literal{value: builder.String()}, nil
+		}
+		builder.WriteByte('\'')
+		p.pos++
+	}
+	return nil, fmt.Errorf("unterminated string in expression")
+}
+
+// parseNumber parses an integer literal, numbers are represented as float64 as in the parsed documents
+func (p *expressionParser) parseNumber() (node, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	number, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, err
+	}
+	return literal{value: number}, nil
+}
+
+// parseIdentifier parses the name of a function or property
+func (p *expressionParser) parseIdentifier() string {
+	start := p.pos
+	for isLetter(p.peek()) || isDigit(p.peek()) || p.peek() == '_' || p.peek() == '$' {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// expect consumes the character c, returning an error if it is not the next character
+func (p *expressionParser) expect(c byte) error {
+	p.skipSpaces()
+	if p.peek() != c {
+		return fmt.Errorf("%q expected at position %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// peek returns the next character, or 0 at the end of the expression
+func (p *expressionParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// skipSpaces moves the position to the next character that is not a space
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n' ||
+		p.input[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,56 @@
+package azureresourcemanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluate tests the evaluation of the template expressions and functions
+func TestEvaluate(t *testing.T) {
+	tmpl := newTemplate(nil, map[string]interface{}{
+		"parameters": map[string]interface{}{
+			"Environment": map[string]interface{}{"defaultValue": "prod"},
+			"location":    map[string]interface{}{"defaultValue": "[resourceGroup().location]"},
+			"sizes":       map[string]interface{}{"defaultValue": []interface{}{float64(1), float64(2)}},
+		},
+		"variables": map[string]interface{}{
+			"settings": map[string]interface{}{"tls": "[if(equals(parameters('environment'), 'prod'), 'TLS1_2', 'TLS1_0')]"},
+			"self":     "[variables('self')]",
+		},
+	}, "", nil, 0)
+
+	tests := []struct {
+		expression string
+		want       interface{}
+		known      bool
+	}{
+		{expression: "[parameters('environment')]", want: "prod", known: true},
+		{expression: "[variables('settings').tls]", want: "TLS1_2", known: true},
+		{expression: "[concat('st', toUpper(parameters('Environment')), '-', string(add(1, 2)))]", want: "stPROD-3", known: true},
+		{expression: "[format('{0}-{1}', 'logs', length(parameters('sizes')))]", want: "logs-2", known: true},
+		{expression: "[parameters('sizes')[1]]", want: float64(2), known: true},
+		{expression: "[createObject('enabled', not(empty('')))]", want: map[string]interface{}{"enabled": false}, known: true},
+		{expression: "[split(replace('a;b', ';', ','), ',')]", want: []interface{}{"a", "b"}, known: true},
+		{expression: "[contains(union(createArray('a'), createArray('b')), 'b')]", want: true, known: true},
+		{expression: "[or(equals(parameters('location'), 'westeurope'), true())]", want: true, known: true},
+		{expression: "[if(false(), resourceGroup().id, 'fallback')]", want: "fallback", known: true},
+		{expression: "[json('{\"a\": [1]}').a]", want: []interface{}{float64(1)}, known: true},
+		{expression: "[substring('o''brien', 0, 3)]", want: "o'b", known: true},
+		{expression: "[[literal]", want: "[literal]", known: true},
+		{expression: "[parameters('location')]", known: false},
+		{expression: "[uniqueString(resourceGroup().id)]", known: false},
+		{expression: "[parameters('missing')]", known: false},
+		{expression: "[variables('self')]", known: false},
+		{expression: "[concat('a']", known: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, known := tmpl.evaluateString(tt.expression)
+			require.Equal(t, tt.known, known)
+			if tt.known {
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
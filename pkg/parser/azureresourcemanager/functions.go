@@ -0,0 +1,791 @@
+package azureresourcemanager
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formatItemRegex matches the format items of the string of the format function, ex: {0} or {0:N2}
+var formatItemRegex = regexp.MustCompile(`\{(\d+)(:[^}]*)?\}`)
+
+// function evaluates a template function whose arguments are known, it returns false when the function
+// can not be evaluated with the arguments passed
+type function func(args []interface{}) (interface{}, bool)
+
+// functions are the template functions that only depend on their arguments, the functions depending on the
+// deployment, such as resourceGroup, resourceId, reference or uniqueString, are not evaluated
+var functions = map[string]function{
+	"add":             arithmetic(func(a, b float64) float64 { return a + b }),
+	"array":           array,
+	"bool":            toBool,
+	"coalesce":        coalesce,
+	"concat":          concat,
+	"contains":        contains,
+	"createarray":     createArray,
+	"createobject":    createObject,
+	"div":             integerArithmetic(func(a, b int) int { return a / b }),
+	"empty":           empty,
+	"endswith":        stringSearch(func(s, v string) interface{} { return strings.HasSuffix(s, v) }),
+	"equals":          equals,
+	"false":           constant(false),
+	"first":           first,
+	"format":          format,
+	"greater":         compare(func(c int) bool { return c > 0 }),
+	"greaterorequals": compare(func(c int) bool { return c >= 0 }),
+	"indexof":         stringSearch(func(s, v string) interface{} { return float64(strings.Index(s, v)) }),
+	"int":             toInt,
+	"join":            join,
+	"json":            parseJSON,
+	"last":            last,
+	"lastindexof":     stringSearch(func(s, v string) interface{} { return float64(strings.LastIndex(s, v)) }),
+	"length":          length,
+	"less":            compare(func(c int) bool { return c < 0 }),
+	"lessorequals":    compare(func(c int) bool { return c <= 0 }),
+	"max":             minMax(func(a, b float64) bool { return a > b }),
+	"min":             minMax(func(a, b float64) bool { return a < b }),
+	"mod":             integerArithmetic(func(a, b int) int { return a % b }),
+	"mul":             arithmetic(func(a, b float64) float64 { return a * b }),
+	"not":             not,
+	"null":            constant(nil),
+	"replace":         replace,
+	"skip":            skip,
+	"split":           split,
+	"startswith":      stringSearch(func(s, v string) interface{} { return strings.HasPrefix(s, v) }),
+	"string":          toStringFunction,
+	"sub":             arithmetic(func(a, b float64) float64 { return a - b }),
+	"substring":       substring,
+	"take":            take,
+	"tolower":         stringFunction(func(s string) interface{} { return strings.ToLower(s) }),
+	"toupper":         stringFunction(func(s string) interface{} { return strings.ToUpper(s) }),
+	"trim":            stringFunction(func(s string) interface{} { return strings.TrimSpace(s) }),
+	"true":            constant(true),
+	"union":           union,
+}
+
+// evaluate returns the value of an expression, known is false when the value depends on the deployment,
+// on parameters without value or on functions that are not evaluated
+func (t *template) evaluate(expression node) (value interface{}, known bool) {
+	switch n := expression.(type) {
+	case literal:
+		return n.value, true
+	case access:
+		target, known := t.evaluate(n.target)
+		if !known {
+			return nil, false
+		}
+		property, known := t.evaluate(n.property)
+		if !known {
+			return nil, false
+		}
+		return getProperty(target, property)
+	case call:
+		return t.call(n)
+	default:
+		return nil, false
+	}
+}
+
+// call returns the value of a function call, the logical functions are evaluated before their arguments
+// are known, so a function like if only needs the value of the branch selected
+func (t *template) call(c call) (interface{}, bool) {
+	switch c.name {
+	case "parameters", "variables":
+		if len(c.args) != 1 {
+			return nil, false
+		}
+		name, known := t.evaluate(c.args[0])
+		if _, ok := name.(string); !known || !ok {
+			return nil, false
+		}
+		if c.name == "parameters" {
+			return t.parameter(name.(string))
+		}
+		return t.variable(name.(string))
+	case "if":
+		return t.evaluateIf(c.args)
+	case "and", "or":
+		return t.evaluateLogical(c.name == "and", c.args)
+	}
+
+	fn, ok := functions[c.name]
+	if !ok {
+		return nil, false
+	}
+	args := make([]interface{}, 0, len(c.args))
+	for _, arg := range c.args {
+		value, known := t.evaluate(arg)
+		if !known {
+			return nil, false
+		}
+		args = append(args, value)
+	}
+	return fn(args)
+}
+
+// evaluateIf returns the value of the branch of the if function selected by its condition
+func (t *template) evaluateIf(args []node) (interface{}, bool) {
+	if len(args) != 3 {
+		return nil, false
+	}
+	condition, known := t.evaluate(args[0])
+	value, ok := condition.(bool)
+	if !known || !ok {
+		return nil, false
+	}
+	if value {
+		return t.evaluate(args[1])
+	}
+	return t.evaluate(args[2])
+}
+
+// evaluateLogical returns the value of the and/or functions, which is known when a condition decides it even
+// if other conditions are unknown
+func (t *template) evaluateLogical(and bool, args []node) (interface{}, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	allKnown := true
+	for _, arg := range args {
+		condition, known := t.evaluate(arg)
+		value, ok := condition.(bool)
+		if !known || !ok {
+			allKnown = false
+			continue
+		}
+		// false decides the value of and, and true the value of or
+		if value != and {
+			return value, true
+		}
+	}
+	if !allKnown {
+		return nil, false
+	}
+	return and, true
+}
+
+// getProperty returns the property of an object, whose names are case insensitive, or the element of an array
+func getProperty(target, property interface{}) (interface{}, bool) {
+	switch v := target.(type) {
+	case map[string]interface{}:
+		name, ok := property.(string)
+		if !ok {
+			return nil, false
+		}
+		if value, ok := v[name]; ok {
+			return value, true
+		}
+		for key, value := range v {
+			if strings.EqualFold(key, name) && key != linesKey {
+				return value, true
+			}
+		}
+		return nil, false
+	case []interface{}:
+		index, ok := toInteger(property)
+		if !ok || index < 0 || index >= len(v) {
+			return nil, false
+		}
+		return v[index], true
+	default:
+		return nil, false
+	}
+}
+
+func constant(value interface{}) function {
+	return func(args []interface{}) (interface{}, bool) {
+		return value, len(args) == 0
+	}
+}
+
+func stringFunction(fn func(string) interface{}) function {
+	return func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, false
+		}
+		return fn(s), true
+	}
+}
+
+// stringSearch returns a function searching a string in another string, the search is case insensitive
+func stringSearch(fn func(string, string) interface{}) function {
+	return func(args []interface{}) (interface{}, bool) {
+		if len(args) != 2 {
+			return nil, false
+		}
+		s, ok := args[0].(string)
+		v, ok2 := args[1].(string)
+		if !ok || !ok2 {
+			return nil, false
+		}
+		return fn(strings.ToLower(s), strings.ToLower(v)), true
+	}
+}
+
+func arithmetic(fn func(float64, float64) float64) function {
+	return func(args []interface{}) (interface{}, bool) {
+		if len(args) != 2 {
+			return nil, false
+		}
+		a, ok := args[0].(float64)
+		b, ok2 := args[1].(float64)
+		if !ok || !ok2 {
+			return nil, false
+		}
+		return fn(a, b), true
+	}
+}
+
+func integerArithmetic(fn func(int, int) int) function {
+	return func(args []interface{}) (interface{}, bool) {
+		if len(args) != 2 {
+			return nil, false
+		}
+		a, ok := toInteger(args[0])
+		b, ok2 := toInteger(args[1])
+		if !ok || !ok2 || b == 0 {
+			return nil, false
+		}
+		return float64(fn(a, b)), true
+	}
+}
+
+func minMax(better func(float64, float64) bool) function {
+	return func(args []interface{}) (interface{}, bool) {
+		if len(args) == 1 {
+			if list, ok := args[0].([]interface{}); ok {
+				args = list
+			}
+		}
+		if len(args) == 0 {
+			return nil, false
+		}
+		var result float64
+		for i, arg := range args {
+			number, ok := arg.(float64)
+			if !ok {
+				return nil, false
+			}
+			if i == 0 || better(number, result) {
+				result = number
+			}
+		}
+		return result, true
+	}
+}
+
+func compare(fn func(int) bool) function {
+	return func(args []interface{}) (interface{}, bool) {
+		if len(args) != 2 {
+			return nil, false
+		}
+		switch a := args[0].(type) {
+		case float64:
+			b, ok := args[1].(float64)
+			if !ok {
+				return nil, false
+			}
+			return fn(compareNumbers(a, b)), true
+		case string:
+			b, ok := args[1].(string)
+			if !ok {
+				return nil, false
+			}
+			return fn(strings.Compare(a, b)), true
+		default:
+			return nil, false
+		}
+	}
+}
+
+func array(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	if list, ok := args[0].([]interface{}); ok {
+		return list, true
+	}
+	return []interface{}{args[0]}, true
+}
+
+func toBool(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case bool:
+		return v, true
+	case string:
+		value, err := strconv.ParseBool(strings.ToLower(v))
+		return value, err == nil
+	case float64:
+		return v != 0, true
+	default:
+		return nil, false
+	}
+}
+
+func coalesce(args []interface{}) (interface{}, bool) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, true
+		}
+	}
+	return nil, true
+}
+
+// concat concatenates strings, or arrays when the first argument is an array
+func concat(args []interface{}) (interface{}, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	if _, ok := args[0].([]interface{}); ok {
+		result := make([]interface{}, 0)
+		for _, arg := range args {
+			list, ok := arg.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			result = append(result, list...)
+		}
+		return result, true
+	}
+	var builder strings.Builder
+	for _, arg := range args {
+		if !isScalar(arg) {
+			return nil, false
+		}
+		builder.WriteString(toString(arg))
+	}
+	return builder.String(), true
+}
+
+func contains(args []interface{}) (interface{}, bool) {
+	if len(args) != 2 {
+		return nil, false
+	}
+	switch container := args[0].(type) {
+	case string:
+		return strings.Contains(container, toString(args[1])), isScalar(args[1])
+	case []interface{}:
+		return containsElement(container, args[1]), true
+	case map[string]interface{}:
+		_, found := getProperty(container, args[1])
+		return found, true
+	default:
+		return nil, false
+	}
+}
+
+func createArray(args []interface{}) (interface{}, bool) {
+	return append(make([]interface{}, 0, len(args)), args...), true
+}
+
+func createObject(args []interface{}) (interface{}, bool) {
+	if len(args)%2 != 0 {
+		return nil, false
+	}
+	object := make(map[string]interface{})
+	for i := 0; i < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok {
+			return nil, false
+		}
+		object[key] = args[i+1]
+	}
+	return object, true
+}
+
+func empty(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case nil:
+		return true, true
+	case string:
+		return v == "", true
+	case []interface{}:
+		return len(v) == 0, true
+	case map[string]interface{}:
+		return len(v) == 0, true
+	default:
+		return nil, false
+	}
+}
+
+func equals(args []interface{}) (interface{}, bool) {
+	if len(args) != 2 {
+		return nil, false
+	}
+	return reflect.DeepEqual(args[0], args[1]), true
+}
+
+func first(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case string:
+		if v == "" {
+			return "", true
+		}
+		return v[:1], true
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, true
+		}
+		return v[0], true
+	default:
+		return nil, false
+	}
+}
+
+func last(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case string:
+		if v == "" {
+			return "", true
+		}
+		return v[len(v)-1:], true
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, true
+		}
+		return v[len(v)-1], true
+	default:
+		return nil, false
+	}
+}
+
+// format replaces the format items of a string by the string value of the arguments
+func format(args []interface{}) (interface{}, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	text, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	known := true
+	result := formatItemRegex.ReplaceAllStringFunc(text, func(item string) string {
+		index, _ := strconv.Atoi(formatItemRegex.FindStringSubmatch(item)[1])
+		if index+1 >= len(args) || !isScalar(args[index+1]) {
+			known = false
+			return item
+		}
+		return toString(args[index+1])
+	})
+	return result, known
+}
+
+func toInt(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case float64:
+		return math.Trunc(v), true
+	case string:
+		value, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return float64(value), err == nil
+	default:
+		return nil, false
+	}
+}
+
+func join(args []interface{}) (interface{}, bool) {
+	if len(args) != 2 {
+		return nil, false
+	}
+	list, ok := args[0].([]interface{})
+	delimiter, ok2 := args[1].(string)
+	if !ok || !ok2 {
+		return nil, false
+	}
+	values := make([]string, 0, len(list))
+	for _, element := range list {
+		if !isScalar(element) {
+			return nil, false
+		}
+		values = append(values, toString(element))
+	}
+	return strings.Join(values, delimiter), true
+}
+
+func parseJSON(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	text, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func length(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case string:
+		return float64(len(v)), true
+	case []interface{}:
+		return float64(len(v)), true
+	case map[string]interface{}:
+		return float64(len(v)), true
+	default:
+		return nil, false
+	}
+}
+
+func not(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	value, ok := args[0].(bool)
+	return !value, ok
+}
+
+func replace(args []interface{}) (interface{}, bool) {
+	if len(args) != 3 {
+		return nil, false
+	}
+	text, ok := args[0].(string)
+	old, ok2 := args[1].(string)
+	replacement, ok3 := args[2].(string)
+	if !ok || !ok2 || !ok3 {
+		return nil, false
+	}
+	return strings.ReplaceAll(text, old, replacement), true
+}
+
+func skip(args []interface{}) (interface{}, bool) {
+	if len(args) != 2 {
+		return nil, false
+	}
+	count, ok := toInteger(args[1])
+	if !ok {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case string:
+		return v[clamp(count, len(v)):], true
+	case []interface{}:
+		return v[clamp(count, len(v)):], true
+	default:
+		return nil, false
+	}
+}
+
+func take(args []interface{}) (interface{}, bool) {
+	if len(args) != 2 {
+		return nil, false
+	}
+	count, ok := toInteger(args[1])
+	if !ok {
+		return nil, false
+	}
+	switch v := args[0].(type) {
+	case string:
+		return v[:clamp(count, len(v))], true
+	case []interface{}:
+		return v[:clamp(count, len(v))], true
+	default:
+		return nil, false
+	}
+}
+
+// split splits a string by a delimiter or by any of the delimiters of an array
+func split(args []interface{}) (interface{}, bool) {
+	if len(args) != 2 {
+		return nil, false
+	}
+	text, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	delimiters := make([]string, 0)
+	switch v := args[1].(type) {
+	case string:
+		delimiters = append(delimiters, v)
+	case []interface{}:
+		for _, delimiter := range v {
+			delimiters = append(delimiters, toString(delimiter))
+		}
+	default:
+		return nil, false
+	}
+	parts := []string{text}
+	for _, delimiter := range delimiters {
+		next := make([]string, 0, len(parts))
+		for _, part := range parts {
+			next = append(next, strings.Split(part, delimiter)...)
+		}
+		parts = next
+	}
+	result := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		result = append(result, part)
+	}
+	return result, true
+}
+
+func toStringFunction(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	if isScalar(args[0]) {
+		return toString(args[0]), true
+	}
+	content, err := json.Marshal(args[0])
+	if err != nil {
+		return nil, false
+	}
+	return string(content), true
+}
+
+func substring(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, false
+	}
+	text, ok := args[0].(string)
+	start, ok2 := toInteger(args[1])
+	if !ok || !ok2 || start < 0 || start > len(text) {
+		return nil, false
+	}
+	end := len(text)
+	if len(args) == 3 {
+		count, ok := toInteger(args[2])
+		if !ok || count < 0 || start+count > len(text) {
+			return nil, false
+		}
+		end = start + count
+	}
+	return text[start:end], true
+}
+
+// union returns an object with the properties of all the objects, or an array with the distinct elements
+// of all the arrays
+func union(args []interface{}) (interface{}, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	if _, ok := args[0].(map[string]interface{}); ok {
+		result := make(map[string]interface{})
+		for _, arg := range args {
+			object, ok := arg.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			keys := make([]string, 0, len(object))
+			for key := range object {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				result[key] = object[key]
+			}
+		}
+		return result, true
+	}
+	result := make([]interface{}, 0)
+	for _, arg := range args {
+		list, ok := arg.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		for _, element := range list {
+			if !containsElement(result, element) {
+				result = append(result, element)
+			}
+		}
+	}
+	return result, true
+}
+
+// containsElement returns true if the array has an element equal to element
+func containsElement(list []interface{}, element interface{}) bool {
+	for _, value := range list {
+		if reflect.DeepEqual(value, element) {
+			return true
+		}
+	}
+	return false
+}
+
+// toInteger returns the integer value of a number
+func toInteger(value interface{}) (int, bool) {
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) {
+		return 0, false
+	}
+	return int(number), true
+}
+
+func clamp(value, size int) int {
+	if value < 0 {
+		return 0
+	}
+	if value > size {
+		return size
+	}
+	return value
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isScalar returns true if value is a string, a number, a boolean or null
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, bool, nil:
+		return true
+	default:
+		return false
+	}
+}
+
+// toString returns the string value of a scalar as the template functions do, booleans are True or False
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case nil:
+		return ""
+	default:
+		content, _ := json.Marshal(v)
+		return string(content)
+	}
+}
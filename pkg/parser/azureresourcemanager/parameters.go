@@ -0,0 +1,188 @@
+package azureresourcemanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+)
+
+// parametersFileSuffix is the suffix of the parameters files of the templates, ex: azuredeploy.parameters.json
+const parametersFileSuffix = ".parameters.json"
+
+// ParametersFile returns the path of the parameters file of a template, which is next to the template and
+// has its name with the .parameters.json suffix, ex: azuredeploy.json and azuredeploy.parameters.json
+func ParametersFile(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + parametersFileSuffix
+}
+
+// LoadParameters reads the values of the parameters of a deployment parameters file, the parameters that reference
+// a Key Vault secret are not loaded since their value is only known during the deployment. The file is read from
+// the FileSystem passed
+func LoadParameters(fileSystem filesystem.FileSystem, path string) (map[string]interface{}, error) {
+	content, err := filesystem.Get(fileSystem).ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var parsed map[string]interface{}
+	if err = json.Unmarshal(content, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse ARM parameters file %s: %w", path, err)
+	}
+	parameters, ok := parsed["parameters"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid ARM parameters file %s: parameters object not found", path)
+	}
+	return passedParameters(parameters), nil
+}
+
+// passedParameters returns the values of the parameters passed to a template, which are objects with their value,
+// the values that are still template expressions are not passed since they could not be evaluated
+func passedParameters(parameters map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	for name, parameter := range parameters {
+		if name == linesKey {
+			continue
+		}
+		value, ok := getMap(parameter)["value"]
+		if text, isString := value.(string); !ok || (isString && isExpression(text)) {
+			continue
+		}
+		values[strings.ToLower(name)] = copyValue(value)
+	}
+	return values
+}
+
+// parameter returns the value of a parameter, which is the value passed to the template or its default value
+func (t *template) parameter(name string) (interface{}, bool) {
+	key := strings.ToLower(name)
+	if value, ok := t.passed[key]; ok {
+		return copyValue(value), true
+	}
+	defaultValue, ok := getMap(t.parameters[key])["defaultValue"]
+	if !ok {
+		return nil, false
+	}
+	return t.cached("parameters/"+key, defaultValue)
+}
+
+// variable returns the value of a variable, the variables with copy loops are not evaluated
+func (t *template) variable(name string) (interface{}, bool) {
+	key := strings.ToLower(name)
+	definition, ok := t.variables[key]
+	if !ok {
+		return nil, false
+	}
+	if _, ok := getMap(definition)["copy"]; ok {
+		return nil, false
+	}
+	return t.cached("variables/"+key, definition)
+}
+
+// cached returns the value of a parameter default value or variable, which is evaluated once, the values that
+// reference themselves are not evaluated
+func (t *template) cached(key string, definition interface{}) (interface{}, bool) {
+	if value, ok := t.values[key]; ok {
+		return copyValue(value), true
+	}
+	if t.evaluating[key] {
+		return nil, false
+	}
+	t.evaluating[key] = true
+	defer delete(t.evaluating, key)
+	value, known := t.value(definition)
+	if known {
+		t.values[key] = value
+	}
+	return copyValue(value), known
+}
+
+// value returns a value with its template expressions evaluated, it is not known when any of them is not known
+func (t *template) value(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		return t.evaluateString(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if key == linesKey {
+				continue
+			}
+			resolved, known := t.value(child)
+			if !known {
+				return nil, false
+			}
+			result[key] = resolved
+		}
+		return result, true
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			resolved, known := t.value(element)
+			if !known {
+				return nil, false
+			}
+			result = append(result, resolved)
+		}
+		return result, true
+	default:
+		return value, true
+	}
+}
+
+// evaluateString returns the value of a string, which is the value of its expression or the string itself
+func (t *template) evaluateString(value string) (interface{}, bool) {
+	if isEscaped(value) {
+		return value[1:], true
+	}
+	if !isExpression(value) {
+		return value, true
+	}
+	expression, err := parseExpression(value)
+	if err != nil {
+		return nil, false
+	}
+	result, known := t.evaluate(expression)
+	if !known {
+		return nil, false
+	}
+	return copyValue(result), true
+}
+
+// getMap returns value as a map, or an empty map if it is not a map
+func getMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	if m, ok := value.(model.Document); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+// copyValue returns a deep copy of a value without its line information
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if key == linesKey {
+				continue
+			}
+			result[key] = copyValue(child)
+		}
+		return result
+	case model.Document:
+		return copyValue(map[string]interface{}(v))
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			result = append(result, copyValue(element))
+		}
+		return result
+	default:
+		return value
+	}
+}
@@ -0,0 +1,55 @@
+package azureresourcemanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadParameters tests the functions [LoadParameters()] and all the methods called by them
+func TestLoadParameters(t *testing.T) {
+	invalidPath := filepath.Join(t.TempDir(), "invalid.parameters.json")
+	require.NoError(t, os.WriteFile(invalidPath, []byte(`{"storageName": "orders"}`), 0o600))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "deployment parameters file",
+			path: filepath.Join(parametersFixturePath, "azuredeploy.parameters.json"),
+			want: map[string]interface{}{"storagename": "orders", "environment": "prod"},
+		},
+		{
+			name:    "parameters file without parameters",
+			path:    invalidPath,
+			wantErr: true,
+		},
+		{
+			name:    "parameters file not found",
+			path:    filepath.Join(parametersFixturePath, "missing.parameters.json"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadParameters(nil, tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestParametersFile tests the path of the parameters file of a template
+func TestParametersFile(t *testing.T) {
+	require.Equal(t, filepath.Join("templates", "azuredeploy.parameters.json"),
+		ParametersFile(filepath.Join("templates", "azuredeploy.json")))
+}
@@ -0,0 +1,434 @@
+package azureresourcemanager
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	linesKey     = "_kics_lines"
+	linesPrefix  = "_kics_"
+	defaultLines = "_kics__default"
+	// deploymentType is the type of the resources that deploy nested and linked templates
+	deploymentType = "Microsoft.Resources/deployments"
+	// maxLinkedDepth is the maximum depth of the linked templates resolved, which avoids linked templates
+	// that link themselves
+	maxLinkedDepth = 5
+)
+
+// resolvedSections are the sections of a template whose expressions are evaluated, the parameters and variables
+// sections are kept as they are since they are the input of the evaluation
+var resolvedSections = []string{"resources", "outputs"}
+
+// template keeps the parameters and variables of a template used to evaluate its expressions
+type template struct {
+	fileSystem filesystem.FileSystem
+	path       string
+	depth      int
+	parameters map[string]interface{}
+	passed     map[string]interface{}
+	variables  map[string]interface{}
+	values     map[string]interface{}
+	evaluating map[string]bool
+}
+
+// Resolve evaluates the template expressions of the resources and outputs of an ARM template, replacing them
+// by their value, and removes the resources whose condition is false. The parameters get the values of the
+// parameters file of the template, or their default values, and the linked templates referenced by a relative
+// path are loaded into the template property of their deployments. The parameters files and linked templates are
+// read from the FileSystem passed, which does not read the files outside the scanned paths. The expressions that
+// can not be evaluated, such as the ones depending on the resource group or on other resources, are kept
+func Resolve(fileSystem filesystem.FileSystem, document model.Document, path string) model.Document {
+	if !IsTemplate(document) {
+		return document
+	}
+	// handle panic during resolve process
+	defer func() {
+		if err := recover(); err != nil {
+			log.Warn().Msgf("Recovered from panic during resolve of ARM template %s: %v", path, err)
+		}
+	}()
+
+	fileSystem = filesystem.Get(fileSystem)
+	parameters := make(map[string]interface{})
+	parametersFile := ParametersFile(path)
+	if _, err := fileSystem.Stat(parametersFile); path != "" && err == nil {
+		if parameters, err = LoadParameters(fileSystem, parametersFile); err != nil {
+			log.Warn().Msgf("Failed to load ARM parameters file %s: %s", parametersFile, err)
+		}
+	}
+	newTemplate(fileSystem, document, path, parameters, 0).resolveTemplate(document, getLines(document))
+	return document
+}
+
+// IsTemplate returns true if the document is an ARM template, which has resources and declares its content version
+func IsTemplate(document map[string]interface{}) bool {
+	if _, ok := document["contentVersion"]; !ok {
+		return false
+	}
+	switch document["resources"].(type) {
+	case []interface{}, map[string]interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Evaluator evaluates template expressions with the parameters and variables of a template, it is used by the
+// parsers of the languages compiled into ARM templates, such as Bicep
+type Evaluator struct {
+	template *template
+}
+
+// NewEvaluator creates an Evaluator for the parameters and variables sections of document, the parameters passed
+// take precedence over the default values of the parameters
+func NewEvaluator(document map[string]interface{}, parameters map[string]interface{}) *Evaluator {
+	return &Evaluator{
+		template: newTemplate(nil, document, "", parameters, 0),
+	}
+}
+
+// Evaluate returns value with its template expressions evaluated and without line information,
+// it is not known when any of its expressions can not be evaluated
+func (e *Evaluator) Evaluate(value interface{}) (interface{}, bool) {
+	return e.template.value(value)
+}
+
+// newTemplate creates a template with the parameters passed, parameter and variable names are case insensitive,
+// its linked templates are read from fileSystem
+func newTemplate(fileSystem filesystem.FileSystem, document map[string]interface{}, path string,
+	passed map[string]interface{}, depth int) *template {
+	t := &template{
+		fileSystem: filesystem.Get(fileSystem),
+		path:       path,
+		depth:      depth,
+		parameters: make(map[string]interface{}),
+		passed:     make(map[string]interface{}),
+		variables:  make(map[string]interface{}),
+		values:     make(map[string]interface{}),
+		evaluating: make(map[string]bool),
+	}
+	for name, definition := range getMap(document["parameters"]) {
+		t.parameters[strings.ToLower(name)] = definition
+	}
+	for name, value := range passed {
+		t.passed[strings.ToLower(name)] = value
+	}
+	for name, definition := range getMap(document["variables"]) {
+		t.variables[strings.ToLower(name)] = definition
+	}
+	return t
+}
+
+// resolveTemplate evaluates the expressions of the resources and outputs of a template
+func (t *template) resolveTemplate(document map[string]interface{}, lines map[string]*model.LineObject) {
+	for _, section := range resolvedSections {
+		if value, ok := document[section]; ok {
+			document[section] = t.resolveChild(value, lines, section)
+		}
+	}
+}
+
+// resolveChild evaluates the expressions of the value of a key of a map with the given lines, the values of
+// the expressions get the line of the key
+func (t *template) resolveChild(child interface{}, lines map[string]*model.LineObject, key string) interface{} {
+	switch v := child.(type) {
+	case string:
+		value, _ := t.resolveString(v, lines)
+		switch resolved := value.(type) {
+		case map[string]interface{}:
+			resolved[linesKey] = generatedLines(resolved, lineOf(lines, key))
+		case []interface{}:
+			if lines != nil && lines[linesPrefix+key] != nil {
+				lines[linesPrefix+key].Arr = elementsLines(resolved, lineOf(lines, key))
+			}
+		}
+		return value
+	case map[string]interface{}:
+		t.resolveMap(v, getLines(v))
+		return v
+	case []interface{}:
+		var lineObject *model.LineObject
+		if lines != nil {
+			lineObject = lines[linesPrefix+key]
+		}
+		return t.resolveArray(v, lineObject, key == "resources")
+	default:
+		return child
+	}
+}
+
+// resolveString evaluates the expression of a string, lines is the line information of the string when it is an
+// element of an array, which is replaced by the line information of the map the expression may return
+func (t *template) resolveString(value string, lines map[string]*model.LineObject) (
+	interface{}, map[string]*model.LineObject) {
+	if !isExpression(value) && !isEscaped(value) {
+		return value, lines
+	}
+	resolved, known := t.evaluateString(value)
+	if !known {
+		return value, lines
+	}
+	if m, ok := resolved.(map[string]interface{}); ok {
+		return m, generatedLines(m, lineOf(lines, ""))
+	}
+	return resolved, lines
+}
+
+// resolveMap evaluates the expressions of the values of a map in place, the names of the resources are kept
+// as they are written since they identify the resources in the results
+func (t *template) resolveMap(value map[string]interface{}, lines map[string]*model.LineObject) {
+	resourceType, isResource := value["type"].(string)
+	if _, ok := value["apiVersion"]; !ok {
+		isResource = false
+	}
+	switch {
+	case isResource && strings.EqualFold(resourceType, deploymentType):
+		t.resolveDeployment(value, lines)
+	case isResource:
+		t.resolveEntries(value, lines, "name")
+	default:
+		t.resolveEntries(value, lines)
+	}
+}
+
+// resolveEntries evaluates the expressions of the values of a map, except the values of the skipped keys
+func (t *template) resolveEntries(value map[string]interface{}, lines map[string]*model.LineObject, skipped ...string) {
+	for key, child := range value {
+		if key == linesKey || utils.Contains(key, skipped) {
+			continue
+		}
+		value[key] = t.resolveChild(child, lines, key)
+	}
+}
+
+// resolveArray evaluates the expressions of the elements of an array, whose line information is kept by
+// the line information of the array. The resources whose condition is false are removed
+func (t *template) resolveArray(value []interface{}, lineObject *model.LineObject, resources bool) []interface{} {
+	var elementsLinesInfo []map[string]*model.LineObject
+	if lineObject != nil && len(lineObject.Arr) == len(value) {
+		elementsLinesInfo = lineObject.Arr
+	}
+	resolved := make([]interface{}, 0, len(value))
+	resolvedLines := make([]map[string]*model.LineObject, 0, len(value))
+	for i, element := range value {
+		var lines map[string]*model.LineObject
+		if elementsLinesInfo != nil {
+			lines = elementsLinesInfo[i]
+		}
+		switch v := element.(type) {
+		case string:
+			element, lines = t.resolveString(v, lines)
+		case map[string]interface{}:
+			t.resolveMap(v, lines)
+		case []interface{}:
+			element = t.resolveArray(v, nil, false)
+		}
+		if resources && isDisabled(element) {
+			log.Debug().Msgf("ARM resource %v is not deployed since its condition is false", getMap(element)["name"])
+			continue
+		}
+		resolved = append(resolved, element)
+		resolvedLines = append(resolvedLines, lines)
+	}
+	if elementsLinesInfo != nil {
+		lineObject.Arr = resolvedLines
+	}
+	return resolved
+}
+
+// isDisabled returns true if the resource has a condition that is false
+func isDisabled(resource interface{}) bool {
+	condition, ok := getMap(resource)["condition"].(bool)
+	return ok && !condition
+}
+
+// resolveDeployment evaluates the expressions of a deployment and its nested or linked template, the nested
+// templates with the outer scope use the parameters and variables of the parent template, while the nested
+// templates with the inner scope and the linked templates are evaluated with the parameters passed to them
+func (t *template) resolveDeployment(deployment map[string]interface{}, lines map[string]*model.LineObject) {
+	properties, ok := deployment["properties"].(map[string]interface{})
+	if !ok {
+		t.resolveEntries(deployment, lines, "name")
+		return
+	}
+	t.resolveEntries(deployment, lines, "name", "properties")
+
+	propertiesLines := getLines(properties)
+	scope, _ := getMap(properties["expressionEvaluationOptions"])["scope"].(string)
+	nested, hasTemplate := properties["template"].(map[string]interface{})
+	if hasTemplate && !strings.EqualFold(scope, "inner") {
+		t.resolveEntries(properties, propertiesLines)
+		return
+	}
+	t.resolveEntries(properties, propertiesLines, "template")
+
+	parameters := t.linkedParameters(getMap(properties["parametersLink"]))
+	for name, value := range passedParameters(getMap(properties["parameters"])) {
+		parameters[name] = value
+	}
+	if hasTemplate {
+		newTemplate(t.fileSystem, nested, t.path, parameters, t.depth).resolveTemplate(nested, getLines(nested))
+		return
+	}
+	t.resolveLinked(properties, propertiesLines, parameters)
+}
+
+// resolveLinked loads the linked template of a deployment, referenced by a relative path, into its template
+// property. The linked template is not written in the file, so all its lines are the line of the template link
+func (t *template) resolveLinked(properties map[string]interface{}, lines map[string]*model.LineObject,
+	parameters map[string]interface{}) {
+	path := t.linkedPath(getMap(properties["templateLink"]))
+	if path == "" {
+		return
+	}
+	if t.depth >= maxLinkedDepth {
+		log.Debug().Msgf("ARM linked template %s is not resolved since the maximum depth was reached", path)
+		return
+	}
+	content, err := t.fileSystem.ReadFile(filepath.Clean(path))
+	if err != nil {
+		log.Debug().Msgf("Failed to read ARM linked template %s: %s", path, err)
+		return
+	}
+	var linked map[string]interface{}
+	if err := json.Unmarshal(content, &linked); err != nil || !IsTemplate(linked) {
+		log.Debug().Msgf("Failed to parse ARM linked template %s", path)
+		return
+	}
+
+	newTemplate(t.fileSystem, linked, path, parameters, t.depth+1).resolveTemplate(linked, nil)
+	line := lineOf(lines, "templateLink")
+	linked[linesKey] = generatedLines(linked, line)
+	properties["template"] = linked
+	if lines != nil {
+		lines[linesPrefix+"template"] = &model.LineObject{Line: line, Arr: []map[string]*model.LineObject{}}
+	}
+}
+
+// linkedParameters returns the values of the parameters file linked to a deployment by a relative path
+func (t *template) linkedParameters(link map[string]interface{}) map[string]interface{} {
+	path := t.linkedPath(link)
+	if path == "" {
+		return make(map[string]interface{})
+	}
+	parameters, err := LoadParameters(t.fileSystem, path)
+	if err != nil {
+		log.Debug().Msgf("Failed to load ARM linked parameters file %s: %s", path, err)
+		return make(map[string]interface{})
+	}
+	return parameters
+}
+
+// linkedPath returns the path of a linked template or parameters file referenced by a path relative to
+// the template, the links with an absolute URI or an expression that could not be evaluated are not loaded
+func (t *template) linkedPath(link map[string]interface{}) string {
+	reference, ok := link["relativePath"].(string)
+	if !ok {
+		reference, ok = link["uri"].(string)
+	}
+	if !ok || reference == "" || t.path == "" || isExpression(reference) || strings.Contains(reference, "://") {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(t.path), filepath.FromSlash(reference))
+}
+
+// LinkedFiles returns the paths of the files a template depends on, which are its parameters file and its
+// linked templates and parameters files, including the ones linked by its linked templates
+func LinkedFiles(document map[string]interface{}, path string) []string {
+	if !IsTemplate(document) {
+		return nil
+	}
+	t := &template{path: path}
+	return append([]string{ParametersFile(path)}, t.linkedFiles(document["resources"])...)
+}
+
+// linkedFiles returns the paths of the files linked by the deployments of the resources of a template
+func (t *template) linkedFiles(value interface{}) []string {
+	files := make([]string, 0)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if resourceType, ok := v["type"].(string); ok && strings.EqualFold(resourceType, deploymentType) {
+			properties := getMap(v["properties"])
+			if linked := t.linkedPath(getMap(properties["parametersLink"])); linked != "" {
+				files = append(files, linked)
+			}
+			if linked := t.linkedPath(getMap(properties["templateLink"])); linked != "" {
+				files = append(files, linked)
+				linkedTemplate := &template{path: linked}
+				return append(files, linkedTemplate.linkedFiles(getMap(properties["template"])["resources"])...)
+			}
+		}
+		for key, child := range v {
+			if key != linesKey {
+				files = append(files, t.linkedFiles(child)...)
+			}
+		}
+	case []interface{}:
+		for _, element := range v {
+			files = append(files, t.linkedFiles(element)...)
+		}
+	}
+	return files
+}
+
+// lineOf returns the line of a key of a map with the given lines, or the line of the map if the key has no line
+func lineOf(lines map[string]*model.LineObject, key string) int {
+	if lines == nil {
+		return 0
+	}
+	if line, ok := lines[linesPrefix+key]; ok && line != nil {
+		return line.Line
+	}
+	if line, ok := lines[defaultLines]; ok && line != nil {
+		return line.Line
+	}
+	return 0
+}
+
+// getLines returns the line information of a map
+func getLines(value interface{}) map[string]*model.LineObject {
+	lines, _ := getMap(value)[linesKey].(map[string]*model.LineObject)
+	return lines
+}
+
+// generatedLines returns the line information of a map generated by the evaluation of an expression, or loaded
+// from a linked template, whose keys are all at the line given, the maps it contains get their line information
+func generatedLines(value map[string]interface{}, line int) map[string]*model.LineObject {
+	lines := map[string]*model.LineObject{
+		defaultLines: {Line: line, Arr: []map[string]*model.LineObject{}},
+	}
+	for key, child := range value {
+		if key == linesKey {
+			continue
+		}
+		lines[linesPrefix+key] = &model.LineObject{Line: line, Arr: elementsLines(child, line)}
+		if m, ok := child.(map[string]interface{}); ok {
+			m[linesKey] = generatedLines(m, line)
+		}
+	}
+	return lines
+}
+
+// elementsLines returns the line information of the elements of a generated array, all at the line given
+func elementsLines(value interface{}, line int) []map[string]*model.LineObject {
+	list, ok := value.([]interface{})
+	if !ok {
+		return []map[string]*model.LineObject{}
+	}
+	lines := make([]map[string]*model.LineObject, 0, len(list))
+	for _, element := range list {
+		if m, ok := element.(map[string]interface{}); ok {
+			lines = append(lines, generatedLines(m, line))
+			continue
+		}
+		lines = append(lines, map[string]*model.LineObject{defaultLines: {Line: line}})
+	}
+	return lines
+}
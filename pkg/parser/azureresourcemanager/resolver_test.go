@@ -0,0 +1,156 @@
+package azureresourcemanager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var parametersFixturePath = filepath.FromSlash("../../../test/fixtures/test_arm_parameters")
+
+func parseTemplate(t *testing.T, path string) model.Document {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var document model.Document
+	require.NoError(t, json.Unmarshal(content, &document))
+	return document
+}
+
+// TestResolve tests the functions [Resolve()] and all the methods called by them
+func TestResolve(t *testing.T) {
+	path := filepath.Join(parametersFixturePath, "azuredeploy.json")
+	got := Resolve(nil, parseTemplate(t, path), path)
+
+	resources, ok := got["resources"].([]interface{})
+	require.True(t, ok)
+	// the scratch storage account is not deployed in prod, the environment of the parameters file
+	require.Len(t, resources, 2)
+
+	storage := getMap(resources[0])
+	require.Equal(t, "[concat(variables('prefix'), parameters('storageName'))]", storage["name"])
+	require.Equal(t, "[resourceGroup().location]", storage["location"])
+	require.Equal(t, map[string]interface{}{"owner": "platform"}, copyValue(storage["tags"]))
+	require.Equal(t, map[string]interface{}{
+		"supportsHttpsTrafficOnly": false,
+		"minimumTlsVersion":        "TLS1_2",
+	}, copyValue(storage["properties"]))
+
+	properties := getMap(getMap(resources[1])["properties"])
+	require.Equal(t, map[string]interface{}{
+		"name":      map[string]interface{}{"value": "stprodlogs"},
+		"httpsOnly": map[string]interface{}{"value": false},
+	}, copyValue(properties["parameters"]))
+	linked := getMap(getMap(properties["template"])["resources"].([]interface{})[0])
+	require.Equal(t, map[string]interface{}{"supportsHttpsTrafficOnly": false}, copyValue(linked["properties"]))
+
+	// the parameters and variables are kept since they are the input of the evaluation
+	require.Equal(t, "[equals(parameters('environment'), 'prod')]", getMap(got["variables"])["isProd"])
+}
+
+// TestResolve_Lines tests that the values of the expressions and the linked templates get the line of their keys
+func TestResolve_Lines(t *testing.T) {
+	lines := map[string]*model.LineObject{
+		defaultLines:               {Line: 1},
+		linesPrefix + "resources":  {Line: 2},
+		linesPrefix + "parameters": {Line: 3},
+	}
+	document := model.Document{
+		"contentVersion": "1.0.0.0",
+		"parameters": map[string]interface{}{
+			"tags": map[string]interface{}{
+				"type":         "object",
+				"defaultValue": map[string]interface{}{"owner": "platform"},
+			},
+		},
+		"resources": map[string]interface{}{
+			"storage": map[string]interface{}{
+				"tags": "[parameters('tags')]",
+				linesKey: map[string]*model.LineObject{
+					defaultLines:         {Line: 4},
+					linesPrefix + "tags": {Line: 5},
+				},
+			},
+		},
+		linesKey: lines,
+	}
+	got := Resolve(nil, document, "")
+
+	tags := getMap(getMap(getMap(got["resources"])["storage"])["tags"])
+	require.Equal(t, "platform", tags["owner"])
+	require.Equal(t, 5, getLines(tags)[linesPrefix+"owner"].Line)
+}
+
+// TestResolve_NotTemplate tests that documents that are not ARM templates are not changed
+func TestResolve_NotTemplate(t *testing.T) {
+	document := model.Document{
+		"resources": []interface{}{
+			map[string]interface{}{"name": "[parameters('name')]"},
+		},
+	}
+	got := Resolve(nil, document, "")
+	require.Equal(t, "[parameters('name')]", getMap(got["resources"].([]interface{})[0])["name"])
+}
+
+// TestResolve_OutsideRoots tests that the linked templates and parameters files outside the roots of the
+// FileSystem are not loaded
+func TestResolve_OutsideRoots(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		filepath.Join("outside", "storage.json"): `{"contentVersion": "1.0.0.0", "parameters": {"owner": {"type": "string"}},
+			"resources": [{"type": "Microsoft.Storage/storageAccounts", "apiVersion": "2021-09-01", "name": "storage",
+			"tags": {"owner": "[parameters('owner')]"}}]}`,
+		filepath.Join("outside", "storage.parameters.json"): `{"parameters": {"owner": {"value": "outside"}}}`,
+		filepath.Join("project", "azuredeploy.json"): `{"contentVersion": "1.0.0.0", "resources": [{"name": "linked",
+			"type": "Microsoft.Resources/deployments", "apiVersion": "2021-04-01", "properties": {
+			"templateLink": {"relativePath": "../outside/storage.json"},
+			"parametersLink": {"uri": "../outside/storage.parameters.json"}}}]}`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	path := filepath.Join(dir, "project", "azuredeploy.json")
+
+	tests := []struct {
+		name       string
+		fileSystem filesystem.FileSystem
+		want       interface{}
+	}{
+		{
+			name:       "unrestricted",
+			fileSystem: &filesystem.OS{},
+			want:       "outside",
+		},
+		{
+			name:       "outside the roots",
+			fileSystem: filesystem.NewOS([]string{filepath.Join(dir, "project")}),
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resolve(tt.fileSystem, parseTemplate(t, path), path)
+			properties := getMap(getMap(got["resources"].([]interface{})[0])["properties"])
+			var owner interface{}
+			if linked, ok := getMap(properties["template"])["resources"].([]interface{}); ok {
+				owner = getMap(getMap(linked[0])["tags"])["owner"]
+			}
+			require.Equal(t, tt.want, owner)
+		})
+	}
+}
+
+// TestLinkedFiles tests the functions [LinkedFiles()] and all the methods called by them
+func TestLinkedFiles(t *testing.T) {
+	path := filepath.Join(parametersFixturePath, "azuredeploy.json")
+	require.Equal(t, []string{
+		filepath.Join(parametersFixturePath, "azuredeploy.parameters.json"),
+		filepath.Join(parametersFixturePath, "nested", "storage.json"),
+	}, LinkedFiles(parseTemplate(t, path), path))
+	require.Empty(t, LinkedFiles(model.Document{"resources": []interface{}{}}, path))
+}
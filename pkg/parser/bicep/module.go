@@ -0,0 +1,341 @@
+package bicep
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser/azureresourcemanager"
+	"github.com/Checkmarx/kics/v2/pkg/parser/bicep/antlr/parser"
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// deploymentType is the type of the ARM resources Bicep modules are compiled into
+	deploymentType = "Microsoft.Resources/deployments"
+	// deploymentAPIVersion is the api version of the deployments generated for the modules
+	deploymentAPIVersion = "2022-09-01"
+	// maxModuleDepth is the maximum depth of the modules resolved, which avoids modules that reference themselves
+	maxModuleDepth = 5
+)
+
+// interpolationRegex matches the expressions of an interpolated string, ex: ${prefix} in '${prefix}-logs'
+var interpolationRegex = regexp.MustCompile(`\$\{(.*?)\}`)
+
+// VisitModuleDecl collects a module declaration, which is resolved after the statements of the file are visited
+func (s *BicepVisitor) VisitModuleDecl(ctx *parser.ModuleDeclContext) interface{} {
+	module := map[string]interface{}{}
+
+	object := ctx.Object()
+	if object == nil && ctx.IfCondition() != nil {
+		object = ctx.IfCondition().Object()
+	}
+	if object != nil {
+		if properties, ok := object.Accept(s).(map[string]interface{}); ok {
+			for key, val := range properties {
+				module[key] = val
+			}
+		}
+	}
+
+	module["identifier"] = checkAcceptAntlrString(ctx.Identifier(), s)
+	module["path"] = checkAcceptAntlrString(ctx.InterpString(), s)
+	module["line"] = ctx.GetStart().GetLine()
+
+	s.moduleList = append(s.moduleList, module)
+
+	return nil
+}
+
+// resolveModules appends a deployment resource to the document for each local module declared, the deployment
+// has the module parsed with its params as its template, as the module is compiled by Bicep
+func resolveModules(fileSystem filesystem.FileSystem, doc model.Document, modules []interface{}, file string,
+	evaluator *azureresourcemanager.Evaluator, depth int) {
+	resources, _ := doc["resources"].([]interface{})
+	for _, module := range modules {
+		declaration, ok := module.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if deployment := resolveModule(fileSystem, declaration, file, evaluator, depth); deployment != nil {
+			resources = append(resources, deployment)
+		}
+	}
+	doc["resources"] = resources
+}
+
+// resolveModule parses the file of a module with the params bound to its parameters, the modules of a registry
+// or template spec, ex: 'br:registry.azurecr.io/storage:v1', are not resolved, nor the modules fileSystem can not read
+func resolveModule(fileSystem filesystem.FileSystem, module map[string]interface{}, file string,
+	evaluator *azureresourcemanager.Evaluator, depth int) map[string]interface{} {
+	modulePath := modulePath(module, file)
+	if modulePath == "" {
+		return nil
+	}
+	if depth >= maxModuleDepth {
+		log.Debug().Msgf("Bicep module %s not resolved, maximum depth reached", modulePath)
+		return nil
+	}
+
+	passed := make(map[string]interface{})
+	parameters := make(map[string]interface{})
+	params, _ := armValue(module["params"]).(map[string]interface{})
+	for name, value := range params {
+		if name == kicsLines {
+			continue
+		}
+		resolved, known := evaluator.Evaluate(value)
+		if !known {
+			parameters[name] = map[string]interface{}{"value": value}
+			continue
+		}
+		passed[name] = resolved
+		parameters[name] = map[string]interface{}{"value": resolved}
+	}
+
+	template, err := parseBicepFile(fileSystem, modulePath, passed, unknownParameters(params, passed), depth+1)
+	if err != nil {
+		log.Debug().Msgf("Failed to parse Bicep module %s: %s", modulePath, err)
+		return nil
+	}
+
+	name, ok := module["name"]
+	if !ok {
+		name = module["identifier"]
+	}
+	deployment := map[string]interface{}{
+		"identifier": module["identifier"],
+		"type":       deploymentType,
+		"apiVersion": deploymentAPIVersion,
+		"name":       armValue(name),
+		"properties": map[string]interface{}{
+			"expressionEvaluationOptions": map[string]interface{}{"scope": "inner"},
+			"mode":                        "Incremental",
+			"parameters":                  parameters,
+			"template":                    map[string]interface{}(template),
+		},
+	}
+	for _, key := range []string{"scope", "dependsOn"} {
+		if value, ok := module[key]; ok {
+			deployment[key] = armValue(value)
+		}
+	}
+	line, _ := module["line"].(int)
+	setLines(deployment, line)
+
+	return deployment
+}
+
+// modulePath returns the path of the file of a local module, relative paths are relative to the file declaring it
+func modulePath(module map[string]interface{}, file string) string {
+	path, _ := module["path"].(string)
+	if path == "" || strings.Contains(path, ":") || strings.Contains(path, "${") {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(file), filepath.FromSlash(path))
+}
+
+// unknownParameters returns the names of the params of a module whose value could not be evaluated
+func unknownParameters(params, passed map[string]interface{}) []string {
+	unknown := make([]string, 0)
+	for name := range params {
+		if _, ok := passed[name]; !ok && name != kicsLines {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// bindParameters sets the values passed to a file as the default values of its parameters, the parameters whose
+// value is passed but not known lose their default value, since it is not the one deployed
+func bindParameters(doc model.Document, passed map[string]interface{}, unknown []string) {
+	parameters, _ := doc["parameters"].(map[string]interface{})
+	for name, definition := range parameters {
+		param, ok := definition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok := passed[name]; ok {
+			param["defaultValue"] = value
+			continue
+		}
+		for _, unknownName := range unknown {
+			if unknownName == name {
+				delete(param, "defaultValue")
+			}
+		}
+	}
+}
+
+// evaluateResources replaces the template expressions of the resources of a module by their value, since the
+// queries can only resolve the parameters of the main file, the names of the resources are kept as they are
+func evaluateResources(value interface{}, evaluator *azureresourcemanager.Evaluator) interface{} {
+	switch v := value.(type) {
+	case string:
+		if resolved, known := evaluator.Evaluate(armValue(v)); known {
+			return resolved
+		}
+		return v
+	case map[string]interface{}:
+		_, isResource := v["apiVersion"]
+		for key, child := range v {
+			if key == kicsLines || (isResource && key == "name") {
+				continue
+			}
+			v[key] = evaluateResources(child, evaluator)
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = evaluateResources(element, evaluator)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// evaluatorOf returns the Evaluator of the parameters and variables of a document, the variables are declared
+// with their value in the value property
+func evaluatorOf(doc model.Document, passed map[string]interface{}) *azureresourcemanager.Evaluator {
+	variables := make(map[string]interface{})
+	if declared, ok := doc["variables"].(map[string]interface{}); ok {
+		for name, definition := range declared {
+			if variable, ok := definition.(map[string]interface{}); ok {
+				variables[name] = armValue(variable["value"])
+			}
+		}
+	}
+	parameters := make(map[string]interface{})
+	if declared, ok := doc["parameters"].(map[string]interface{}); ok {
+		for name, definition := range declared {
+			if param, ok := definition.(map[string]interface{}); ok {
+				if defaultValue, ok := param["defaultValue"]; ok {
+					parameters[name] = map[string]interface{}{"defaultValue": armValue(defaultValue)}
+				}
+			}
+		}
+	}
+	return azureresourcemanager.NewEvaluator(map[string]interface{}{
+		"parameters": parameters,
+		"variables":  variables,
+	}, passed)
+}
+
+// armValue converts the values visited into template expressions, the function calls are converted into their
+// expression, ex: uniqueString(name), and the interpolated strings into concat calls
+func armValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string][]interface{}:
+		return "[" + parseFunctionCall(v) + "]"
+	case string:
+		return armString(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[key] = armValue(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			result = append(result, armValue(element))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// armString converts an interpolated string, ex: '${parameters('prefix')}-logs', into a concat expression and
+// wraps the parameter and variable references that are not enclosed in brackets
+func armString(value string) string {
+	body := strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if len(body) < 2 || !strings.HasPrefix(body, "'") || !strings.HasSuffix(body, "'") || !strings.Contains(body, "${") {
+		if !strings.HasPrefix(value, "[") && isParameter(value) {
+			return "[" + value + "]"
+		}
+		return value
+	}
+	body = body[1 : len(body)-1]
+	args := make([]string, 0)
+	last := 0
+	for _, match := range interpolationRegex.FindAllStringSubmatchIndex(body, -1) {
+		expression := strings.TrimSuffix(strings.TrimPrefix(body[match[2]:match[3]], "["), "]")
+		args = append(args, quote(body[last:match[0]]), expression)
+		last = match[1]
+	}
+	args = append(args, quote(body[last:]))
+	return "[concat(" + strings.Join(args, ", ") + ")]"
+}
+
+// quote returns a string literal of a template expression
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// setLines sets the line of all the keys of a generated value, such as the deployments of the modules
+// and their templates, which are all at the line of the module declaration
+func setLines(value interface{}, line int) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		lines := map[string]interface{}{kicsPrefix + "_default": map[string]interface{}{kicsLine: line}}
+		for key, child := range v {
+			if key == kicsLines {
+				continue
+			}
+			setLines(child, line)
+			keyLines := map[string]interface{}{kicsLine: line}
+			if list, ok := child.([]interface{}); ok {
+				arr := make([]interface{}, 0, len(list))
+				for range list {
+					arr = append(arr, map[string]interface{}{kicsPrefix + "_default": map[string]interface{}{kicsLine: line}})
+				}
+				keyLines[kicsArray] = arr
+			}
+			lines[kicsPrefix+key] = keyLines
+		}
+		v[kicsLines] = lines
+	case []interface{}:
+		for _, element := range v {
+			setLines(element, line)
+		}
+	}
+}
+
+// LinkedFiles returns the files a Bicep file depends on, which are the parameters files next to it, as a pattern,
+// and the files of its local modules
+func LinkedFiles(path string) []string {
+	files := []string{filepath.Join(filepath.Dir(path), "*"+parametersFileExtension)}
+	return append(files, moduleFiles(path, 0)...)
+}
+
+// moduleFiles returns the files of the local modules declared by a Bicep file and by its modules
+func moduleFiles(path string, depth int) []string {
+	files := make([]string, 0)
+	if depth >= maxModuleDepth {
+		return files
+	}
+	stream, err := antlr.NewFileStream(path)
+	if err != nil {
+		return files
+	}
+	_, modules, err := visitBicep(stream)
+	if err != nil {
+		return files
+	}
+	for _, module := range modules {
+		declaration, ok := module.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if file := modulePath(declaration, path); file != "" {
+			files = append(files, file)
+			files = append(files, moduleFiles(file, depth+1)...)
+		}
+	}
+	return files
+}
@@ -0,0 +1,88 @@
+package bicep
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// parametersFileExtension is the extension of the Bicep parameters files, ex: main.bicepparam
+const parametersFileExtension = ".bicepparam"
+
+var (
+	// usingRegex matches the using declaration of a parameters file, which references the Bicep file it is for
+	usingRegex = regexp.MustCompile(`(?m)^[ \t]*using[ \t]+'([^']*)'.*$`)
+	// assignmentRegex matches the parameter assignments of a parameters file, which have no type
+	assignmentRegex = regexp.MustCompile(`(?m)^([ \t]*)param[ \t]+(\w+)[ \t]*=`)
+)
+
+// ParametersFile returns the path of the parameters file whose using declaration references the Bicep file,
+// the parameters file with the same name as the Bicep file is preferred, an empty path is returned if there is none.
+// The parameters files are searched in fileSystem
+func ParametersFile(fileSystem filesystem.FileSystem, path string) string {
+	fileSystem = filesystem.Get(fileSystem)
+	candidates, err := fileSystem.Glob(filepath.Join(filepath.Dir(path), "*"+parametersFileExtension))
+	if err != nil {
+		return ""
+	}
+	sameName := strings.TrimSuffix(path, filepath.Ext(path)) + parametersFileExtension
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i] == sameName && candidates[j] != sameName
+	})
+	for _, candidate := range candidates {
+		content, err := fileSystem.ReadFile(filepath.Clean(candidate))
+		if err != nil {
+			continue
+		}
+		using := usingRegex.FindSubmatch(content)
+		if using == nil {
+			continue
+		}
+		referenced := filepath.Join(filepath.Dir(candidate), filepath.FromSlash(string(using[1])))
+		if filepath.Clean(referenced) == filepath.Clean(path) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// LoadParameters reads the values of the parameters assigned by a parameters file, the values that depend on
+// anything else than the other parameters and variables of the file, such as getSecret calls, are not loaded.
+// The file is read from fileSystem
+func LoadParameters(fileSystem filesystem.FileSystem, path string) (map[string]interface{}, error) {
+	content, err := filesystem.Get(fileSystem).ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	// the using declaration is blanked and the assignments get a type, so the file is parsed as a Bicep file
+	// whose parameters have the values assigned as default values, the lines are kept
+	source := usingRegex.ReplaceAllString(string(content), "")
+	source = assignmentRegex.ReplaceAllString(source, "${1}param $2 any =")
+
+	doc, _, err := visitBicep(antlr.NewInputStream(source))
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := evaluatorOf(doc, nil)
+	values := make(map[string]interface{})
+	parameters, _ := doc["parameters"].(map[string]interface{})
+	for name, definition := range parameters {
+		param, ok := definition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		defaultValue, ok := param["defaultValue"]
+		if !ok {
+			continue
+		}
+		if value, known := evaluator.Evaluate(armValue(defaultValue)); known {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
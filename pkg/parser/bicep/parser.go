@@ -2,15 +2,19 @@ package bicep
 
 import (
 	"encoding/json"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/Checkmarx/kics/v2/pkg/parser/bicep/antlr/parser"
 	"github.com/antlr4-go/antlr/v4"
+	"github.com/rs/zerolog/log"
 )
 
 type Parser struct {
+	fileSystem filesystem.FileSystem
 }
 
 const kicsPrefix = "_kics_"
@@ -25,6 +29,7 @@ type BicepVisitor struct {
 	paramList    map[string]interface{}
 	varList      map[string]interface{}
 	resourceList []interface{}
+	moduleList   []interface{}
 }
 
 type JSONBicep struct {
@@ -42,7 +47,8 @@ func NewBicepVisitor() *BicepVisitor {
 	paramList := map[string]interface{}{}
 	varList := map[string]interface{}{}
 	resourceList := []interface{}{}
-	return &BicepVisitor{paramList: paramList, varList: varList, resourceList: resourceList}
+	moduleList := []interface{}{}
+	return &BicepVisitor{paramList: paramList, varList: varList, resourceList: resourceList, moduleList: moduleList}
 }
 
 func convertVisitorToJSONBicep(visitor *BicepVisitor) *JSONBicep {
@@ -190,13 +196,63 @@ func makeResourcesNestedStructure(jBicep *JSONBicep) []interface{} {
 	return filteredResources
 }
 
+// SetFileSystem sets the FileSystem the bicep files, their parameters files and their modules are read from
+func (p *Parser) SetFileSystem(fileSystem filesystem.FileSystem) {
+	p.fileSystem = fileSystem
+}
+
+// getFileSystem returns the FileSystem the bicep files are read from, the disk by default
+func (p *Parser) getFileSystem() filesystem.FileSystem {
+	return filesystem.Get(p.fileSystem)
+}
+
 // Parse - parses bicep to BicepVisitor template (json file)
 func (p *Parser) Parse(file string, _ []byte) ([]model.Document, []int, error) {
-	bicepVisitor := NewBicepVisitor()
-	stream, err := antlr.NewFileStream(file)
+	fileSystem := p.getFileSystem()
+	parameters := make(map[string]interface{})
+	if parametersFile := ParametersFile(fileSystem, file); parametersFile != "" {
+		var err error
+		if parameters, err = LoadParameters(fileSystem, parametersFile); err != nil {
+			log.Warn().Msgf("Failed to load Bicep parameters file %s: %s", parametersFile, err)
+		}
+	}
+
+	doc, err := parseBicepFile(fileSystem, file, parameters, nil, 0)
 	if err != nil {
 		return nil, nil, err
 	}
+
+	return []model.Document{doc}, nil, nil
+}
+
+// parseBicepFile parses a bicep file read from fileSystem with the values passed to its parameters, the local modules
+// it declares are parsed recursively, the expressions of the resources of the modules are evaluated with the values
+// of their params
+func parseBicepFile(fileSystem filesystem.FileSystem, file string, passed map[string]interface{}, unknown []string,
+	depth int) (model.Document, error) {
+	content, err := fileSystem.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+
+	doc, modules, err := visitBicep(antlr.NewInputStream(string(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	bindParameters(doc, passed, unknown)
+	evaluator := evaluatorOf(doc, passed)
+	if depth > 0 {
+		doc["resources"] = evaluateResources(doc["resources"], evaluator)
+	}
+	resolveModules(fileSystem, doc, modules, file, evaluator, depth)
+
+	return doc, nil
+}
+
+// visitBicep visits the statements of a bicep source and returns its template and the modules it declares
+func visitBicep(stream antlr.CharStream) (model.Document, []interface{}, error) {
+	bicepVisitor := NewBicepVisitor()
 	lexer := parser.NewbicepLexer(stream)
 
 	tokenStream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
@@ -227,7 +283,7 @@ func (p *Parser) Parse(file string, _ []byte) ([]model.Document, []int, error) {
 		return nil, nil, err
 	}
 
-	return []model.Document{doc}, nil, nil
+	return doc, bicepVisitor.moduleList, nil
 }
 
 func (s *BicepVisitor) VisitProgram(ctx *parser.ProgramContext) interface{} {
@@ -248,6 +304,9 @@ func (s *BicepVisitor) VisitStatement(ctx *parser.StatementContext) interface{}
 	if ctx.ResourceDecl() != nil {
 		return ctx.ResourceDecl().Accept(s)
 	}
+	if ctx.ModuleDecl() != nil {
+		return ctx.ModuleDecl().Accept(s)
+	}
 
 	return nil
 }
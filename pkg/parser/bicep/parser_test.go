@@ -2,10 +2,12 @@ package bicep
 
 import (
 	"encoding/json"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/stretchr/testify/require"
 )
@@ -1735,3 +1737,129 @@ func TestParseBicepFile(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_Parse_Modules(t *testing.T) {
+	parser := &Parser{}
+	filename := filepath.Join("..", "..", "..", "test", "fixtures", "test_bicep_modules", "main.bicep")
+
+	documents, _, err := parser.Parse(filename, nil)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+
+	parameters := documents[0]["parameters"].(map[string]interface{})
+	require.Equal(t, "prod", parameters["environment"].(map[string]interface{})["defaultValue"])
+
+	// the registry module is not resolved
+	resources := documents[0]["resources"].([]interface{})
+	require.Len(t, resources, 1)
+
+	deployment := resources[0].(map[string]interface{})
+	require.Equal(t, "Microsoft.Resources/deployments", deployment["type"])
+	require.Equal(t, 5, deployment[kicsLines].(map[string]interface{})[kicsPrefix+"type"].(map[string]interface{})[kicsLine])
+
+	template := deployment["properties"].(map[string]interface{})["template"].(map[string]interface{})
+	storage := template["resources"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "[parameters('name')]", storage["name"])
+	require.Equal(t, "westeurope", storage["location"])
+	require.Equal(t, map[string]interface{}{"environment": "prod"}, withoutLines(storage["tags"]))
+	require.Equal(t, false, storage["properties"].(map[string]interface{})["allowBlobPublicAccess"])
+}
+
+func TestParser_Parse_ModulesOutsideRoots(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		filepath.Join("outside", "storage.bicep"): "param name string\n" +
+			"resource storageAccount 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: name\n}\n",
+		filepath.Join("project", "main.bicep"): "module storage '../outside/storage.bicep' = {\n" +
+			"  name: 'storageDeployment'\n  params: {\n    name: 'stdata'\n  }\n}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	tests := []struct {
+		name       string
+		fileSystem filesystem.FileSystem
+		want       int
+	}{
+		{
+			name:       "unrestricted",
+			fileSystem: &filesystem.OS{},
+			want:       1,
+		},
+		{
+			name:       "outside the roots",
+			fileSystem: filesystem.NewOS([]string{filepath.Join(dir, "project")}),
+			want:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{}
+			parser.SetFileSystem(tt.fileSystem)
+			documents, _, err := parser.Parse(filepath.Join(dir, "project", "main.bicep"), nil)
+			require.NoError(t, err)
+			require.Len(t, documents[0]["resources"], tt.want)
+		})
+	}
+}
+
+func TestParametersFile(t *testing.T) {
+	dir := filepath.Join("..", "..", "..", "test", "fixtures", "test_bicep_modules")
+
+	require.Equal(t, filepath.Join(dir, "main.bicepparam"), ParametersFile(nil, filepath.Join(dir, "main.bicep")))
+	require.Equal(t, "", ParametersFile(nil, filepath.Join(dir, "modules", "storage.bicep")))
+}
+
+func TestLoadParameters(t *testing.T) {
+	parameters, err := LoadParameters(nil, filepath.Join("..", "..", "..", "test", "fixtures", "test_bicep_modules", "main.bicepparam"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"environment": "prod", "storageName": "stproddata"}, parameters)
+}
+
+func TestLinkedFiles(t *testing.T) {
+	dir := filepath.Join("..", "..", "..", "test", "fixtures", "test_bicep_modules")
+
+	require.Equal(t, []string{
+		filepath.Join(dir, "*.bicepparam"),
+		filepath.Join(dir, "modules", "storage.bicep"),
+	}, LinkedFiles(filepath.Join(dir, "main.bicep")))
+}
+
+func Test_armString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "literal", value: "westeurope", want: "westeurope"},
+		{name: "parameter reference", value: "parameters('name')", want: "[parameters('name')]"},
+		{name: "expression", value: "[parameters('name')]", want: "[parameters('name')]"},
+		{
+			name:  "interpolated string",
+			value: "['${parameters('prefix')}-logs']",
+			want:  "[concat('', parameters('prefix'), '-logs')]",
+		},
+		{
+			name:  "interpolated function call",
+			value: "'st${[uniqueString(resourceGroup().id)]}'",
+			want:  "[concat('st', uniqueString(resourceGroup().id), '')]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, armString(tt.value))
+		})
+	}
+}
+
+func withoutLines(value interface{}) interface{} {
+	result := map[string]interface{}{}
+	for key, child := range value.(map[string]interface{}) {
+		if key != kicsLines {
+			result[key] = child
+		}
+	}
+	return result
+}
@@ -0,0 +1,371 @@
+package cloudformation
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+)
+
+// subVariableRegex matches the variables of the string of a Fn::Sub function, ex: ${BucketName}
+var subVariableRegex = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// evaluate returns the value of an intrinsic function and its line information, when the function can not be
+// evaluated it is returned with its arguments resolved
+func (t *template) evaluate(function map[string]interface{}, name string, args interface{},
+	lines map[string]*model.LineObject) (interface{}, map[string]*model.LineObject, bool) {
+	if name == "Fn::If" {
+		return t.evaluateIf(function, args, lines)
+	}
+	if ref, ok := args.(string); ok && name == "Ref" && ref == noValue {
+		return nil, nil, false
+	}
+
+	// the arguments are evaluated first since they can be intrinsic functions
+	argument := t.arguments > 0
+	t.arguments++
+	resolvedArgs, argsLines, _ := t.resolve(args, getChildLines(args, lines, name))
+	t.arguments--
+	function[name] = resolvedArgs
+	setChildLines(resolvedArgs, argsLines, lines, name)
+
+	var value interface{}
+	var ok bool
+	switch name {
+	case "Ref":
+		value, ok = t.ref(resolvedArgs, argument)
+	case "Fn::Sub":
+		value, ok = t.sub(resolvedArgs)
+	case "Fn::Join":
+		value, ok = join(resolvedArgs)
+	case "Fn::Select":
+		value, ok = selectElement(resolvedArgs)
+	case "Fn::Split":
+		value, ok = split(resolvedArgs)
+	case "Fn::FindInMap":
+		value, ok = t.findInMap(resolvedArgs)
+	}
+	if !ok {
+		return function, lines, true
+	}
+	return value, valueLines(lines), true
+}
+
+// evaluateIf returns the value of the Fn::If branch selected by its condition, when the condition
+// can not be evaluated both branches are resolved and the function is kept
+func (t *template) evaluateIf(function map[string]interface{}, args interface{},
+	lines map[string]*model.LineObject) (interface{}, map[string]*model.LineObject, bool) {
+	argsLines := getChildLines(args, lines, "Fn::If")
+	arguments, ok := args.([]interface{})
+	if !ok || len(arguments) != 3 {
+		return function, lines, true
+	}
+	condition, _ := arguments[0].(string)
+	value, known := t.condition(condition)
+	if !known {
+		resolvedArgs, resolvedLines, _ := t.resolve(args, argsLines)
+		function["Fn::If"] = resolvedArgs
+		setChildLines(resolvedArgs, resolvedLines, lines, "Fn::If")
+		return function, lines, true
+	}
+
+	branch := 2
+	if value {
+		branch = 1
+	}
+	var branchLines map[string]*model.LineObject
+	if elementsLines := getElementsLines(argsLines, len(arguments)); elementsLines != nil {
+		branchLines = elementsLines[branch]
+	}
+	if list, isList := getList(arguments[branch]); isList {
+		return t.resolve(list, branchLines)
+	}
+	return t.resolve(arguments[branch], branchLines)
+}
+
+// condition returns the value of a condition of the template and true if it could be evaluated
+func (t *template) condition(name string) (value, known bool) {
+	if value, ok := t.evaluated[name]; ok {
+		return value, true
+	}
+	definition, ok := t.conditions[name]
+	if !ok || t.evaluating[name] {
+		return false, false
+	}
+	t.evaluating[name] = true
+	value, known = t.evaluateCondition(definition)
+	delete(t.evaluating, name)
+	if known {
+		t.evaluated[name] = value
+	}
+	return value, known
+}
+
+// evaluateCondition returns the value of a condition function and true if it could be evaluated
+func (t *template) evaluateCondition(definition interface{}) (value, known bool) {
+	if v, ok := definition.(bool); ok {
+		return v, true
+	}
+	function, ok := definition.(map[string]interface{})
+	if !ok {
+		return false, false
+	}
+	name, args := "", interface{}(nil)
+	for key, arg := range function {
+		if key != linesKey && key != model.ShortFormKey {
+			name, args = key, arg
+		}
+	}
+	arguments, _ := args.([]interface{})
+
+	switch name {
+	case "Condition":
+		condition, _ := args.(string)
+		return t.condition(condition)
+	case "Fn::Equals":
+		if len(arguments) != 2 {
+			return false, false
+		}
+		t.arguments++
+		first, _, _ := t.resolve(copyValue(arguments[0]), nil)
+		second, _, _ := t.resolve(copyValue(arguments[1]), nil)
+		t.arguments--
+		if !isScalar(first) || !isScalar(second) {
+			return false, false
+		}
+		return toString(first) == toString(second), true
+	case "Fn::Not":
+		if len(arguments) != 1 {
+			return false, false
+		}
+		value, known = t.evaluateCondition(arguments[0])
+		return !value, known
+	case "Fn::And", "Fn::Or":
+		return t.evaluateConditions(name == "Fn::And", arguments)
+	default:
+		return false, false
+	}
+}
+
+// evaluateConditions returns the value of the conditions of a Fn::And or a Fn::Or function, the value
+// is known as soon as a condition is false for Fn::And or true for Fn::Or
+func (t *template) evaluateConditions(and bool, conditions []interface{}) (value, known bool) {
+	if len(conditions) == 0 {
+		return false, false
+	}
+	known = true
+	for _, condition := range conditions {
+		conditionValue, conditionKnown := t.evaluateCondition(condition)
+		if !conditionKnown {
+			known = false
+			continue
+		}
+		if conditionValue != and {
+			return conditionValue, true
+		}
+	}
+	return and, known
+}
+
+// ref returns the value of a parameter. A property set to a parameter taking its default value keeps its
+// reference, so the queries checking the default values of the parameters find them at their line
+func (t *template) ref(args interface{}, argument bool) (interface{}, bool) {
+	name, ok := args.(string)
+	if !ok || (!argument && t.defaults[name]) {
+		return nil, false
+	}
+	value, ok := t.parameters[name]
+	if !ok {
+		return nil, false
+	}
+	return copyValue(value), true
+}
+
+// sub returns the string of a Fn::Sub function with its variables replaced by the values of
+// the variables map of the function or the parameters
+func (t *template) sub(args interface{}) (interface{}, bool) {
+	var text string
+	variables := map[string]interface{}{}
+	switch v := args.(type) {
+	case string:
+		text = v
+	case []interface{}:
+		if len(v) != 2 {
+			return nil, false
+		}
+		text, _ = v[0].(string)
+		variables = getMap(v[1])
+	default:
+		return nil, false
+	}
+
+	known := true
+	value := subVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		name := strings.TrimSpace(match[2 : len(match)-1])
+		// ${!Literal} is written as ${Literal}
+		if strings.HasPrefix(name, "!") {
+			return "${" + name[1:] + "}"
+		}
+		variable, ok := variables[name]
+		if !ok {
+			variable, ok = t.parameters[name]
+		}
+		if !ok || !isScalar(variable) {
+			known = false
+			return match
+		}
+		return toString(variable)
+	})
+	return value, known
+}
+
+// join returns the string of the values of a Fn::Join function joined by its delimiter
+func join(args interface{}) (interface{}, bool) {
+	arguments, ok := args.([]interface{})
+	if !ok || len(arguments) != 2 {
+		return nil, false
+	}
+	delimiter, ok := arguments[0].(string)
+	values, isList := getList(arguments[1])
+	if !ok || !isList {
+		return nil, false
+	}
+	elements := make([]string, 0, len(values))
+	for _, value := range values {
+		if !isScalar(value) {
+			return nil, false
+		}
+		elements = append(elements, toString(value))
+	}
+	return strings.Join(elements, delimiter), true
+}
+
+// selectElement returns the element of the list of a Fn::Select function in its index
+func selectElement(args interface{}) (interface{}, bool) {
+	arguments, ok := args.([]interface{})
+	if !ok || len(arguments) != 2 {
+		return nil, false
+	}
+	index, err := strconv.Atoi(toString(arguments[0]))
+	values, isList := getList(arguments[1])
+	if err != nil || !isList || index < 0 || index >= len(values) {
+		return nil, false
+	}
+	return values[index], true
+}
+
+// split returns the list of the parts of the string of a Fn::Split function
+func split(args interface{}) (interface{}, bool) {
+	arguments, ok := args.([]interface{})
+	if !ok || len(arguments) != 2 {
+		return nil, false
+	}
+	delimiter, ok := arguments[0].(string)
+	text, isString := arguments[1].(string)
+	if !ok || !isString || delimiter == "" {
+		return nil, false
+	}
+	parts := strings.Split(text, delimiter)
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		values = append(values, part)
+	}
+	return values, true
+}
+
+// findInMap returns the value of the second level key of the top level key of a mapping
+func (t *template) findInMap(args interface{}) (interface{}, bool) {
+	arguments, ok := args.([]interface{})
+	if !ok || len(arguments) < 3 {
+		return nil, false
+	}
+	keys := make([]string, 0, len(arguments))
+	for _, argument := range arguments[:3] {
+		key, isString := argument.(string)
+		if !isString {
+			return nil, false
+		}
+		keys = append(keys, key)
+	}
+	value, ok := getMap(getMap(t.mappings[keys[0]])[keys[1]])[keys[2]]
+	if !ok {
+		return nil, false
+	}
+	return copyValue(value), true
+}
+
+// getList returns value as a list, the YAML parser keeps the lists nested in lists as maps with a playbooks key
+func getList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case map[string]interface{}:
+		list, ok := v["playbooks"].([]interface{})
+		return list, ok && len(v) == 1
+	default:
+		return nil, false
+	}
+}
+
+// valueLines returns the line information of a value replacing an intrinsic function, which is
+// the line of the function
+func valueLines(lines map[string]*model.LineObject) map[string]*model.LineObject {
+	if lines == nil || lines[defaultLines] == nil {
+		return nil
+	}
+	return map[string]*model.LineObject{
+		defaultLines: {
+			Line: lines[defaultLines].Line,
+		},
+	}
+}
+
+// copyValue returns a copy of value without line information, so the values of the parameters and
+// mappings can be used in several places of the template
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			if key != linesKey {
+				copied[key] = copyValue(element)
+			}
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			copied = append(copied, copyValue(element))
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// isScalar returns true if value is a string, a number or a boolean
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, int, bool:
+		return true
+	default:
+		return false
+	}
+}
+
+// toString returns the string representation of a scalar value
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
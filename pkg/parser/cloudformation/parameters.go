@@ -0,0 +1,109 @@
+package cloudformation
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadParameters reads the values of the parameters of a parameters file, which can be a list of ParameterKey and
+// ParameterValue entries, as used by the AWS CLI, or a template configuration file with a Parameters map, as used by
+// AWS CodePipeline. The file can be written in JSON or YAML and is read from the FileSystem passed
+func LoadParameters(fileSystem filesystem.FileSystem, path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := filesystem.Get(fileSystem).ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var parsed interface{}
+	if err = yaml.Unmarshal(content, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse CloudFormation parameters file %s: %w", path, err)
+	}
+
+	parameters := make(map[string]string)
+	switch v := parsed.(type) {
+	case []interface{}:
+		for _, entry := range v {
+			key, ok := getMap(entry)["ParameterKey"].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid CloudFormation parameters file %s: entry without ParameterKey", path)
+			}
+			parameters[key] = toString(getMap(entry)["ParameterValue"])
+		}
+	case map[string]interface{}:
+		values, ok := v["Parameters"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid CloudFormation parameters file %s: Parameters map not found", path)
+		}
+		for key, value := range values {
+			parameters[key] = toString(value)
+		}
+	default:
+		return nil, fmt.Errorf("invalid CloudFormation parameters file %s", path)
+	}
+	return parameters, nil
+}
+
+// getParameters returns the values of the parameters of a template, which are the values passed to the resolver
+// or the default values of the template, and the names of the parameters taking their default value. Parameters
+// without value are not resolved, nor the NoEcho parameters, whose values are secrets never written in the template
+func (r *Resolver) getParameters(document model.Document) (values map[string]interface{}, defaults map[string]bool) {
+	values = make(map[string]interface{})
+	defaults = make(map[string]bool)
+	for name, definition := range getMap(document["Parameters"]) {
+		if name == linesKey || isNoEcho(getMap(definition)) {
+			continue
+		}
+		parameterType, _ := getMap(definition)["Type"].(string)
+		value, ok := getMap(definition)["Default"]
+		passed, found := r.parameters[name]
+		if found {
+			value, ok = passed, true
+		}
+		// the values of SSM parameter types are the names of the SSM parameters
+		if !ok || strings.HasPrefix(parameterType, "AWS::SSM::Parameter::Value") {
+			continue
+		}
+		values[name] = getParameterValue(parameterType, value)
+		defaults[name] = !found
+	}
+	return values, defaults
+}
+
+// isNoEcho returns true if the value of a parameter is masked, NoEcho can be a boolean or a string
+func isNoEcho(definition map[string]interface{}) bool {
+	return strings.EqualFold(toString(definition["NoEcho"]), "true")
+}
+
+// getParameterValue converts the value of a parameter to its type, list types are comma delimited strings
+func getParameterValue(parameterType string, value interface{}) interface{} {
+	switch {
+	case parameterType == "Number":
+		if number, err := strconv.ParseFloat(toString(value), 64); err == nil {
+			return number
+		}
+		return value
+	case parameterType == "CommaDelimitedList" || strings.HasPrefix(parameterType, "List<"):
+		text, ok := value.(string)
+		if !ok {
+			return value
+		}
+		parts := strings.Split(text, ",")
+		values := make([]interface{}, 0, len(parts))
+		for _, part := range parts {
+			values = append(values, strings.TrimSpace(part))
+		}
+		return values
+	case isScalar(value):
+		return toString(value)
+	default:
+		return value
+	}
+}
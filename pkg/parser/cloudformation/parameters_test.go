@@ -0,0 +1,66 @@
+package cloudformation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadParameters tests the functions [LoadParameters()] and all the methods called by them
+func TestLoadParameters(t *testing.T) {
+	invalidPath := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(invalidPath, []byte(`{"Environment": "prod"}`), 0o600))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "parameters file in the format of the AWS CLI",
+			path: filepath.Join(intrinsicsFixturePath, "parameters.json"),
+			want: map[string]string{"Environment": "prod"},
+		},
+		{
+			name: "template configuration file",
+			path: filepath.Join(intrinsicsFixturePath, "configuration.yaml"),
+			want: map[string]string{"Environment": "prod", "Size": "20"},
+		},
+		{
+			name: "no parameters file",
+			path: "",
+		},
+		{
+			name:    "parameters file without parameters",
+			path:    invalidPath,
+			wantErr: true,
+		},
+		{
+			name:    "parameters file not found",
+			path:    filepath.Join(intrinsicsFixturePath, "missing.json"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadParameters(nil, tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestGetParameterValue tests the conversion of the parameters values to their types
+func TestGetParameterValue(t *testing.T) {
+	require.Equal(t, float64(20), getParameterValue("Number", "20"))
+	require.Equal(t, []interface{}{"a", "b"}, getParameterValue("CommaDelimitedList", "a, b"))
+	require.Equal(t, []interface{}{"sg-1"}, getParameterValue("List<AWS::EC2::SecurityGroup::Id>", "sg-1"))
+	require.Equal(t, "true", getParameterValue("String", true))
+}
@@ -0,0 +1,314 @@
+package cloudformation
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	linesKey     = "_kics_lines"
+	linesPrefix  = "_kics_"
+	defaultLines = "_kics__default"
+	noValue      = "AWS::NoValue"
+)
+
+// resolvedSections are the sections of a template whose intrinsic functions are evaluated, the Parameters,
+// Mappings and Conditions sections are kept as they are since they are the input of the evaluation
+var resolvedSections = []string{"Resources", "Outputs"}
+
+// Resolver evaluates the intrinsic functions and conditions of CloudFormation templates, using the default
+// values of the template parameters or the values passed in a parameters file
+type Resolver struct {
+	parameters map[string]string
+}
+
+// NewResolver creates a Resolver using the values of the parameters passed
+func NewResolver(parameters map[string]string) *Resolver {
+	return &Resolver{
+		parameters: parameters,
+	}
+}
+
+// template keeps the sections of the template used to evaluate its intrinsic functions
+type template struct {
+	parameters map[string]interface{}
+	// defaults are the parameters taking their default value
+	defaults map[string]bool
+	// arguments is the depth of the function arguments being evaluated
+	arguments  int
+	mappings   map[string]interface{}
+	conditions map[string]interface{}
+	evaluated  map[string]bool
+	evaluating map[string]bool
+}
+
+// Resolve evaluates the intrinsic functions of the Resources and Outputs of a CloudFormation template, replacing
+// them by their value, and removes the resources and outputs whose condition is false. The functions that
+// can not be evaluated, such as the ones depending on the attributes of other resources, are kept
+func (r *Resolver) Resolve(document model.Document) model.Document {
+	if !IsTemplate(document) {
+		return document
+	}
+	// handle panic during resolve process
+	defer func() {
+		if err := recover(); err != nil {
+			log.Warn().Msgf("Recovered from panic during resolve of CloudFormation template: %v", err)
+		}
+	}()
+
+	parameters, defaults := r.getParameters(document)
+	t := &template{
+		parameters: parameters,
+		defaults:   defaults,
+		mappings:   getMap(document["Mappings"]),
+		conditions: getMap(document["Conditions"]),
+		evaluated:  make(map[string]bool),
+		evaluating: make(map[string]bool),
+	}
+
+	for _, section := range resolvedSections {
+		entries, ok := document[section].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name, entry := range entries {
+			if name == linesKey {
+				continue
+			}
+			if t.isDisabled(entry) {
+				log.Debug().Msgf("CloudFormation %s %s is not created since its condition is false", section, name)
+				delete(entries, name)
+				deleteLines(entries, name)
+				continue
+			}
+			if resolved, _, keep := t.resolve(entry, nil); keep {
+				entries[name] = resolved
+			}
+		}
+	}
+	return document
+}
+
+// IsTemplate returns true if the document is a CloudFormation template, which has resources and declares the
+// template format version or AWS resources
+func IsTemplate(document model.Document) bool {
+	resources, ok := document["Resources"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok := document["AWSTemplateFormatVersion"]; ok {
+		return true
+	}
+	for _, resource := range resources {
+		if resourceType, ok := getMap(resource)["Type"].(string); ok && strings.HasPrefix(resourceType, "AWS::") {
+			return true
+		}
+	}
+	return false
+}
+
+// isDisabled returns true if the resource or output has a condition that is false
+func (t *template) isDisabled(entry interface{}) bool {
+	name, ok := getMap(entry)["Condition"].(string)
+	if !ok {
+		return false
+	}
+	value, known := t.condition(name)
+	return known && !value
+}
+
+// resolve evaluates the intrinsic functions of value, lines is the line information of value, which for arrays
+// keeps the line information of the elements in the default entry. It returns the resolved value, its line
+// information and false when the value should be removed, as it happens with AWS::NoValue
+func (t *template) resolve(value interface{}, lines map[string]*model.LineObject) (
+	interface{}, map[string]*model.LineObject, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if hasOwnLines(v) {
+			lines = getLines(v)
+		}
+		if name, args, ok := getFunction(v); ok {
+			return t.evaluate(v, name, args, lines)
+		}
+		t.resolveMap(v, lines)
+		return v, lines, true
+	case []interface{}:
+		return t.resolveArray(v, lines)
+	default:
+		return value, lines, true
+	}
+}
+
+// resolveMap evaluates the intrinsic functions of the values of a map in place
+func (t *template) resolveMap(value map[string]interface{}, lines map[string]*model.LineObject) {
+	for key, child := range value {
+		if key == linesKey {
+			continue
+		}
+		childLines := getChildLines(child, lines, key)
+		resolved, resolvedLines, keep := t.resolve(child, childLines)
+		if !keep {
+			delete(value, key)
+			if lines != nil {
+				delete(lines, linesPrefix+key)
+			}
+			continue
+		}
+		value[key] = resolved
+		setChildLines(resolved, resolvedLines, lines, key)
+	}
+}
+
+// resolveArray evaluates the intrinsic functions of the elements of an array, removing the ones without value
+func (t *template) resolveArray(value []interface{}, lines map[string]*model.LineObject) (
+	interface{}, map[string]*model.LineObject, bool) {
+	elementsLines := getElementsLines(lines, len(value))
+	resolved := make([]interface{}, 0, len(value))
+	resolvedLines := make([]map[string]*model.LineObject, 0, len(value))
+	for i, element := range value {
+		var elementLines map[string]*model.LineObject
+		if elementsLines != nil {
+			elementLines = elementsLines[i]
+		}
+		element, elementLines, keep := t.resolve(element, elementLines)
+		if !keep {
+			continue
+		}
+		resolved = append(resolved, element)
+		resolvedLines = append(resolvedLines, elementLines)
+	}
+	if elementsLines == nil {
+		return resolved, lines, true
+	}
+	return resolved, arrayLines(lines, resolvedLines), true
+}
+
+// getFunction returns the name and the arguments of an intrinsic function, which is a map
+// with a single key that is Ref or starts with Fn::
+func getFunction(value map[string]interface{}) (name string, args interface{}, ok bool) {
+	for key, arg := range value {
+		if key == linesKey || key == model.ShortFormKey {
+			continue
+		}
+		if name != "" {
+			return "", nil, false
+		}
+		name, args = key, arg
+	}
+	return name, args, name == "Ref" || strings.HasPrefix(name, "Fn::")
+}
+
+// getMap returns value as a map, or an empty map if it is not a map
+func getMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	if m, ok := value.(model.Document); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+// hasOwnLines returns true if value is a map with its own line information
+func hasOwnLines(value interface{}) bool {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m[linesKey]
+	return ok
+}
+
+// getLines returns the line information of a map, converting it to its typed representation, since the
+// YAML parser keeps it as generic maps
+func getLines(value interface{}) map[string]*model.LineObject {
+	m := getMap(value)
+	switch lines := m[linesKey].(type) {
+	case map[string]*model.LineObject:
+		return lines
+	case nil:
+		return nil
+	default:
+		content, err := json.Marshal(lines)
+		if err != nil {
+			return nil
+		}
+		var typed map[string]*model.LineObject
+		if err := json.Unmarshal(content, &typed); err != nil {
+			return nil
+		}
+		m[linesKey] = typed
+		return typed
+	}
+}
+
+// getChildLines returns the line information of the value of a key of a map with the given lines,
+// maps have their own line information while the line information of arrays is kept by their parent
+func getChildLines(child interface{}, lines map[string]*model.LineObject, key string) map[string]*model.LineObject {
+	switch child.(type) {
+	case map[string]interface{}:
+		return getLines(child)
+	case []interface{}:
+		if lines == nil || lines[linesPrefix+key] == nil {
+			return nil
+		}
+		return map[string]*model.LineObject{
+			defaultLines: {
+				Line: lines[linesPrefix+key].Line,
+				Arr:  lines[linesPrefix+key].Arr,
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+// setChildLines updates the line information of the key of a map with the line information of its resolved value
+func setChildLines(resolved interface{}, resolvedLines, lines map[string]*model.LineObject, key string) {
+	switch v := resolved.(type) {
+	case map[string]interface{}:
+		if _, ok := v[linesKey]; !ok && resolvedLines != nil {
+			v[linesKey] = resolvedLines
+		}
+	case []interface{}:
+		if lines == nil || lines[linesPrefix+key] == nil {
+			return
+		}
+		lines[linesPrefix+key].Arr = nil
+		if resolvedLines != nil && resolvedLines[defaultLines] != nil {
+			lines[linesPrefix+key].Arr = resolvedLines[defaultLines].Arr
+		}
+	}
+}
+
+// getElementsLines returns the line information of the elements of an array, if it matches its elements
+func getElementsLines(lines map[string]*model.LineObject, size int) []map[string]*model.LineObject {
+	if lines == nil || lines[defaultLines] == nil || len(lines[defaultLines].Arr) != size {
+		return nil
+	}
+	return lines[defaultLines].Arr
+}
+
+// arrayLines returns the line information of an array with the line information of its elements
+func arrayLines(lines map[string]*model.LineObject, elementsLines []map[string]*model.LineObject) map[string]*model.LineObject {
+	line := 0
+	if lines != nil && lines[defaultLines] != nil {
+		line = lines[defaultLines].Line
+	}
+	return map[string]*model.LineObject{
+		defaultLines: {
+			Line: line,
+			Arr:  elementsLines,
+		},
+	}
+}
+
+// deleteLines removes the line information of the key of a map
+func deleteLines(value map[string]interface{}, key string) {
+	if lines := getLines(value); lines != nil {
+		delete(lines, linesPrefix+key)
+	}
+}
@@ -0,0 +1,230 @@
+package cloudformation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var intrinsicsFixturePath = filepath.FromSlash("../../../test/fixtures/test_cloudformation_intrinsics")
+
+func parseTemplate(t *testing.T, path string) model.Document {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var document model.Document
+	require.NoError(t, yaml.Unmarshal(content, &document))
+	return document
+}
+
+// TestResolver_Resolve tests the functions [Resolve()] and all the methods called by them
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		want       map[string]interface{}
+	}{
+		{
+			name: "resolve with the default values of the parameters",
+			want: map[string]interface{}{
+				"Bucket": map[string]interface{}{
+					"Type": "AWS::S3::Bucket",
+					"Properties": map[string]interface{}{
+						"BucketName":    "logs-dev",
+						"AccessControl": "PublicRead",
+						"Tags": []interface{}{
+							map[string]interface{}{"Key": "Name", "Value": "logs-dev"},
+						},
+					},
+				},
+				"Volume": map[string]interface{}{
+					"Type": "AWS::EC2::Volume",
+					"Properties": map[string]interface{}{
+						"AvailabilityZone": []interface{}{float64(0), ""},
+						"Encrypted":        false,
+						"Size":             float64(10),
+					},
+				},
+				"DevTopic": map[string]interface{}{
+					"Type":      "AWS::SNS::Topic",
+					"Condition": "IsDev",
+				},
+				"Instance": map[string]interface{}{
+					"Type": "AWS::EC2::Instance",
+					"Properties": map[string]interface{}{
+						"SubnetId":           "subnet-b",
+						"IamInstanceProfile": "Profile.Arn",
+					},
+				},
+			},
+		},
+		{
+			name:       "resolve with the values of the parameters passed",
+			parameters: map[string]string{"Environment": "prod"},
+			want: map[string]interface{}{
+				"Bucket": map[string]interface{}{
+					"Type": "AWS::S3::Bucket",
+					"Properties": map[string]interface{}{
+						"BucketName":    "logs-prod",
+						"AccessControl": "Private",
+						"Tags": []interface{}{
+							map[string]interface{}{"Key": "Name", "Value": "logs-prod"},
+							map[string]interface{}{"Key": "Production", "Value": "true"},
+						},
+					},
+				},
+				"Volume": map[string]interface{}{
+					"Type": "AWS::EC2::Volume",
+					"Properties": map[string]interface{}{
+						"AvailabilityZone": []interface{}{float64(0), ""},
+						"Encrypted":        true,
+						"Size":             float64(10),
+					},
+				},
+				"Instance": map[string]interface{}{
+					"Type": "AWS::EC2::Instance",
+					"Properties": map[string]interface{}{
+						"SubnetId":           "subnet-b",
+						"IamInstanceProfile": "Profile.Arn",
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := parseTemplate(t, filepath.Join(intrinsicsFixturePath, "template.yaml"))
+			got := RestoreShortForm(NewResolver(tt.parameters).Resolve(document))
+			require.Equal(t, tt.want, copyValue(got["Resources"]))
+			// the sections used to evaluate the functions are kept
+			require.Equal(t, []interface{}{"Environment", "prod"},
+				copyValue(getMap(got["Conditions"])["IsProd"]))
+		})
+	}
+}
+
+// TestResolver_Resolve_Lines tests that the values selected by Fn::If keep their line information
+func TestResolver_Resolve_Lines(t *testing.T) {
+	document := parseTemplate(t, filepath.Join(intrinsicsFixturePath, "template.yaml"))
+	got := RestoreShortForm(NewResolver(map[string]string{"Environment": "prod"}).Resolve(document))
+
+	properties := getMap(getMap(getMap(got["Resources"])["Bucket"])["Properties"])
+	lines := getLines(properties)
+	require.Equal(t, 26, lines["_kics_AccessControl"].Line)
+	require.Len(t, lines["_kics_Tags"].Arr, 2)
+	require.Equal(t, 32, lines["_kics_Tags"].Arr[1]["_kics_Key"].Line)
+	require.Equal(t, 33, lines["_kics_Tags"].Arr[1]["_kics_Value"].Line)
+}
+
+// TestResolver_Resolve_NotTemplate tests that documents that are not CloudFormation templates are not changed
+func TestResolver_Resolve_NotTemplate(t *testing.T) {
+	document := model.Document{
+		"Resources": map[string]interface{}{
+			"Value": map[string]interface{}{
+				"Ref": "Parameter",
+			},
+		},
+	}
+	got := NewResolver(nil).Resolve(document)
+	require.Equal(t, map[string]interface{}{"Ref": "Parameter"}, getMap(got["Resources"])["Value"])
+}
+
+// TestResolver_Resolve_Parameters tests the references to the parameters taking their default value, passed or
+// masked with NoEcho
+func TestResolver_Resolve_Parameters(t *testing.T) {
+	template := func() model.Document {
+		return model.Document{
+			"Parameters": map[string]interface{}{
+				"Password": map[string]interface{}{"Type": "String", "NoEcho": true},
+				"Token":    map[string]interface{}{"Type": "String", "NoEcho": "true", "Default": "token"},
+				"Name":     map[string]interface{}{"Type": "String", "Default": "default"},
+			},
+			"Resources": map[string]interface{}{
+				"Cluster": map[string]interface{}{
+					"Type": "AWS::DocDB::DBCluster",
+					"Properties": map[string]interface{}{
+						"MasterUserPassword": map[string]interface{}{"Ref": "Password"},
+						"Token":              map[string]interface{}{"Fn::Join": []interface{}{"", []interface{}{map[string]interface{}{"Ref": "Token"}}}},
+						"Name":               map[string]interface{}{"Ref": "Name"},
+						"Identifier":         map[string]interface{}{"Fn::Sub": "${Name}-cluster"},
+					},
+				},
+			},
+		}
+	}
+	properties := func(document model.Document) map[string]interface{} {
+		return getMap(getMap(getMap(document["Resources"])["Cluster"])["Properties"])
+	}
+
+	got := properties(NewResolver(nil).Resolve(template()))
+	require.Equal(t, map[string]interface{}{"Ref": "Password"}, got["MasterUserPassword"])
+	require.Equal(t, map[string]interface{}{"Fn::Join": []interface{}{"", []interface{}{map[string]interface{}{"Ref": "Token"}}}},
+		got["Token"])
+	require.Equal(t, map[string]interface{}{"Ref": "Name"}, got["Name"])
+	require.Equal(t, "default-cluster", got["Identifier"])
+
+	got = properties(NewResolver(map[string]string{"Password": "secret", "Name": "passed"}).Resolve(template()))
+	require.Equal(t, map[string]interface{}{"Ref": "Password"}, got["MasterUserPassword"])
+	require.Equal(t, "passed", got["Name"])
+	require.Equal(t, "passed-cluster", got["Identifier"])
+}
+
+// TestEvaluateConditions tests the evaluation of the condition functions
+func TestEvaluateConditions(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition interface{}
+		value     bool
+		known     bool
+	}{
+		{
+			name:      "and with a false condition",
+			condition: map[string]interface{}{"Fn::And": []interface{}{map[string]interface{}{"Condition": "Unknown"}, false}},
+			value:     false,
+			known:     true,
+		},
+		{
+			name:      "and with an unknown condition",
+			condition: map[string]interface{}{"Fn::And": []interface{}{map[string]interface{}{"Condition": "Unknown"}, true}},
+			known:     false,
+		},
+		{
+			name:      "or with a true condition",
+			condition: map[string]interface{}{"Fn::Or": []interface{}{map[string]interface{}{"Condition": "Unknown"}, true}},
+			value:     true,
+			known:     true,
+		},
+		{
+			name: "equals with a reference to a resource",
+			condition: map[string]interface{}{
+				"Fn::Equals": []interface{}{map[string]interface{}{"Ref": "Bucket"}, "bucket"},
+			},
+			known: false,
+		},
+		{
+			name: "equals with a number parameter",
+			condition: map[string]interface{}{
+				"Fn::Equals": []interface{}{map[string]interface{}{"Ref": "Size"}, "10"},
+			},
+			value: true,
+			known: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := &template{
+				parameters: map[string]interface{}{"Size": float64(10)},
+				evaluated:  make(map[string]bool),
+				evaluating: make(map[string]bool),
+			}
+			value, known := tmpl.evaluateCondition(tt.condition)
+			require.Equal(t, tt.known, known)
+			if tt.known {
+				require.Equal(t, tt.value, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,485 @@
+package cloudformation
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// serverlessTransform is the transform of the templates written with the AWS Serverless Application Model (SAM)
+	serverlessTransform = "AWS::Serverless-2016-10-31"
+	// SamResourceIDKey is the key of the Metadata of a generated resource with the name of its SAM resource
+	SamResourceIDKey = "SamResourceId"
+)
+
+// logicalIDRegex matches the characters that are not allowed in the logical IDs of the resources
+var logicalIDRegex = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// serverlessResources are the SAM resource types expanded by the transform, in the order they are expanded,
+// and the section of the Globals that applies to them. The functions are expanded first since their events
+// define the paths of the APIs
+var serverlessResources = []struct {
+	resourceType string
+	globals      string
+	expand       func(s *serverless, resource *samResource)
+}{
+	{resourceType: "AWS::Serverless::Function", globals: "Function", expand: (*serverless).function},
+	{resourceType: "AWS::Serverless::Api", globals: "Api", expand: func(s *serverless, resource *samResource) {
+		s.restAPI(resource.name, resource)
+	}},
+	{resourceType: "AWS::Serverless::HttpApi", globals: "HttpApi", expand: func(s *serverless, resource *samResource) {
+		s.httpAPI(resource.name, resource)
+	}},
+	{resourceType: "AWS::Serverless::SimpleTable", globals: "SimpleTable", expand: (*serverless).simpleTable},
+}
+
+// serverless keeps the resources generated by the SAM transform of a template
+type serverless struct {
+	globals        map[string]interface{}
+	resourcesLines map[string]*model.LineObject
+	resources      map[string]interface{}
+	lines          map[string]*model.LineObject
+	apiEvents      map[string][]apiEvent
+}
+
+// samResource is a SAM resource of the template, its properties include the Globals of its type
+type samResource struct {
+	name            string
+	resource        map[string]interface{}
+	properties      map[string]interface{}
+	propertiesLines map[string]*model.LineObject
+	line            int
+	propertiesLine  int
+}
+
+// generatedResource is a resource generated from a SAM resource, its properties point to the lines
+// of the SAM resource they are generated from
+type generatedResource struct {
+	source     *samResource
+	properties map[string]interface{}
+	lines      map[string]*model.LineObject
+}
+
+// TransformServerless expands the SAM resources of the templates with the AWS::Serverless-2016-10-31 transform into
+// the CloudFormation resources they are deployed as, ex: an AWS::Serverless::Function into an AWS::Lambda::Function
+// and its AWS::IAM::Role, so the CloudFormation queries apply to them. The generated resources of each template
+// are returned in a new document, so the SAM queries still see the template as it is written
+func TransformServerless(documents []model.Document) []model.Document {
+	transformed := make([]model.Document, 0)
+	for _, document := range documents {
+		if document := transformServerless(document); document != nil {
+			transformed = append(transformed, document)
+		}
+	}
+	return transformed
+}
+
+// IsServerless returns true if the document is a CloudFormation template with the SAM transform
+func IsServerless(document model.Document) bool {
+	if !IsTemplate(document) {
+		return false
+	}
+	if transform, ok := document["Transform"].(string); ok {
+		return transform == serverlessTransform
+	}
+	transforms, _ := getList(document["Transform"])
+	for _, transform := range transforms {
+		if transform == serverlessTransform {
+			return true
+		}
+	}
+	return false
+}
+
+// transformServerless returns the document with the resources generated from the SAM resources of a template,
+// or nil if the template has none
+func transformServerless(document model.Document) (transformed model.Document) {
+	if !IsServerless(document) {
+		return nil
+	}
+	// handle panic during transform process
+	defer func() {
+		if err := recover(); err != nil {
+			log.Warn().Msgf("Recovered from panic during transform of SAM template: %v", err)
+			transformed = nil
+		}
+	}()
+
+	resources := getMap(document["Resources"])
+	s := &serverless{
+		globals:        getMap(document["Globals"]),
+		resourcesLines: getLines(resources),
+		resources:      make(map[string]interface{}),
+		lines:          make(map[string]*model.LineObject),
+		apiEvents:      make(map[string][]apiEvent),
+	}
+
+	names := sortedKeys(resources)
+	for _, serverlessResource := range serverlessResources {
+		for _, name := range names {
+			resource := getMap(resources[name])
+			if resource["Type"] != serverlessResource.resourceType {
+				continue
+			}
+			serverlessResource.expand(s, s.newSamResource(name, resource, serverlessResource.globals))
+		}
+	}
+	s.implicitAPIs()
+
+	if len(s.resources) == 0 {
+		return nil
+	}
+	s.lines[defaultLines] = &model.LineObject{Line: lineOf(getLines(document), "Resources")}
+	s.resources[linesKey] = s.lines
+
+	transformed = model.Document{
+		"Resources": s.resources,
+		linesKey: map[string]*model.LineObject{
+			defaultLines:              {Line: lineOf(getLines(document), "")},
+			linesPrefix + "Resources": {Line: lineOf(getLines(document), "Resources")},
+		},
+	}
+	if version, ok := document["AWSTemplateFormatVersion"]; ok {
+		transformed["AWSTemplateFormatVersion"] = version
+	}
+	log.Debug().Msgf("Generated %d CloudFormation resources from the SAM resources of the template", len(s.resources)-1)
+	return transformed
+}
+
+// newSamResource returns a SAM resource of the template with its properties merged with the Globals of its type
+func (s *serverless) newSamResource(name string, resource map[string]interface{}, globals string) *samResource {
+	properties, propertiesLines := mergeGlobals(getMap(s.globals[globals]), getMap(resource["Properties"]))
+	line := lineOf(s.resourcesLines, name)
+	propertiesLine := lineOf(getLines(resource), "Properties")
+	if propertiesLine == 0 {
+		propertiesLine = line
+	}
+	return &samResource{
+		name:            name,
+		resource:        resource,
+		properties:      properties,
+		propertiesLines: propertiesLines,
+		line:            line,
+		propertiesLine:  propertiesLine,
+	}
+}
+
+// propertyLine returns the line of a property of the SAM resource, or the line of its properties if it is not set
+func (r *samResource) propertyLine(key string) int {
+	if line := lineOf(r.propertiesLines, key); line != 0 {
+		return line
+	}
+	return r.propertiesLine
+}
+
+// generate adds a resource generated from a SAM resource to the transformed template, the resource keeps
+// the name of the SAM resource in its Metadata as the SAM transform does and is marked as generated in its
+// line information, so its lines are found there
+func (s *serverless) generate(source *samResource, name, resourceType string) *generatedResource {
+	generated := &generatedResource{
+		source:     source,
+		properties: make(map[string]interface{}),
+		lines: map[string]*model.LineObject{
+			defaultLines: {Line: source.propertiesLine},
+		},
+	}
+	generated.properties[linesKey] = generated.lines
+
+	resourceLines := getLines(source.resource)
+	resource := map[string]interface{}{
+		"Type":       resourceType,
+		"Properties": generated.properties,
+		"Metadata": map[string]interface{}{
+			SamResourceIDKey: source.name,
+			linesKey: map[string]*model.LineObject{
+				defaultLines:                   {Line: source.line},
+				linesPrefix + SamResourceIDKey: {Line: source.line},
+			},
+		},
+	}
+	lines := map[string]*model.LineObject{
+		defaultLines:               {Line: source.line, Generated: true},
+		linesPrefix + "Type":       {Line: lineOf(resourceLines, "Type")},
+		linesPrefix + "Properties": {Line: source.propertiesLine},
+		linesPrefix + "Metadata":   {Line: source.line},
+	}
+	if condition, ok := source.resource["Condition"]; ok {
+		resource["Condition"] = condition
+		lines[linesPrefix+"Condition"] = &model.LineObject{Line: lineOf(resourceLines, "Condition")}
+	}
+	resource[linesKey] = lines
+
+	s.resources[name] = resource
+	s.lines[linesPrefix+name] = &model.LineObject{Line: source.line}
+	return generated
+}
+
+// set sets a property of the generated resource in the line of the property of the SAM resource it
+// is generated from, the maps created by the transform get the same line for all their keys
+func (g *generatedResource) set(key string, value interface{}, from string) {
+	line := g.source.propertyLine(from)
+	setLines(value, line)
+	g.properties[key] = value
+	g.lines[linesPrefix+key] = &model.LineObject{
+		Line: line,
+		Arr:  elementsLinesAt(value, line),
+	}
+}
+
+// copy sets a property of the generated resource with the value of a property of the SAM resource, keeping
+// its line information, and returns false if the SAM resource does not have the property
+func (g *generatedResource) copy(key, from string) bool {
+	value, ok := g.source.properties[from]
+	if !ok {
+		return false
+	}
+	g.properties[key] = copyWithLines(value)
+	line := &model.LineObject{Line: g.source.propertyLine(from)}
+	if lines := g.source.propertiesLines[linesPrefix+from]; lines != nil {
+		line.Arr = lines.Arr
+	}
+	g.lines[linesPrefix+key] = line
+	return true
+}
+
+// copyAll copies the properties of the SAM resource that have the same name in the generated resource
+func (g *generatedResource) copyAll(keys []string) {
+	for _, key := range keys {
+		g.copy(key, key)
+	}
+}
+
+// mergeGlobals returns the properties of a SAM resource merged with the Globals of its type and their line
+// information, the properties of the resource override the globals, maps are merged and lists are appended
+func mergeGlobals(globals, properties map[string]interface{}) (merged map[string]interface{},
+	lines map[string]*model.LineObject) {
+	merged = make(map[string]interface{})
+	lines = make(map[string]*model.LineObject)
+	globalsLines, propertiesLines := getLines(globals), getLines(properties)
+	if line := lineOf(propertiesLines, ""); line != 0 {
+		lines[defaultLines] = &model.LineObject{Line: line}
+	}
+	for key, value := range globals {
+		if key == linesKey {
+			continue
+		}
+		merged[key] = value
+		if line := copyLine(globalsLines[linesPrefix+key]); line != nil {
+			lines[linesPrefix+key] = line
+		}
+	}
+	for key, value := range properties {
+		if key == linesKey {
+			continue
+		}
+		global, isGlobal := merged[key]
+		merged[key] = value
+		line := copyLine(propertiesLines[linesPrefix+key])
+		switch v := value.(type) {
+		case map[string]interface{}:
+			globalMap, isMap := global.(map[string]interface{})
+			if _, _, isFunction := getFunction(v); isGlobal && isMap && !isFunction {
+				mergedMap, mergedLines := mergeGlobals(globalMap, v)
+				mergedMap[linesKey] = mergedLines
+				merged[key] = mergedMap
+			}
+		case []interface{}:
+			if globalList, isList := global.([]interface{}); isGlobal && isList {
+				merged[key] = append(append([]interface{}{}, globalList...), v...)
+				if globalLine := lines[linesPrefix+key]; line != nil && globalLine != nil &&
+					len(globalLine.Arr) == len(globalList) && len(line.Arr) == len(v) {
+					line.Arr = append(append([]map[string]*model.LineObject{}, globalLine.Arr...), line.Arr...)
+				}
+			}
+		}
+		if line != nil {
+			lines[linesPrefix+key] = line
+		}
+	}
+	return merged, lines
+}
+
+// copyWithLines returns a copy of value keeping its line information, so the generated resources
+// do not share values with the SAM resources
+func copyWithLines(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		lines := getLines(v)
+		copied := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			if key != linesKey {
+				copied[key] = copyWithLines(element)
+			}
+		}
+		if lines != nil {
+			copied[linesKey] = lines
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			copied = append(copied, copyWithLines(element))
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// setLines sets the line information of the maps created by the transform, all their keys point to the
+// given line, the maps copied from the template keep their own line information
+func setLines(value interface{}, line int) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if _, hasLines := m[linesKey]; !hasLines {
+		m[linesKey] = keysLines(m, line)
+		return
+	}
+	for key, child := range m {
+		if key != linesKey {
+			setLines(child, line)
+		}
+	}
+}
+
+// keysLines returns the line information of the keys of a map created by the transform, all in the given line
+func keysLines(value map[string]interface{}, line int) map[string]*model.LineObject {
+	lines := map[string]*model.LineObject{
+		defaultLines: {Line: line},
+	}
+	for key, child := range value {
+		if key == linesKey {
+			continue
+		}
+		lines[linesPrefix+key] = &model.LineObject{
+			Line: line,
+			Arr:  elementsLinesAt(child, line),
+		}
+		setLines(child, line)
+	}
+	return lines
+}
+
+// elementsLinesAt returns the line information of the elements of an array created by the transform,
+// the maps of an array keep the line information of their keys in the array line information
+func elementsLinesAt(value interface{}, line int) []map[string]*model.LineObject {
+	elements, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	lines := make([]map[string]*model.LineObject, 0, len(elements))
+	for _, element := range elements {
+		if m, isMap := element.(map[string]interface{}); isMap {
+			lines = append(lines, keysLines(m, line))
+			continue
+		}
+		lines = append(lines, map[string]*model.LineObject{
+			defaultLines: {Line: line},
+		})
+	}
+	return lines
+}
+
+// copyLine returns a copy of a line object, sharing the line information of its elements
+func copyLine(line *model.LineObject) *model.LineObject {
+	if line == nil {
+		return nil
+	}
+	return &model.LineObject{
+		Line: line.Line,
+		Arr:  line.Arr,
+	}
+}
+
+// lineOf returns the line of a key in the line information of a map, or the line of the map if key is empty
+func lineOf(lines map[string]*model.LineObject, key string) int {
+	name := linesPrefix + key
+	if key == "" {
+		name = defaultLines
+	}
+	if lines == nil || lines[name] == nil {
+		return 0
+	}
+	return lines[name].Line
+}
+
+// sortedKeys returns the keys of a map without its line information, in alphabetical order
+func sortedKeys(value map[string]interface{}) []string {
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		if key != linesKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// logicalID returns value without the characters that are not allowed in the logical IDs of the resources
+func logicalID(value string) string {
+	return logicalIDRegex.ReplaceAllString(value, "")
+}
+
+// ref returns a Ref intrinsic function to a resource or a parameter
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"Ref": name,
+	}
+}
+
+// getAtt returns a reference to an attribute of a resource, written as the arguments of the YAML short form of
+// Fn::GetAtt, ex: "Function.Arn", which is how the queries expect them
+func getAtt(name, attribute string) string {
+	return name + "." + attribute
+}
+
+// tagsList returns the tags of a SAM resource, written as a map, as the list of Key and Value entries used by the
+// CloudFormation resources, createdBy adds the tag SAM adds to the functions and their roles
+func tagsList(value interface{}, createdBy bool) []interface{} {
+	tags := make([]interface{}, 0)
+	if createdBy {
+		tags = append(tags, map[string]interface{}{
+			"Key":   "lambda:createdBy",
+			"Value": "SAM",
+		})
+	}
+	values := getMap(value)
+	for _, key := range sortedKeys(values) {
+		tags = append(tags, map[string]interface{}{
+			"Key":   key,
+			"Value": copyValue(values[key]),
+		})
+	}
+	return tags
+}
+
+// s3Location returns the bucket, key and version of an S3 location, written as an s3:// URI or as a map with
+// Bucket, Key and Version, with the names used by the CloudFormation resource. Local paths, which are uploaded
+// by the SAM CLI when the template is packaged, return an empty location
+func s3Location(value interface{}, bucket, key, version string) map[string]interface{} {
+	location := make(map[string]interface{})
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(v, "s3://") {
+			return location
+		}
+		parts := strings.SplitN(strings.TrimPrefix(v, "s3://"), "/", 2)
+		location[bucket] = parts[0]
+		if len(parts) == 2 {
+			location[key] = parts[1]
+		}
+	case map[string]interface{}:
+		for from, to := range map[string]string{"Bucket": bucket, "Key": key, "Version": version} {
+			if element, ok := v[from]; ok {
+				location[to] = copyValue(element)
+			}
+		}
+	}
+	return location
+}
@@ -0,0 +1,269 @@
+package cloudformation
+
+import (
+	"strings"
+)
+
+const (
+	// implicitRestAPI is the API SAM creates for the Api events of the functions without RestApiId
+	implicitRestAPI = "ServerlessRestApi"
+	// implicitHTTPAPI is the API SAM creates for the HttpApi events of the functions without ApiId
+	implicitHTTPAPI = "ServerlessHttpApi"
+)
+
+// restAPIProperties are the properties of an AWS::Serverless::Api with the same name and value
+// in its AWS::ApiGateway::RestApi
+var restAPIProperties = []string{
+	"ApiKeySourceType", "BinaryMediaTypes", "Description", "DisableExecuteApiEndpoint", "FailOnWarnings",
+	"MinimumCompressionSize", "Mode", "Name",
+}
+
+// restAPIStageProperties are the properties of an AWS::Serverless::Api with the same name and value
+// in its AWS::ApiGateway::Stage
+var restAPIStageProperties = []string{
+	"AccessLogSetting", "CacheClusterEnabled", "CacheClusterSize", "CanarySetting", "MethodSettings",
+	"TracingEnabled", "Variables",
+}
+
+// httpAPIStageProperties are the properties of an AWS::Serverless::HttpApi with the same name and value
+// in its AWS::ApiGatewayV2::Stage
+var httpAPIStageProperties = []string{
+	"AccessLogSettings", "DefaultRouteSettings", "RouteSettings", "StageVariables",
+}
+
+// attributeTypes are the DynamoDB attribute types of the types of the primary key of an AWS::Serverless::SimpleTable
+var attributeTypes = map[string]string{
+	"String": "S",
+	"Number": "N",
+	"Binary": "B",
+}
+
+// apiEvent is an Api or HttpApi event of a function, which adds a path to its API
+type apiEvent struct {
+	function *samResource
+	path     string
+	method   string
+}
+
+// apiEvent adds the path of an Api or HttpApi event to its API and generates the permission of the API to
+// invoke the function, the events without API use the API that SAM creates for the template
+func (s *serverless) apiEvent(function *samResource, name, eventType string, properties map[string]interface{}) {
+	api, apiKey := implicitRestAPI, "RestApiId"
+	if eventType == "HttpApi" {
+		api, apiKey = implicitHTTPAPI, "ApiId"
+	}
+	if apiID, ok := properties[apiKey]; ok {
+		api, _ = getMap(apiID)["Ref"].(string)
+	}
+	event := apiEvent{
+		function: function,
+		path:     toString(properties["Path"]),
+		method:   strings.ToLower(toString(properties["Method"])),
+	}
+	if api != "" {
+		s.apiEvents[api] = append(s.apiEvents[api], event)
+	}
+
+	sourceArn := "arn:${AWS::Partition}:execute-api:${AWS::Region}:${AWS::AccountId}:${__ApiId__}/${__Stage__}/*"
+	if event.path != "" {
+		method := strings.ToUpper(event.method)
+		if method == "" || method == "ANY" {
+			method = "*"
+		}
+		sourceArn = strings.TrimSuffix(sourceArn, "*") + method + event.path
+	}
+	apiID := interface{}(ref(api))
+	if api == "" {
+		apiID = copyValue(properties[apiKey])
+	}
+	permission := s.permission(function, name, "apigateway.amazonaws.com")
+	permission.set("SourceArn", map[string]interface{}{
+		"Fn::Sub": []interface{}{
+			sourceArn,
+			map[string]interface{}{
+				"__ApiId__": apiID,
+				"__Stage__": "*",
+			},
+		},
+	}, "Events")
+}
+
+// implicitAPIs generates the APIs SAM creates for the events of the functions without API, they get the
+// Globals of their type and point to the first function with such events
+func (s *serverless) implicitAPIs() {
+	if events := s.apiEvents[implicitRestAPI]; len(events) > 0 && s.resources[implicitRestAPI] == nil {
+		s.restAPI(implicitRestAPI, s.implicitAPI(events[0].function, "Api"))
+	}
+	if events := s.apiEvents[implicitHTTPAPI]; len(events) > 0 && s.resources[implicitHTTPAPI] == nil {
+		s.httpAPI(implicitHTTPAPI, s.implicitAPI(events[0].function, "HttpApi"))
+	}
+}
+
+// implicitAPI returns the SAM resource of an implicit API, which has the lines of the function it is created for
+func (s *serverless) implicitAPI(function *samResource, globals string) *samResource {
+	api := *function
+	// the implicit APIs are created for all the functions, so they do not have their conditions
+	api.resource = map[string]interface{}{
+		linesKey: getLines(function.resource),
+	}
+	api.properties, api.propertiesLines = mergeGlobals(getMap(s.globals[globals]), map[string]interface{}{})
+	return &api
+}
+
+// restAPI generates the AWS::ApiGateway::RestApi of an AWS::Serverless::Api, with its deployment and stage
+func (s *serverless) restAPI(name string, api *samResource) {
+	restAPI := s.generate(api, name, "AWS::ApiGateway::RestApi")
+	restAPI.copyAll(restAPIProperties)
+	setBody(restAPI, s.apiBody(name, false))
+	switch endpoint := api.properties["EndpointConfiguration"].(type) {
+	case string:
+		restAPI.set("EndpointConfiguration", map[string]interface{}{
+			"Types": []interface{}{endpoint},
+		}, "EndpointConfiguration")
+	case map[string]interface{}:
+		configuration := map[string]interface{}{
+			"Types": []interface{}{copyValue(endpoint["Type"])},
+		}
+		if vpcEndpoints, ok := endpoint["VPCEndpointIds"]; ok {
+			configuration["VpcEndpointIds"] = copyValue(vpcEndpoints)
+		}
+		restAPI.set("EndpointConfiguration", configuration, "EndpointConfiguration")
+	}
+
+	deployment := s.generate(api, name+"Deployment", "AWS::ApiGateway::Deployment")
+	deployment.set("RestApiId", ref(name), "")
+	deployment.set("StageName", "Stage", "")
+
+	stageName := "Prod"
+	if value, ok := api.properties["StageName"].(string); ok {
+		stageName = value
+	}
+	stage := s.generate(api, name+logicalID(stageName)+"Stage", "AWS::ApiGateway::Stage")
+	stage.set("RestApiId", ref(name), "")
+	stage.set("DeploymentId", ref(name+"Deployment"), "")
+	if !stage.copy("StageName", "StageName") {
+		stage.set("StageName", stageName, "")
+	}
+	stage.copyAll(restAPIStageProperties)
+	if _, ok := api.properties["Tags"]; ok {
+		stage.set("Tags", tagsList(api.properties["Tags"], false), "Tags")
+	}
+}
+
+// httpAPI generates the AWS::ApiGatewayV2::Api of an AWS::Serverless::HttpApi and its stage, which
+// is the $default stage when the API does not have StageName
+func (s *serverless) httpAPI(name string, api *samResource) {
+	httpAPI := s.generate(api, name, "AWS::ApiGatewayV2::Api")
+	httpAPI.copyAll([]string{"Description", "DisableExecuteApiEndpoint", "FailOnWarnings", "Name", "Tags"})
+	httpAPI.copy("CorsConfiguration", "CorsConfiguration")
+	setBody(httpAPI, s.apiBody(name, true))
+
+	stageID := name + "ApiGatewayDefaultStage"
+	stageName := interface{}("$default")
+	if value, ok := api.properties["StageName"]; ok {
+		stageName = copyValue(value)
+		stageID = name + logicalID(toString(value)) + "Stage"
+	}
+	stage := s.generate(api, stageID, "AWS::ApiGatewayV2::Stage")
+	stage.set("ApiId", ref(name), "")
+	stage.set("StageName", stageName, "StageName")
+	stage.set("AutoDeploy", true, "")
+	stage.copyAll(httpAPIStageProperties)
+	stage.copy("Tags", "Tags")
+}
+
+// setBody sets the definition of an API from its DefinitionBody or DefinitionUri, or the definition
+// SAM generates from the events of the functions for the APIs without definition
+func setBody(api *generatedResource, body map[string]interface{}) {
+	if api.copy("Body", "DefinitionBody") {
+		return
+	}
+	if location := s3Location(api.source.properties["DefinitionUri"], "Bucket", "Key", "Version"); len(location) > 0 {
+		api.set("BodyS3Location", location, "DefinitionUri")
+		return
+	}
+	api.set("Body", body, "")
+}
+
+// apiBody returns the OpenAPI definition SAM generates for an API with the paths of the events of its functions,
+// which are integrated with the functions through a Lambda proxy integration
+func (s *serverless) apiBody(name string, http bool) map[string]interface{} {
+	paths := make(map[string]interface{})
+	for _, event := range s.apiEvents[name] {
+		path, method := event.path, event.method
+		if path == "" {
+			path = "$default"
+		}
+		if method == "" || method == "any" {
+			method = "x-amazon-apigateway-any-method"
+		}
+		integration := map[string]interface{}{
+			"httpMethod": "POST",
+			"type":       "aws_proxy",
+			"uri": map[string]interface{}{
+				"Fn::Sub": "arn:${AWS::Partition}:apigateway:${AWS::Region}:lambda:path/2015-03-31/functions/${" +
+					event.function.name + ".Arn}/invocations",
+			},
+		}
+		if http {
+			integration["payloadFormatVersion"] = "2.0"
+		}
+		methods, ok := paths[path].(map[string]interface{})
+		if !ok {
+			methods = make(map[string]interface{})
+			paths[path] = methods
+		}
+		methods[method] = map[string]interface{}{
+			"x-amazon-apigateway-integration": integration,
+			"responses":                       map[string]interface{}{},
+		}
+	}
+
+	body := map[string]interface{}{
+		"info": map[string]interface{}{
+			"version": "1.0",
+			"title":   ref("AWS::StackName"),
+		},
+		"paths": paths,
+	}
+	if http {
+		body["openapi"] = "3.0.1"
+	} else {
+		body["swagger"] = "2.0"
+	}
+	return body
+}
+
+// simpleTable generates the AWS::DynamoDB::Table of an AWS::Serverless::SimpleTable, which has a primary key
+// named id of type String by default and uses the on-demand billing mode when it does not set its throughput
+func (s *serverless) simpleTable(table *samResource) {
+	dynamoDB := s.generate(table, table.name, "AWS::DynamoDB::Table")
+	dynamoDB.copyAll([]string{"SSESpecification", "TableName"})
+
+	primaryKey := getMap(table.properties["PrimaryKey"])
+	keyName, keyType := "id", "String"
+	if value, ok := primaryKey["Name"]; ok {
+		keyName = toString(value)
+	}
+	if value, ok := primaryKey["Type"]; ok {
+		keyType = toString(value)
+	}
+	dynamoDB.set("AttributeDefinitions", []interface{}{
+		map[string]interface{}{
+			"AttributeName": keyName,
+			"AttributeType": attributeTypes[keyType],
+		},
+	}, "PrimaryKey")
+	dynamoDB.set("KeySchema", []interface{}{
+		map[string]interface{}{
+			"AttributeName": keyName,
+			"KeyType":       "HASH",
+		},
+	}, "PrimaryKey")
+	if !dynamoDB.copy("ProvisionedThroughput", "ProvisionedThroughput") {
+		dynamoDB.set("BillingMode", "PAY_PER_REQUEST", "")
+	}
+	if _, ok := table.properties["Tags"]; ok {
+		dynamoDB.set("Tags", tagsList(table.properties["Tags"], false), "Tags")
+	}
+}
@@ -0,0 +1,279 @@
+package cloudformation
+
+import (
+	"strconv"
+	"strings"
+)
+
+// functionProperties are the properties of an AWS::Serverless::Function with the same name and value
+// in its AWS::Lambda::Function
+var functionProperties = []string{
+	"Architectures", "CodeSigningConfigArn", "Description", "Environment", "EphemeralStorage", "FileSystemConfigs",
+	"FunctionName", "Handler", "ImageConfig", "KmsKeyArn", "Layers", "LoggingConfig", "MemorySize", "PackageType",
+	"ReservedConcurrentExecutions", "Runtime", "RuntimeManagementConfig", "SnapStart", "Timeout", "VpcConfig",
+}
+
+// eventSourceMappingProperties are the properties of the SQS, Kinesis and DynamoDB events with the same name
+// and value in their AWS::Lambda::EventSourceMapping
+var eventSourceMappingProperties = []string{
+	"BatchSize", "BisectBatchOnFunctionError", "DestinationConfig", "Enabled", "FilterCriteria",
+	"FunctionResponseTypes", "MaximumBatchingWindowInSeconds", "MaximumRecordAgeInSeconds", "MaximumRetryAttempts",
+	"ParallelizationFactor", "StartingPosition",
+}
+
+// eventSources are the properties with the ARN of the event source of the events that poll it, and the
+// managed policy SAM adds to the role of the function to read from it
+var eventSources = map[string]struct {
+	property string
+	policy   string
+}{
+	"SQS":      {property: "Queue", policy: "service-role/AWSLambdaSQSQueueExecutionRole"},
+	"Kinesis":  {property: "Stream", policy: "service-role/AWSLambdaKinesisExecutionRole"},
+	"DynamoDB": {property: "Stream", policy: "service-role/AWSLambdaDynamoDBExecutionRole"},
+}
+
+// function generates the AWS::Lambda::Function of an AWS::Serverless::Function, its role when the function does
+// not have one, and the resources of its events and its function URL
+func (s *serverless) function(function *samResource) {
+	lambda := s.generate(function, function.name, "AWS::Lambda::Function")
+	lambda.copyAll(functionProperties)
+	setCode(lambda)
+	if targetArn, ok := getMap(function.properties["DeadLetterQueue"])["TargetArn"]; ok {
+		lambda.set("DeadLetterConfig", map[string]interface{}{"TargetArn": copyValue(targetArn)}, "DeadLetterQueue")
+	}
+	if tracing, ok := function.properties["Tracing"]; ok {
+		lambda.set("TracingConfig", map[string]interface{}{"Mode": copyValue(tracing)}, "Tracing")
+	}
+	if !lambda.copy("Role", "Role") {
+		lambda.set("Role", getAtt(function.name+"Role", "Arn"), "")
+		s.functionRole(function)
+	}
+	lambda.set("Tags", tagsList(function.properties["Tags"], true), "Tags")
+
+	s.functionEvents(function)
+	s.functionURL(function)
+}
+
+// setCode sets the Code of the AWS::Lambda::Function of a function from its InlineCode, ImageUri or CodeUri
+func setCode(lambda *generatedResource) {
+	properties := lambda.source.properties
+	switch {
+	case properties["InlineCode"] != nil:
+		lambda.set("Code", map[string]interface{}{"ZipFile": copyValue(properties["InlineCode"])}, "InlineCode")
+	case properties["ImageUri"] != nil:
+		lambda.set("Code", map[string]interface{}{"ImageUri": copyValue(properties["ImageUri"])}, "ImageUri")
+	default:
+		if location := s3Location(properties["CodeUri"], "S3Bucket", "S3Key", "S3ObjectVersion"); len(location) > 0 {
+			lambda.set("Code", location, "CodeUri")
+		}
+	}
+}
+
+// functionRole generates the AWS::IAM::Role of a function without Role, with the managed policies SAM adds for
+// the features of the function and the policies of its Policies property
+func (s *serverless) functionRole(function *samResource) {
+	role := s.generate(function, function.name+"Role", "AWS::IAM::Role")
+	if !role.copy("AssumeRolePolicyDocument", "AssumeRolePolicyDocument") {
+		role.set("AssumeRolePolicyDocument", assumeRolePolicy("lambda.amazonaws.com"), "")
+	}
+	role.copy("PermissionsBoundary", "PermissionsBoundary")
+
+	managedPolicies, inlinePolicies := rolePolicies(function)
+	role.set("ManagedPolicyArns", managedPolicies, "Policies")
+	if len(inlinePolicies) > 0 {
+		role.set("Policies", inlinePolicies, "Policies")
+	}
+	role.set("Tags", tagsList(function.properties["Tags"], true), "Tags")
+}
+
+// rolePolicies returns the managed and inline policies of the role of a function, the statements of the Policies
+// property are inline policies and its names and ARNs are managed policies. The SAM policy templates are not expanded
+func rolePolicies(function *samResource) (managedPolicies, inlinePolicies []interface{}) {
+	managedPolicies = []interface{}{managedPolicyArn("service-role/AWSLambdaBasicExecutionRole")}
+	if function.properties["Tracing"] == "Active" {
+		managedPolicies = append(managedPolicies, managedPolicyArn("AWSXrayWriteOnlyAccess"))
+	}
+	if _, ok := function.properties["VpcConfig"]; ok {
+		managedPolicies = append(managedPolicies, managedPolicyArn("service-role/AWSLambdaVPCAccessExecutionRole"))
+	}
+	events := getMap(function.properties["Events"])
+	for _, name := range sortedKeys(events) {
+		if source, ok := eventSources[toString(getMap(events[name])["Type"])]; ok {
+			managedPolicies = append(managedPolicies, managedPolicyArn(source.policy))
+		}
+	}
+
+	inlinePolicies = make([]interface{}, 0)
+	for i, policy := range getPolicies(function.properties["Policies"]) {
+		switch v := policy.(type) {
+		case string:
+			managedPolicies = append(managedPolicies, managedPolicyArn(v))
+		case map[string]interface{}:
+			if _, ok := v["Statement"]; ok {
+				inlinePolicies = append(inlinePolicies, map[string]interface{}{
+					"PolicyName":     function.name + "RolePolicy" + strconv.Itoa(i),
+					"PolicyDocument": copyWithLines(v),
+				})
+			} else if _, _, isFunction := getFunction(v); isFunction {
+				managedPolicies = append(managedPolicies, copyValue(v))
+			}
+		}
+	}
+	if dlq := getMap(function.properties["DeadLetterQueue"]); dlq["TargetArn"] != nil {
+		inlinePolicies = append(inlinePolicies, deadLetterQueuePolicy(function.name, dlq))
+	}
+	return managedPolicies, inlinePolicies
+}
+
+// deadLetterQueuePolicy returns the inline policy that allows a function to send the failed events to its dead
+// letter queue, which can be an SQS queue or an SNS topic
+func deadLetterQueuePolicy(function string, dlq map[string]interface{}) map[string]interface{} {
+	action := "sqs:SendMessage"
+	if dlq["Type"] == "SNS" {
+		action = "sns:Publish"
+	}
+	return map[string]interface{}{
+		"PolicyName": function + "RoleDeadLetterQueuePolicy",
+		"PolicyDocument": policyDocument(map[string]interface{}{
+			"Action":   action,
+			"Effect":   "Allow",
+			"Resource": copyValue(dlq["TargetArn"]),
+		}),
+	}
+}
+
+// functionEvents generates the resources that invoke a function for each of its events
+func (s *serverless) functionEvents(function *samResource) {
+	events := getMap(function.properties["Events"])
+	for _, name := range sortedKeys(events) {
+		event := getMap(events[name])
+		properties := getMap(event["Properties"])
+		switch eventType := toString(event["Type"]); eventType {
+		case "Api", "HttpApi":
+			s.apiEvent(function, name, eventType, properties)
+		case "S3":
+			permission := s.permission(function, name, "s3.amazonaws.com")
+			permission.set("SourceAccount", ref("AWS::AccountId"), "Events")
+		case "SNS":
+			permission := s.permission(function, name, "sns.amazonaws.com")
+			permission.set("SourceArn", copyValue(properties["Topic"]), "Events")
+			subscription := s.generate(function, function.name+name, "AWS::SNS::Subscription")
+			subscription.set("Endpoint", getAtt(function.name, "Arn"), "Events")
+			subscription.set("Protocol", "lambda", "Events")
+			subscription.set("TopicArn", copyValue(properties["Topic"]), "Events")
+			if filterPolicy, ok := properties["FilterPolicy"]; ok {
+				subscription.set("FilterPolicy", copyValue(filterPolicy), "Events")
+			}
+		case "SQS", "Kinesis", "DynamoDB":
+			mapping := s.generate(function, function.name+name, "AWS::Lambda::EventSourceMapping")
+			mapping.set("FunctionName", ref(function.name), "Events")
+			mapping.set("EventSourceArn", copyValue(properties[eventSources[eventType].property]), "Events")
+			for _, key := range eventSourceMappingProperties {
+				if value, ok := properties[key]; ok {
+					mapping.set(key, copyValue(value), "Events")
+				}
+			}
+		case "Schedule", "CloudWatchEvent", "EventBridgeRule":
+			s.eventRule(function, name, eventType, properties)
+		}
+	}
+}
+
+// eventRule generates the AWS::Events::Rule of a Schedule, CloudWatchEvent or EventBridgeRule event of a function
+// and the permission of the rule to invoke the function
+func (s *serverless) eventRule(function *samResource, name, eventType string, properties map[string]interface{}) {
+	rule := s.generate(function, function.name+name, "AWS::Events::Rule")
+	if eventType == "Schedule" {
+		rule.set("ScheduleExpression", copyValue(properties["Schedule"]), "Events")
+	} else {
+		rule.set("EventPattern", copyValue(properties["Pattern"]), "Events")
+		if eventBusName, ok := properties["EventBusName"]; ok {
+			rule.set("EventBusName", copyValue(eventBusName), "Events")
+		}
+	}
+	state := "ENABLED"
+	if properties["Enabled"] == false {
+		state = "DISABLED"
+	}
+	rule.set("State", state, "Events")
+	rule.set("Targets", []interface{}{
+		map[string]interface{}{
+			"Arn": getAtt(function.name, "Arn"),
+			"Id":  function.name + name + "LambdaTarget",
+		},
+	}, "Events")
+
+	permission := s.permission(function, name, "events.amazonaws.com")
+	permission.set("SourceArn", getAtt(function.name+name, "Arn"), "Events")
+}
+
+// functionURL generates the AWS::Lambda::Url of a function with FunctionUrlConfig, and the permission
+// that allows anyone to invoke it when the URL does not use IAM authentication
+func (s *serverless) functionURL(function *samResource) {
+	config := getMap(function.properties["FunctionUrlConfig"])
+	if len(config) == 0 {
+		return
+	}
+	url := s.generate(function, function.name+"Url", "AWS::Lambda::Url")
+	url.set("TargetFunctionArn", ref(function.name), "FunctionUrlConfig")
+	for _, key := range []string{"AuthType", "Cors", "InvokeMode"} {
+		if value, ok := config[key]; ok {
+			url.set(key, copyValue(value), "FunctionUrlConfig")
+		}
+	}
+	if config["AuthType"] != "NONE" {
+		return
+	}
+	permission := s.generate(function, function.name+"UrlPublicPermissions", "AWS::Lambda::Permission")
+	permission.set("Action", "lambda:InvokeFunctionUrl", "FunctionUrlConfig")
+	permission.set("FunctionName", ref(function.name), "FunctionUrlConfig")
+	permission.set("FunctionUrlAuthType", "NONE", "FunctionUrlConfig")
+	permission.set("Principal", "*", "FunctionUrlConfig")
+}
+
+// permission generates the AWS::Lambda::Permission that allows the service of an event to invoke a function
+func (s *serverless) permission(function *samResource, event, principal string) *generatedResource {
+	permission := s.generate(function, function.name+event+"Permission", "AWS::Lambda::Permission")
+	permission.set("Action", "lambda:InvokeFunction", "Events")
+	permission.set("FunctionName", ref(function.name), "Events")
+	permission.set("Principal", principal, "Events")
+	return permission
+}
+
+// getPolicies returns the policies of the Policies property of a function, which can be a single policy or a list
+func getPolicies(value interface{}) []interface{} {
+	if policies, ok := getList(value); ok {
+		return policies
+	}
+	if value == nil {
+		return nil
+	}
+	return []interface{}{value}
+}
+
+// managedPolicyArn returns the ARN of a managed policy, the names of the AWS managed policies are converted to ARNs
+func managedPolicyArn(policy string) string {
+	if strings.HasPrefix(policy, "arn:") {
+		return policy
+	}
+	return "arn:aws:iam::aws:policy/" + policy
+}
+
+// assumeRolePolicy returns the policy that allows a service to assume a role
+func assumeRolePolicy(service string) map[string]interface{} {
+	return policyDocument(map[string]interface{}{
+		"Action": []interface{}{"sts:AssumeRole"},
+		"Effect": "Allow",
+		"Principal": map[string]interface{}{
+			"Service": []interface{}{service},
+		},
+	})
+}
+
+// policyDocument returns an IAM policy document with the statements passed
+func policyDocument(statements ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	}
+}
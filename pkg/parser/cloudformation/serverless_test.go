@@ -0,0 +1,108 @@
+package cloudformation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var samFixturePath = filepath.FromSlash("../../../test/fixtures/test_cloudformation_sam/template.yaml")
+
+// TestTransformServerless tests the functions [TransformServerless()] and all the methods called by them
+func TestTransformServerless(t *testing.T) {
+	documents := TransformServerless([]model.Document{parseTemplate(t, samFixturePath)})
+	require.Len(t, documents, 1)
+	resources := getMap(documents[0]["Resources"])
+
+	types := make(map[string]interface{})
+	for _, name := range sortedKeys(resources) {
+		types[name] = getMap(resources[name])["Type"]
+	}
+	require.Equal(t, map[string]interface{}{
+		"OrdersApi":                          "AWS::ApiGatewayV2::Api",
+		"OrdersApiprodStage":                 "AWS::ApiGatewayV2::Stage",
+		"OrdersFunction":                     "AWS::Lambda::Function",
+		"OrdersFunctionGetOrdersPermission":  "AWS::Lambda::Permission",
+		"OrdersFunctionQueue":                "AWS::Lambda::EventSourceMapping",
+		"OrdersFunctionRole":                 "AWS::IAM::Role",
+		"OrdersFunctionUrl":                  "AWS::Lambda::Url",
+		"OrdersFunctionUrlPublicPermissions": "AWS::Lambda::Permission",
+		"OrdersTable":                        "AWS::DynamoDB::Table",
+		"ServerlessRestApi":                  "AWS::ApiGateway::RestApi",
+		"ServerlessRestApiDeployment":        "AWS::ApiGateway::Deployment",
+		"ServerlessRestApiProdStage":         "AWS::ApiGateway::Stage",
+	}, types)
+
+	function := getMap(resources["OrdersFunction"])
+	require.Equal(t, map[string]interface{}{
+		"Handler": "app.handler",
+		"Runtime": "python3.12",
+		"Timeout": float64(30),
+		"Code": map[string]interface{}{
+			"S3Bucket": "artifacts",
+			"S3Key":    "orders.zip",
+		},
+		"Environment": map[string]interface{}{
+			"Variables": map[string]interface{}{
+				"LOG_LEVEL": "info",
+				"TABLE":     "orders",
+			},
+		},
+		"TracingConfig": map[string]interface{}{"Mode": "PassThrough"},
+		"Role":          "OrdersFunctionRole.Arn",
+		"Tags": []interface{}{
+			map[string]interface{}{"Key": "lambda:createdBy", "Value": "SAM"},
+		},
+	}, copyValue(function["Properties"]))
+	require.Equal(t, map[string]interface{}{SamResourceIDKey: "OrdersFunction"}, copyValue(function["Metadata"]))
+
+	role := getMap(getMap(resources["OrdersFunctionRole"])["Properties"])
+	require.Equal(t, []interface{}{
+		"arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole",
+		"arn:aws:iam::aws:policy/service-role/AWSLambdaSQSQueueExecutionRole",
+		"arn:aws:iam::aws:policy/AmazonDynamoDBReadOnlyAccess",
+	}, role["ManagedPolicyArns"])
+	policies, ok := role["Policies"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, policies, 1)
+	require.Equal(t, "OrdersFunctionRolePolicy1", getMap(policies[0])["PolicyName"])
+
+	permission := getMap(getMap(resources["OrdersFunctionUrlPublicPermissions"])["Properties"])
+	require.Equal(t, "*", permission["Principal"])
+}
+
+// TestTransformServerless_Lines tests that the generated resources point to the lines of their SAM resources
+func TestTransformServerless_Lines(t *testing.T) {
+	documents := TransformServerless([]model.Document{parseTemplate(t, samFixturePath)})
+	require.Len(t, documents, 1)
+	resources := getMap(documents[0]["Resources"])
+
+	require.Equal(t, 11, getLines(resources)["_kics_OrdersFunctionRole"].Line)
+	functionLines := getLines(getMap(resources["OrdersFunction"])["Properties"])
+	require.Equal(t, 5, functionLines["_kics_Runtime"].Line)
+	require.Equal(t, 16, functionLines["_kics_TracingConfig"].Line)
+	require.Equal(t, 17, functionLines["_kics_Environment"].Line)
+	roleLines := getLines(getMap(resources["OrdersFunctionRole"])["Properties"])
+	require.Equal(t, 20, roleLines["_kics_Policies"].Line)
+	require.Equal(t, 13, roleLines["_kics_AssumeRolePolicyDocument"].Line)
+	urlLines := getLines(getMap(resources["OrdersFunctionUrlPublicPermissions"])["Properties"])
+	require.Equal(t, 37, urlLines["_kics_Principal"].Line)
+}
+
+// TestTransformServerless_NotServerless tests that the templates without the SAM transform are not transformed
+func TestTransformServerless_NotServerless(t *testing.T) {
+	document := model.Document{
+		"Resources": map[string]interface{}{
+			"Function": map[string]interface{}{
+				"Type": "AWS::Serverless::Function",
+			},
+		},
+		"AWSTemplateFormatVersion": "2010-09-09",
+	}
+	require.Empty(t, TransformServerless([]model.Document{document}))
+
+	document["Transform"] = []interface{}{"AWS::LanguageExtensions", serverlessTransform}
+	require.Len(t, TransformServerless([]model.Document{document}), 1)
+}
@@ -0,0 +1,106 @@
+package cloudformation
+
+import (
+	"github.com/Checkmarx/kics/v2/pkg/model"
+)
+
+// RestoreShortForm replaces the intrinsic functions written in their YAML short form that were not evaluated,
+// ex: "!GetAtt Bucket.Arn", by their arguments, which is how the queries expect them
+func RestoreShortForm(document model.Document) model.Document {
+	restoreMap(document)
+	return document
+}
+
+// restoreMap restores the short form functions of the values of a map
+func restoreMap(value map[string]interface{}) {
+	for key, child := range value {
+		if key == linesKey {
+			continue
+		}
+		switch v := child.(type) {
+		case map[string]interface{}:
+			name, args, ok := getShortForm(v)
+			if !ok {
+				restoreMap(v)
+				continue
+			}
+			restored := restoreFunction(v, name, args)
+			value[key] = restored
+			// the line information of array arguments is kept by the function
+			if _, isArray := restored.([]interface{}); isArray {
+				if lines, functionLines := getLines(value), getLines(v); lines != nil && lines[linesPrefix+key] != nil &&
+					functionLines != nil && functionLines[linesPrefix+name] != nil {
+					lines[linesPrefix+key].Arr = functionLines[linesPrefix+name].Arr
+				}
+			}
+		case []interface{}:
+			restoreArray(v, func() []map[string]*model.LineObject {
+				if lines := getLines(value); lines != nil && lines[linesPrefix+key] != nil {
+					return lines[linesPrefix+key].Arr
+				}
+				return nil
+			})
+		}
+	}
+}
+
+// restoreArray restores the short form functions of the elements of an array, elementsLines returns the line
+// information of the elements, which is only needed when an element is restored
+func restoreArray(value []interface{}, elementsLines func() []map[string]*model.LineObject) {
+	for i, element := range value {
+		switch v := element.(type) {
+		case map[string]interface{}:
+			name, args, ok := getShortForm(v)
+			if !ok {
+				restoreMap(v)
+				continue
+			}
+			value[i] = restoreFunction(v, name, args)
+			lines := elementsLines()
+			if len(lines) != len(value) || lines[i] == nil || lines[i][linesPrefix+name] == nil {
+				continue
+			}
+			if _, isArray := value[i].([]interface{}); isArray {
+				lines[i] = map[string]*model.LineObject{
+					defaultLines: {
+						Line: lines[i][linesPrefix+name].Line,
+						Arr:  lines[i][linesPrefix+name].Arr,
+					},
+				}
+			}
+		case []interface{}:
+			index := i
+			restoreArray(v, func() []map[string]*model.LineObject {
+				if lines := elementsLines(); len(lines) == len(value) && lines[index] != nil && lines[index][defaultLines] != nil {
+					return lines[index][defaultLines].Arr
+				}
+				return nil
+			})
+		}
+	}
+}
+
+// restoreFunction restores the short form functions of the arguments of a short form function and returns them
+func restoreFunction(function map[string]interface{}, name string, args interface{}) interface{} {
+	switch v := args.(type) {
+	case map[string]interface{}:
+		restoreMap(v)
+	case []interface{}:
+		restoreArray(v, func() []map[string]*model.LineObject {
+			if lines := getLines(function); lines != nil && lines[linesPrefix+name] != nil {
+				return lines[linesPrefix+name].Arr
+			}
+			return nil
+		})
+	}
+	return args
+}
+
+// getShortForm returns the name and the arguments of a function written in its short form
+func getShortForm(value map[string]interface{}) (name string, args interface{}, ok bool) {
+	if _, ok = value[model.ShortFormKey]; !ok {
+		return "", nil, false
+	}
+	name, args, ok = getFunction(value)
+	return name, args, ok || name == "Condition"
+}
@@ -19,6 +19,7 @@ type Parser struct {
 type Resource struct {
 	CommandList map[string][]Command `json:"command"`
 	Arguments   []Command            `json:"args"`
+	Stages      []Stage              `json:"stages"`
 }
 
 // Command is the struct for each dockerfile command
@@ -51,6 +52,7 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, []int, e
 	fromValue := ""
 	from := make(map[string][]Command)
 	arguments := make([]Command, 0)
+	stages := make([]Stage, 0)
 	ignoreStruct := newIgnore()
 
 	args := make(map[string]string, 0)
@@ -98,6 +100,8 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, []int, e
 			envs = saveEnvs(envs, cmd.Value)
 		}
 
+		stages = addToStages(stages, fromValue, &cmd)
+
 		if fromValue == "" {
 			arguments = append(arguments, cmd)
 		} else {
@@ -106,9 +110,11 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, []int, e
 	}
 
 	doc := &model.Document{}
-	var resource Resource
-	resource.CommandList = from
-	resource.Arguments = arguments
+	resource := Resource{
+		CommandList: from,
+		Arguments:   arguments,
+		Stages:      linkStages(stages),
+	}
 
 	j, err := json.Marshal(resource)
 	if err != nil {
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Stage is a build stage of a Dockerfile, which starts at a FROM instruction
+type Stage struct {
+	// Name is the name of the stage, which is its alias, or its index when it has none
+	Name string
+	// Command is the key of the commands of the stage, which is the value of its FROM instruction
+	Command string
+	// Image is the image the stage is built from, which can be a previous stage
+	Image string
+	// BaseStage is the name of the previous stage the stage is built from, if any
+	BaseStage string
+	// CopyFrom are the names of the previous stages the stage copies files from, with COPY --from
+	// or RUN --mount=from
+	CopyFrom []string
+	// Chain are the keys of the commands of the stages the stage is built from followed by its own key,
+	// the stage inherits their instructions, such as USER and HEALTHCHECK
+	Chain []string
+	// Final is true for the last stage, which is the image built when no target is set
+	Final bool
+	// Shipped is true for the final stage and the stages it is built from, whose instructions are part of
+	// the image built, the stages only copied from are not shipped
+	Shipped   bool
+	StartLine int `json:"_kics_line"`
+
+	// references are the stages or images copied from, as written
+	references []string
+	base       int
+}
+
+// newStage creates the stage started by a FROM instruction, ex: FROM golang:1.21 AS builder
+func newStage(key string, from *Command, index int) Stage {
+	stage := Stage{
+		Name:       strconv.Itoa(index),
+		Command:    key,
+		CopyFrom:   make([]string, 0),
+		Chain:      make([]string, 0),
+		StartLine:  from.StartLine,
+		references: make([]string, 0),
+		base:       -1,
+	}
+	if len(from.Value) > 0 {
+		stage.Image = from.Value[0]
+	}
+	if len(from.Value) > 2 && strings.EqualFold(from.Value[1], "as") {
+		stage.Name = from.Value[2]
+	}
+	return stage
+}
+
+// addToStages adds the stage started by a FROM instruction to the stages, the other instructions record the
+// stages copied from by the current stage
+func addToStages(stages []Stage, key string, cmd *Command) []Stage {
+	if cmd.Cmd == "from" {
+		return append(stages, newStage(key, cmd, len(stages)))
+	}
+	if len(stages) > 0 {
+		stages[len(stages)-1].addReferences(cmd)
+	}
+	return stages
+}
+
+// addReferences records the stages or images a COPY or RUN instruction of the stage copies files from
+func (s *Stage) addReferences(cmd *Command) {
+	for _, flag := range cmd.Flags {
+		switch {
+		case cmd.Cmd == "copy" && strings.HasPrefix(flag, "--from="):
+			s.references = append(s.references, strings.TrimPrefix(flag, "--from="))
+		case cmd.Cmd == "run" && strings.HasPrefix(flag, "--mount="):
+			for _, option := range strings.Split(strings.TrimPrefix(flag, "--mount="), ",") {
+				if strings.HasPrefix(option, "from=") {
+					s.references = append(s.references, strings.TrimPrefix(option, "from="))
+				}
+			}
+		}
+	}
+}
+
+// linkStages builds the graph of the stages of a Dockerfile, the base stage and the stages copied from are
+// previous stages referenced by their name or index, the other references are images
+func linkStages(stages []Stage) []Stage {
+	for i := range stages {
+		stages[i].base = stageIndex(stages[:i], stages[i].Image)
+		if base := stages[i].base; base >= 0 {
+			stages[i].BaseStage = stages[base].Name
+			stages[i].Chain = append(stages[i].Chain, stages[base].Chain...)
+		}
+		stages[i].Chain = append(stages[i].Chain, stages[i].Command)
+		for _, reference := range stages[i].references {
+			if index := stageIndex(stages[:i], reference); index >= 0 {
+				stages[i].CopyFrom = append(stages[i].CopyFrom, stages[index].Name)
+			}
+		}
+	}
+	if len(stages) == 0 {
+		return stages
+	}
+	stages[len(stages)-1].Final = true
+	for i := len(stages) - 1; i >= 0; i = stages[i].base {
+		stages[i].Shipped = true
+	}
+	return stages
+}
+
+// stageIndex returns the index of the stage referenced by its name, which is case insensitive, or by its index,
+// -1 is returned if the reference is not a stage
+func stageIndex(stages []Stage, reference string) int {
+	if index, err := strconv.Atoi(reference); err == nil && index >= 0 && index < len(stages) {
+		return index
+	}
+	for i := len(stages) - 1; i >= 0; i-- {
+		if strings.EqualFold(stages[i].Name, reference) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParser_Parse_Stages tests the stage graph built by [Parse()]
+func TestParser_Parse_Stages(t *testing.T) {
+	p := &Parser{}
+	sample := `FROM golang:1.21 AS builder
+RUN go build -o /app .
+
+FROM alpine:3.19 AS certs
+RUN apk add --no-cache ca-certificates
+
+FROM alpine:3.19 AS base
+USER app
+
+FROM base
+COPY --from=builder /app /app
+COPY --from=1 /etc/ssl/certs /etc/ssl/certs
+COPY --from=nginx:latest /etc/nginx/nginx.conf /etc/nginx/nginx.conf
+RUN --mount=type=cache,from=Builder,target=/cache ls /cache
+`
+
+	doc, _, err := p.Parse("Dockerfile", []byte(sample))
+	require.NoError(t, err)
+	require.Len(t, doc, 1)
+
+	stages := doc[0]["stages"].([]interface{})
+	require.Len(t, stages, 4)
+
+	builder := stages[0].(map[string]interface{})
+	require.Equal(t, "builder", builder["Name"])
+	require.Equal(t, "golang:1.21 AS builder", builder["Command"])
+	require.Equal(t, "golang:1.21", builder["Image"])
+	require.Equal(t, false, builder["Shipped"])
+	require.Equal(t, false, builder["Final"])
+	require.Equal(t, float64(1), builder["_kics_line"])
+
+	base := stages[2].(map[string]interface{})
+	require.Equal(t, "base", base["Name"])
+	require.Equal(t, true, base["Shipped"])
+	require.Equal(t, false, base["Final"])
+
+	final := stages[3].(map[string]interface{})
+	require.Equal(t, "3", final["Name"])
+	require.Equal(t, "base", final["BaseStage"])
+	require.Equal(t, true, final["Final"])
+	require.Equal(t, true, final["Shipped"])
+	require.Equal(t, []interface{}{"alpine:3.19 AS base", "base"}, final["Chain"])
+	require.Equal(t, []interface{}{"builder", "certs", "builder"}, final["CopyFrom"])
+	require.Equal(t, float64(10), final["_kics_line"])
+}
+
+// TestStageIndex tests the function [stageIndex()]
+func TestStageIndex(t *testing.T) {
+	stages := []Stage{{Name: "builder"}, {Name: "1"}}
+	require.Equal(t, 0, stageIndex(stages, "builder"))
+	require.Equal(t, 0, stageIndex(stages, "BUILDER"))
+	require.Equal(t, 1, stageIndex(stages, "1"))
+	require.Equal(t, -1, stageIndex(stages, "2"))
+	require.Equal(t, -1, stageIndex(stages, "alpine:3.19"))
+}
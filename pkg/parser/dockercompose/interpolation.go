@@ -0,0 +1,243 @@
+package dockercompose
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+)
+
+// envFileName is the name of the file where Docker Compose reads the variables of a project from
+const envFileName = ".env"
+
+// singleExpressionRegex matches a value that is a single variable reference, ex: ${PRIVILEGED} or $PRIVILEGED
+var singleExpressionRegex = regexp.MustCompile(`^(?:\$\{[^{}]*\}|\$[A-Za-z_]\w*)$`)
+
+// loadEnv loads the variables of an env file, ex: .env, along with its content, the values of the variables can
+// reference the variables declared before them, the variables of the file are not known if it can not be read
+func loadEnv(fileSystem filesystem.FileSystem, path string) (env map[string]string, content []byte, ok bool) {
+	content, err := fileSystem.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return map[string]string{}, nil, false
+	}
+	return parseEnv(content), content, true
+}
+
+// parseEnv parses the KEY=VALUE lines of an env file, the values can be quoted, the single quoted values are
+// taken as written and the double quoted values have their escape sequences replaced
+func parseEnv(content []byte) map[string]string {
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, "'"):
+			value = strings.TrimPrefix(value, "'")
+			if end := strings.Index(value, "'"); end >= 0 {
+				value = value[:end]
+			}
+		case strings.HasPrefix(value, `"`):
+			value = unquote(strings.TrimPrefix(value, `"`))
+			if interpolated, known := interpolate(value, env); known {
+				value = interpolated
+			}
+		default:
+			if comment := strings.Index(value, " #"); comment >= 0 {
+				value = strings.TrimSpace(value[:comment])
+			}
+			if interpolated, known := interpolate(value, env); known {
+				value = interpolated
+			}
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// unquote returns the value of a double quoted string up to its closing quote, with its escape sequences replaced
+func unquote(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '"':
+			return builder.String()
+		case value[i] == '\\' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				builder.WriteByte('\n')
+			case 't':
+				builder.WriteByte('\t')
+			default:
+				builder.WriteByte(value[i])
+			}
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
+
+// interpolateValues replaces the variables referenced by the strings of a value by their value in env, the
+// strings referencing variables that are not in env are kept as written
+func interpolateValues(value interface{}, env map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		interpolated, known := interpolate(v, env)
+		if !known {
+			return v
+		}
+		if singleExpressionRegex.MatchString(v) {
+			return typedValue(interpolated)
+		}
+		return interpolated
+	case map[string]interface{}:
+		for key, child := range v {
+			if key != linesKey {
+				v[key] = interpolateValues(child, env)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = interpolateValues(element, env)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// typedValue returns the boolean or number written by a value that is a single variable reference, as Docker
+// Compose converts them to the type of the attribute, ex: privileged: ${PRIVILEGED}
+func typedValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+	return value
+}
+
+// interpolate replaces the variables referenced by a string, ex: ${TAG:-latest}, by their value, $$ is an
+// escaped $, the string is not known if it references a variable that is not in env and has no default value
+func interpolate(value string, env map[string]string) (string, bool) {
+	var builder strings.Builder
+	known := true
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+		switch next := value[i+1]; {
+		case next == '$':
+			builder.WriteByte('$')
+			i++
+		case next == '{':
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				builder.WriteString(value[i:])
+				return builder.String(), known
+			}
+			expanded, expandedKnown := expand(value[i+2:end], env)
+			builder.WriteString(expanded)
+			known = known && expandedKnown
+			i = end
+		case isNameChar(next, true):
+			end := i + 1
+			for end < len(value) && isNameChar(value[end], false) {
+				end++
+			}
+			variable, ok := env[value[i+1:end]]
+			builder.WriteString(variable)
+			known = known && ok
+			i = end - 1
+		default:
+			builder.WriteByte('$')
+		}
+	}
+	return builder.String(), known
+}
+
+// expand returns the value of the expression of a braced variable reference, which is the name of the variable
+// followed by an optional modifier, ex: TAG:-latest, whose default or alternative value can reference variables
+func expand(expression string, env map[string]string) (string, bool) {
+	end := 0
+	for end < len(expression) && isNameChar(expression[end], end == 0) {
+		end++
+	}
+	name, modifier := expression[:end], expression[end:]
+	variable, set := env[name]
+	nonEmpty := set && variable != ""
+	switch {
+	case modifier == "":
+		return variable, set
+	case strings.HasPrefix(modifier, ":-"):
+		if nonEmpty {
+			return variable, true
+		}
+		return interpolate(modifier[2:], env)
+	case strings.HasPrefix(modifier, "-"):
+		if set {
+			return variable, true
+		}
+		return interpolate(modifier[1:], env)
+	case strings.HasPrefix(modifier, ":+"):
+		if nonEmpty {
+			return interpolate(modifier[2:], env)
+		}
+		return "", true
+	case strings.HasPrefix(modifier, "+"):
+		if set {
+			return interpolate(modifier[1:], env)
+		}
+		return "", true
+	case strings.HasPrefix(modifier, ":?"):
+		return variable, nonEmpty
+	case strings.HasPrefix(modifier, "?"):
+		return variable, set
+	default:
+		return "", false
+	}
+}
+
+// closingBrace returns the index of the brace closing a variable reference, whose default value can have
+// variable references, -1 is returned if the reference is not closed
+func closingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isNameChar returns true if the character can be part of the name of a variable, names do not start with a digit
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (!first && c >= '0' && c <= '9')
+}
@@ -0,0 +1,71 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"TAG": "1.25", "EMPTY": "", "REGISTRY": "registry.example.com"}
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		known bool
+	}{
+		{name: "braced", value: "nginx:${TAG}", want: "nginx:1.25", known: true},
+		{name: "unbraced", value: "nginx:$TAG", want: "nginx:1.25", known: true},
+		{name: "default when unset", value: "${MISSING:-latest}", want: "latest", known: true},
+		{name: "default when empty", value: "${EMPTY:-latest}", want: "latest", known: true},
+		{name: "default only when unset", value: "${EMPTY-latest}", want: "", known: true},
+		{name: "nested default", value: "${MISSING:-${REGISTRY}/nginx}", want: "registry.example.com/nginx", known: true},
+		{name: "alternative", value: "${TAG:+pinned}", want: "pinned", known: true},
+		{name: "required", value: "${MISSING:?tag is required}", want: "", known: false},
+		{name: "escaped", value: "$$HOME", want: "$HOME", known: true},
+		{name: "unknown", value: "${MISSING}", want: "", known: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, known := interpolate(tt.value, env)
+			require.Equal(t, tt.known, known)
+			if tt.known {
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInterpolateValues(t *testing.T) {
+	env := map[string]string{"PRIVILEGED": "true", "CPUS": "0.5"}
+	value := map[string]interface{}{
+		"privileged": "${PRIVILEGED}",
+		"cpus":       "$CPUS",
+		"command":    []interface{}{"run", "--privileged=${PRIVILEGED}"},
+		"user":       "${USER}",
+	}
+	require.Equal(t, map[string]interface{}{
+		"privileged": true,
+		"cpus":       0.5,
+		"command":    []interface{}{"run", "--privileged=true"},
+		"user":       "${USER}",
+	}, interpolateValues(value, env))
+}
+
+func TestParseEnv(t *testing.T) {
+	content := []byte(`# comment
+TAG=1.25 # inline comment
+export REGISTRY=registry.example.com
+IMAGE="${REGISTRY}/nginx:${TAG}"
+PATTERN='${TAG}'
+MESSAGE="line\nbreak"
+INVALID
+`)
+	require.Equal(t, map[string]string{
+		"TAG":      "1.25",
+		"REGISTRY": "registry.example.com",
+		"IMAGE":    "registry.example.com/nginx:1.25",
+		"PATTERN":  "${TAG}",
+		"MESSAGE":  "line\nbreak",
+	}, parseEnv(content))
+}
@@ -0,0 +1,192 @@
+package dockercompose
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+)
+
+var (
+	// replacedKeys are the attributes whose list is replaced by the list of the file merged, instead of appended to
+	replacedKeys = []string{"command", "entrypoint", "test"}
+	// mappingKeys are the attributes that are mappings which can be written as lists of KEY=VALUE entries,
+	// their entries are merged by key
+	mappingKeys = []string{"environment", "labels", "annotations", "sysctls", "extra_hosts", "args", "networks"}
+	// mountKeys are the attributes whose entries are merged by their target, ex: the path of the container of a volume
+	mountKeys = []string{"volumes", "devices"}
+	// sourceKeys are the attributes whose entries are merged by their source, ex: the name of a secret
+	sourceKeys = []string{"secrets", "configs"}
+)
+
+// mergeMaps merges the attributes of override into base as Docker Compose merges the files of a project and
+// the services extended, the mappings are merged, the lists are merged depending on the attribute and the other
+// values are replaced, the line information of each attribute is the one of the file it comes from
+func mergeMaps(base, override map[string]interface{}) map[string]interface{} {
+	baseLines := getMap(base[linesKey])
+	overrideLines := getMap(override[linesKey])
+	for key, value := range override {
+		if key == linesKey {
+			continue
+		}
+		entry := getMap(overrideLines[linesPrefix+key])
+		current, exists := base[key]
+		if !exists {
+			base[key] = value
+			baseLines[linesPrefix+key] = entry
+			continue
+		}
+		base[key], baseLines[linesPrefix+key] = mergeValue(key, current, value, getMap(baseLines[linesPrefix+key]), entry)
+	}
+	base[linesKey] = baseLines
+	return base
+}
+
+// mergeValue merges the value of an attribute written by two files and returns it along with its line information
+func mergeValue(key string, base, override interface{}, baseEntry, overrideEntry map[string]interface{}) (
+	value interface{}, entry map[string]interface{}) {
+	baseMap, baseIsMap := base.(map[string]interface{})
+	overrideMap, overrideIsMap := override.(map[string]interface{})
+	baseList, baseIsList := base.([]interface{})
+	overrideList, overrideIsList := override.([]interface{})
+
+	switch {
+	case baseIsMap && overrideIsMap:
+		return mergeMaps(baseMap, overrideMap), baseEntry
+	case baseIsMap && overrideIsList && utils.Contains(key, mappingKeys):
+		return mergeMaps(baseMap, listToMap(key, overrideList, overrideEntry)), baseEntry
+	case baseIsList && overrideIsMap && utils.Contains(key, mappingKeys):
+		return mergeMaps(listToMap(key, baseList, baseEntry), overrideMap), baseEntry
+	case baseIsList && overrideIsList && !utils.Contains(key, replacedKeys):
+		list, arr := mergeLists(key, baseList, overrideList, toList(baseEntry[arrayKey]), toList(overrideEntry[arrayKey]))
+		entry = make(map[string]interface{}, len(baseEntry))
+		for name, line := range baseEntry {
+			entry[name] = line
+		}
+		entry[arrayKey] = arr
+		return list, entry
+	default:
+		return override, overrideEntry
+	}
+}
+
+// mergeLists merges the entries of two lists, the entries with the same key replace the entry of the base list
+// and the other entries are appended, the lists whose entries have no key are appended with the entries missing
+func mergeLists(key string, base, override, baseArr, overrideArr []interface{}) (list, arr []interface{}) {
+	list = append(make([]interface{}, 0, len(base)+len(override)), base...)
+	arr = make([]interface{}, 0, len(base)+len(override))
+	for i := range base {
+		arr = append(arr, entryAt(baseArr, i))
+	}
+	for i, element := range override {
+		index := indexOf(key, list, element)
+		switch {
+		case index < 0:
+			list = append(list, element)
+			arr = append(arr, entryAt(overrideArr, i))
+		case entryKey(key, element) != "":
+			list[index] = element
+			arr[index] = entryAt(overrideArr, i)
+		}
+	}
+	return list, arr
+}
+
+// indexOf returns the index of the entry of a list with the same key as element, or equal to it when the
+// entries of the attribute have no key, -1 is returned if there is none
+func indexOf(key string, list []interface{}, element interface{}) int {
+	elementKey := entryKey(key, element)
+	for i, candidate := range list {
+		if elementKey != "" && entryKey(key, candidate) == elementKey {
+			return i
+		}
+		if elementKey == "" && reflect.DeepEqual(candidate, element) {
+			return i
+		}
+	}
+	return -1
+}
+
+// entryKey returns the key an entry of a list is merged by, which depends on the attribute of the list
+func entryKey(key string, element interface{}) string {
+	switch {
+	case utils.Contains(key, mappingKeys):
+		name, _ := splitEntry(key, element)
+		return name
+	case utils.Contains(key, mountKeys):
+		if mount, ok := element.(map[string]interface{}); ok {
+			target, _ := mount["target"].(string)
+			return target
+		}
+		if mount, ok := element.(string); ok {
+			parts := strings.Split(mount, ":")
+			if len(parts) > 1 {
+				return parts[1]
+			}
+			return parts[0]
+		}
+	case utils.Contains(key, sourceKeys):
+		if reference, ok := element.(map[string]interface{}); ok {
+			source, _ := reference["source"].(string)
+			return source
+		}
+		if reference, ok := element.(string); ok {
+			return reference
+		}
+	}
+	return ""
+}
+
+// splitEntry returns the key and the value of a KEY=VALUE entry, the value of an entry without a value is nil,
+// the extra hosts can also be written as HOST:IP
+func splitEntry(key string, element interface{}) (name string, value interface{}) {
+	entry, ok := element.(string)
+	if !ok {
+		return "", nil
+	}
+	separator := "="
+	if key == "extra_hosts" && !strings.Contains(entry, "=") {
+		separator = ":"
+	}
+	name, entryValue, found := strings.Cut(entry, separator)
+	if !found {
+		return name, nil
+	}
+	return name, entryValue
+}
+
+// listToMap converts a list of KEY=VALUE entries into a mapping, each key is at the line of its entry
+func listToMap(key string, list []interface{}, entry map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(list)+1)
+	lines := map[string]interface{}{defaultLines: copyEntry(entry)}
+	arr := toList(entry[arrayKey])
+	for i, element := range list {
+		name, value := splitEntry(key, element)
+		if name == "" {
+			continue
+		}
+		result[name] = value
+		lines[linesPrefix+name] = getMap(entryAt(arr, i))[defaultLines]
+	}
+	result[linesKey] = lines
+	return result
+}
+
+// copyEntry returns the line information of an attribute without the line information of its entries
+func copyEntry(entry map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(entry))
+	for key, value := range entry {
+		if key != arrayKey {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// entryAt returns the line information of an entry of a list
+func entryAt(arr []interface{}, index int) interface{} {
+	if index < len(arr) {
+		return arr[index]
+	}
+	return map[string]interface{}{}
+}
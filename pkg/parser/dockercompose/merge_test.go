@@ -0,0 +1,82 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeMaps(t *testing.T) {
+	base := map[string]interface{}{
+		"image":       "nginx",
+		"command":     []interface{}{"nginx", "-g"},
+		"ports":       []interface{}{"80:80"},
+		"volumes":     []interface{}{"./html:/usr/share/nginx/html:ro", "logs:/var/log/nginx"},
+		"environment": []interface{}{"LOG_LEVEL=info", "MODE=prod"},
+		"secrets":     []interface{}{"tls"},
+	}
+	override := map[string]interface{}{
+		"image":   "nginx:1.25",
+		"command": []interface{}{"nginx-debug"},
+		"ports":   []interface{}{"80:80", "443:443"},
+		"volumes": []interface{}{
+			map[string]interface{}{"type": "bind", "source": "/var/log", "target": "/var/log/nginx"},
+		},
+		"environment": map[string]interface{}{"LOG_LEVEL": "debug"},
+		"secrets":     []interface{}{map[string]interface{}{"source": "tls", "target": "cert.pem"}},
+	}
+
+	merged := mergeMaps(base, override)
+	delete(merged, linesKey)
+	environment := merged["environment"].(map[string]interface{})
+	delete(environment, linesKey)
+	require.Equal(t, map[string]interface{}{
+		"image":   "nginx:1.25",
+		"command": []interface{}{"nginx-debug"},
+		"ports":   []interface{}{"80:80", "443:443"},
+		"volumes": []interface{}{
+			"./html:/usr/share/nginx/html:ro",
+			map[string]interface{}{"type": "bind", "source": "/var/log", "target": "/var/log/nginx"},
+		},
+		"environment": map[string]interface{}{"LOG_LEVEL": "debug", "MODE": "prod"},
+		"secrets":     []interface{}{map[string]interface{}{"source": "tls", "target": "cert.pem"}},
+	}, merged)
+}
+
+func TestMergeMaps_Lines(t *testing.T) {
+	base := map[string]interface{}{
+		"ports": []interface{}{"80:80"},
+		linesKey: map[string]interface{}{
+			linesPrefix + "ports": map[string]interface{}{
+				lineKey:  float64(3),
+				arrayKey: []interface{}{map[string]interface{}{defaultLines: map[string]interface{}{lineKey: float64(4)}}},
+			},
+		},
+	}
+	override := map[string]interface{}{
+		"ports":      []interface{}{"443:443"},
+		"privileged": true,
+		linesKey: map[string]interface{}{
+			linesPrefix + "ports": map[string]interface{}{
+				lineKey: float64(2),
+				arrayKey: []interface{}{
+					map[string]interface{}{defaultLines: map[string]interface{}{lineKey: float64(3), FileKey: "override.yml"}},
+				},
+				FileKey: "override.yml",
+			},
+			linesPrefix + "privileged": map[string]interface{}{lineKey: float64(4), FileKey: "override.yml"},
+		},
+	}
+
+	lines := mergeMaps(base, override)[linesKey].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{
+		linesPrefix + "ports": map[string]interface{}{
+			lineKey: float64(3),
+			arrayKey: []interface{}{
+				map[string]interface{}{defaultLines: map[string]interface{}{lineKey: float64(4)}},
+				map[string]interface{}{defaultLines: map[string]interface{}{lineKey: float64(3), FileKey: "override.yml"}},
+			},
+		},
+		linesPrefix + "privileged": map[string]interface{}{lineKey: float64(4), FileKey: "override.yml"},
+	}, lines)
+}
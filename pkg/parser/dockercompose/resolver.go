@@ -0,0 +1,467 @@
+package dockercompose
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	linesKey     = "_kics_lines"
+	linesPrefix  = "_kics_"
+	defaultLines = "_kics__default"
+	lineKey      = "_kics_line"
+	arrayKey     = "_kics_arr"
+	// FileKey is the key of the line information of the attributes that come from another file than the one
+	// scanned, such as an override file, its value is the path of the file
+	FileKey     = "_kics_file"
+	servicesKey = "services"
+	extendsKey  = "extends"
+	includeKey  = "include"
+	// defaultMaxDepth is the maximum depth of the files included and extended when no maximum resolver depth is set
+	defaultMaxDepth = 15
+)
+
+var (
+	// composeFileRegex matches the names of the Compose files, ex: docker-compose.yml or compose.prod.yaml
+	composeFileRegex = regexp.MustCompile(`^(?:docker-)?compose(?:\.[\w-]+)*\.ya?ml$`)
+	// defaultFileNames are the names of the Compose files Docker Compose loads by default, along with their
+	// override file, ex: docker-compose.override.yml
+	defaultFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+	// includedKeys are the top-level elements of the included files added to the project including them
+	includedKeys = []string{"services", "networks", "volumes", "secrets", "configs"}
+)
+
+// Resolver resolves the project model of a Compose file, which is the file merged with its override file,
+// with its variables interpolated from the .env file of the project, its services extended and its included
+// files added, as Docker Compose does before running it. The files of the project are read from its FileSystem,
+// which does not read the files outside the scanned paths
+type Resolver struct {
+	maxDepth   int
+	path       string
+	files      map[string]model.ResolvedFile
+	fileSystem filesystem.FileSystem
+}
+
+// NewResolver creates a Resolver following the files included and extended up to maxDepth files deep
+func NewResolver(maxDepth int) *Resolver {
+	return NewResolverWithFileSystem(maxDepth, nil)
+}
+
+// NewResolverWithFileSystem creates a Resolver following the files included and extended up to maxDepth files
+// deep, reading them from the given FileSystem
+func NewResolverWithFileSystem(maxDepth int, fileSystem filesystem.FileSystem) *Resolver {
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	return &Resolver{
+		maxDepth:   maxDepth,
+		files:      make(map[string]model.ResolvedFile),
+		fileSystem: filesystem.Get(fileSystem),
+	}
+}
+
+// IsCompose returns true if the document is a Compose file, which has services and is named as a Compose file
+// or has services with an image, a build or extending another service
+func IsCompose(document map[string]interface{}, path string) bool {
+	services, ok := document[servicesKey].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if composeFileRegex.MatchString(filepath.Base(path)) {
+		return true
+	}
+	for name, service := range services {
+		if s, ok := service.(map[string]interface{}); ok && name != linesKey {
+			if s["image"] != nil || s["build"] != nil || s[extendsKey] != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Resolve returns the project model of a Compose file. The default Compose files, ex: docker-compose.yml, are
+// merged with their override file, ex: docker-compose.override.yml, and an override file is resolved as the
+// project of its Compose file, so the results of both files are the same. The variables are interpolated with
+// the variables of the .env file next to the Compose file, the services extending another service are merged
+// with it and the elements of the included files are added. The attributes of the other files keep their line
+// information, with the path of their file
+func (r *Resolver) Resolve(document model.Document, path string) (resolved model.Document) {
+	resolved = document
+	// handle panic during resolve process
+	defer func() {
+		if err := recover(); err != nil {
+			log.Warn().Msgf("Recovered from panic during resolve of Compose file %s: %v", path, err)
+		}
+	}()
+
+	r.path = path
+	files := projectFiles(r.fileSystem, path)
+	env := r.loadEnv(filepath.Join(filepath.Dir(files[0]), envFileName))
+	project := r.loadProject(files, env, []string{path}, document)
+	if project == nil {
+		return document
+	}
+	return model.Document(project)
+}
+
+// ResolvedFiles returns the files the project model was resolved from, other than the file resolved
+func (r *Resolver) ResolvedFiles() map[string]model.ResolvedFile {
+	return r.files
+}
+
+// LinkedFiles returns the files of the project of a Compose file that are not included or extended, which are
+// its .env file and its override or Compose file
+func LinkedFiles(path string) []string {
+	dir, name := filepath.Split(path)
+	files := []string{filepath.Join(dir, envFileName)}
+	for _, base := range defaultFileNames {
+		names := append([]string{base}, overrideFileNames(base)...)
+		if !utils.Contains(name, names) {
+			continue
+		}
+		for _, linked := range names {
+			if linked != name && !utils.Contains(filepath.Join(dir, linked), files) {
+				files = append(files, filepath.Join(dir, linked))
+			}
+		}
+	}
+	return files
+}
+
+// projectFiles returns the files of the project of a Compose file, which are the file itself, unless it is
+// a default Compose file or the override file of an existing Compose file, in which case both files are returned
+func projectFiles(fileSystem filesystem.FileSystem, path string) []string {
+	dir, name := filepath.Split(path)
+	for _, base := range defaultFileNames {
+		overrides := overrideFileNames(base)
+		if name != base && !utils.Contains(name, overrides) {
+			continue
+		}
+		basePath := filepath.Join(dir, base)
+		if !isFile(fileSystem, basePath) {
+			continue
+		}
+		for _, override := range overrides {
+			if overridePath := filepath.Join(dir, override); isFile(fileSystem, overridePath) {
+				if name != base && name != override {
+					return []string{path}
+				}
+				return []string{basePath, overridePath}
+			}
+		}
+		return []string{basePath}
+	}
+	return []string{path}
+}
+
+// overrideFileNames returns the names of the override file of a default Compose file
+func overrideFileNames(base string) []string {
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	return []string{stem + ".override.yaml", stem + ".override.yml"}
+}
+
+// loadProject loads the files of a project and merges them in order, the file resolved is not loaded again
+// since its document is already parsed
+func (r *Resolver) loadProject(files []string, env map[string]string, stack []string,
+	document map[string]interface{}) map[string]interface{} {
+	var project map[string]interface{}
+	for _, file := range files {
+		doc := document
+		if file != r.path || document == nil {
+			if utils.Contains(file, stack) || len(stack) >= r.maxDepth {
+				log.Debug().Msgf("Compose file %s not loaded, cycle or maximum depth reached", file)
+				continue
+			}
+			loaded, ok := r.loadFile(file)
+			if !ok {
+				continue
+			}
+			doc = loaded
+		}
+		doc = r.resolveFile(doc, file, env, append(append([]string{}, stack...), file))
+		if project == nil {
+			project = doc
+			continue
+		}
+		project = mergeMaps(project, doc)
+	}
+	return project
+}
+
+// resolveFile interpolates the variables of a Compose file, adds the elements of the files it includes and
+// merges its services with the services they extend
+func (r *Resolver) resolveFile(doc map[string]interface{}, file string, env map[string]string,
+	stack []string) map[string]interface{} {
+	interpolateValues(doc, env)
+	r.resolveIncludes(doc, file, stack)
+	if services, ok := doc[servicesKey].(map[string]interface{}); ok {
+		for name := range services {
+			if name != linesKey {
+				services[name] = r.extendService(name, services, file, env, stack)
+			}
+		}
+	}
+	return doc
+}
+
+// resolveIncludes adds the services, networks, volumes, secrets and configs of the projects included by
+// a Compose file, the elements declared by the file are kept
+func (r *Resolver) resolveIncludes(doc map[string]interface{}, file string, stack []string) {
+	includes, ok := doc[includeKey].([]interface{})
+	if !ok {
+		return
+	}
+	for _, include := range includes {
+		paths, envFiles := includeEntry(include, filepath.Dir(file))
+		if len(paths) == 0 {
+			continue
+		}
+		env := make(map[string]string)
+		for _, envFile := range envFiles {
+			for name, value := range r.loadEnv(envFile) {
+				env[name] = value
+			}
+		}
+		included := r.loadProject(paths, env, stack, nil)
+		for _, key := range includedKeys {
+			addMissing(doc, included, key)
+		}
+	}
+	delete(doc, includeKey)
+	delete(getMap(doc[linesKey]), linesPrefix+includeKey)
+}
+
+// includeEntry returns the paths of the files of an include entry and its env files, which are the .env file
+// of the project directory of the entry unless they are set, the paths are relative to dir
+func includeEntry(include interface{}, dir string) (paths, envFiles []string) {
+	var projectDir string
+	switch entry := include.(type) {
+	case string:
+		paths = []string{filepath.Join(dir, filepath.FromSlash(entry))}
+	case map[string]interface{}:
+		for _, path := range toStrings(entry["path"]) {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(path)))
+		}
+		for _, envFile := range toStrings(entry["env_file"]) {
+			envFiles = append(envFiles, filepath.Join(dir, filepath.FromSlash(envFile)))
+		}
+		if project, ok := entry["project_directory"].(string); ok {
+			projectDir = filepath.Join(dir, filepath.FromSlash(project))
+		}
+	}
+	if len(paths) > 0 && projectDir == "" {
+		projectDir = filepath.Dir(paths[0])
+	}
+	if len(paths) > 0 && len(envFiles) == 0 {
+		envFiles = []string{filepath.Join(projectDir, envFileName)}
+	}
+	return paths, envFiles
+}
+
+// addMissing adds the elements of a top-level key of an included project that the project including it does
+// not declare, along with their line information
+func addMissing(doc, included map[string]interface{}, key string) {
+	source, ok := included[key].(map[string]interface{})
+	if !ok {
+		return
+	}
+	target, ok := doc[key].(map[string]interface{})
+	if !ok {
+		if _, exists := doc[key]; exists {
+			return
+		}
+		target = map[string]interface{}{linesKey: map[string]interface{}{defaultLines: getMap(source[linesKey])[defaultLines]}}
+		doc[key] = target
+		lines := getMap(doc[linesKey])
+		lines[linesPrefix+key] = getMap(included[linesKey])[linesPrefix+key]
+		doc[linesKey] = lines
+	}
+	targetLines := getMap(target[linesKey])
+	sourceLines := getMap(source[linesKey])
+	for name, value := range source {
+		if _, exists := target[name]; exists || name == linesKey {
+			continue
+		}
+		target[name] = value
+		targetLines[linesPrefix+name] = sourceLines[linesPrefix+name]
+	}
+	target[linesKey] = targetLines
+}
+
+// extendService returns a service merged with the service it extends, which is in the same file or in the file
+// set by extends, the extended services are resolved first
+func (r *Resolver) extendService(name string, services map[string]interface{}, file string, env map[string]string,
+	stack []string) interface{} {
+	service, ok := services[name].(map[string]interface{})
+	if !ok {
+		return services[name]
+	}
+	extends, ok := service[extendsKey]
+	if !ok {
+		return service
+	}
+	baseName, baseFile := extendsTarget(extends, file)
+	if baseName == "" || (baseName == name && baseFile == file) || utils.Contains(baseFile+"#"+baseName, stack) ||
+		len(stack) >= r.maxDepth {
+		log.Debug().Msgf("Compose service %s not extended, cycle or maximum depth reached", name)
+		return service
+	}
+
+	baseServices := services
+	if baseFile != file {
+		loaded, ok := r.loadFile(baseFile)
+		if !ok {
+			return service
+		}
+		interpolateValues(loaded, env)
+		if baseServices, ok = loaded[servicesKey].(map[string]interface{}); !ok {
+			return service
+		}
+	}
+	base, ok := r.extendService(baseName, baseServices, baseFile, env,
+		append(append([]string{}, stack...), file+"#"+name)).(map[string]interface{})
+	if !ok {
+		return service
+	}
+
+	delete(service, extendsKey)
+	serviceLines := getMap(service[linesKey])
+	delete(serviceLines, linesPrefix+extendsKey)
+	extended := mergeMaps(deepCopy(base).(map[string]interface{}), service)
+	getMap(extended[linesKey])[defaultLines] = serviceLines[defaultLines]
+	return extended
+}
+
+// extendsTarget returns the name of the service extended and the path of its file, the file is relative to
+// the file of the service extending it
+func extendsTarget(extends interface{}, file string) (name, path string) {
+	switch e := extends.(type) {
+	case string:
+		return e, file
+	case map[string]interface{}:
+		name, _ = e["service"].(string)
+		if baseFile, ok := e["file"].(string); ok {
+			return name, filepath.Join(filepath.Dir(file), filepath.FromSlash(baseFile))
+		}
+		return name, file
+	}
+	return "", file
+}
+
+// loadFile loads the first document of a Compose file, the line information of the files other than the
+// file resolved gets the path of their file
+func (r *Resolver) loadFile(path string) (map[string]interface{}, bool) {
+	content, err := r.fileSystem.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, false
+	}
+	document := model.Document{}
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&document); err != nil {
+		return nil, false
+	}
+	if path != r.path {
+		r.addFile(path, content)
+		setFile(map[string]interface{}(document), path)
+	}
+	return document, true
+}
+
+// loadEnv loads the variables of an env file of the project
+func (r *Resolver) loadEnv(path string) map[string]string {
+	env, content, ok := loadEnv(r.fileSystem, path)
+	if ok {
+		r.addFile(path, content)
+	}
+	return env
+}
+
+// addFile records a file the project model was resolved from
+func (r *Resolver) addFile(path string, content []byte) {
+	r.files[path] = model.ResolvedFile{
+		Path:         path,
+		Content:      content,
+		LinesContent: utils.SplitLines(string(content)),
+	}
+}
+
+// setFile sets the path of the file of all the line information of a value
+func setFile(value interface{}, path string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := v[lineKey]; ok {
+			v[FileKey] = path
+		}
+		for _, child := range v {
+			setFile(child, path)
+		}
+	case []interface{}:
+		for _, element := range v {
+			setFile(element, path)
+		}
+	}
+}
+
+// deepCopy returns a copy of a value, so a service extended by several services is not modified by them
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[key] = deepCopy(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			result = append(result, deepCopy(element))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// isFile returns true if the path is an existing file of the FileSystem
+func isFile(fileSystem filesystem.FileSystem, path string) bool {
+	info, err := fileSystem.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// getMap returns a value as a map, an empty map is returned if the value is not a map
+func getMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+// toList returns a value as a list, an empty list is returned if the value is not a list
+func toList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{}
+}
+
+// toStrings returns the strings of a value that is a string or a list of strings
+func toStrings(value interface{}) []string {
+	if s, ok := value.(string); ok {
+		return []string{s}
+	}
+	strs := make([]string, 0)
+	for _, element := range toList(value) {
+		if s, ok := element.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
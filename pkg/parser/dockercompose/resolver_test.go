@@ -0,0 +1,207 @@
+package dockercompose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var fixtureDir = filepath.Join("..", "..", "..", "test", "fixtures", "test_compose_project")
+
+func resolveFixture(t *testing.T, name string) (map[string]interface{}, *Resolver) {
+	path := filepath.Join(fixtureDir, name)
+	resolver := NewResolver(0)
+	resolver.path = path
+	document, ok := resolver.loadFile(path)
+	require.True(t, ok)
+	return resolver.Resolve(model.Document(document), path), resolver
+}
+
+func TestResolve(t *testing.T) {
+	project, resolver := resolveFixture(t, "docker-compose.yml")
+	services := project["services"].(map[string]interface{})
+	require.NotContains(t, project, "include")
+
+	web := services["web"].(map[string]interface{})
+	require.Equal(t, "nginx:latest", web["image"])
+	require.Equal(t, true, web["privileged"])
+	require.Equal(t, "host", web["network_mode"])
+	require.Equal(t, "debug", web["environment"].(map[string]interface{})["LOG_LEVEL"])
+
+	worker := services["worker"].(map[string]interface{})
+	require.NotContains(t, worker, "extends")
+	require.Equal(t, "busybox:1.36", worker["image"])
+	require.Equal(t, []interface{}{"ALL"}, worker["cap_add"])
+	require.Equal(t, []interface{}{"worker", "--queue", "jobs"}, worker["command"])
+
+	exporter := services["exporter"].(map[string]interface{})
+	require.Equal(t, "host", exporter["pid"])
+
+	files := make([]string, 0)
+	for path := range resolver.ResolvedFiles() {
+		files = append(files, filepath.Base(path))
+	}
+	require.ElementsMatch(t, []string{".env", "docker-compose.override.yml", "common.yml", "compose.yaml"}, files)
+}
+
+func TestResolve_Lines(t *testing.T) {
+	project, _ := resolveFixture(t, "docker-compose.yml")
+	web := project["services"].(map[string]interface{})["web"].(map[string]interface{})
+	webLines := web[linesKey].(map[string]interface{})
+
+	require.Equal(t, float64(5), webLines[linesPrefix+"image"].(map[string]interface{})[lineKey])
+	require.NotContains(t, webLines[linesPrefix+"image"], FileKey)
+
+	privileged := webLines[linesPrefix+"privileged"].(map[string]interface{})
+	require.Equal(t, float64(3), privileged[lineKey])
+	require.Equal(t, filepath.Join(fixtureDir, "docker-compose.override.yml"), privileged[FileKey])
+
+	worker := project["services"].(map[string]interface{})["worker"].(map[string]interface{})
+	workerLines := worker[linesKey].(map[string]interface{})
+	require.Equal(t, float64(10), workerLines[defaultLines].(map[string]interface{})[lineKey])
+	capAdd := workerLines[linesPrefix+"cap_add"].(map[string]interface{})
+	require.Equal(t, float64(4), capAdd[lineKey])
+	require.Equal(t, filepath.Join(fixtureDir, "common.yml"), capAdd[FileKey])
+}
+
+func TestResolve_OverrideFile(t *testing.T) {
+	project, resolver := resolveFixture(t, "docker-compose.override.yml")
+	services := project["services"].(map[string]interface{})
+	require.Contains(t, services, "worker")
+	web := services["web"].(map[string]interface{})
+	require.Equal(t, true, web["privileged"])
+
+	webLines := web[linesKey].(map[string]interface{})
+	require.NotContains(t, webLines[linesPrefix+"privileged"], FileKey)
+	require.Equal(t, filepath.Join(fixtureDir, "docker-compose.yml"), webLines[linesPrefix+"image"].(map[string]interface{})[FileKey])
+	require.Contains(t, resolver.ResolvedFiles(), filepath.Join(fixtureDir, "docker-compose.yml"))
+}
+
+func TestIsCompose(t *testing.T) {
+	tests := []struct {
+		name     string
+		document map[string]interface{}
+		path     string
+		want     bool
+	}{
+		{
+			name:     "compose file name",
+			document: map[string]interface{}{"services": map[string]interface{}{"web": map[string]interface{}{}}},
+			path:     "compose.prod.yaml",
+			want:     true,
+		},
+		{
+			name: "services with image",
+			document: map[string]interface{}{"services": map[string]interface{}{
+				"web": map[string]interface{}{"image": "nginx"},
+			}},
+			path: "stack.yml",
+			want: true,
+		},
+		{
+			name:     "services without image",
+			document: map[string]interface{}{"services": map[string]interface{}{"web": map[string]interface{}{}}},
+			path:     "stack.yml",
+			want:     false,
+		},
+		{
+			name:     "playbook",
+			document: map[string]interface{}{"playbooks": []interface{}{}},
+			path:     "docker-compose.yml",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsCompose(tt.document, tt.path))
+		})
+	}
+}
+
+func TestProjectFiles(t *testing.T) {
+	fileSystem := &filesystem.OS{}
+	base := filepath.Join(fixtureDir, "docker-compose.yml")
+	override := filepath.Join(fixtureDir, "docker-compose.override.yml")
+	require.Equal(t, []string{base, override}, projectFiles(fileSystem, base))
+	require.Equal(t, []string{base, override}, projectFiles(fileSystem, override))
+	common := filepath.Join(fixtureDir, "common.yml")
+	require.Equal(t, []string{common}, projectFiles(fileSystem, common))
+	included := filepath.Join(fixtureDir, "monitoring", "compose.yaml")
+	require.Equal(t, []string{included}, projectFiles(fileSystem, included))
+}
+
+func TestLinkedFiles(t *testing.T) {
+	require.Equal(t, []string{
+		filepath.Join(fixtureDir, ".env"),
+		filepath.Join(fixtureDir, "docker-compose.override.yaml"),
+		filepath.Join(fixtureDir, "docker-compose.override.yml"),
+	}, LinkedFiles(filepath.Join(fixtureDir, "docker-compose.yml")))
+}
+
+func TestResolve_OutsideRoots(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		filepath.Join("outside", ".env"):        "PRIVILEGED=true\n",
+		filepath.Join("outside", "common.yml"):  "services:\n  base:\n    privileged: true\n",
+		filepath.Join("outside", "compose.yml"): "services:\n  exporter:\n    pid: host\n",
+		filepath.Join("project", "compose.yml"): "include:\n  - path: ../outside/compose.yml\n    env_file: ../outside/.env\n" +
+			"services:\n  web:\n    extends:\n      file: ../outside/common.yml\n      service: base\n    image: nginx\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	path := filepath.Join(dir, "project", "compose.yml")
+
+	tests := []struct {
+		name       string
+		fileSystem filesystem.FileSystem
+		services   []string
+		privileged interface{}
+		resolved   []string
+	}{
+		{
+			name:       "unrestricted",
+			fileSystem: &filesystem.OS{},
+			services:   []string{"exporter", "web"},
+			privileged: true,
+			resolved:   []string{filepath.Join("outside", ".env"), filepath.Join("outside", "common.yml"), filepath.Join("outside", "compose.yml")},
+		},
+		{
+			name:       "outside the roots",
+			fileSystem: filesystem.NewOS([]string{filepath.Join(dir, "project")}),
+			services:   []string{"web"},
+			privileged: nil,
+			resolved:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewResolverWithFileSystem(0, tt.fileSystem)
+			resolver.path = path
+			document, ok := resolver.loadFile(path)
+			require.True(t, ok)
+			services := resolver.Resolve(model.Document(document), path)["services"].(map[string]interface{})
+
+			names := make([]string, 0)
+			for name := range services {
+				if name != linesKey {
+					names = append(names, name)
+				}
+			}
+			require.ElementsMatch(t, tt.services, names)
+			require.Equal(t, tt.privileged, services["web"].(map[string]interface{})["privileged"])
+			resolved := make([]string, 0)
+			for file := range resolver.ResolvedFiles() {
+				rel, err := filepath.Rel(dir, file)
+				require.NoError(t, err)
+				resolved = append(resolved, rel)
+			}
+			require.ElementsMatch(t, tt.resolved, resolved)
+		})
+	}
+}
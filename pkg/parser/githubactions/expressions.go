@@ -0,0 +1,150 @@
+package githubactions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// expressionRegex matches the expressions of a workflow, ex: ${{ inputs.name }}
+	expressionRegex = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	// referenceRegex matches the references to the inputs and secrets passed to a reusable workflow or
+	// a composite action, ex: inputs.name or secrets.TOKEN
+	referenceRegex = regexp.MustCompile(`\b(inputs|secrets)\.([A-Za-z_][\w-]*)`)
+)
+
+// context holds the inputs and secrets passed to a reusable workflow or a composite action by the workflow
+// calling it, the names of the inputs and secrets are case insensitive
+type context struct {
+	inputs  map[string]interface{}
+	secrets map[string]interface{}
+	// inherit is true when the secrets of the caller are inherited, the references to the secrets are kept
+	inherit bool
+}
+
+// newContext creates the context of a reusable workflow or a composite action, the inputs are the values
+// passed with 'with', or their default value, and the secrets are the values passed with 'secrets'
+func newContext(with, definitions, secrets interface{}) *context {
+	c := &context{
+		inputs:  make(map[string]interface{}),
+		secrets: make(map[string]interface{}),
+	}
+	for name, definition := range getMap(definitions) {
+		if value, ok := getMap(definition)["default"]; ok && name != linesKey {
+			c.inputs[strings.ToLower(name)] = value
+		}
+	}
+	for name, value := range getMap(with) {
+		if name != linesKey {
+			c.inputs[strings.ToLower(name)] = value
+		}
+	}
+	if s, ok := secrets.(string); ok && s == "inherit" {
+		c.inherit = true
+	}
+	for name, value := range getMap(secrets) {
+		if name != linesKey {
+			c.secrets[strings.ToLower(name)] = value
+		}
+	}
+	return c
+}
+
+// lookup returns the value of an input or a secret of the context
+func (c *context) lookup(kind, name string) (interface{}, bool) {
+	values := c.inputs
+	if kind == "secrets" {
+		if c.inherit {
+			return nil, false
+		}
+		values = c.secrets
+	}
+	value, ok := values[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	switch value.(type) {
+	case string, bool, float64, int:
+		return value, true
+	default:
+		return nil, false
+	}
+}
+
+// render replaces the references to the inputs and secrets of the strings of a value by the values passed
+func (c *context) render(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = c.render(child)
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = c.render(element)
+		}
+		return v
+	case string:
+		return c.renderString(v)
+	default:
+		return value
+	}
+}
+
+// renderString replaces the references to the inputs and secrets of the expressions of a string. An expression
+// that is only a reference is replaced by the value passed, as GitHub does, so the expressions of the value,
+// ex: ${{ github.event.issue.title }}, are found in the scripts using the input, the references of the other
+// expressions are replaced by an operand with the same value
+func (c *context) renderString(value string) string {
+	return expressionRegex.ReplaceAllStringFunc(value, func(expression string) string {
+		inner := expressionRegex.FindStringSubmatch(expression)[1]
+		if match := referenceRegex.FindStringSubmatch(strings.TrimSpace(inner)); match != nil &&
+			match[0] == strings.TrimSpace(inner) {
+			if passed, ok := c.lookup(match[1], match[2]); ok {
+				return fmt.Sprint(passed)
+			}
+			return expression
+		}
+		return "${{" + referenceRegex.ReplaceAllStringFunc(inner, func(reference string) string {
+			match := referenceRegex.FindStringSubmatch(reference)
+			if passed, ok := c.lookup(match[1], match[2]); ok {
+				return operand(passed)
+			}
+			return reference
+		}) + "}}"
+	})
+}
+
+// operand returns a value as the operand of an expression, the expressions of a string are kept as the
+// arguments of a format call, ex: "PR ${{ github.head_ref }}" is format('PR {0}', github.head_ref)
+func operand(value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	matches := expressionRegex.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return "(" + strings.TrimSpace(s[matches[0][2]:matches[0][3]]) + ")"
+	}
+
+	format := &strings.Builder{}
+	args := make([]string, 0, len(matches))
+	last := 0
+	for _, match := range matches {
+		format.WriteString(formatLiteral(s[last:match[0]]))
+		fmt.Fprintf(format, "{%d}", len(args))
+		args = append(args, strings.TrimSpace(s[match[2]:match[3]]))
+		last = match[1]
+	}
+	format.WriteString(formatLiteral(s[last:]))
+	if len(args) == 0 {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return fmt.Sprintf("format('%s', %s)", format.String(), strings.Join(args, ", "))
+}
+
+// formatLiteral escapes the text of the format string of a format call
+func formatLiteral(text string) string {
+	return strings.NewReplacer("'", "''", "{", "{{", "}", "}}").Replace(text)
+}
@@ -0,0 +1,44 @@
+package githubactions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_RenderString(t *testing.T) {
+	c := newContext(
+		map[string]interface{}{"Title": "${{ github.event.issue.title }}", "count": float64(2), "label": "it's {a}"},
+		map[string]interface{}{"level": map[string]interface{}{"default": "info"}},
+		map[string]interface{}{"token": "${{ secrets.GH_TOKEN }}"},
+	)
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "input", value: `echo "${{ inputs.title }}"`, want: `echo "${{ github.event.issue.title }}"`},
+		{name: "default", value: "--level ${{inputs.level}}", want: "--level info"},
+		{name: "secret", value: "${{ secrets.TOKEN }}", want: "${{ secrets.GH_TOKEN }}"},
+		{name: "expression", value: "${{ inputs.title || 'none' }}", want: "${{ (github.event.issue.title) || 'none' }}"},
+		{name: "number", value: "${{ inputs.count > 1 }}", want: "${{ 2 > 1 }}"},
+		{name: "literal", value: "${{ contains(inputs.label, 'a') }}", want: "${{ contains('it''s {a}', 'a') }}"},
+		{name: "unknown", value: "${{ inputs.missing }} ${{ github.sha }}", want: "${{ inputs.missing }} ${{ github.sha }}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, c.renderString(tt.value))
+		})
+	}
+}
+
+func TestOperand(t *testing.T) {
+	require.Equal(t, "(github.head_ref)", operand("${{ github.head_ref }}"))
+	require.Equal(t, "format('PR {0} by {1}', github.head_ref, github.actor)", operand("PR ${{ github.head_ref }} by ${{ github.actor }}"))
+	require.Equal(t, "true", operand(true))
+}
+
+func TestContext_InheritedSecrets(t *testing.T) {
+	c := newContext(nil, nil, "inherit")
+	require.Equal(t, "${{ secrets.NPM_TOKEN }}", c.renderString("${{ secrets.NPM_TOKEN }}"))
+}
@@ -0,0 +1,391 @@
+package githubactions
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	linesKey     = "_kics_lines"
+	linesPrefix  = "_kics_"
+	defaultLines = "_kics__default"
+	lineKey      = "_kics_line"
+	arrayKey     = "_kics_arr"
+	onKey        = "on"
+	jobsKey      = "jobs"
+	stepsKey     = "steps"
+	usesKey      = "uses"
+	// defaultMaxDepth is the maximum depth of the reusable workflows and composite actions followed when no
+	// maximum resolver depth is set
+	defaultMaxDepth = 15
+)
+
+// actionFileNames are the names of the metadata file of an action, in the directory of the action
+var actionFileNames = []string{"action.yml", "action.yaml"}
+
+// Resolver resolves the local reusable workflows called by the jobs of a GitHub Actions workflow and the local
+// composite actions used by its steps, with the inputs and secrets passed to them, so the data of the events
+// flowing into their scripts through the inputs is analyzed with the events triggering the workflow
+type Resolver struct {
+	maxDepth   int
+	fileSystem filesystem.FileSystem
+	// root is the root of the repository, where the paths of the local workflows and actions start
+	root  string
+	files map[string]bool
+}
+
+// NewResolver creates a Resolver following the reusable workflows and composite actions up to maxDepth files deep
+func NewResolver(maxDepth int) *Resolver {
+	return NewResolverWithFileSystem(maxDepth, nil)
+}
+
+// NewResolverWithFileSystem creates a Resolver reading the reusable workflows and composite actions from the given
+// FileSystem
+func NewResolverWithFileSystem(maxDepth int, fileSystem filesystem.FileSystem) *Resolver {
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	return &Resolver{
+		maxDepth:   maxDepth,
+		fileSystem: filesystem.Get(fileSystem),
+		files:      make(map[string]bool),
+	}
+}
+
+// IsWorkflow returns true if the document is a GitHub Actions workflow, which has triggers and jobs
+func IsWorkflow(document map[string]interface{}) bool {
+	_, on := document[onKey]
+	_, jobs := document[jobsKey].(map[string]interface{})
+	return on && jobs
+}
+
+// Resolve adds the jobs of the local reusable workflows called by the jobs of a workflow, named after the job
+// calling the workflow and their name, ex: call-build/build, and replaces the steps using a local composite
+// action by the steps of the action. The references to the inputs and secrets of the workflows and actions are
+// replaced by the values passed, ex: ${{ inputs.title }} by ${{ github.event.pull_request.title }}. The jobs and
+// steps loaded from other files are placed at the line of the job or step using them
+func (r *Resolver) Resolve(document model.Document, path string) model.Document {
+	// handle panic during resolve process
+	defer func() {
+		if err := recover(); err != nil {
+			log.Warn().Msgf("Recovered from panic during resolve of GitHub workflow %s: %v", path, err)
+		}
+	}()
+
+	r.root = repositoryRoot(path)
+	r.resolveJobs(getMap(document[jobsKey]), []string{path})
+	return document
+}
+
+// LinkedFiles returns the files a workflow depends on, which are the files of the reusable workflows and
+// composite actions resolved, as patterns since they may not exist yet
+func LinkedFiles(path string, maxDepth int) []string {
+	document, err := loadDocument(filesystem.Get(nil), path)
+	if err != nil || !IsWorkflow(document) {
+		return []string{}
+	}
+	r := NewResolver(maxDepth)
+	r.Resolve(document, path)
+	delete(r.files, path)
+	files := make([]string, 0, len(r.files))
+	for file := range r.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// resolveJobs resolves the jobs of a workflow, the jobs of the reusable workflows called are added to the jobs
+// and the steps using composite actions are replaced by their steps
+func (r *Resolver) resolveJobs(jobs map[string]interface{}, stack []string) {
+	jobsLines := getMap(jobs[linesKey])
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		if name != linesKey {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		job := getMap(jobs[name])
+		if job == nil {
+			continue
+		}
+		jobLines := getMap(job[linesKey])
+		if uses, ok := job[usesKey].(string); ok {
+			line := lineOf(getMap(jobLines[linesPrefix+usesKey]))
+			for calledName, called := range r.calledJobs(job, uses, stack) {
+				key := name + "/" + calledName
+				jobs[key] = called
+				called[linesKey] = generatedLines(called, line)
+				if jobsLines != nil {
+					jobsLines[linesPrefix+key] = map[string]interface{}{lineKey: line}
+				}
+			}
+			continue
+		}
+		stepsLines := getMap(jobLines[linesPrefix+stepsKey])
+		steps, lines := r.resolveSteps(toList(job[stepsKey]), toList(stepsLines[arrayKey]), stack)
+		if _, ok := job[stepsKey]; ok {
+			job[stepsKey] = steps
+			setArray(stepsLines, lines)
+		}
+	}
+}
+
+// calledJobs returns the jobs of the local reusable workflow called by a job, with the inputs and secrets
+// passed by the job, the reusable workflows and composite actions they use are also resolved
+func (r *Resolver) calledJobs(job map[string]interface{}, uses string, stack []string) map[string]map[string]interface{} {
+	path, ok := r.localPath(uses)
+	if !ok || !isWorkflowFile(path) {
+		return nil
+	}
+	document, ok := r.load(path, stack)
+	if !ok || !IsWorkflow(document) {
+		return nil
+	}
+	call := getMap(getMap(document[onKey])["workflow_call"])
+	c := newContext(job["with"], call["inputs"], job["secrets"])
+	jobs := getMap(c.render(withoutLines(document[jobsKey])))
+	r.resolveJobs(jobs, append(stack, path))
+
+	called := make(map[string]map[string]interface{}, len(jobs))
+	for name, value := range jobs {
+		if m := getMap(value); m != nil {
+			called[name] = m
+		}
+	}
+	return called
+}
+
+// resolveSteps resolves a list of steps, the steps using a local composite action are replaced by the steps
+// of the action, with the inputs passed by the step
+func (r *Resolver) resolveSteps(steps, lines []interface{}, stack []string) (resolved, resolvedLines []interface{}) {
+	resolved = make([]interface{}, 0, len(steps))
+	resolvedLines = make([]interface{}, 0, len(steps))
+	for i, element := range steps {
+		stepLines := lineAt(lines, i)
+		step := getMap(element)
+		uses, ok := step[usesKey].(string)
+		if !ok {
+			resolved, resolvedLines = append(resolved, element), append(resolvedLines, stepLines)
+			continue
+		}
+		actionSteps, ok := r.compositeSteps(step, uses, stack)
+		if !ok {
+			resolved, resolvedLines = append(resolved, element), append(resolvedLines, stepLines)
+			continue
+		}
+		line := lineOf(getMap(stepLines[linesPrefix+usesKey]))
+		if line == 0 {
+			line = lineOf(stepLines)
+		}
+		resolved, resolvedLines = appendGenerated(resolved, resolvedLines, actionSteps, line)
+	}
+	return resolved, resolvedLines
+}
+
+// compositeSteps returns the steps of the local composite action used by a step, with the inputs passed by
+// the step, the composite actions they use are also resolved
+func (r *Resolver) compositeSteps(step map[string]interface{}, uses string, stack []string) ([]interface{}, bool) {
+	dir, ok := r.localPath(uses)
+	if !ok {
+		return nil, false
+	}
+	for _, name := range actionFileNames {
+		path := filepath.Join(dir, name)
+		document, ok := r.load(path, stack)
+		if !ok {
+			continue
+		}
+		runs := getMap(document["runs"])
+		if using, _ := runs["using"].(string); using != "composite" {
+			return nil, false
+		}
+		c := newContext(step["with"], document["inputs"], nil)
+		steps := toList(c.render(withoutLines(runs[stepsKey])))
+		resolved, _ := r.resolveSteps(steps, nil, append(stack, path))
+		return resolved, true
+	}
+	return nil, false
+}
+
+// localPath returns the path of a local reusable workflow or action, which starts with ./ and is relative to
+// the root of the repository, the paths outside the root of the repository are not local
+func (r *Resolver) localPath(uses string) (string, bool) {
+	if !strings.HasPrefix(uses, "./") {
+		return "", false
+	}
+	local := filepath.Join(r.root, filepath.FromSlash(strings.TrimSpace(uses)))
+	rel, err := filepath.Rel(r.root, local)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return local, true
+}
+
+// load loads a reusable workflow or an action, the files being resolved and the files beyond the maximum depth
+// are not loaded
+func (r *Resolver) load(path string, stack []string) (model.Document, bool) {
+	r.files[path] = true
+	if utils.Contains(path, stack) || len(stack) >= r.maxDepth {
+		return nil, false
+	}
+	document, err := loadDocument(r.fileSystem, path)
+	if err != nil {
+		return nil, false
+	}
+	return document, true
+}
+
+// repositoryRoot returns the root of the repository of a workflow, which is the directory of the .github
+// directory holding it or, when it is not in a .github directory, its directory
+func repositoryRoot(path string) string {
+	dir := filepath.Dir(path)
+	for current := dir; ; {
+		if filepath.Base(current) == ".github" {
+			return filepath.Dir(current)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// isWorkflowFile returns true if the path is the path of a workflow file
+func isWorkflowFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// loadDocument loads the first YAML document of a file
+func loadDocument(fileSystem filesystem.FileSystem, path string) (model.Document, error) {
+	content, err := fileSystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	document := model.Document{}
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// appendGenerated appends values loaded from another file, along with their line information, which is the line
+// of the job or step loading them
+func appendGenerated(values, lines, loaded []interface{}, line int) (resultValues, resultLines []interface{}) {
+	for _, value := range loaded {
+		elementLines := map[string]interface{}{defaultLines: map[string]interface{}{lineKey: line}}
+		if m, ok := value.(map[string]interface{}); ok {
+			elementLines = generatedLines(m, line)
+		}
+		values = append(values, value)
+		lines = append(lines, elementLines)
+	}
+	return values, lines
+}
+
+// generatedLines returns the line information of a map loaded from another file, whose keys are all at the
+// line given, the maps it contains get their line information
+func generatedLines(value map[string]interface{}, line int) map[string]interface{} {
+	lines := map[string]interface{}{
+		defaultLines: map[string]interface{}{lineKey: line},
+	}
+	for key, child := range value {
+		if key == linesKey {
+			continue
+		}
+		arr := make([]interface{}, 0)
+		if list, ok := child.([]interface{}); ok {
+			_, arr = appendGenerated(nil, nil, list, line)
+		}
+		lines[linesPrefix+key] = map[string]interface{}{lineKey: line, arrayKey: arr}
+		if m, ok := child.(map[string]interface{}); ok {
+			m[linesKey] = generatedLines(m, line)
+		}
+	}
+	return lines
+}
+
+// lineAt returns the line information of the element of a list
+func lineAt(lines []interface{}, index int) map[string]interface{} {
+	if index < len(lines) {
+		if m, ok := lines[index].(map[string]interface{}); ok {
+			return m
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// lineOf returns the line of an element from its line information
+func lineOf(lines map[string]interface{}) int {
+	if line, ok := lines[lineKey].(float64); ok {
+		return int(line)
+	}
+	if line, ok := lines[lineKey].(int); ok {
+		return line
+	}
+	if defaults, ok := lines[defaultLines].(map[string]interface{}); ok {
+		return lineOf(defaults)
+	}
+	return 0
+}
+
+// getMap returns value as a map, or nil if it is not a map
+func getMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	if m, ok := value.(model.Document); ok {
+		return m
+	}
+	return nil
+}
+
+// toList returns value as a list, or an empty list if it is not a list
+func toList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{}
+}
+
+// withoutLines returns a deep copy of a value without its line information
+func withoutLines(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if key != linesKey {
+				result[key] = withoutLines(child)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			result = append(result, withoutLines(element))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// setArray sets the line information of the elements of a list, when the line information of the list exists
+func setArray(lines map[string]interface{}, arr []interface{}) {
+	if lines != nil {
+		lines[arrayKey] = arr
+	}
+}
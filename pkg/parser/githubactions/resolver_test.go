@@ -0,0 +1,96 @@
+package githubactions
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	fixtureDir   = filepath.Join("..", "..", "..", "test", "fixtures", "test_github_actions")
+	workflowsDir = filepath.Join(fixtureDir, ".github", "workflows")
+)
+
+func resolveFixture(t *testing.T, name string) map[string]interface{} {
+	path := filepath.Join(workflowsDir, name)
+	document, err := loadDocument(filesystem.Get(nil), path)
+	require.NoError(t, err)
+	require.True(t, IsWorkflow(document))
+	return NewResolver(0).Resolve(document, path)
+}
+
+func TestResolve_CompositeActions(t *testing.T) {
+	workflow := resolveFixture(t, "pull_request.yml")
+	job := workflow["jobs"].(map[string]interface{})["greet"].(map[string]interface{})
+	steps := job["steps"].([]interface{})
+	require.Len(t, steps, 3)
+
+	require.Equal(t, "actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11", steps[0].(map[string]interface{})["uses"])
+	require.Equal(t, `echo "Hello, ${{ github.event.pull_request.title }}"`, steps[1].(map[string]interface{})["run"])
+	require.Equal(t, `curl -d "${{ format('text={0}', format('PR {0}', github.event.pull_request.title)) }}" `+
+		`https://chat.example.com/hooks/ci`, steps[2].(map[string]interface{})["run"])
+}
+
+func TestResolve_ReusableWorkflows(t *testing.T) {
+	workflow := resolveFixture(t, "pull_request.yml")
+	jobs := workflow["jobs"].(map[string]interface{})
+	require.Contains(t, jobs, "call-build")
+
+	build := jobs["call-build/build"].(map[string]interface{})
+	run := build["steps"].([]interface{})[1].(map[string]interface{})
+	require.Equal(t, `make release BRANCH="${{ github.head_ref }}"`, run["run"])
+	// the secrets are inherited from the caller
+	require.Equal(t, "${{ secrets.DEPLOY_TOKEN }}", run["env"].(map[string]interface{})["TOKEN"])
+}
+
+func TestResolve_Lines(t *testing.T) {
+	workflow := resolveFixture(t, "pull_request.yml")
+	jobs := workflow["jobs"].(map[string]interface{})
+
+	stepsLines := jobs["greet"].(map[string]interface{})[linesKey].(map[string]interface{})[linesPrefix+"steps"]
+	arr := stepsLines.(map[string]interface{})[arrayKey].([]interface{})
+	require.Len(t, arr, 3)
+	require.Equal(t, 9, lineOf(arr[0].(map[string]interface{})))
+	require.Equal(t, 11, lineOf(arr[1].(map[string]interface{})))
+	require.Equal(t, 11, lineOf(arr[2].(map[string]interface{})[linesPrefix+"run"].(map[string]interface{})))
+
+	jobsLines := jobs[linesKey].(map[string]interface{})
+	require.Equal(t, 15, lineOf(jobsLines[linesPrefix+"call-build/build"].(map[string]interface{})))
+}
+
+func TestLinkedFiles(t *testing.T) {
+	files := LinkedFiles(filepath.Join(workflowsDir, "pull_request.yml"), 0)
+	require.ElementsMatch(t, []string{
+		filepath.Join(workflowsDir, "build.yml"),
+		filepath.Join(fixtureDir, ".github", "actions", "greet", "action.yml"),
+		filepath.Join(fixtureDir, ".github", "actions", "notify", "action.yml"),
+		filepath.Join(fixtureDir, ".github", "actions", "notify", "action.yaml"),
+	}, files)
+}
+
+func TestRepositoryRoot(t *testing.T) {
+	require.Equal(t, filepath.Join("repo"), repositoryRoot(filepath.Join("repo", ".github", "workflows", "ci.yml")))
+	require.Equal(t, filepath.Join("samples"), repositoryRoot(filepath.Join("samples", "ci.yml")))
+}
+
+func TestLocalPath(t *testing.T) {
+	r := NewResolver(0)
+	r.root = filepath.Join("repo")
+
+	local, ok := r.localPath("./.github/actions/greet")
+	require.True(t, ok)
+	require.Equal(t, filepath.Join("repo", ".github", "actions", "greet"), local)
+
+	local, ok = r.localPath("./tools/../.github/workflows/build.yml")
+	require.True(t, ok)
+	require.Equal(t, filepath.Join("repo", ".github", "workflows", "build.yml"), local)
+
+	_, ok = r.localPath("./../other/.github/actions/greet")
+	require.False(t, ok)
+	_, ok = r.localPath("./.github/../../../etc/action")
+	require.False(t, ok)
+	_, ok = r.localPath("actions/checkout@v4")
+	require.False(t, ok)
+}
@@ -4,20 +4,56 @@ import (
 	"bytes"
 	"encoding/json"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser/azureresourcemanager"
+	"github.com/Checkmarx/kics/v2/pkg/parser/cloudformation"
 	"github.com/Checkmarx/kics/v2/pkg/resolver/file"
 )
 
 // Parser defines a parser type
 type Parser struct {
-	shouldIdent   bool
-	resolvedFiles map[string]model.ResolvedFile
+	shouldIdent    bool
+	resolvedFiles  map[string]model.ResolvedFile
+	cloudFormation *cloudformation.Resolver
+	fileSystem     filesystem.FileSystem
+}
+
+// NewWithCloudFormationParameters initializes a parser resolving CloudFormation templates with the parameters passed
+func NewWithCloudFormationParameters(parameters map[string]string) *Parser {
+	return &Parser{
+		cloudFormation: cloudformation.NewResolver(parameters),
+	}
+}
+
+// SetFileSystem sets the FileSystem the files surrounding the parsed ones are read from
+func (p *Parser) SetFileSystem(fileSystem filesystem.FileSystem) {
+	p.fileSystem = fileSystem
+}
+
+// getFileSystem returns the FileSystem the files surrounding the parsed ones are read from, the disk by default
+func (p *Parser) getFileSystem() filesystem.FileSystem {
+	return filesystem.Get(p.fileSystem)
+}
+
+// getCloudFormationResolver returns the resolver of the intrinsic functions of CloudFormation templates
+func (p *Parser) getCloudFormationResolver() *cloudformation.Resolver {
+	if p.cloudFormation == nil {
+		return cloudformation.NewResolver(nil)
+	}
+	return p.cloudFormation
 }
 
 // Resolve - replace or modifies in-memory content before parsing
 func (p *Parser) Resolve(fileContent []byte, filename string, resolveReferences bool, maxResolverDepth int) ([]byte, error) {
+	// the linked templates of ARM templates are loaded by the ARM resolver with the parameters passed to them
+	var document map[string]interface{}
+	if json.Unmarshal(fileContent, &document) == nil && azureresourcemanager.IsTemplate(document) {
+		p.resolvedFiles = make(map[string]model.ResolvedFile)
+		return fileContent, nil
+	}
 	// Resolve files passed as arguments with file resolver (e.g. file://)
-	res := file.NewResolver(json.Unmarshal, json.Marshal, p.SupportedExtensions())
+	res := file.NewResolverWithFileSystem(json.Unmarshal, json.Marshal, p.SupportedExtensions(), p.getFileSystem())
 	resolvedFilesCache := make(map[string]file.ResolvedFile)
 	resolved := res.Resolve(fileContent, filename, 0, maxResolverDepth, resolvedFilesCache, resolveReferences)
 	p.resolvedFiles = res.ResolvedFiles
@@ -28,7 +64,7 @@ func (p *Parser) Resolve(fileContent []byte, filename string, resolveReferences
 }
 
 // Parse parses json file and returns it as a Document
-func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, []int, error) {
+func (p *Parser) Parse(filePath string, fileContent []byte) ([]model.Document, []int, error) {
 	r := model.Document{}
 	err := json.Unmarshal(fileContent, &r)
 	if err != nil {
@@ -44,7 +80,9 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, []int, e
 	kicsPlan, err := parseTFPlan(kicsJSON)
 	if err != nil {
 		// JSON is not a tf plan
-		return []model.Document{kicsJSON}, []int{}, nil
+		document := azureresourcemanager.Resolve(p.getFileSystem(), p.getCloudFormationResolver().Resolve(kicsJSON), filePath)
+		documents := []model.Document{document}
+		return append(documents, cloudformation.TransformServerless(documents)...), []int{}, nil
 	}
 
 	p.shouldIdent = true
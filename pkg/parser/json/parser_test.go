@@ -1,6 +1,8 @@
 package json
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -45,6 +47,81 @@ func TestParser_Parse(t *testing.T) {
 	require.Contains(t, doc[0], "martin")
 }
 
+// TestParser_Parse_CloudFormation tests that the intrinsic functions of CloudFormation templates are resolved
+func TestParser_Parse_CloudFormation(t *testing.T) {
+	content, err := os.ReadFile(filepath.FromSlash("../../../test/fixtures/test_cloudformation_intrinsics/template.json"))
+	require.NoError(t, err)
+
+	p := NewWithCloudFormationParameters(map[string]string{"Environment": "prod"})
+	doc, _, err := p.Parse("template.json", content)
+	require.NoError(t, err)
+	require.Len(t, doc, 1)
+
+	properties := doc[0]["Resources"].(map[string]interface{})["Bucket"].(map[string]interface{})["Properties"].(map[string]interface{})
+	require.Equal(t, "logs-prod", properties["BucketName"])
+	require.Equal(t, "Private", properties["AccessControl"])
+	versioning := properties["VersioningConfiguration"].(map[string]interface{})
+	require.Equal(t, "Enabled", versioning["Status"])
+	// the selected branch keeps its line information
+	require.Contains(t, versioning["_kics_lines"], "_kics_Status")
+}
+
+// TestParser_Parse_Serverless tests that the SAM resources are expanded into a document with their CloudFormation resources
+func TestParser_Parse_Serverless(t *testing.T) {
+	content := []byte(`{
+  "Transform": "AWS::Serverless-2016-10-31",
+  "Resources": {
+    "Function": {
+      "Type": "AWS::Serverless::Function",
+      "Properties": {
+        "Handler": "index.handler",
+        "Role": "arn:aws:iam::123456789012:role/lambda"
+      }
+    }
+  }
+}`)
+	p := &Parser{}
+	doc, _, err := p.Parse("template.json", content)
+	require.NoError(t, err)
+	require.Len(t, doc, 2)
+
+	function := doc[1]["Resources"].(map[string]interface{})["Function"].(map[string]interface{})
+	require.Equal(t, "AWS::Lambda::Function", function["Type"])
+	properties := function["Properties"].(map[string]interface{})
+	require.Equal(t, "index.handler", properties["Handler"])
+	require.Equal(t, "arn:aws:iam::123456789012:role/lambda", properties["Role"])
+}
+
+// TestParser_Parse_AzureResourceManager tests that the ARM templates are parsed with their linked templates,
+// which are not resolved as file references, and that the values of their expressions keep their lines
+func TestParser_Parse_AzureResourceManager(t *testing.T) {
+	path := filepath.FromSlash("../../../test/fixtures/test_arm_parameters/azuredeploy.json")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	p := &Parser{}
+	resolved, err := p.Resolve(content, path, true, 15)
+	require.NoError(t, err)
+	require.Equal(t, content, resolved)
+	require.Empty(t, p.GetResolvedFiles())
+
+	doc, _, err := p.Parse(path, resolved)
+	require.NoError(t, err)
+	require.Len(t, doc, 1)
+
+	resources := doc[0]["resources"].([]interface{})
+	require.Len(t, resources, 2)
+	storage := resources[0].(map[string]interface{})
+	tags := storage["tags"].(map[string]interface{})
+	require.Equal(t, "platform", tags["owner"])
+	require.Equal(t, 33, tags["_kics_lines"].(map[string]*model.LineObject)["_kics_owner"].Line)
+
+	properties := resources[1].(map[string]interface{})["properties"].(map[string]interface{})
+	linked := properties["template"].(map[string]interface{})["resources"].([]interface{})
+	require.Len(t, linked, 1)
+	require.Equal(t, 63, properties["_kics_lines"].(map[string]*model.LineObject)["_kics_template"].Line)
+}
+
 // Test_Resolve tests the functions [Resolve()] and all the methods called by them
 func Test_Resolve(t *testing.T) {
 	parser := &Parser{}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/Checkmarx/kics/v2/pkg/utils"
 	"github.com/rs/zerolog/log"
@@ -22,9 +23,15 @@ type kindParser interface {
 	GetResolvedFiles() map[string]model.ResolvedFile
 }
 
+// fileSystemParser is implemented by the parsers reading the files surrounding the parsed ones
+type fileSystemParser interface {
+	SetFileSystem(fileSystem filesystem.FileSystem)
+}
+
 // Builder is a representation of parsers that will be construct
 type Builder struct {
-	parsers []kindParser
+	parsers    []kindParser
+	fileSystem filesystem.FileSystem
 }
 
 // NewBuilder creates a new Builder's reference
@@ -39,12 +46,22 @@ func (b *Builder) Add(p kindParser) *Builder {
 	return b
 }
 
+// WithFileSystem sets the FileSystem the parsers read the files surrounding the parsed ones from, they are read
+// from disk by default. The Terraform parser takes its FileSystem when created, see terraform.NewDefaultWithFileSystem
+func (b *Builder) WithFileSystem(fileSystem filesystem.FileSystem) *Builder {
+	b.fileSystem = fileSystem
+	return b
+}
+
 // Build prepares parsers and associates a parser to its extension and returns it
 func (b *Builder) Build(types, cloudProviders []string) ([]*Parser, error) {
 	parserSlice := make([]*Parser, 0, len(b.parsers))
 	for _, parser := range b.parsers {
 		supportedTypes := parser.SupportedTypes()
 		if contains(types, supportedTypes) {
+			if fileSystemParser, ok := parser.(fileSystemParser); ok && b.fileSystem != nil {
+				fileSystemParser.SetFileSystem(b.fileSystem)
+			}
 			extensions := make(model.Extensions, len(b.parsers))
 			var platforms []string
 			for _, ext := range parser.SupportedExtensions() {
@@ -87,7 +104,7 @@ type ParsedDocument struct {
 
 // CommentsCommands gets commands on comments in the file beginning, before the code starts
 func (c *Parser) CommentsCommands(filePath string, fileContent []byte) model.CommentsCommands {
-	if c.isValidExtension(filePath) {
+	if c.isValidExtension(filePath, fileContent) {
 		commentsCommands := make(model.CommentsCommands)
 		commentToken := c.parsers.GetCommentToken()
 		if commentToken != "" {
@@ -128,9 +145,10 @@ func (c *Parser) Parse(
 	fileContent []byte,
 	openAPIResolveReferences, isMinified bool,
 	maxResolverDepth int) (ParsedDocument, error) {
+	validExtension := c.isValidExtension(filePath, fileContent)
 	fileContent = utils.DecryptAnsibleVault(fileContent, os.Getenv("ANSIBLE_VAULT_PASSWORD_FILE"))
 
-	if c.isValidExtension(filePath) {
+	if validExtension {
 		resolved, err := c.parsers.Resolve(fileContent, filePath, openAPIResolveReferences, maxResolverDepth)
 		if err != nil {
 			return ParsedDocument{}, err
@@ -183,8 +201,10 @@ func contains(types []string, supportedTypes map[string]bool) bool {
 	return false
 }
 
-func (c *Parser) isValidExtension(filePath string) bool {
-	ext, _ := utils.GetExtension(filePath)
+// isValidExtension checks the extension of the file using its content, so files that are not in the
+// filesystem can also be parsed
+func (c *Parser) isValidExtension(filePath string, fileContent []byte) bool {
+	ext := utils.GetExtensionFromContent(filePath, fileContent)
 	_, ok := c.extensions[ext]
 	return ok
 }
@@ -112,9 +112,11 @@ func TestIsValidExtension(t *testing.T) {
 		Add(&jsonParser.Parser{}).
 		Add(&dockerParser.Parser{}).
 		Build([]string{""}, []string{""})
-	require.True(t, parser[0].isValidExtension("../../test/fixtures/test_extension/test.json"), "test.json should be a valid extension")
-	require.True(t, parser[1].isValidExtension("../../test/fixtures/test_extension/Dockerfile"), "dockerfile should be a valid extension")
-	require.False(t, parser[0].isValidExtension("../../test/fixtures/test_extension/test.xml"), "test.xml should not be a valid extension")
+	require.True(t, parser[0].isValidExtension("../../test/fixtures/test_extension/test.json", nil), "test.json should be a valid extension")
+	require.True(t, parser[1].isValidExtension("../../test/fixtures/test_extension/Dockerfile", nil), "dockerfile should be a valid extension")
+	require.False(t, parser[0].isValidExtension("../../test/fixtures/test_extension/test.xml", nil), "test.xml should not be a valid extension")
+	require.True(t, parser[1].isValidExtension("in-memory/base-image", []byte("FROM alpine:3.18\n")),
+		"file content starting with FROM should be a valid extension")
 }
 
 func TestCommentsCommands(t *testing.T) {
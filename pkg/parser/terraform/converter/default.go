@@ -2,7 +2,6 @@ package converter
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	sentryReport "github.com/Checkmarx/kics/v2/internal/sentry"
@@ -19,16 +18,13 @@ import (
 // VariableMap represents a set of terraform input variables
 type VariableMap map[string]cty.Value
 
-var inputVarMap = make(VariableMap)
-
 // This file is attributed to https://github.com/tmccombs/hcl2json.
 // convertBlock() is manipulated for combining the both blocks and labels for one given resource.
 
 // DefaultConverted an hcl File to a toJson serializable object
 // This assumes that the body is a hclsyntax.Body
 var DefaultConverted = func(file *hcl.File, inputVariables VariableMap) (model.Document, error) {
-	inputVarMap = inputVariables
-	c := converter{bytes: file.Bytes}
+	c := converter{bytes: file.Bytes, variables: inputVariables}
 	body, err := c.convertBody(file.Body.(*hclsyntax.Body), 0)
 
 	if err != nil {
@@ -49,7 +45,8 @@ var DefaultConverted = func(file *hcl.File, inputVariables VariableMap) (model.D
 }
 
 type converter struct {
-	bytes []byte
+	bytes     []byte
+	variables VariableMap
 }
 
 const kicsLinesKey = "_kics_"
@@ -60,28 +57,8 @@ func (c *converter) rangeSource(r hcl.Range) string {
 
 func (c *converter) convertBody(body *hclsyntax.Body, defLine int) (model.Document, error) {
 	var err error
-	var v string
-	countValue := body.Attributes["count"]
-	count := -1
-
-	if countValue != nil {
-		value, err := countValue.Expr.Value(nil)
-		if err == nil {
-			switch value.Type() {
-			case cty.String:
-				v = value.AsString()
-			case cty.Number:
-				v = value.AsBigFloat().String()
-			}
-
-			intValue, err := strconv.Atoi(v)
-			if err == nil {
-				count = intValue
-			}
-		}
-	}
 
-	if count == 0 {
+	if count, ok := c.countValue(body); ok && count == 0 {
 		return nil, nil
 	}
 
@@ -113,6 +90,17 @@ func (c *converter) convertBody(body *hclsyntax.Body, defLine int) (model.Docume
 	}
 
 	for _, block := range body.Blocks {
+		expanded, err := c.convertDynamicBlock(block, out)
+		if err != nil {
+			return nil, err
+		}
+		if expanded {
+			// set kics line for the blocks generated by the dynamic block
+			kicsS[kicsLinesKey+block.Labels[0]] = model.LineObject{
+				Line: block.TypeRange.Start.Line,
+			}
+			continue
+		}
 		// set kics line for block
 		kicsS[kicsLinesKey+block.Type] = model.LineObject{
 			Line: block.TypeRange.Start.Line,
@@ -180,7 +168,7 @@ func (c *converter) getArrLines(expr hclsyntax.Expression) []map[string]*model.L
 
 func (c *converter) convertBlock(block *hclsyntax.Block, out model.Document, defLine int) error {
 	var key = block.Type
-	value, err := c.convertBody(block.Body, defLine)
+	value, err := c.convertBlockBody(block, defLine)
 
 	if err != nil {
 		return err
@@ -205,6 +193,13 @@ func (c *converter) convertBlock(block *hclsyntax.Block, out model.Document, def
 		key = label
 	}
 
+	appendBlock(out, key, value)
+
+	return nil
+}
+
+// appendBlock sets the block in out, blocks with the same key are grouped in a list
+func appendBlock(out model.Document, key string, value model.Document) {
 	if current, exists := out[key]; exists {
 		if list, ok := current.([]interface{}); ok {
 			out[key] = append(list, value)
@@ -214,8 +209,6 @@ func (c *converter) convertBlock(block *hclsyntax.Block, out model.Document, def
 	} else {
 		out[key] = value
 	}
-
-	return nil
 }
 
 func (c *converter) convertExpression(expr hclsyntax.Expression) (interface{}, error) {
@@ -249,7 +242,7 @@ func (c *converter) convertExpression(expr hclsyntax.Expression) (interface{}, e
 		return c.evalFunction(expr)
 	case *hclsyntax.ConditionalExpr:
 		expressionEvaluated, err := expr.Value(&hcl.EvalContext{
-			Variables: inputVarMap,
+			Variables: c.variables,
 			Functions: functions.TerraformFuncs,
 		})
 		if err != nil {
@@ -259,7 +252,7 @@ func (c *converter) convertExpression(expr hclsyntax.Expression) (interface{}, e
 	default:
 		// try to evaluate with variables and functions
 		valueConverted, _ := expr.Value(&hcl.EvalContext{
-			Variables: inputVarMap,
+			Variables: c.variables,
 			Functions: functions.TerraformFuncs,
 		})
 		if !checkDynamicKnownTypes(valueConverted) {
@@ -397,11 +390,18 @@ func (c *converter) convertStringPart(expr hclsyntax.Expression) (string, error)
 	default:
 		// try to evaluate with variables
 		valueConverted, _ := expr.Value(&hcl.EvalContext{
-			Variables: inputVarMap,
+			Variables: c.variables,
 		})
 		if valueConverted.Type().FriendlyName() == "string" {
 			return valueConverted.AsString(), nil
 		}
+		// references to numbers and bools, such as count.index, are interpolated as strings
+		if _, isTraversal := expr.(*hclsyntax.ScopeTraversalExpr); isTraversal &&
+			valueConverted.Type().IsPrimitiveType() && valueConverted.IsKnown() && !valueConverted.IsNull() {
+			if s, err := ctyconvert.Convert(valueConverted, cty.String); err == nil {
+				return s.AsString(), nil
+			}
+		}
 		// treating as an embedded expression
 		return c.wrapExpr(expr)
 	}
@@ -488,7 +488,7 @@ func (c *converter) wrapExpr(expr hclsyntax.Expression) (string, error) {
 
 func (c *converter) evalFunction(expression hclsyntax.Expression) (interface{}, error) {
 	expressionEvaluated, err := expression.Value(&hcl.EvalContext{
-		Variables: inputVarMap,
+		Variables: c.variables,
 		Functions: functions.TerraformFuncs,
 	})
 	if err != nil {
@@ -501,14 +501,14 @@ func (c *converter) evalFunction(expression hclsyntax.Expression) (interface{},
 					if convertErr != nil {
 						return c.wrapExpr(expression)
 					}
-					inputVarMap[rootKey] = jsonCtyValue
+					c.variables[rootKey] = jsonCtyValue
 				} else {
-					inputVarMap[rootKey] = cty.StringVal(jsonPath)
+					c.variables[rootKey] = cty.StringVal(jsonPath)
 				}
 			}
 		}
 		expressionEvaluated, err = expression.Value(&hcl.EvalContext{
-			Variables: inputVarMap,
+			Variables: c.variables,
 			Functions: functions.TerraformFuncs,
 		})
 		if err != nil {
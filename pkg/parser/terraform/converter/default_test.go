@@ -495,7 +495,7 @@ block "label_one" {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			file, _ := hclsyntax.ParseConfig([]byte(tt.input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
-			c := converter{bytes: file.Bytes}
+			c := converter{bytes: file.Bytes, variables: VariableMap{}}
 			got, err := c.convertBody(file.Body.(*hclsyntax.Body), 0)
 			fmt.Println(err)
 			require.True(t, (err != nil) == tt.wantErr)
@@ -772,3 +772,151 @@ variable "region" {
 	require.NoError(t, err)
 	compareJSONLine(t, body, expected)
 }
+
+// TestDynamicBlock tests the expansion of dynamic blocks with statically resolvable for_each
+func TestDynamicBlock(t *testing.T) {
+	input := `
+resource "aws_security_group" "sg" {
+	dynamic "ingress" {
+		for_each = var.ports
+		content {
+			from_port   = ingress.value
+			to_port     = ingress.value
+			cidr_blocks = ["0.0.0.0/0"]
+		}
+	}
+	dynamic "egress" {
+		for_each = { http = 80 }
+		iterator = rule
+		content {
+			description = rule.key
+			from_port   = rule.value
+		}
+	}
+	dynamic "tag" {
+		for_each = var.unknown
+		content {
+			key = tag.key
+		}
+	}
+}`
+
+	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
+
+	body, err := DefaultConverted(file, VariableMap{
+		"var": cty.ObjectVal(map[string]cty.Value{
+			"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(22), cty.NumberIntVal(3389)}),
+		}),
+	})
+	require.NoError(t, err)
+
+	sg := toJSONMap(t, body)["resource"].(map[string]interface{})["aws_security_group"].(map[string]interface{})["sg"].(map[string]interface{})
+
+	ingress := sg["ingress"].([]interface{})
+	require.Len(t, ingress, 2)
+	require.Equal(t, float64(22), ingress[0].(map[string]interface{})["from_port"])
+	require.Equal(t, float64(3389), ingress[1].(map[string]interface{})["to_port"])
+	require.Equal(t, float64(3), ingress[1].(map[string]interface{})["_kics_lines"].(map[string]interface{})["_kics__default"].(map[string]interface{})["_kics_line"])
+
+	egress := sg["egress"].(map[string]interface{})
+	require.Equal(t, "http", egress["description"])
+	require.Equal(t, float64(80), egress["from_port"])
+
+	require.NotContains(t, sg, "tag")
+	require.Contains(t, sg["dynamic"], "tag")
+}
+
+// TestBlockInstances tests the expansion of resources with statically resolvable count and for_each
+func TestBlockInstances(t *testing.T) {
+	input := `
+resource "aws_instance" "single" {
+	count = 1
+	name  = "server-${count.index}"
+}
+resource "aws_instance" "counted" {
+	count = 2
+	name  = "server-${count.index}"
+	type  = "t2.micro"
+}
+resource "aws_s3_bucket" "each" {
+	for_each = var.buckets
+	bucket   = "logs"
+	acl      = each.value
+}
+resource "aws_s3_bucket" "acls" {
+	for_each = var.acls
+	bucket   = each.key
+	acl      = each.value
+}
+resource "aws_s3_bucket" "unknown" {
+	for_each = var.unknown
+	bucket   = each.key
+}
+resource "aws_s3_bucket" "disabled" {
+	count  = var.enabled ? 1 : 0
+	bucket = "disabled"
+}`
+
+	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
+
+	body, err := DefaultConverted(file, VariableMap{
+		"var": cty.ObjectVal(map[string]cty.Value{
+			"buckets": cty.MapVal(map[string]cty.Value{
+				"logs":    cty.StringVal("private"),
+				"archive": cty.StringVal("private"),
+			}),
+			"acls": cty.MapVal(map[string]cty.Value{
+				"logs": cty.StringVal("private"),
+				"site": cty.StringVal("public-read"),
+			}),
+			"enabled": cty.False,
+		}),
+	})
+	require.NoError(t, err)
+
+	resources := toJSONMap(t, body)["resource"].(map[string]interface{})
+
+	instances := resources["aws_instance"].(map[string]interface{})
+	require.Len(t, instances, 2)
+	single := instances["single"].(map[string]interface{})
+	require.Equal(t, "server-0", single["name"])
+	require.Len(t, single["_kics_instances"], 1)
+	require.Equal(t, "server-0", instanceAttribute(t, single, "0", "name"))
+	counted := instances["counted"].(map[string]interface{})
+	require.Equal(t, "server-${count.index}", counted["name"])
+	require.Equal(t, "t2.micro", counted["type"])
+	require.Len(t, counted["_kics_instances"], 2)
+	require.Equal(t, "server-0", instanceAttribute(t, counted, "0", "name"))
+	require.Equal(t, "server-1", instanceAttribute(t, counted, "1", "name"))
+
+	buckets := resources["aws_s3_bucket"].(map[string]interface{})
+	each := buckets["each"].(map[string]interface{})
+	require.Equal(t, "private", each["acl"])
+	require.Len(t, each["_kics_instances"], 2)
+	require.Equal(t, "private", instanceAttribute(t, each, "archive", "acl"))
+	require.Equal(t, "private", instanceAttribute(t, each, "logs", "acl"))
+	acls := buckets["acls"].(map[string]interface{})
+	require.Equal(t, "${each.value}", acls["acl"])
+	require.Equal(t, "private", instanceAttribute(t, acls, "logs", "acl"))
+	require.Equal(t, "public-read", instanceAttribute(t, acls, "site", "acl"))
+	require.Equal(t, "site", instanceAttribute(t, acls, "site", "bucket"))
+	require.Equal(t, "${each.key}", buckets["unknown"].(map[string]interface{})["bucket"])
+	require.NotContains(t, buckets["unknown"], "_kics_instances")
+	require.NotContains(t, buckets, "disabled")
+}
+
+func instanceAttribute(t *testing.T, resource map[string]interface{}, key, attribute string) interface{} {
+	instances, ok := resource["_kics_instances"].(map[string]interface{})
+	require.True(t, ok)
+	instance, ok := instances[key].(map[string]interface{})
+	require.True(t, ok)
+	return instance[attribute]
+}
+
+func toJSONMap(t *testing.T, body model.Document) map[string]interface{} {
+	bytes, err := json.Marshal(body)
+	require.NoError(t, err)
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(bytes, &out))
+	return out
+}
@@ -0,0 +1,231 @@
+package converter
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/functions"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyconvert "github.com/zclconf/go-cty/cty/convert"
+)
+
+// maxBlockInstances is the maximum number of instances a block is expanded into,
+// blocks with more instances are kept as they are written
+const maxBlockInstances = 1000
+
+// instancesKey is the key of the instances of a resource or data source, keyed by their index or key
+const instancesKey = "_kics_instances"
+
+// blockInstance is a concrete instance of a block repeated by count or for_each
+type blockInstance struct {
+	// key is the index or key of the instance, e.g. 0 or "key"
+	key string
+	// variables are the count or each objects of the instance
+	variables VariableMap
+}
+
+func (c *converter) evalContext() *hcl.EvalContext {
+	return &hcl.EvalContext{
+		Variables: c.variables,
+		Functions: functions.TerraformFuncs,
+	}
+}
+
+// withVariables returns a copy of the converter with the variables added to its own
+func (c *converter) withVariables(variables VariableMap) *converter {
+	merged := make(VariableMap, len(c.variables)+len(variables))
+	for name, value := range c.variables {
+		merged[name] = value
+	}
+	for name, value := range variables {
+		merged[name] = value
+	}
+	return &converter{bytes: c.bytes, variables: merged}
+}
+
+// countValue returns the value of the count attribute of the body when it can be statically resolved
+func (c *converter) countValue(body *hclsyntax.Body) (int, bool) {
+	attr, exists := body.Attributes["count"]
+	if !exists {
+		return 0, false
+	}
+	value, diagnostics := attr.Expr.Value(c.evalContext())
+	if diagnostics.HasErrors() || value.IsNull() || !value.IsWhollyKnown() {
+		return 0, false
+	}
+	value, err := ctyconvert.Convert(value, cty.Number)
+	if err != nil {
+		return 0, false
+	}
+	count := value.AsBigFloat()
+	if !count.IsInt() || count.Sign() < 0 {
+		return 0, false
+	}
+	count64, _ := count.Int64()
+	return int(count64), true
+}
+
+// forEachValue returns the keys and values of the collection in the for_each attribute of the body
+// when it can be statically resolved, the elements of sets are keyed by their values
+func (c *converter) forEachValue(body *hclsyntax.Body) (keys, values []cty.Value, ok bool) {
+	attr, exists := body.Attributes["for_each"]
+	if !exists {
+		return nil, nil, false
+	}
+	collection, diagnostics := attr.Expr.Value(c.evalContext())
+	if diagnostics.HasErrors() || collection.IsNull() || !collection.IsWhollyKnown() ||
+		!collection.CanIterateElements() || collection.LengthInt() > maxBlockInstances {
+		return nil, nil, false
+	}
+	for it := collection.ElementIterator(); it.Next(); {
+		key, value := it.Element()
+		if collection.Type().IsSetType() {
+			key = value
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	return keys, values, true
+}
+
+// blockInstances returns the instances of a resource or data source with a statically resolvable
+// count or for_each, otherwise the block is converted as it is written
+func (c *converter) blockInstances(block *hclsyntax.Block) ([]blockInstance, bool) {
+	if (block.Type != "resource" && block.Type != "data") || len(block.Labels) != 2 {
+		return nil, false
+	}
+
+	if count, ok := c.countValue(block.Body); ok {
+		if count > maxBlockInstances {
+			return nil, false
+		}
+		instances := make([]blockInstance, 0, count)
+		for i := 0; i < count; i++ {
+			instances = append(instances, blockInstance{
+				key: strconv.Itoa(i),
+				variables: VariableMap{
+					"count": cty.ObjectVal(map[string]cty.Value{"index": cty.NumberIntVal(int64(i))}),
+				},
+			})
+		}
+		return instances, true
+	}
+
+	keys, values, ok := c.forEachValue(block.Body)
+	if !ok {
+		return nil, false
+	}
+	instances := make([]blockInstance, 0, len(keys))
+	for i := range keys {
+		key, err := ctyconvert.Convert(keys[i], cty.String)
+		if err != nil {
+			return nil, false
+		}
+		instances = append(instances, blockInstance{
+			key: key.AsString(),
+			variables: VariableMap{
+				"each": cty.ObjectVal(map[string]cty.Value{"key": key, "value": values[i]}),
+			},
+		})
+	}
+	return instances, true
+}
+
+// convertBlockBody converts the body of a block. A resource or data source with a statically resolvable count or
+// for_each keeps its name and holds each of its instances, keyed by their index or key, with the count and each
+// references replaced by their values. The references of the block itself are replaced when all the instances are
+// the same, otherwise it is converted as it is written
+func (c *converter) convertBlockBody(block *hclsyntax.Block, defLine int) (model.Document, error) {
+	blockInstances, ok := c.blockInstances(block)
+	if !ok {
+		return c.convertBody(block.Body, defLine)
+	}
+	if len(blockInstances) == 0 {
+		return nil, nil
+	}
+
+	var value model.Document
+	instances := make(map[string]interface{}, len(blockInstances))
+	for i := range blockInstances {
+		instance, err := c.withVariables(blockInstances[i].variables).convertBody(block.Body, defLine)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			value = instance
+		} else if value != nil && !reflect.DeepEqual(value, instance) {
+			value = nil
+		}
+		instances[blockInstances[i].key] = instance
+	}
+
+	if value == nil {
+		var err error
+		if value, err = c.convertBody(block.Body, defLine); err != nil || value == nil {
+			return value, err
+		}
+	} else {
+		value = copyDocument(value)
+	}
+	value[instancesKey] = instances
+	return value, nil
+}
+
+// copyDocument returns a shallow copy of the document, so the block does not hold itself among its instances
+func copyDocument(document model.Document) model.Document {
+	copied := make(model.Document, len(document))
+	for key, value := range document {
+		copied[key] = value
+	}
+	return copied
+}
+
+// convertDynamicBlock sets in out a block for each element of the for_each collection of a dynamic block,
+// it returns false when the dynamic block can not be statically expanded and should be converted as it is written
+func (c *converter) convertDynamicBlock(block *hclsyntax.Block, out model.Document) (bool, error) {
+	if block.Type != "dynamic" || len(block.Labels) != 1 {
+		return false, nil
+	}
+
+	var content *hclsyntax.Block
+	for _, nested := range block.Body.Blocks {
+		if nested.Type == "content" {
+			content = nested
+			break
+		}
+	}
+	if content == nil {
+		return false, nil
+	}
+
+	iterator := block.Labels[0]
+	if attr, exists := block.Body.Attributes["iterator"]; exists {
+		iterator = hcl.ExprAsKeyword(attr.Expr)
+		if iterator == "" {
+			return false, nil
+		}
+	}
+
+	keys, values, ok := c.forEachValue(block.Body)
+	if !ok {
+		return false, nil
+	}
+
+	for i := range keys {
+		instance := c.withVariables(VariableMap{
+			iterator: cty.ObjectVal(map[string]cty.Value{"key": keys[i], "value": values[i]}),
+		})
+		value, err := instance.convertBody(content.Body, block.TypeRange.Start.Line)
+		if err != nil {
+			return true, err
+		}
+		if value != nil {
+			appendBlock(out, block.Labels[0], value)
+		}
+	}
+
+	return true, nil
+}
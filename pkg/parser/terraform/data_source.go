@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"path/filepath"
-	"sync"
 
 	"github.com/Checkmarx/kics/v2/pkg/builder/engine"
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
 	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/functions"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcldec"
@@ -71,10 +71,9 @@ type convertedPolicy struct {
 	Version   string                     `json:"Version,omitempty"`
 }
 
-var mutexData = &sync.Mutex{}
-
-func getDataSourcePolicy(currentPath string) {
-	tfFiles, err := filepath.Glob(filepath.Join(currentPath, "*.tf"))
+// getDataSourcePolicy sets in inputVariables the aws_iam_policy_document data sources of the files in currentPath
+func getDataSourcePolicy(fileSystem FileSystem, inputVariables converter.VariableMap, currentPath string) {
+	tfFiles, err := fileSystem.Glob(filepath.Join(currentPath, "*.tf"))
 	if err != nil {
 		log.Error().Msg("Error getting .tf files to parse data source")
 		return
@@ -84,7 +83,7 @@ func getDataSourcePolicy(currentPath string) {
 	}
 	jsonMap := make(map[string]map[string]string)
 	for _, tfFile := range tfFiles {
-		parsedFile, parseErr := parseFile(tfFile, true)
+		parsedFile, parseErr := parseFile(fileSystem, tfFile, true)
 		if parseErr != nil {
 			log.Debug().Msgf("Error trying to parse file %s for data source.", tfFile)
 			continue
@@ -95,7 +94,7 @@ func getDataSourcePolicy(currentPath string) {
 		}
 		for _, block := range body.Blocks {
 			if block.Type == "data" && block.Labels[0] == "aws_iam_policy_document" && len(block.Labels) > 1 {
-				policyJSON := parseDataSourceBody(block.Body)
+				policyJSON := parseDataSourceBody(block.Body, inputVariables)
 				jsonMap[block.Labels[1]] = map[string]string{
 					"json": policyJSON,
 				}
@@ -111,9 +110,7 @@ func getDataSourcePolicy(currentPath string) {
 		return
 	}
 
-	mutexData.Lock()
-	inputVariableMap["data"] = data
-	mutexData.Unlock()
+	inputVariables["data"] = data
 }
 
 func decodeDataSourcePolicy(value cty.Value) dataSourcePolicy {
@@ -216,7 +213,7 @@ func getStatementSpec() *hcldec.BlockListSpec {
 	}
 }
 
-func parseDataSourceBody(body *hclsyntax.Body) string {
+func parseDataSourceBody(body *hclsyntax.Body, inputVariables converter.VariableMap) string {
 	dataSourceSpec := &hcldec.ObjectSpec{
 		"id": &hcldec.AttrSpec{
 			Name:     "id",
@@ -234,7 +231,7 @@ func parseDataSourceBody(body *hclsyntax.Body) string {
 	resolveDataResources(body)
 
 	target, decodeErrs := hcldec.Decode(body, dataSourceSpec, &hcl.EvalContext{
-		Variables: inputVariableMap,
+		Variables: inputVariables,
 		Functions: functions.TerraformFuncs,
 	})
 
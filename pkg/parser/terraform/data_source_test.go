@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
 	"github.com/stretchr/testify/require"
 	"github.com/zclconf/go-cty/cty/gocty"
@@ -40,8 +41,9 @@ func Test_getDataSourcePolicy(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			getDataSourcePolicy(tt.args.currentPath)
-			data, ok := inputVariableMap["data"]
+			inputVariables := make(converter.VariableMap)
+			getDataSourcePolicy(&filesystem.OS{}, inputVariables, tt.args.currentPath)
+			data, ok := inputVariables["data"]
 			if !ok {
 				t.FailNow()
 			}
@@ -57,8 +59,4 @@ func Test_getDataSourcePolicy(t *testing.T) {
 			require.Equal(t, tt.want, got)
 		})
 	}
-
-	t.Cleanup(func() {
-		inputVariableMap = make(converter.VariableMap)
-	})
 }
@@ -0,0 +1,7 @@
+package terraform
+
+import "github.com/Checkmarx/kics/v2/pkg/filesystem"
+
+// FileSystem gives the parser access to the files surrounding a parsed file, used to load the input
+// variables, the data sources and the local modules of its directory
+type FileSystem = filesystem.FileSystem
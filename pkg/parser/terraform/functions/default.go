@@ -63,6 +63,7 @@ var TerraformFuncs = map[string]function.Function{
 	"jsondecode":      stdlib.JSONDecodeFunc,
 	"jsonencode":      stdlib.JSONEncodeFunc,
 	"keys":            stdlib.KeysFunc,
+	"length":          stdlib.LengthFunc,
 	"log":             stdlib.LogFunc,
 	"lower":           stdlib.LowerFunc,
 	"max":             stdlib.MaxFunc,
@@ -86,6 +87,9 @@ var TerraformFuncs = map[string]function.Function{
 	"substr":          stdlib.SubstrFunc,
 	"timeadd":         stdlib.TimeAddFunc,
 	"title":           stdlib.TitleFunc,
+	"tolist":          stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+	"tomap":           stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+	"toset":           stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
 	"trim":            stdlib.TrimFunc,
 	"trimprefix":      stdlib.TrimPrefixFunc,
 	"trimspace":       stdlib.TrimSpaceFunc,
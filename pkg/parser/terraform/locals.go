@@ -0,0 +1,93 @@
+package terraform
+
+import (
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/functions"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// getLocalsExpressions returns the expressions of the locals declared in a file
+func getLocalsExpressions(fileSystem FileSystem, filename string) (map[string]hcl.Expression, error) {
+	parsedFile, err := parseFile(fileSystem, filename, false)
+	if err != nil || parsedFile == nil {
+		return nil, err
+	}
+	content, _, _ := parsedFile.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type: "locals",
+			},
+		},
+	})
+
+	expressions := make(map[string]hcl.Expression)
+	for _, block := range content.Blocks {
+		attrs, _ := block.Body.JustAttributes()
+		for name, attr := range attrs {
+			expressions[name] = attr.Expr
+		}
+	}
+	return expressions, nil
+}
+
+// evaluateLocals evaluates the locals expressions with the variables, locals referencing other locals are
+// evaluated once the locals they reference are known, locals that can not be evaluated are left out
+func evaluateLocals(expressions map[string]hcl.Expression, variables converter.VariableMap) map[string]cty.Value {
+	locals := make(map[string]cty.Value)
+	evalVariables := copyVariableMap(variables)
+
+	for resolved := true; resolved && len(expressions) > 0; {
+		resolved = false
+		evalVariables["local"] = cty.ObjectVal(locals)
+		evalContext := &hcl.EvalContext{
+			Variables: evalVariables,
+			Functions: functions.TerraformFuncs,
+		}
+		for name, expr := range expressions {
+			value, diags := expr.Value(evalContext)
+			if diags.HasErrors() || !value.IsWhollyKnown() {
+				continue
+			}
+			locals[name] = value
+			delete(expressions, name)
+			resolved = true
+		}
+	}
+
+	for name := range expressions {
+		log.Trace().Msgf("Local ${local.%s} value could not be evaluated", name)
+	}
+	return locals
+}
+
+// getLocals sets in inputVariables the locals of the files in currentPath, evaluated with the input variables
+// and data sources already set in inputVariables
+func getLocals(fileSystem FileSystem, inputVariables converter.VariableMap, currentPath string) {
+	tfFiles, err := fileSystem.Glob(filepath.Join(currentPath, "*.tf"))
+	if err != nil {
+		log.Error().Msg("Error getting .tf files to parse locals")
+		return
+	}
+
+	expressions := make(map[string]hcl.Expression)
+	for _, tfFile := range tfFiles {
+		fileExpressions, errLocals := getLocalsExpressions(fileSystem, tfFile)
+		if errLocals != nil {
+			log.Error().Msgf("Error getting locals from %s", tfFile)
+			log.Err(errLocals)
+			continue
+		}
+		for name, expr := range fileExpressions {
+			expressions[name] = expr
+		}
+	}
+	if len(expressions) == 0 {
+		return
+	}
+
+	inputVariables["local"] = cty.ObjectVal(evaluateLocals(expressions, inputVariables))
+}
@@ -0,0 +1,80 @@
+package terraform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var localsFixturePath = filepath.FromSlash("../../../test/fixtures/test_terraform_locals")
+
+// TestGetLocals tests the functions [getLocals()] and all the methods called by them
+func TestGetLocals(t *testing.T) {
+	inputVariables := make(converter.VariableMap)
+	getInputVariables(&filesystem.OS{}, inputVariables, localsFixturePath, "", "")
+	getLocals(&filesystem.OS{}, inputVariables, localsFixturePath)
+
+	require.Equal(t, cty.ObjectVal(map[string]cty.Value{
+		"prefix":        cty.StringVal("PROD"),
+		"name":          cty.StringVal("PROD-data"),
+		"encrypted":     cty.True,
+		"ingress_cidrs": cty.TupleVal([]cty.Value{cty.StringVal("10.0.0.0/16")}),
+	}), inputVariables["local"])
+}
+
+// TestEvaluateLocals tests that the locals referencing unknown locals or resources are left out
+func TestEvaluateLocals(t *testing.T) {
+	expressions, err := getLocalsExpressions(&filesystem.OS{}, filepath.Join(localsFixturePath, "locals.tf"))
+	require.NoError(t, err)
+	require.Len(t, expressions, 4)
+
+	locals := evaluateLocals(expressions, converter.VariableMap{
+		"var": cty.ObjectVal(map[string]cty.Value{
+			"environment": cty.StringVal("dev"),
+			"vpc_cidr":    cty.StringVal("0.0.0.0/0"),
+		}),
+	})
+	require.Equal(t, cty.False, locals["encrypted"])
+	require.Equal(t, cty.TupleVal([]cty.Value{cty.StringVal("0.0.0.0/0")}), locals["ingress_cidrs"])
+	require.NotContains(t, locals, "name")
+	require.NotContains(t, locals, "kms_key_id")
+}
+
+// TestParser_Locals tests that the locals of the directory are substituted in the parsed documents
+func TestParser_Locals(t *testing.T) {
+	mainFile := filepath.Join(localsFixturePath, "main.tf")
+	content, err := os.ReadFile(mainFile)
+	require.NoError(t, err)
+
+	parser := NewDefault()
+	_, err = parser.Resolve(content, mainFile, false, 15)
+	require.NoError(t, err)
+	documents, _, err := parser.Parse(mainFile, content)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+
+	resources := documents[0]["resource"].(model.Document)
+	volume := resources["aws_ebs_volume"].(model.Document)["data"].(model.Document)
+	ingress := resources["aws_security_group"].(model.Document)["data"].(model.Document)["ingress"].(model.Document)
+
+	got, err := json.Marshal(map[string]interface{}{
+		"encrypted":   volume["encrypted"],
+		"kms_key_id":  volume["kms_key_id"],
+		"tags":        volume["tags"],
+		"cidr_blocks": ingress["cidr_blocks"],
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"encrypted": true,
+		"kms_key_id": "${local.kms_key_id}",
+		"tags": {"Name": "PROD-data"},
+		"cidr_blocks": ["10.0.0.0/16"]
+	}`, string(got))
+}
@@ -0,0 +1,220 @@
+package terraform
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/comment"
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
+	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/functions"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/rs/zerolog/log"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxModuleDepth is the maximum number of nested local module calls that will be followed
+const maxModuleDepth = 10
+
+// moduleMetaArguments are the module block arguments that are not bound to the module input variables
+var moduleMetaArguments = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"count":      true,
+	"for_each":   true,
+	"providers":  true,
+	"depends_on": true,
+}
+
+// moduleCall represents a module block calling a local module
+type moduleCall struct {
+	name     string
+	source   string
+	dir      string
+	path     string
+	callFile string
+	callLine int
+	endLine  int
+	block    *hclsyntax.Block
+}
+
+// isLocalModuleSource returns true if the module source is a local path
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") ||
+		strings.HasPrefix(source, ".\\") || strings.HasPrefix(source, "..\\")
+}
+
+// getModuleCalls returns the module blocks of a body that point to a local module directory
+func getModuleCalls(body *hclsyntax.Body, filename, parentPath string, callerVariables converter.VariableMap) []moduleCall {
+	calls := make([]moduleCall, 0)
+	for _, block := range body.Blocks {
+		if block.Type != "module" || len(block.Labels) == 0 {
+			continue
+		}
+		sourceAttr, ok := block.Body.Attributes["source"]
+		if !ok {
+			continue
+		}
+		sourceValue, diags := sourceAttr.Expr.Value(nil)
+		if diags.HasErrors() || sourceValue.Type() != cty.String || sourceValue.IsNull() {
+			continue
+		}
+		source := sourceValue.AsString()
+		if !isLocalModuleSource(source) {
+			log.Trace().Msgf("Module %s source %s is not local, skipping resolution", block.Labels[0], source)
+			continue
+		}
+		if countAttr, hasCount := block.Body.Attributes["count"]; hasCount {
+			countValue, countDiags := countAttr.Expr.Value(&hcl.EvalContext{
+				Variables: callerVariables,
+				Functions: functions.TerraformFuncs,
+			})
+			if !countDiags.HasErrors() && countValue.Type() == cty.Number && countValue.IsKnown() &&
+				countValue.Equals(cty.NumberIntVal(0)).True() {
+				continue
+			}
+		}
+		modulePath := "module." + block.Labels[0]
+		if parentPath != "" {
+			modulePath = parentPath + "." + modulePath
+		}
+		calls = append(calls, moduleCall{
+			name:     block.Labels[0],
+			source:   source,
+			dir:      filepath.Join(filepath.Dir(filename), filepath.FromSlash(source)),
+			path:     modulePath,
+			callFile: filename,
+			callLine: block.TypeRange.Start.Line,
+			endLine:  block.Range().End.Line,
+			block:    block,
+		})
+	}
+	return calls
+}
+
+// getModuleVariables binds the module block arguments to the module input variables,
+// falling back to the module variables default values
+func getModuleVariables(fileSystem FileSystem, call *moduleCall, callerVariables converter.VariableMap,
+	moduleFiles []string) converter.VariableMap {
+	variablesMap := make(converter.VariableMap)
+	for _, tfFile := range moduleFiles {
+		variables, err := setInputVariablesDefaultValues(fileSystem, tfFile)
+		if err != nil {
+			log.Error().Msgf("Error getting default values from %s", tfFile)
+			log.Err(err)
+			continue
+		}
+		mergeMaps(variablesMap, variables)
+	}
+
+	evalContext := &hcl.EvalContext{
+		Variables: callerVariables,
+		Functions: functions.TerraformFuncs,
+	}
+	for name, attr := range call.block.Body.Attributes {
+		if moduleMetaArguments[name] {
+			continue
+		}
+		value, diags := attr.Expr.Value(evalContext)
+		if diags.HasErrors() || !value.IsWhollyKnown() {
+			log.Trace().Msgf("Could not evaluate argument %s of %s", name, call.path)
+			continue
+		}
+		variablesMap[name] = value
+	}
+
+	return converter.VariableMap{
+		"var": cty.ObjectVal(variablesMap),
+	}
+}
+
+// resolveModules converts the files of the local modules called in body, binding the module
+// block arguments to the module variables, and returns one document per module file instance
+func (p *Parser) resolveModules(body *hclsyntax.Body, filename, parentPath string,
+	callerVariables converter.VariableMap, visited map[string]bool, depth int) []model.Document {
+	documents := make([]model.Document, 0)
+	if depth >= maxModuleDepth {
+		log.Debug().Msgf("Max module depth reached resolving modules of %s", filename)
+		return documents
+	}
+
+	for _, call := range getModuleCalls(body, filename, parentPath, callerVariables) {
+		absDir, err := filepath.Abs(call.dir)
+		if err != nil || visited[absDir] {
+			continue
+		}
+		moduleFiles, err := p.getFileSystem().Glob(filepath.Join(call.dir, "*.tf"))
+		if err != nil || len(moduleFiles) == 0 {
+			log.Debug().Msgf("Module %s source %s has no terraform files", call.path, call.source)
+			continue
+		}
+		sort.Strings(moduleFiles)
+
+		moduleVariables := getModuleVariables(p.getFileSystem(), &call, callerVariables, moduleFiles)
+		getLocals(p.getFileSystem(), moduleVariables, call.dir)
+
+		visited[absDir] = true
+		for _, moduleFile := range moduleFiles {
+			documents = append(documents, p.convertModuleFile(&call, moduleFile, moduleVariables, visited, depth)...)
+		}
+		delete(visited, absDir)
+	}
+	return documents
+}
+
+// convertModuleFile converts a single file of a module instance and the modules it calls
+func (p *Parser) convertModuleFile(call *moduleCall, moduleFile string, moduleVariables converter.VariableMap,
+	visited map[string]bool, depth int) []model.Document {
+	content, err := p.getFileSystem().ReadFile(moduleFile)
+	if err != nil {
+		log.Error().Msgf("Failed to read module file %s: %s", moduleFile, err)
+		return []model.Document{}
+	}
+	file, diagnostics := hclsyntax.ParseConfig(content, filepath.Base(moduleFile), hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	if diagnostics != nil && diagnostics.HasErrors() {
+		log.Debug().Msgf("Failed to parse module file %s: %s", moduleFile, diagnostics.Error())
+		return []model.Document{}
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return []model.Document{}
+	}
+
+	// the converter keeps the variables of the last converted file, so the module ones are copied
+	converted, err := p.convertFunc(file, copyVariableMap(moduleVariables))
+	if err != nil || converted == nil {
+		log.Debug().Msgf("Failed to convert module file %s", moduleFile)
+		return []model.Document{}
+	}
+
+	setReferences(converted, body)
+
+	linesToIgnore := make([]int, 0)
+	if ignore, errComments := comment.ParseComments(content, moduleFile); errComments == nil {
+		linesToIgnore = comment.GetIgnoreLines(ignore, body)
+	}
+
+	converted[model.ModuleCallKey] = &model.ModuleCall{
+		Name:        call.name,
+		Source:      call.source,
+		Path:        call.path,
+		ModuleFile:  moduleFile,
+		FileName:    call.callFile,
+		Line:        call.callLine,
+		EndLine:     call.endLine,
+		LinesIgnore: linesToIgnore,
+	}
+
+	documents := []model.Document{converted}
+	return append(documents, p.resolveModules(body, moduleFile, call.path, moduleVariables, visited, depth+1)...)
+}
+
+func copyVariableMap(variables converter.VariableMap) converter.VariableMap {
+	variablesCopy := make(converter.VariableMap, len(variables))
+	for key, value := range variables {
+		variablesCopy[key] = value
+	}
+	return variablesCopy
+}
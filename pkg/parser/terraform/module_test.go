@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParser_ResolveModules tests that local module calls are resolved with the call site arguments
+func TestParser_ResolveModules(t *testing.T) {
+	mainFile := filepath.Join("..", "..", "..", "test", "fixtures", "test_terraform_modules", "main.tf")
+	content, err := os.ReadFile(mainFile)
+	require.NoError(t, err)
+
+	parser := NewDefault()
+	_, err = parser.Resolve(content, mainFile, false, 15)
+	require.NoError(t, err)
+	documents, _, err := parser.Parse(mainFile, content)
+	require.NoError(t, err)
+	require.Len(t, documents, 3)
+
+	var volume map[string]interface{}
+	var moduleCall *model.ModuleCall
+	for _, document := range documents[1:] {
+		call, ok := document[model.ModuleCallKey].(*model.ModuleCall)
+		require.True(t, ok)
+		if resources, ok := document["resource"].(model.Document); ok {
+			volume = resources["aws_ebs_volume"].(model.Document)["this"].(model.Document)
+			moduleCall = call
+		}
+	}
+	require.NotNil(t, volume)
+
+	got, err := json.Marshal(map[string]interface{}{
+		"encrypted": volume["encrypted"],
+		"size":      volume["size"],
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"encrypted": false, "size": 40}`, string(got))
+
+	require.Equal(t, "ebs", moduleCall.Name)
+	require.Equal(t, "module.ebs", moduleCall.Path)
+	require.Equal(t, "./modules/ebs", moduleCall.Source)
+	require.Equal(t, mainFile, moduleCall.FileName)
+	require.Equal(t, 5, moduleCall.Line)
+	require.Equal(t, 9, moduleCall.EndLine)
+	require.Equal(t, filepath.Join(filepath.Dir(mainFile), "modules", "ebs", "main.tf"), moduleCall.ModuleFile)
+}
+
+// TestIsLocalModuleSource tests the functions [isLocalModuleSource()]
+func TestIsLocalModuleSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{source: "./modules/ebs", want: true},
+		{source: "../shared", want: true},
+		{source: "terraform-aws-modules/vpc/aws", want: false},
+		{source: "git::https://example.com/vpc.git", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			require.Equal(t, tt.want, isLocalModuleSource(tt.source))
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// referenceRoots are the roots of the traversals that do not reference a resource, data source or module
+var referenceRoots = map[string]bool{
+	"var":       true,
+	"local":     true,
+	"count":     true,
+	"each":      true,
+	"path":      true,
+	"self":      true,
+	"terraform": true,
+}
+
+// getReferences returns the references of the attributes of the resources and data sources declared in body
+// to other resources, data sources and modules, the names of the resources are the ones written in their blocks
+func getReferences(body *hclsyntax.Body) []model.Reference {
+	references := make([]model.Reference, 0)
+	for _, block := range body.Blocks {
+		if (block.Type != "resource" && block.Type != "data") || len(block.Labels) != 2 {
+			continue
+		}
+		sourceType := block.Labels[0]
+		if block.Type == "data" {
+			sourceType = "data." + sourceType
+		}
+		source := model.Reference{
+			SourceType: sourceType,
+			SourceName: block.Labels[1],
+		}
+		references = append(references, getBodyReferences(block.Body, &source, "", make(map[string]bool))...)
+	}
+	return references
+}
+
+// setReferences sets in the document the references of the resources and data sources declared in body
+func setReferences(document model.Document, body *hclsyntax.Body) {
+	if document == nil {
+		return
+	}
+	if references := getReferences(body); len(references) > 0 {
+		document[model.ReferencesKey] = references
+	}
+}
+
+// getBodyReferences returns the references of the attributes of a body, path is the path of the body
+// in the resource and iterators are the names of the dynamic blocks iterators in scope
+func getBodyReferences(body *hclsyntax.Body, source *model.Reference, path string,
+	iterators map[string]bool) []model.Reference {
+	references := make([]model.Reference, 0)
+
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		attr := body.Attributes[name]
+		found := make(map[string]bool)
+		for _, traversal := range attr.Expr.Variables() {
+			if iterators[traversal.RootName()] {
+				continue
+			}
+			reference, ok := getReferenceTarget(traversal)
+			if !ok {
+				continue
+			}
+			target := reference.TargetType + "." + reference.TargetName + "." + reference.TargetAttribute
+			if found[target] {
+				continue
+			}
+			found[target] = true
+
+			reference.SourceType = source.SourceType
+			reference.SourceName = source.SourceName
+			reference.Attribute = joinReferencePath(path, name)
+			reference.Line = attr.SrcRange.Start.Line
+			references = append(references, reference)
+		}
+	}
+
+	for _, block := range body.Blocks {
+		blockPath := joinReferencePath(path, block.Type)
+		blockIterators := iterators
+		switch {
+		case block.Type == "content":
+			blockPath = path
+		case block.Type == "dynamic" && len(block.Labels) == 1:
+			blockPath = joinReferencePath(path, block.Labels[0])
+			blockIterators = make(map[string]bool, len(iterators)+1)
+			for iterator := range iterators {
+				blockIterators[iterator] = true
+			}
+			blockIterators[getDynamicIterator(block)] = true
+		}
+		references = append(references, getBodyReferences(block.Body, source, blockPath, blockIterators)...)
+	}
+
+	return references
+}
+
+// getReferenceTarget returns the resource, data source or module referenced by a traversal
+func getReferenceTarget(traversal hcl.Traversal) (model.Reference, bool) {
+	root := traversal.RootName()
+	if referenceRoots[root] {
+		return model.Reference{}, false
+	}
+
+	targetType := root
+	steps := traversal[1:]
+	if root == "data" {
+		if len(steps) == 0 {
+			return model.Reference{}, false
+		}
+		dataType, ok := steps[0].(hcl.TraverseAttr)
+		if !ok {
+			return model.Reference{}, false
+		}
+		targetType = "data." + dataType.Name
+		steps = steps[1:]
+	}
+	if len(steps) == 0 {
+		return model.Reference{}, false
+	}
+	targetName, ok := steps[0].(hcl.TraverseAttr)
+	if !ok {
+		return model.Reference{}, false
+	}
+
+	attributes := make([]string, 0)
+	for _, step := range steps[1:] {
+		if attr, isAttr := step.(hcl.TraverseAttr); isAttr {
+			attributes = append(attributes, attr.Name)
+		}
+	}
+
+	return model.Reference{
+		TargetType:      targetType,
+		TargetName:      targetName.Name,
+		TargetAttribute: strings.Join(attributes, "."),
+	}, true
+}
+
+// getDynamicIterator returns the name of the iterator of a dynamic block, which defaults to its label
+func getDynamicIterator(block *hclsyntax.Block) string {
+	if attr, exists := block.Body.Attributes["iterator"]; exists {
+		if iterator := hcl.ExprAsKeyword(attr.Expr); iterator != "" {
+			return iterator
+		}
+	}
+	return block.Labels[0]
+}
+
+func joinReferencePath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
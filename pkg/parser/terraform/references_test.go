@@ -0,0 +1,189 @@
+package terraform
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/assets"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/stretchr/testify/require"
+)
+
+var referencesSample = `
+resource "aws_s3_bucket" "example" {
+  bucket = "${var.prefix}-example"
+}
+
+resource "aws_s3_bucket_public_access_block" "example" {
+  bucket     = aws_s3_bucket.example.id
+  depends_on = [aws_s3_bucket.example]
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "example" {
+  bucket = aws_s3_bucket.example[0].id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      kms_master_key_id = data.aws_kms_key.example.arn
+      sse_algorithm     = "aws:kms"
+    }
+  }
+}
+
+resource "aws_security_group" "example" {
+  vpc_id = module.vpc.vpc_id
+
+  dynamic "ingress" {
+    for_each = local.ports
+    iterator = port_rule
+    content {
+      from_port       = port_rule.value
+      security_groups = [for sg in aws_security_group.other : sg.id]
+    }
+  }
+}
+`
+
+// TestGetReferences tests the functions [getReferences()] and all the methods called by them
+func TestGetReferences(t *testing.T) {
+	file, diagnostics := hclsyntax.ParseConfig([]byte(referencesSample), "references.tf", hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	require.False(t, diagnostics.HasErrors())
+
+	got := getReferences(file.Body.(*hclsyntax.Body))
+	require.Equal(t, []model.Reference{
+		{
+			SourceType:      "aws_s3_bucket_public_access_block",
+			SourceName:      "example",
+			Attribute:       "bucket",
+			TargetType:      "aws_s3_bucket",
+			TargetName:      "example",
+			TargetAttribute: "id",
+			Line:            7,
+		},
+		{
+			SourceType: "aws_s3_bucket_public_access_block",
+			SourceName: "example",
+			Attribute:  "depends_on",
+			TargetType: "aws_s3_bucket",
+			TargetName: "example",
+			Line:       8,
+		},
+		{
+			SourceType:      "aws_s3_bucket_server_side_encryption_configuration",
+			SourceName:      "example",
+			Attribute:       "bucket",
+			TargetType:      "aws_s3_bucket",
+			TargetName:      "example",
+			TargetAttribute: "id",
+			Line:            12,
+		},
+		{
+			SourceType:      "aws_s3_bucket_server_side_encryption_configuration",
+			SourceName:      "example",
+			Attribute:       "rule.apply_server_side_encryption_by_default.kms_master_key_id",
+			TargetType:      "data.aws_kms_key",
+			TargetName:      "example",
+			TargetAttribute: "arn",
+			Line:            16,
+		},
+		{
+			SourceType:      "aws_security_group",
+			SourceName:      "example",
+			Attribute:       "vpc_id",
+			TargetType:      "module",
+			TargetName:      "vpc",
+			TargetAttribute: "vpc_id",
+			Line:            23,
+		},
+		{
+			SourceType: "aws_security_group",
+			SourceName: "example",
+			Attribute:  "ingress.security_groups",
+			TargetType: "aws_security_group",
+			TargetName: "other",
+			Line:       30,
+		},
+	}, got)
+}
+
+// TestParser_References tests that the references are set in the parsed document
+func TestParser_References(t *testing.T) {
+	parser := NewDefault()
+	documents, _, err := parser.Parse("references.tf", []byte(referencesSample))
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	require.Len(t, documents[0][model.ReferencesKey], 6)
+
+	documents, _, err = parser.Parse("no_references.tf", []byte(have))
+	require.NoError(t, err)
+	require.NotContains(t, documents[0], model.ReferencesKey)
+}
+
+// TestLibrary_References tests that the reference helpers of the Terraform library only follow the references of
+// the documents of the same directory and module instance
+func TestLibrary_References(t *testing.T) {
+	bucket := `
+resource "aws_s3_bucket" "example" {
+  bucket = "example"
+}
+`
+	publicAccessBlock := `
+resource "aws_s3_bucket_public_access_block" "example" {
+  bucket = aws_s3_bucket.example.id
+}
+`
+	parser := NewDefault()
+	document := func(path, content string, moduleCall *model.ModuleCall) model.Document {
+		documents, _, err := parser.Parse(path, []byte(content))
+		require.NoError(t, err)
+		require.Len(t, documents, 1)
+		documents[0]["file"] = path
+		if moduleCall != nil {
+			documents[0][model.ModuleCallKey] = moduleCall
+		}
+		return documents[0]
+	}
+	documents := []model.Document{
+		document(filepath.Join("a", "main.tf"), bucket, nil),
+		document(filepath.Join("a", "access.tf"), publicAccessBlock, nil),
+		document(filepath.Join("b", "main.tf"), bucket, nil),
+		document(filepath.Join("a", "main.tf"), bucket, &model.ModuleCall{Path: "module.a"}),
+		document(filepath.Join("c", "access.tf"), publicAccessBlock, nil),
+	}
+
+	common, err := assets.GetEmbeddedLibrary("common")
+	require.NoError(t, err)
+	library, err := assets.GetEmbeddedLibrary("terraform")
+	require.NoError(t, err)
+	prepared, err := rego.New(
+		rego.Query(`data.generic.terraform.is_referenced_by(input.document[input.index], "aws_s3_bucket", "example", `+
+			`"aws_s3_bucket_public_access_block")`),
+		rego.Module("common.rego", common),
+		rego.Module("terraform.rego", library),
+	).PrepareForEval(context.Background())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		index int
+		want  bool
+	}{
+		{name: "should follow the references of the same directory", index: 0, want: true},
+		{name: "should not follow the references of another directory", index: 2, want: false},
+		{name: "should not follow the references of another module instance", index: 3, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := prepared.Eval(context.Background(), rego.EvalInput(map[string]interface{}{
+				"document": documents,
+				"index":    tt.index,
+			}))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, results.Allowed())
+		})
+	}
+}
@@ -1,10 +1,10 @@
 package terraform
 
 import (
-	"os"
 	"path/filepath"
 	"regexp"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/comment"
 	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
@@ -28,6 +28,8 @@ type Parser struct {
 	convertFunc       Converter
 	numOfRetries      int
 	terraformVarsPath string
+	fileSystem        FileSystem
+	inputVariables    converter.VariableMap
 }
 
 // NewDefault initializes a parser with Parser default values
@@ -45,6 +47,19 @@ func NewDefaultWithVarsPath(terraformVarsPath string) *Parser {
 	return parser
 }
 
+// NewDefaultWithFileSystem initializes a parser with the default values using a variables path and
+// reading the files surrounding the parsed ones from the given FileSystem
+func NewDefaultWithFileSystem(terraformVarsPath string, fileSystem FileSystem) *Parser {
+	parser := NewDefaultWithVarsPath(terraformVarsPath)
+	parser.fileSystem = fileSystem
+	return parser
+}
+
+// getFileSystem returns the FileSystem used to read the files surrounding the parsed ones
+func (p *Parser) getFileSystem() FileSystem {
+	return filesystem.Get(p.fileSystem)
+}
+
 // Resolve - replace or modifies in-memory content before parsing
 func (p *Parser) Resolve(fileContent []byte, filename string, _ bool, _ int) ([]byte, error) {
 	// handle panic during resolve process
@@ -54,22 +69,24 @@ func (p *Parser) Resolve(fileContent []byte, filename string, _ bool, _ int) ([]
 			masterUtils.HandlePanic(r, errMessage)
 		}
 	}()
-	getInputVariables(filepath.Dir(filename), string(fileContent), p.terraformVarsPath)
-	getDataSourcePolicy(filepath.Dir(filename))
+	p.inputVariables = make(converter.VariableMap)
+	getInputVariables(p.getFileSystem(), p.inputVariables, filepath.Dir(filename), string(fileContent), p.terraformVarsPath)
+	getDataSourcePolicy(p.getFileSystem(), p.inputVariables, filepath.Dir(filename))
+	getLocals(p.getFileSystem(), p.inputVariables, filepath.Dir(filename))
 	return fileContent, nil
 }
 
-func processContent(elements model.Document, content, path string) {
+func processContent(fileSystem FileSystem, elements model.Document, content, path string) {
 	var certInfo map[string]interface{}
 	if content != "" {
-		certInfo = utils.AddCertificateInfo(path, content)
+		certInfo = utils.AddCertificateInfo(fileSystem, path, content)
 		if certInfo != nil {
 			elements["certificate_body"] = certInfo
 		}
 	}
 }
 
-func processElements(elements model.Document, path string) {
+func processElements(fileSystem FileSystem, elements model.Document, path string) {
 	for k, v3 := range elements { // resource elements
 		if k != "certificate_body" {
 			continue
@@ -77,29 +94,29 @@ func processElements(elements model.Document, path string) {
 		switch value := v3.(type) {
 		case string:
 			content := utils.CheckCertificate(value)
-			processContent(elements, content, path)
+			processContent(fileSystem, elements, content, path)
 		case ctyjson.SimpleJSONValue:
 			content := utils.CheckCertificate(value.Value.AsString())
-			processContent(elements, content, path)
+			processContent(fileSystem, elements, content, path)
 		}
 	}
 }
 
-func processResourcesElements(resourcesElements model.Document, path string) error {
+func processResourcesElements(fileSystem FileSystem, resourcesElements model.Document, path string) error {
 	for _, v2 := range resourcesElements {
 		switch t := v2.(type) {
 		case []interface{}:
 			return errors.New("failed to process resources")
 		case interface{}:
 			if elements, ok := t.(model.Document); ok {
-				processElements(elements, path)
+				processElements(fileSystem, elements, path)
 			}
 		}
 	}
 	return nil
 }
 
-func processResources(doc model.Document, path string) error {
+func processResources(fileSystem FileSystem, doc model.Document, path string) error {
 	var resourcesElements model.Document
 
 	defer func() {
@@ -114,7 +131,7 @@ func processResources(doc model.Document, path string) error {
 		case []interface{}: // support the case of nameless resources - where we get a list of resources
 			for _, value := range t {
 				resourcesElements = value.(model.Document)
-				err := processResourcesElements(resourcesElements, path)
+				err := processResourcesElements(fileSystem, resourcesElements, path)
 				if err != nil {
 					return err
 				}
@@ -122,7 +139,7 @@ func processResources(doc model.Document, path string) error {
 
 		case interface{}:
 			resourcesElements = t.(model.Document)
-			err := processResourcesElements(resourcesElements, path)
+			err := processResourcesElements(fileSystem, resourcesElements, path)
 			if err != nil {
 				return err
 			}
@@ -131,7 +148,7 @@ func processResources(doc model.Document, path string) error {
 	return nil
 }
 
-func addExtraInfo(json []model.Document, path string) ([]model.Document, error) {
+func addExtraInfo(fileSystem FileSystem, json []model.Document, path string) ([]model.Document, error) {
 	// handle panic during resource processing
 	defer func() {
 		if r := recover(); r != nil {
@@ -141,7 +158,7 @@ func addExtraInfo(json []model.Document, path string) ([]model.Document, error)
 	}()
 	for _, documents := range json { // iterate over documents
 		if resources, ok := documents["resource"].(model.Document); ok {
-			err := processResources(resources, path)
+			err := processResources(fileSystem, resources, path)
 			if err != nil {
 				return []model.Document{}, err
 			}
@@ -151,8 +168,8 @@ func addExtraInfo(json []model.Document, path string) ([]model.Document, error)
 	return json, nil
 }
 
-func parseFile(filename string, shouldReplaceDataSource bool) (*hcl.File, error) {
-	file, err := os.ReadFile(filename)
+func parseFile(fileSystem FileSystem, filename string, shouldReplaceDataSource bool) (*hcl.File, error) {
+	file, err := fileSystem.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -186,8 +203,17 @@ func (p *Parser) Parse(path string, content []byte) ([]model.Document, []int, er
 
 	linesToIgnore := comment.GetIgnoreLines(ignore, file.Body.(*hclsyntax.Body))
 
-	fc, parseErr := p.convertFunc(file, inputVariableMap)
-	json, err := addExtraInfo([]model.Document{fc}, path)
+	if p.inputVariables == nil {
+		p.inputVariables = make(converter.VariableMap)
+	}
+	fc, parseErr := p.convertFunc(file, p.inputVariables)
+	documents := []model.Document{fc}
+	if parseErr == nil {
+		setReferences(fc, file.Body.(*hclsyntax.Body))
+		documents = append(documents,
+			p.resolveModules(file.Body.(*hclsyntax.Body), path, "", copyVariableMap(p.inputVariables), make(map[string]bool), 0)...)
+	}
+	json, err := addExtraInfo(p.getFileSystem(), documents, path)
 	if err != nil {
 		return json, []int{}, errors.Wrap(err, "failed terraform parse")
 	}
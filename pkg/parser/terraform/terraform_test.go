@@ -9,6 +9,7 @@ import (
 	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/stretchr/testify/require"
 )
@@ -151,7 +152,8 @@ func Test_Count(t *testing.T) {
 // Test_Parentheses_Expr tests if parentheses expr is well parsed
 func Test_Parentheses_Expr(t *testing.T) {
 	parser := NewDefault()
-	getInputVariables(filepath.FromSlash("../../../test/fixtures/test-tf-parentheses"), parentheses, "")
+	parser.inputVariables = make(converter.VariableMap)
+	getInputVariables(&filesystem.OS{}, parser.inputVariables, filepath.FromSlash("../../../test/fixtures/test-tf-parentheses"), parentheses, "")
 	document, _, err := parser.Parse("parentheses.tf", []byte(parentheses))
 	require.NoError(t, err)
 	require.Len(t, document, 1)
@@ -212,7 +214,7 @@ func TestTerraform_ProcessContent(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			processContent(tt.args.elements, tt.args.content, tt.args.path)
+			processContent(filesystem.Get(nil), tt.args.elements, tt.args.content, tt.args.path)
 			require.Equal(t, tt.want, tt.args.elements["certificate_body"])
 		})
 	}
@@ -365,7 +367,7 @@ data "aws_iam_policy_document" "test_destination_policy" {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parsedFile, err := parseFile(tt.filename, tt.shouldReplaceDataSource)
+			parsedFile, err := parseFile(&filesystem.OS{}, tt.filename, tt.shouldReplaceDataSource)
 			if tt.wantErr {
 				require.NotNil(t, err)
 				require.Nil(t, parsedFile)
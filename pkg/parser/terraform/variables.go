@@ -2,7 +2,6 @@ package terraform
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -14,16 +13,14 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-var inputVariableMap = make(converter.VariableMap)
-
 func mergeMaps(baseMap, newItems converter.VariableMap) {
 	for key, value := range newItems {
 		baseMap[key] = value
 	}
 }
 
-func setInputVariablesDefaultValues(filename string) (converter.VariableMap, error) {
-	parsedFile, err := parseFile(filename, false)
+func setInputVariablesDefaultValues(fileSystem FileSystem, filename string) (converter.VariableMap, error) {
+	parsedFile, err := parseFile(fileSystem, filename, false)
 	if err != nil || parsedFile == nil {
 		return nil, err
 	}
@@ -68,8 +65,8 @@ func checkTfvarsValid(f *hcl.File, filename string) error {
 	return nil
 }
 
-func getInputVariablesFromFile(filename string) (converter.VariableMap, error) {
-	parsedFile, err := parseFile(filename, false)
+func getInputVariablesFromFile(fileSystem FileSystem, filename string) (converter.VariableMap, error) {
+	parsedFile, err := parseFile(fileSystem, filename, false)
 	if err != nil || parsedFile == nil {
 		return nil, err
 	}
@@ -87,14 +84,16 @@ func getInputVariablesFromFile(filename string) (converter.VariableMap, error) {
 	return variables, nil
 }
 
-func getInputVariables(currentPath, fileContent, terraformVarsPath string) {
+// getInputVariables sets in inputVariables the input variables values of the files in currentPath
+func getInputVariables(fileSystem FileSystem, inputVariables converter.VariableMap,
+	currentPath, fileContent, terraformVarsPath string) {
 	variablesMap := make(converter.VariableMap)
-	tfFiles, err := filepath.Glob(filepath.Join(currentPath, "*.tf"))
+	tfFiles, err := fileSystem.Glob(filepath.Join(currentPath, "*.tf"))
 	if err != nil {
 		log.Error().Msg("Error getting .tf files")
 	}
 	for _, tfFile := range tfFiles {
-		variables, errDefaultValues := setInputVariablesDefaultValues(tfFile)
+		variables, errDefaultValues := setInputVariablesDefaultValues(fileSystem, tfFile)
 		if errDefaultValues != nil {
 			log.Error().Msgf("Error getting default values from %s", tfFile)
 			log.Err(errDefaultValues)
@@ -102,12 +101,12 @@ func getInputVariables(currentPath, fileContent, terraformVarsPath string) {
 		}
 		mergeMaps(variablesMap, variables)
 	}
-	tfVarsFiles, err := filepath.Glob(filepath.Join(currentPath, "*.auto.tfvars"))
+	tfVarsFiles, err := fileSystem.Glob(filepath.Join(currentPath, "*.auto.tfvars"))
 	if err != nil {
 		log.Error().Msg("Error getting .auto.tfvars files")
 	}
 
-	_, err = os.Stat(filepath.Join(currentPath, "terraform.tfvars"))
+	_, err = fileSystem.ReadFile(filepath.Join(currentPath, "terraform.tfvars"))
 	if err != nil {
 		log.Trace().Msgf("terraform.tfvars not found on %s", currentPath)
 	} else {
@@ -115,7 +114,7 @@ func getInputVariables(currentPath, fileContent, terraformVarsPath string) {
 	}
 
 	for _, tfVarsFile := range tfVarsFiles {
-		variables, errInputVariables := getInputVariablesFromFile(tfVarsFile)
+		variables, errInputVariables := getInputVariablesFromFile(fileSystem, tfVarsFile)
 		if errInputVariables != nil {
 			log.Error().Msgf("Error getting values from %s", tfVarsFile)
 			log.Err(errInputVariables)
@@ -142,11 +141,11 @@ func getInputVariables(currentPath, fileContent, terraformVarsPath string) {
 	// If the terraformVarsPath is empty, this means that it is not in the flag
 	// and it is not in the first written line of the file
 	if terraformVarsPath != "" {
-		_, err = os.Stat(terraformVarsPath)
+		_, err = fileSystem.ReadFile(terraformVarsPath)
 		if err != nil {
 			log.Trace().Msgf("%s file not found", terraformVarsPath)
 		} else {
-			variables, errInputVariables := getInputVariablesFromFile(terraformVarsPath)
+			variables, errInputVariables := getInputVariablesFromFile(fileSystem, terraformVarsPath)
 			if errInputVariables != nil {
 				log.Error().Msgf("Error getting values from %s", terraformVarsPath)
 				log.Err(errInputVariables)
@@ -156,5 +155,5 @@ func getInputVariables(currentPath, fileContent, terraformVarsPath string) {
 		}
 	}
 
-	inputVariableMap["var"] = cty.ObjectVal(variablesMap)
+	inputVariables["var"] = cty.ObjectVal(variablesMap)
 }
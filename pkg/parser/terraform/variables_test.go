@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/parser/terraform/converter"
 	"github.com/stretchr/testify/require"
 	"github.com/zclconf/go-cty/cty"
@@ -56,9 +57,6 @@ func TestMergeMaps(t *testing.T) {
 			require.Equal(t, tt.want, tt.args.baseMap)
 		})
 	}
-	t.Cleanup(func() {
-		inputVariableMap = make(converter.VariableMap)
-	})
 }
 
 func TestSetInputVariablesDefaultValues(t *testing.T) {
@@ -95,7 +93,7 @@ func TestSetInputVariablesDefaultValues(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			defaultValues, err := setInputVariablesDefaultValues(tt.filename)
+			defaultValues, err := setInputVariablesDefaultValues(&filesystem.OS{}, tt.filename)
 			if tt.wantErr {
 				require.NotNil(t, err)
 			} else {
@@ -104,9 +102,6 @@ func TestSetInputVariablesDefaultValues(t *testing.T) {
 			require.Equal(t, tt.want, defaultValues)
 		})
 	}
-	t.Cleanup(func() {
-		inputVariableMap = make(converter.VariableMap)
-	})
 }
 
 func TestGetInputVariablesFromFile(t *testing.T) {
@@ -142,7 +137,7 @@ func TestGetInputVariablesFromFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			inputVars, err := getInputVariablesFromFile(tt.filename)
+			inputVars, err := getInputVariablesFromFile(&filesystem.OS{}, tt.filename)
 			if tt.wantErr {
 				require.NotNil(t, err)
 			} else {
@@ -151,9 +146,6 @@ func TestGetInputVariablesFromFile(t *testing.T) {
 			require.Equal(t, tt.want, inputVars)
 		})
 	}
-	t.Cleanup(func() {
-		inputVariableMap = make(converter.VariableMap)
-	})
 }
 
 func TestGetInputVariables(t *testing.T) {
@@ -198,11 +190,10 @@ func TestGetInputVariables(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			fileContent, _ := os.ReadFile(tt.filename)
-			getInputVariables(tt.filename, string(fileContent), "../../../test/fixtures/test_terraform_variables/varsToUse/varsToUse.tf")
-			require.Equal(t, tt.want, inputVariableMap)
+			inputVariables := make(converter.VariableMap)
+			getInputVariables(&filesystem.OS{}, inputVariables, tt.filename, string(fileContent),
+				"../../../test/fixtures/test_terraform_variables/varsToUse/varsToUse.tf")
+			require.Equal(t, tt.want, inputVariables)
 		})
 	}
-	t.Cleanup(func() {
-		inputVariableMap = make(converter.VariableMap)
-	})
 }
@@ -5,10 +5,10 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
-	"os"
 	"path/filepath"
 	"regexp"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/rs/zerolog/log"
 )
 
@@ -26,8 +26,8 @@ func CheckCertificate(content string) string {
 	return match
 }
 
-func getCertificateInfo(filePath string) (certInfo, error) {
-	certPEM, err := os.ReadFile(filePath)
+func getCertificateInfo(fileSystem filesystem.FileSystem, filePath string) (certInfo, error) {
+	certPEM, err := fileSystem.ReadFile(filePath)
 
 	if err != nil {
 		return certInfo{}, err
@@ -60,11 +60,11 @@ func getCertificateInfo(filePath string) (certInfo, error) {
 	return certInfo{date: certDate, rsaKeyBytes: rsaBytes}, nil
 }
 
-// AddCertificateInfo gets and adds certificate information of a certificate file
-func AddCertificateInfo(path, content string) map[string]interface{} {
+// AddCertificateInfo gets and adds certificate information of a certificate file read from the FileSystem
+func AddCertificateInfo(fileSystem filesystem.FileSystem, path, content string) map[string]interface{} {
 	var filePath string
 
-	_, err := os.Stat(content)
+	_, err := fileSystem.Stat(content)
 
 	if err != nil { // content is not a full valid path or is an incomplete path
 		log.Trace().Msgf("path to the certificate content is not a valid: %s", content)
@@ -73,7 +73,7 @@ func AddCertificateInfo(path, content string) map[string]interface{} {
 		filePath = content
 	}
 
-	date, err := getCertificateInfo(filePath)
+	date, err := getCertificateInfo(fileSystem, filePath)
 
 	if err == nil {
 		attributes := make(map[string]interface{})
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/stretchr/testify/require"
 )
 
@@ -11,7 +12,7 @@ func TestAddCertificateInfo(t *testing.T) {
 	path := filepath.Join("..", "..", "..", "test", "fixtures", "test_certificate", "positive.tf")
 	certificatePath := "certificate.pem"
 
-	info := AddCertificateInfo(path, certificatePath)
+	info := AddCertificateInfo(filesystem.Get(nil), path, certificatePath)
 
 	require.NotEmpty(t, info)
 }
@@ -19,7 +20,7 @@ func TestAddCertificateInfo(t *testing.T) {
 func TestGetCertificateInfo(t *testing.T) {
 	filePath := filepath.Join("..", "..", "..", "test", "fixtures", "test_certificate", "certificate.pem")
 
-	date, err := getCertificateInfo(filePath)
+	date, err := getCertificateInfo(filesystem.Get(nil), filePath)
 
 	require.NoError(t, err)
 	require.NotEmpty(t, date)
@@ -5,7 +5,12 @@ import (
 
 	"github.com/Checkmarx/kics/v2/pkg/parser/utils"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/parser/ansible"
+	"github.com/Checkmarx/kics/v2/pkg/parser/cloudformation"
+	"github.com/Checkmarx/kics/v2/pkg/parser/dockercompose"
+	"github.com/Checkmarx/kics/v2/pkg/parser/githubactions"
 	"github.com/Checkmarx/kics/v2/pkg/resolver/file"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -14,13 +19,50 @@ import (
 
 // Parser defines a parser type
 type Parser struct {
-	resolvedFiles map[string]model.ResolvedFile
+	resolvedFiles    map[string]model.ResolvedFile
+	cloudFormation   *cloudformation.Resolver
+	fileSystem       filesystem.FileSystem
+	maxResolverDepth int
+}
+
+// NewWithCloudFormationParameters initializes a parser resolving CloudFormation templates with the parameters passed
+func NewWithCloudFormationParameters(parameters map[string]string) *Parser {
+	return &Parser{
+		cloudFormation: cloudformation.NewResolver(parameters),
+	}
+}
+
+// SetFileSystem sets the FileSystem the files surrounding the parsed ones are read from
+func (p *Parser) SetFileSystem(fileSystem filesystem.FileSystem) {
+	p.fileSystem = fileSystem
+}
+
+// getFileSystem returns the FileSystem the files surrounding the parsed ones are read from, the disk by default
+func (p *Parser) getFileSystem() filesystem.FileSystem {
+	return filesystem.Get(p.fileSystem)
+}
+
+// getCloudFormationResolver returns the resolver of the intrinsic functions of CloudFormation templates
+func (p *Parser) getCloudFormationResolver() *cloudformation.Resolver {
+	if p.cloudFormation == nil {
+		return cloudformation.NewResolver(nil)
+	}
+	return p.cloudFormation
 }
 
 // Resolve - replace or modifies in-memory content before parsing
 func (p *Parser) Resolve(fileContent []byte, filename string, resolveReferences bool, maxResolverDepth int) ([]byte, error) {
+	// Ansible roles, includes and imports, Compose projects and GitHub Actions workflows are resolved while parsing,
+	// see ansible.Resolver, dockercompose.Resolver and githubactions.Resolver
+	p.maxResolverDepth = maxResolverDepth
+	var document model.Document
+	if yaml.Unmarshal(fileContent, &document) == nil && (ansible.IsAnsible(document, filename) ||
+		dockercompose.IsCompose(document, filename) || githubactions.IsWorkflow(document)) {
+		p.resolvedFiles = make(map[string]model.ResolvedFile)
+		return fileContent, nil
+	}
 	// Resolve files passed as arguments with file resolver (e.g. file://)
-	res := file.NewResolver(yaml.Unmarshal, yaml.Marshal, p.SupportedExtensions())
+	res := file.NewResolverWithFileSystem(yaml.Unmarshal, yaml.Marshal, p.SupportedExtensions(), p.getFileSystem())
 	resolvedFilesCache := make(map[string]file.ResolvedFile)
 	resolved := res.Resolve(fileContent, filename, 0, maxResolverDepth, resolvedFilesCache, resolveReferences)
 	p.resolvedFiles = res.ResolvedFiles
@@ -52,7 +94,36 @@ func (p *Parser) Parse(filePath string, fileContent []byte) ([]model.Document, [
 
 	linesToIgnore := model.NewIgnore.GetLines()
 
-	return convertKeysToString(addExtraInfo(documents, filePath)), linesToIgnore, nil
+	documents = convertKeysToString(addExtraInfo(p.getFileSystem(), documents, filePath))
+	for i := range documents {
+		if ansible.IsAnsible(documents[i], filePath) {
+			documents[i] = ansible.NewResolverWithFileSystem(p.maxResolverDepth, p.getFileSystem()).Resolve(documents[i], filePath)
+		}
+		if dockercompose.IsCompose(documents[i], filePath) {
+			documents[i] = p.resolveCompose(documents[i], filePath)
+		}
+		if githubactions.IsWorkflow(documents[i]) {
+			documents[i] = githubactions.NewResolverWithFileSystem(p.maxResolverDepth, p.getFileSystem()).Resolve(documents[i], filePath)
+		}
+		documents[i] = cloudformation.RestoreShortForm(p.getCloudFormationResolver().Resolve(documents[i]))
+	}
+	documents = append(documents, cloudformation.TransformServerless(documents)...)
+
+	return documents, linesToIgnore, nil
+}
+
+// resolveCompose resolves the project model of a Compose file, the files it is resolved from are kept as the
+// resolved files, so the results point to the file of the attributes
+func (p *Parser) resolveCompose(document model.Document, filePath string) model.Document {
+	resolver := dockercompose.NewResolverWithFileSystem(p.maxResolverDepth, p.getFileSystem())
+	resolved := resolver.Resolve(document, filePath)
+	if p.resolvedFiles == nil {
+		p.resolvedFiles = make(map[string]model.ResolvedFile)
+	}
+	for path, file := range resolver.ResolvedFiles() {
+		p.resolvedFiles[path] = file
+	}
+	return resolved
 }
 
 // convertKeysToString goes through every document to convert map[interface{}]interface{}
@@ -119,44 +190,44 @@ func (p *Parser) GetKind() model.FileKind {
 	return model.KindYAML
 }
 
-func processCertContent(elements map[string]interface{}, content, filePath string) {
+func processCertContent(fileSystem filesystem.FileSystem, elements map[string]interface{}, content, filePath string) {
 	var certInfo map[string]interface{}
 	if content != "" {
-		certInfo = utils.AddCertificateInfo(filePath, content)
+		certInfo = utils.AddCertificateInfo(fileSystem, filePath, content)
 		if certInfo != nil {
 			elements["certificate"] = certInfo
 		}
 	}
 }
 
-func processElements(elements map[string]interface{}, filePath string) {
+func processElements(fileSystem filesystem.FileSystem, elements map[string]interface{}, filePath string) {
 	if elements["certificate"] != nil {
-		processCertContent(elements, utils.CheckCertificate(elements["certificate"].(string)), filePath)
+		processCertContent(fileSystem, elements, utils.CheckCertificate(elements["certificate"].(string)), filePath)
 	}
 }
 
-func addExtraInfo(documents []model.Document, filePath string) []model.Document {
+func addExtraInfo(fileSystem filesystem.FileSystem, documents []model.Document, filePath string) []model.Document {
 	for _, documentPlaybooks := range documents { // iterate over documents
 		if playbooks, ok := documentPlaybooks["playbooks"]; ok {
-			processPlaybooks(playbooks, filePath)
+			processPlaybooks(fileSystem, playbooks, filePath)
 		}
 	}
 
 	return documents
 }
 
-func processPlaybooks(playbooks interface{}, filePath string) {
+func processPlaybooks(fileSystem filesystem.FileSystem, playbooks interface{}, filePath string) {
 	sliceResources, ok := playbooks.([]interface{})
 	if !ok { // prevent panic if playbooks is not a slice
 		log.Warn().Msgf("Failed to parse playbooks: %s", filePath)
 		return
 	}
 	for _, resources := range sliceResources { // iterate over playbooks
-		processPlaybooksElements(resources, filePath)
+		processPlaybooksElements(fileSystem, resources, filePath)
 	}
 }
 
-func processPlaybooksElements(resources interface{}, filePath string) {
+func processPlaybooksElements(fileSystem filesystem.FileSystem, resources interface{}, filePath string) {
 	mapResources, ok := resources.(map[string]interface{})
 	if !ok {
 		log.Warn().Msgf("Failed to parse playbooks elements: %s", filePath)
@@ -167,7 +238,7 @@ func processPlaybooksElements(resources interface{}, filePath string) {
 		if !ok {
 			continue
 		}
-		processElements(mapValue, filePath)
+		processElements(fileSystem, mapValue, filePath)
 	}
 }
 
@@ -3,10 +3,12 @@ package json
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/stretchr/testify/require"
 )
@@ -425,7 +427,7 @@ func TestYaml_processElements(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			processElements(tt.args.elements, tt.args.filePath)
+			processElements(filesystem.Get(nil), tt.args.elements, tt.args.filePath)
 			require.Equal(t, tt.wantCert, tt.args.elements["certificate"])
 			require.Equal(t, tt.wantSwag, tt.args.elements["swagger_file"])
 		})
@@ -471,7 +473,97 @@ func TestModel_TestYamlParser(t *testing.T) {
 	}
 }
 
+// TestParser_Parse_CloudFormation tests that the intrinsic functions of CloudFormation templates written in their
+// short form are resolved and the ones that can not be evaluated are kept as their arguments
+func TestParser_Parse_CloudFormation(t *testing.T) {
+	sample := `
+AWSTemplateFormatVersion: "2010-09-09"
+Parameters:
+  BucketName:
+    Type: String
+    Default: logs
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Sub "${BucketName}-bucket"
+      LoggingConfiguration:
+        DestinationBucketName: !Ref LogsBucket
+`
+	parser := Parser{}
+	got, _, err := parser.Parse("template.yaml", []byte(sample))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	properties := got[0]["Resources"].(map[string]interface{})["Bucket"].(map[string]interface{})["Properties"].(map[string]interface{})
+	require.Equal(t, "logs-bucket", properties["BucketName"])
+	require.Equal(t, "LogsBucket", properties["LoggingConfiguration"].(map[string]interface{})["DestinationBucketName"])
+}
+
 // Test_GetCommentToken must get the token that represents a comment
+// TestParser_Parse_Ansible tests that the roles and includes of an Ansible playbook are resolved while parsing
+// and that the generic file resolver does not inline them
+func TestParser_Parse_Ansible(t *testing.T) {
+	path := filepath.Join("..", "..", "..", "test", "fixtures", "test_ansible_roles", "site.yml")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	parser := Parser{}
+	resolved, err := parser.Resolve(content, path, true, 15)
+	require.NoError(t, err)
+	require.Equal(t, content, resolved)
+	require.Empty(t, parser.GetResolvedFiles())
+
+	got, _, err := parser.Parse(path, resolved)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	play := got[0]["playbooks"].([]interface{})[0].(map[string]interface{})
+	task := play["tasks"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "Create data bucket", task["name"])
+	require.Equal(t, "prod-data", task["amazon.aws.s3_bucket"].(map[string]interface{})["name"])
+}
+
+func TestParser_Parse_DockerCompose(t *testing.T) {
+	dir := filepath.Join("..", "..", "..", "test", "fixtures", "test_compose_project")
+	path := filepath.Join(dir, "docker-compose.yml")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	parser := Parser{}
+	resolved, err := parser.Resolve(content, path, true, 15)
+	require.NoError(t, err)
+	require.Equal(t, content, resolved)
+
+	got, _, err := parser.Parse(path, resolved)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	web := got[0]["services"].(map[string]interface{})["web"].(map[string]interface{})
+	require.Equal(t, true, web["privileged"])
+	require.Contains(t, parser.GetResolvedFiles(), filepath.Join(dir, "docker-compose.override.yml"))
+}
+
+func TestParser_Parse_GitHubActions(t *testing.T) {
+	path := filepath.Join("..", "..", "..", "test", "fixtures", "test_github_actions", ".github", "workflows", "pull_request.yml")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	parser := Parser{}
+	resolved, err := parser.Resolve(content, path, true, 15)
+	require.NoError(t, err)
+	require.Equal(t, content, resolved)
+
+	got, _, err := parser.Parse(path, resolved)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	jobs := got[0]["jobs"].(map[string]interface{})
+	steps := jobs["greet"].(map[string]interface{})["steps"].([]interface{})
+	require.Equal(t, `echo "Hello, ${{ github.event.pull_request.title }}"`, steps[1].(map[string]interface{})["run"])
+	require.Contains(t, jobs, "call-build/build")
+}
+
 func Test_GetCommentToken(t *testing.T) {
 	parser := &Parser{}
 	require.Equal(t, "#", parser.GetCommentToken())
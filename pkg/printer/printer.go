@@ -163,6 +163,12 @@ func PrintResult(summary *model.Summary, printer *Printer, usingCustomQueries bo
 	printSeverityCounter(model.SeverityInfo, summary.SeveritySummary.SeverityCounters[model.SeverityInfo], printer.Info)
 	fmt.Printf("TOTAL: %d\n\n", summary.SeveritySummary.TotalCounter)
 
+	if summary.Baseline != nil {
+		fmt.Printf("Baseline Summary (%s):\n", summary.Baseline.Path)
+		fmt.Printf("NEW: %d\nUNCHANGED: %d\nFIXED: %d\n\n",
+			summary.Baseline.NewCounter, summary.Baseline.UnchangedCounter, summary.Baseline.FixedCounter)
+	}
+
 	log.Info().Msgf("Scanned Files: %d", summary.ScannedFiles)
 	log.Info().Msgf("Parsed Files: %d", summary.ParsedFiles)
 	log.Info().Msgf("Scanned Lines: %d", summary.ScannedFilesLines)
@@ -181,8 +187,18 @@ func printSeverityCounter(severity string, counter int, printColor color.RGBColo
 
 func printFiles(query *model.QueryResult, printer *Printer) {
 	for fileIdx := range query.Files {
-		fmt.Printf("\t%s %s:%s\n", printer.PrintBySev(fmt.Sprintf("[%d]:", fileIdx+1), string(query.Severity)),
-			query.Files[fileIdx].FileName, printer.Success.Sprint(query.Files[fileIdx].Line))
+		baselineState := ""
+		if query.Files[fileIdx].BaselineState != "" {
+			baselineState = fmt.Sprintf(" (%s)", query.Files[fileIdx].BaselineState)
+		}
+		fmt.Printf("\t%s %s:%s%s\n", printer.PrintBySev(fmt.Sprintf("[%d]:", fileIdx+1), string(query.Severity)),
+			query.Files[fileIdx].FileName, printer.Success.Sprint(query.Files[fileIdx].Line), baselineState)
+		if moduleCall := query.Files[fileIdx].ModuleCall; moduleCall != nil {
+			fmt.Printf("\t    %s called at %s:%d\n", moduleCall.Path, moduleCall.FileName, moduleCall.Line)
+		}
+		if environment := query.Files[fileIdx].Environment; environment != "" {
+			fmt.Printf("\t    rendered with %s\n", environment)
+		}
 		if !printer.minimal {
 			fmt.Println()
 			for _, line := range *query.Files[fileIdx].VulnLines {
@@ -0,0 +1,136 @@
+// Package querytest holds the outcome of testing queries against the positive and negative samples kept in
+// their test directory
+package querytest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+)
+
+// Parameters represents all available test-queries parameters
+type Parameters struct {
+	QueriesPath   []string
+	LibrariesPath string
+	OutputPath    string
+	OutputName    string
+	ReportFormats []string
+	CoveragePath  string
+}
+
+// Result is a result of a query, as listed in the expected results file
+type Result struct {
+	QueryName string         `json:"queryName"`
+	Severity  model.Severity `json:"severity"`
+	Line      int            `json:"line"`
+	FileName  string         `json:"fileName,omitempty"`
+}
+
+// String returns the result as shown in the reports
+func (r Result) String() string {
+	fileName := r.FileName
+	if fileName == "" {
+		fileName = "any file"
+	}
+	return fmt.Sprintf("%s [%s] in %s line %d", r.QueryName, r.Severity, fileName, r.Line)
+}
+
+// CaseReport is the outcome of scanning the positive or the negative samples of a query
+type CaseReport struct {
+	Name       string   `json:"name"`
+	Files      []string `json:"files"`
+	Passed     bool     `json:"passed"`
+	Error      string   `json:"error,omitempty"`
+	Missing    []Result `json:"missing"`
+	Unexpected []Result `json:"unexpected"`
+}
+
+// QueryReport is the outcome of the tests of a query
+type QueryReport struct {
+	QueryName string       `json:"query_name"`
+	QueryID   string       `json:"query_id"`
+	Platform  string       `json:"platform"`
+	Dir       string       `json:"dir"`
+	Passed    bool         `json:"passed"`
+	Cases     []CaseReport `json:"cases"`
+}
+
+// Counters contains the totals of the tested queries
+type Counters struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+}
+
+// Report is the outcome of the tests of all queries found in the queries paths
+type Report struct {
+	QueriesPath []string      `json:"queries_path"`
+	Counters    Counters      `json:"counters"`
+	Queries     []QueryReport `json:"queries"`
+}
+
+// Add adds the outcome of the tests of a query to the report
+func (r *Report) Add(queryReport *QueryReport) {
+	r.Counters.Total++
+	if queryReport.Passed {
+		r.Counters.Passed++
+	} else {
+		r.Counters.Failed++
+	}
+	r.Queries = append(r.Queries, *queryReport)
+}
+
+// Compare matches the actual results with the expected ones by query name, severity, line and, when the expected
+// result has one, file name, returning the expected results not found and the actual results not expected
+func Compare(expected, actual []Result) (missing, unexpected []Result) {
+	matched := make([]bool, len(actual))
+	missing = make([]Result, 0)
+
+	// expected results with a file name are matched first, so the ones without it can not take their matches
+	for _, withFileName := range []bool{true, false} {
+		for _, e := range expected {
+			if (e.FileName != "") != withFileName {
+				continue
+			}
+			found := false
+			for i := range actual {
+				if !matched[i] && matches(&e, &actual[i]) {
+					matched[i], found = true, true
+					break
+				}
+			}
+			if !found {
+				missing = append(missing, e)
+			}
+		}
+	}
+
+	unexpected = make([]Result, 0)
+	for i := range actual {
+		if !matched[i] {
+			unexpected = append(unexpected, actual[i])
+		}
+	}
+
+	sortResults(missing)
+	sortResults(unexpected)
+	return missing, unexpected
+}
+
+func matches(expected, actual *Result) bool {
+	return expected.QueryName == actual.QueryName &&
+		strings.EqualFold(string(expected.Severity), string(actual.Severity)) &&
+		expected.Line == actual.Line &&
+		(expected.FileName == "" || expected.FileName == actual.FileName)
+}
+
+func sortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].FileName != results[j].FileName {
+			return results[i].FileName < results[j].FileName
+		}
+		return results[i].Line < results[j].Line
+	})
+}
@@ -0,0 +1,82 @@
+package querytest
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name           string
+		expected       []Result
+		actual         []Result
+		wantMissing    []Result
+		wantUnexpected []Result
+	}{
+		{
+			name: "should match results regardless of the severity case",
+			expected: []Result{
+				{QueryName: "q", Severity: "high", Line: 3, FileName: "positive1.tf"},
+			},
+			actual: []Result{
+				{QueryName: "q", Severity: model.SeverityHigh, Line: 3, FileName: "positive1.tf"},
+			},
+			wantMissing:    []Result{},
+			wantUnexpected: []Result{},
+		},
+		{
+			name: "should report missing and unexpected results",
+			expected: []Result{
+				{QueryName: "q", Severity: model.SeverityHigh, Line: 3, FileName: "positive1.tf"},
+			},
+			actual: []Result{
+				{QueryName: "q", Severity: model.SeverityHigh, Line: 4, FileName: "positive1.tf"},
+			},
+			wantMissing: []Result{
+				{QueryName: "q", Severity: model.SeverityHigh, Line: 3, FileName: "positive1.tf"},
+			},
+			wantUnexpected: []Result{
+				{QueryName: "q", Severity: model.SeverityHigh, Line: 4, FileName: "positive1.tf"},
+			},
+		},
+		{
+			name: "should match expected results with a file name first",
+			expected: []Result{
+				{QueryName: "q", Severity: model.SeverityLow, Line: 1},
+				{QueryName: "q", Severity: model.SeverityLow, Line: 1, FileName: "positive1.tf"},
+			},
+			actual: []Result{
+				{QueryName: "q", Severity: model.SeverityLow, Line: 1, FileName: "positive1.tf"},
+				{QueryName: "q", Severity: model.SeverityLow, Line: 1, FileName: "positive2.tf"},
+			},
+			wantMissing:    []Result{},
+			wantUnexpected: []Result{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, unexpected := Compare(tt.expected, tt.actual)
+			require.Equal(t, tt.wantMissing, missing)
+			require.Equal(t, tt.wantUnexpected, unexpected)
+		})
+	}
+}
+
+func TestReport_Add(t *testing.T) {
+	report := &Report{}
+	report.Add(&QueryReport{QueryName: "passing", Passed: true})
+	report.Add(&QueryReport{QueryName: "failing"})
+
+	require.Equal(t, Counters{Total: 2, Passed: 1, Failed: 1}, report.Counters)
+	require.Len(t, report.Queries, 2)
+}
+
+func TestResult_String(t *testing.T) {
+	require.Equal(t, "q [HIGH] in positive.tf line 3",
+		Result{QueryName: "q", Severity: model.SeverityHigh, Line: 3, FileName: "positive.tf"}.String())
+	require.Equal(t, "q [HIGH] in any file line 3",
+		Result{QueryName: "q", Severity: model.SeverityHigh, Line: 3}.String())
+}
@@ -0,0 +1,254 @@
+// Package runner runs the tests of queries with the engine, scanning the samples of each query with it
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/api"
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
+	"github.com/Checkmarx/kics/v2/pkg/engine/source"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/querytest"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// TestDirName is the directory of a query holding its samples
+	TestDirName = "test"
+	// ExpectedResultsFileName is the file listing the results expected from the positive samples
+	ExpectedResultsFileName = "positive_expected_result.json"
+
+	positivePrefix = "positive"
+	negativePrefix = "negative"
+)
+
+// FindQueries returns the directories holding a query, a query file along with its metadata, sorted by path
+func FindQueries(queriesPath []string) ([]string, error) {
+	dirs := make([]string, 0)
+	for _, queriesDir := range queriesPath {
+		err := filepath.WalkDir(queriesDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || d.Name() != source.QueryFileName {
+				return nil
+			}
+			dir := filepath.Dir(path)
+			if _, err := os.Stat(filepath.Join(dir, source.MetadataFileName)); err == nil {
+				dirs = append(dirs, dir)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// Run tests each query against its samples, the positive samples must produce exactly the expected results
+// and the negative samples no results at all. When withCoverage is set, it also returns the lines of the
+// queries exercised by their samples
+func Run(ctx context.Context, queryDirs []string, librariesPath string,
+	withCoverage bool) (*querytest.Report, *coverage.Report) {
+	report := &querytest.Report{
+		Queries: make([]querytest.QueryReport, 0, len(queryDirs)),
+	}
+	var coverageReport *coverage.Report
+	if withCoverage {
+		coverageReport = coverage.NewReport()
+	}
+	for _, dir := range queryDirs {
+		queryReport := testQuery(ctx, dir, librariesPath, coverageReport)
+		report.Add(&queryReport)
+	}
+	return report, coverageReport
+}
+
+func testQuery(ctx context.Context, dir, librariesPath string, coverageReport *coverage.Report) querytest.QueryReport {
+	log.Debug().Msgf("Testing query %s", dir)
+	queryReport := querytest.QueryReport{
+		Dir:   dir,
+		Cases: make([]querytest.CaseReport, 0, 2),
+	}
+
+	samples, err := getSamples(dir)
+	if err != nil {
+		return failQuery(&queryReport, err)
+	}
+	if len(samples.positives) == 0 || len(samples.negatives) == 0 {
+		return failQuery(&queryReport, fmt.Errorf("at least one positive and one negative sample are required in %s",
+			filepath.Join(dir, TestDirName)))
+	}
+
+	metadata, err := source.ReadMetadata(dir)
+	if err != nil {
+		return failQuery(&queryReport, err)
+	}
+	queryReport.QueryName, _ = metadata["queryName"].(string)
+	queryReport.QueryID, _ = metadata["id"].(string)
+	queryReport.Platform, _ = metadata["platform"].(string)
+
+	scanner, err := api.NewScanner(ctx, api.Options{
+		QueriesPath:         []string{dir},
+		LibrariesPath:       librariesPath,
+		ExperimentalQueries: true,
+		BillOfMaterials:     true,
+		DisableSecrets:      true,
+		CoverageReport:      coverageReport != nil,
+	})
+	if err != nil {
+		return failQuery(&queryReport, err)
+	}
+	if scanner.LoadedQueries() == 0 {
+		return failQuery(&queryReport, fmt.Errorf("query %s could not be loaded", dir))
+	}
+
+	expected, err := readExpectedResults(filepath.Join(dir, TestDirName, ExpectedResultsFileName))
+	if err != nil {
+		return failQuery(&queryReport, err)
+	}
+
+	queryReport.Cases = append(queryReport.Cases,
+		testCase(ctx, scanner, positivePrefix, samples.positives, samples.others, expected),
+		testCase(ctx, scanner, negativePrefix, samples.negatives, samples.others, []querytest.Result{}))
+	if coverageReport != nil {
+		coverageReport.Merge(scanner.CoverageReport())
+	}
+
+	queryReport.Passed = true
+	for i := range queryReport.Cases {
+		queryReport.Passed = queryReport.Passed && queryReport.Cases[i].Passed
+	}
+	return queryReport
+}
+
+// failQuery records an error preventing the samples of the query from being scanned
+func failQuery(queryReport *querytest.QueryReport, err error) querytest.QueryReport {
+	queryReport.Passed = false
+	queryReport.Cases = append(queryReport.Cases, querytest.CaseReport{
+		Name:       "setup",
+		Files:      []string{},
+		Error:      err.Error(),
+		Missing:    []querytest.Result{},
+		Unexpected: []querytest.Result{},
+	})
+	return *queryReport
+}
+
+// testCase scans the samples along with the other files of the test directory, such as Terraform variables files,
+// and compares the results found in the samples with the expected ones
+func testCase(ctx context.Context, scanner *api.Scanner, name string, files, others []string,
+	expected []querytest.Result) querytest.CaseReport {
+	caseReport := querytest.CaseReport{
+		Name:       name,
+		Files:      files,
+		Missing:    []querytest.Result{},
+		Unexpected: []querytest.Result{},
+	}
+
+	sampleNames := make(map[string]bool, len(files))
+	inMemoryFiles := make([]api.InMemoryFile, 0, len(files)+len(others))
+	for _, file := range append(append([]string{}, files...), others...) {
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			caseReport.Error = err.Error()
+			return caseReport
+		}
+		inMemoryFiles = append(inMemoryFiles, api.InMemoryFile{Path: file, Content: content})
+	}
+	for _, file := range files {
+		sampleNames[filepath.Base(file)] = true
+	}
+
+	summary, err := scanner.Scan(ctx, inMemoryFiles)
+	if err != nil {
+		caseReport.Error = err.Error()
+		return caseReport
+	}
+
+	caseReport.Missing, caseReport.Unexpected = querytest.Compare(expected, getResults(&summary, sampleNames))
+	caseReport.Passed = len(caseReport.Missing) == 0 && len(caseReport.Unexpected) == 0
+	return caseReport
+}
+
+// samples are the files of the test directory of a query
+type samples struct {
+	positives []string
+	negatives []string
+	others    []string
+}
+
+// getSamples returns the files in the test directory of the query, the samples are the files whose name starts
+// with positive or negative, except the expected results file
+func getSamples(dir string) (*samples, error) {
+	testDir := filepath.Join(dir, TestDirName)
+	entries, err := os.ReadDir(testDir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &samples{
+		positives: make([]string, 0),
+		negatives: make([]string, 0),
+		others:    make([]string, 0),
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(testDir, name)
+		switch {
+		case entry.IsDir() || name == ExpectedResultsFileName:
+			continue
+		case strings.HasPrefix(name, positivePrefix):
+			s.positives = append(s.positives, path)
+		case strings.HasPrefix(name, negativePrefix):
+			s.negatives = append(s.negatives, path)
+		default:
+			s.others = append(s.others, path)
+		}
+	}
+	return s, nil
+}
+
+func readExpectedResults(path string) ([]querytest.Result, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var expected []querytest.Result
+	if err := json.Unmarshal(content, &expected); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return expected, nil
+}
+
+// getResults returns the results found in the given files, identified by their base name
+func getResults(summary *model.Summary, fileNames map[string]bool) []querytest.Result {
+	results := make([]querytest.Result, 0)
+	// bill of materials results are kept apart from the other results in the summary
+	queries := append(append([]model.QueryResult{}, summary.Queries...), summary.Bom...)
+	for i := range queries {
+		query := &queries[i]
+		for j := range query.Files {
+			if !fileNames[filepath.Base(query.Files[j].FileName)] {
+				continue
+			}
+			results = append(results, querytest.Result{
+				QueryName: query.QueryName,
+				Severity:  query.Severity,
+				Line:      query.Files[j].Line,
+				FileName:  filepath.Base(query.Files[j].FileName),
+			})
+		}
+	}
+	return results
+}
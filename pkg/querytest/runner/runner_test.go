@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const queryDir = "../../../assets/queries/dockerfile/apt_get_install_pin_version_not_defined"
+
+func TestFindQueries(t *testing.T) {
+	dirs, err := FindQueries([]string{filepath.Dir(queryDir)})
+	require.NoError(t, err)
+	require.Contains(t, dirs, filepath.Clean(queryDir))
+
+	dirs, err = FindQueries([]string{filepath.Join(queryDir, TestDirName)})
+	require.NoError(t, err)
+	require.Empty(t, dirs)
+}
+
+func TestRun(t *testing.T) {
+	report, coverageReport := Run(context.Background(), []string{queryDir}, "", true)
+	require.Equal(t, 1, report.Counters.Passed, "%+v", report.Queries)
+	require.Len(t, coverageReport.Queries, 1)
+	require.Positive(t, coverageReport.Queries[0].CoveredLines)
+	require.Equal(t, "Apt Get Install Pin Version Not Defined", report.Queries[0].QueryName)
+	require.Len(t, report.Queries[0].Cases, 2)
+
+	dir := copyQuery(t, queryDir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TestDirName, ExpectedResultsFileName), []byte("[]"), 0o600))
+	report, coverageReport = Run(context.Background(), []string{dir}, "", false)
+	require.Nil(t, coverageReport)
+	require.Equal(t, 1, report.Counters.Failed)
+	require.False(t, report.Queries[0].Cases[0].Passed)
+	require.Empty(t, report.Queries[0].Cases[0].Missing)
+	require.NotEmpty(t, report.Queries[0].Cases[0].Unexpected)
+	require.True(t, report.Queries[0].Cases[1].Passed)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, TestDirName)))
+	report, _ = Run(context.Background(), []string{dir}, "", false)
+	require.Equal(t, 1, report.Counters.Failed)
+	require.Equal(t, "setup", report.Queries[0].Cases[0].Name)
+	require.NotEmpty(t, report.Queries[0].Cases[0].Error)
+}
+
+func copyQuery(t *testing.T, src string) string {
+	dst := t.TempDir()
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o700)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), content, 0o600)
+	})
+	require.NoError(t, err)
+	return dst
+}
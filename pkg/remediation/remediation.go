@@ -92,6 +92,27 @@ func (s *Summary) RemediateFile(filePath string, remediationSet Set, openAPIReso
 	return nil
 }
 
+// RemediateLines returns a copy of the lines with the remediation of the given type, replacement or addition,
+// applied, without verifying it removes the result. The returned slice is empty when the remediation can not be
+// applied or is already done
+func RemediateLines(r *Remediation, remediationType string, lines []string) []string {
+	if r.Line < 1 || r.Line > len(lines) {
+		return []string{}
+	}
+
+	remediated := make([]string, len(lines))
+	copy(remediated, lines)
+
+	switch remediationType {
+	case "replacement":
+		return replacement(r, remediated)
+	case "addition":
+		return addition(r, &remediated)
+	default:
+		return []string{}
+	}
+}
+
 // ReplacementInfo presents the relevant information to do the replacement
 type ReplacementInfo struct {
 	Before string `json:"before"`
@@ -119,3 +119,59 @@ func Test_RemediateFile(t *testing.T) {
 		})
 	}
 }
+
+func Test_RemediateLines(t *testing.T) {
+	lines := []string{
+		`resource "aws_iam_account_password_policy" "strict" {`,
+		`  require_symbols = false`,
+		`}`,
+	}
+
+	tests := []struct {
+		name            string
+		remediation     Remediation
+		remediationType string
+		want            []string
+	}{
+		{
+			name:            "should replace the value in the line",
+			remediation:     Remediation{Line: 2, Remediation: `{"after":"true","before":"false"}`},
+			remediationType: "replacement",
+			want:            []string{lines[0], `  require_symbols = true`, lines[2]},
+		},
+		{
+			name:            "should add the line after the parent line with its indentation",
+			remediation:     Remediation{Line: 1, Remediation: "minimum_password_length = 14"},
+			remediationType: "addition",
+			want:            []string{lines[0], `  minimum_password_length = 14`, lines[1], lines[2]},
+		},
+		{
+			name:            "should ignore replacements already done",
+			remediation:     Remediation{Line: 2, Remediation: `{"after":"false","before":"false"}`},
+			remediationType: "replacement",
+			want:            []string{},
+		},
+		{
+			name:            "should ignore lines out of the file",
+			remediation:     Remediation{Line: 4, Remediation: `{"after":"true","before":"false"}`},
+			remediationType: "replacement",
+			want:            []string{},
+		},
+		{
+			name:            "should ignore unknown remediation types",
+			remediation:     Remediation{Line: 2, Remediation: `{"after":"true","before":"false"}`},
+			remediationType: "removal",
+			want:            []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make([]string, len(lines))
+			copy(original, lines)
+
+			require.Equal(t, tt.want, RemediateLines(&tt.remediation, tt.remediationType, lines))
+			require.Equal(t, original, lines)
+		})
+	}
+}
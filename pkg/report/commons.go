@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -99,7 +100,11 @@ func ExportJSONReport(path, filename string, body interface{}) error {
 
 	defer closeFile(fullPath, filename, f)
 
-	encoder := json.NewEncoder(f)
+	return writeJSON(f, body)
+}
+
+func writeJSON(w io.Writer, body interface{}) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "\t")
 
 	return encoder.Encode(body)
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"bytes"
+	_ "embed" // used for embedding coverage report templates
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
+)
+
+var (
+	//go:embed template/html/coverage.tmpl
+	htmlCoverageTemplate string
+)
+
+// PrintCoverageJSONReport creates a report file on JSON format with the lines of the queries exercised by a scan
+func PrintCoverageJSONReport(path, filename string, body *coverage.Report) error {
+	if !strings.HasSuffix(filename, jsonExtension) {
+		filename += jsonExtension
+	}
+	return ExportJSONReport(path, filename, body)
+}
+
+// PrintCoverageHTMLReport creates a report file on HTML format with the source of the queries exercised by a scan,
+// highlighting their covered and not covered lines
+func PrintCoverageHTMLReport(path, filename string, body *coverage.Report) error {
+	if !strings.HasSuffix(filename, ".html") {
+		filename += ".html"
+	}
+
+	templateFuncs["includeSVG"] = includeSVG
+	templateFuncs["includeCSS"] = includeCSS
+	templateFuncs["getVersion"] = getVersion
+
+	t := template.Must(template.New("coverage.tmpl").Funcs(templateFuncs).Parse(htmlCoverageTemplate))
+
+	fullPath := filepath.Join(path, filename)
+	f, err := os.OpenFile(filepath.Clean(fullPath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer closeFile(fullPath, filename, f)
+
+	var buffer bytes.Buffer
+	if err = t.Execute(&buffer, body); err != nil {
+		return err
+	}
+	return writeMinifiedHTML(f, buffer.Bytes())
+}
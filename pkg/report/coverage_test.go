@@ -0,0 +1,42 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrintCoverageReports tests the functions [PrintCoverageJSONReport()] and [PrintCoverageHTMLReport()]
+func TestPrintCoverageReports(t *testing.T) {
+	path := t.TempDir()
+	report := coverage.NewReport()
+	report.Add(&coverage.Query{
+		QueryName:       "Query",
+		QueryID:         "a-b-c",
+		Platform:        "Terraform",
+		Query:           "query",
+		Covered:         []coverage.Range{{Start: 3, End: 4}},
+		NotCovered:      []coverage.Range{{Start: 7, End: 8}},
+		NotCoveredRules: []coverage.Rule{{Name: "CxPolicy", Line: 7}},
+		Lines: []coverage.Line{
+			{Number: 3, Content: "CxPolicy[result] {", Status: coverage.LineCovered},
+			{Number: 7, Content: "CxPolicy[result] {", Status: coverage.LineNotCovered},
+		},
+	})
+
+	require.NoError(t, PrintCoverageJSONReport(path, "coverage", report))
+	content, err := os.ReadFile(filepath.Join(path, "coverage.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"not_covered_rules"`)
+	require.NotContains(t, string(content), `"Lines"`)
+
+	require.NoError(t, PrintCoverageHTMLReport(path, "coverage", report))
+	content, err = os.ReadFile(filepath.Join(path, "coverage.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "50.00%")
+	require.Contains(t, string(content), "CxPolicy (line 7)")
+	require.Contains(t, string(content), `<span class="not-covered">   7  CxPolicy[result] {</span>`)
+}
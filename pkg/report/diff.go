@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bytes"
+	_ "embed" // used for embedding diff report templates
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	textTemplate "text/template"
+
+	"github.com/Checkmarx/kics/v2/pkg/diff"
+)
+
+var (
+	//go:embed template/html/diff.tmpl
+	htmlDiffTemplate string
+	//go:embed template/markdown/diff.tmpl
+	markdownDiffTemplate string
+)
+
+// PrintDiffJSONReport creates a report file on JSON format with the delta between two scans
+func PrintDiffJSONReport(path, filename string, body *diff.Report) error {
+	if !strings.HasSuffix(filename, jsonExtension) {
+		filename += jsonExtension
+	}
+	return ExportJSONReport(path, filename, body)
+}
+
+// PrintDiffMarkdownReport creates a report file on Markdown format with the delta between two scans
+func PrintDiffMarkdownReport(path, filename string, body *diff.Report) error {
+	if !strings.HasSuffix(filename, ".md") {
+		filename += ".md"
+	}
+
+	t := textTemplate.Must(textTemplate.New("diff.tmpl").Funcs(textTemplate.FuncMap{
+		"escape": escapeMarkdown,
+	}).Parse(markdownDiffTemplate))
+
+	fullPath := filepath.Join(path, filename)
+	f, err := os.OpenFile(filepath.Clean(fullPath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer closeFile(fullPath, filename, f)
+
+	return t.Execute(f, body)
+}
+
+// PrintDiffHTMLReport creates a report file on HTML format with the delta between two scans
+func PrintDiffHTMLReport(path, filename string, body *diff.Report) error {
+	if !strings.HasSuffix(filename, ".html") {
+		filename += ".html"
+	}
+
+	templateFuncs["includeSVG"] = includeSVG
+	templateFuncs["includeCSS"] = includeCSS
+	templateFuncs["getVersion"] = getVersion
+
+	t := template.Must(template.New("diff.tmpl").Funcs(templateFuncs).Parse(htmlDiffTemplate))
+
+	fullPath := filepath.Join(path, filename)
+	f, err := os.OpenFile(filepath.Clean(fullPath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer closeFile(fullPath, filename, f)
+
+	var buffer bytes.Buffer
+	if err = t.Execute(&buffer, body); err != nil {
+		return err
+	}
+	return writeMinifiedHTML(f, buffer.Bytes())
+}
+
+func escapeMarkdown(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
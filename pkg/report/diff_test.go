@@ -0,0 +1,36 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/diff"
+	"github.com/Checkmarx/kics/v2/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrintDiffReports tests the functions [PrintDiffJSONReport()], [PrintDiffMarkdownReport()]
+// and [PrintDiffHTMLReport()]
+func TestPrintDiffReports(t *testing.T) {
+	path := t.TempDir()
+	report := diff.Compare(&test.SummaryMock, &test.ComplexSummaryMock, "old.json", "new.json")
+
+	require.NoError(t, PrintDiffJSONReport(path, "diff", report))
+	require.FileExists(t, filepath.Join(path, "diff.json"))
+
+	require.NoError(t, PrintDiffMarkdownReport(path, "diff", report))
+	content, err := os.ReadFile(filepath.Join(path, "diff.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "## Added Findings")
+	require.Contains(t, string(content), "| old.json |")
+
+	require.NoError(t, PrintDiffHTMLReport(path, "diff", report))
+	content, err = os.ReadFile(filepath.Join(path, "diff.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "Added findings")
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	require.Equal(t, "a\\|b", escapeMarkdown("a|b"))
+}
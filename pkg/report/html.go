@@ -4,6 +4,7 @@ import (
 	"bytes"
 	_ "embed" // used for embedding report static files
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -103,6 +104,10 @@ func PrintHTMLReport(path, filename string, body interface{}) error {
 	if err != nil {
 		return err
 	}
+	return writeMinifiedHTML(f, buffer.Bytes())
+}
+
+func writeMinifiedHTML(w io.Writer, content []byte) error {
 	minifier := minify.New()
 	minifier.AddFunc(textHTML, minifyHtml.Minify)
 	minifier.Add(textHTML, &minifyHtml.Minifier{
@@ -111,13 +116,13 @@ func PrintHTMLReport(path, filename string, body interface{}) error {
 		KeepQuotes:       true,
 	})
 
-	minifierWriter := minifier.Writer(textHTML, f)
+	minifierWriter := minifier.Writer(textHTML, w)
 	defer func() {
 		if closeErr := minifierWriter.Close(); closeErr != nil {
 			log.Err(closeErr).Msg("Error closing file")
 		}
 	}()
 
-	_, err = minifierWriter.Write(buffer.Bytes())
+	_, err := minifierWriter.Write(content)
 	return err
 }
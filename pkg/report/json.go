@@ -1,26 +1,49 @@
 package report
 
-import "github.com/Checkmarx/kics/v2/internal/constants"
+import (
+	"io"
+
+	"github.com/Checkmarx/kics/v2/internal/constants"
+)
 
 const jsonExtension = ".json"
 
 // PrintJSONReport prints on JSON file the summary results
 func PrintJSONReport(path, filename string, body interface{}) error {
-	if body != "" {
-		summary, err := getSummary(body)
-		if err != nil {
-			return err
-		}
-		for idx := range summary.Queries {
-			summary.Queries[idx].CISBenchmarkName = ""
-			summary.Queries[idx].CISBenchmarkVersion = ""
-			summary.Queries[idx].CISDescriptionID = ""
-			summary.Queries[idx].CISDescriptionText = ""
-			summary.Queries[idx].CISRationaleText = ""
-		}
-		summary.Version = constants.Version
-		body = summary
+	report, err := buildJSONReport(body)
+	if err != nil {
+		return err
+	}
+
+	return ExportJSONReport(path, filename, report)
+}
+
+// WriteJSONReport writes the JSON report of the summary results to the writer
+func WriteJSONReport(w io.Writer, body interface{}) error {
+	report, err := buildJSONReport(body)
+	if err != nil {
+		return err
 	}
 
-	return ExportJSONReport(path, filename, body)
+	return writeJSON(w, report)
+}
+
+func buildJSONReport(body interface{}) (interface{}, error) {
+	if body == "" {
+		return body, nil
+	}
+
+	summary, err := getSummary(body)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range summary.Queries {
+		summary.Queries[idx].CISBenchmarkName = ""
+		summary.Queries[idx].CISBenchmarkVersion = ""
+		summary.Queries[idx].CISDescriptionID = ""
+		summary.Queries[idx].CISDescriptionText = ""
+		summary.Queries[idx].CISRationaleText = ""
+	}
+	summary.Version = constants.Version
+	return summary, nil
 }
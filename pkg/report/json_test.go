@@ -1,6 +1,7 @@
 package report
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -77,3 +78,17 @@ func TestPrintJSONReport(t *testing.T) {
 		})
 	}
 }
+
+// TestWriteJSONReport tests the function [WriteJSONReport()]
+func TestWriteJSONReport(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJSONReport(&buf, test.SummaryMockCWE)
+	require.NoError(t, err)
+
+	var resultSummary model.Summary
+	err = json.Unmarshal(buf.Bytes(), &resultSummary)
+	require.NoError(t, err)
+	require.Equal(t, "development", resultSummary.Version)
+	require.Len(t, resultSummary.Queries, len(test.SummaryMockCWE.Queries))
+	require.Empty(t, resultSummary.Queries[0].CISDescriptionID)
+}
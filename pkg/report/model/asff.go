@@ -34,6 +34,7 @@ type AwsSecurityFinding struct {
 	Types         []string
 	UpdatedAt     string
 	CWE           string
+	ProductFields map[string]string `json:",omitempty"`
 }
 
 // AsffRecommendation includes the recommendation to avoid the finding
@@ -147,6 +148,12 @@ func (a *AwsAccountInfo) getFinding(query *model.QueryResult, file *model.Vulner
 		CWE:        *aws.String(query.CWE),
 	}
 
+	if file.BaselineState != "" {
+		finding.ProductFields = map[string]string{
+			"kics/baselineState": file.BaselineState,
+		}
+	}
+
 	return finding
 }
 
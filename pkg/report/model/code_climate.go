@@ -13,14 +13,15 @@ type location struct {
 
 // CodeClimateReport struct contains all the info to create the code climate report
 type CodeClimateReport struct {
-	Type        string   `json:"type"`
-	CheckName   string   `json:"check_name"`
-	CWE         string   `json:"cwe,omitempty"`
-	Description string   `json:"description"`
-	Categories  []string `json:"categories"`
-	Location    location `json:"location"`
-	Severity    string   `json:"severity"`
-	Fingerprint string   `json:"fingerprint"`
+	Type          string   `json:"type"`
+	CheckName     string   `json:"check_name"`
+	CWE           string   `json:"cwe,omitempty"`
+	Description   string   `json:"description"`
+	Categories    []string `json:"categories"`
+	Location      location `json:"location"`
+	Severity      string   `json:"severity"`
+	Fingerprint   string   `json:"fingerprint"`
+	BaselineState string   `json:"baseline_state,omitempty"`
 }
 
 var severityMap = map[string]string{
@@ -48,8 +49,9 @@ func BuildCodeClimateReport(summary *model.Summary) []CodeClimateReport {
 					Path:  summary.Queries[i].Files[j].FileName,
 					Lines: lines{Begin: summary.Queries[i].Files[j].Line},
 				},
-				Severity:    severityMap[string(summary.Queries[i].Severity)],
-				Fingerprint: summary.Queries[i].Files[j].SimilarityID,
+				Severity:      severityMap[string(summary.Queries[i].Severity)],
+				Fingerprint:   summary.Queries[i].Files[j].SimilarityID,
+				BaselineState: summary.Queries[i].Files[j].BaselineState,
 			})
 		}
 	}
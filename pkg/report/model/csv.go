@@ -26,6 +26,7 @@ type CSVReport struct {
 	SearchValue                 string `csv:"search_value"`
 	ExpectedValue               string `csv:"expected_value"`
 	ActualValue                 string `csv:"actual_value"`
+	BaselineState               string `csv:"baseline_state"`
 }
 
 // BuildCSVReport builds the CSV report
@@ -57,6 +58,7 @@ func BuildCSVReport(summary *model.Summary) []CSVReport {
 				SearchValue:                 summary.Queries[i].Files[j].SearchValue,
 				ExpectedValue:               summary.Queries[i].Files[j].KeyExpectedValue,
 				ActualValue:                 summary.Queries[i].Files[j].KeyActualValue,
+				BaselineState:               summary.Queries[i].Files[j].BaselineState,
 			})
 		}
 	}
@@ -89,6 +89,7 @@ type Vulnerability struct {
 	Ratings         []Rating         `xml:"v:ratings>v:rating"`
 	Description     string           `xml:"v:description"`
 	Recommendations []Recommendation `xml:"v:recommendations>v:recommendation"`
+	BaselineState   string           `xml:"v:baselineState,omitempty"`
 }
 
 // Source includes information about the origin where the vulnerability was reported
@@ -193,6 +194,7 @@ func getVulnerabilitiesByFile(query *model.QueryResult, fileName, purl string) [
 						),
 					},
 				},
+				BaselineState: file.BaselineState,
 			}
 			vulns = append(vulns, vuln)
 		}
@@ -152,6 +152,12 @@ func (glsr *gitlabSASTReport) BuildGitlabSASTVulnerability(issue *model.QueryRes
 				"cisId":    issue.CISDescriptionIDFormatted,
 			}
 		}
+		if file.BaselineState != "" {
+			if vulnerability.Details == nil {
+				vulnerability.Details = gitlabSASTVulnerabilityDetails{}
+			}
+			vulnerability.Details["baselineState"] = file.BaselineState
+		}
 		glsr.Vulnerabilities = append(glsr.Vulnerabilities, vulnerability)
 	}
 }
@@ -27,11 +27,12 @@ type junitTestSuite struct {
 }
 
 type junitTestCase struct {
-	XMLName   xml.Name       `xml:"testcase"`
-	CWE       string         `xml:"cwe,attr,omitempty"`
-	Name      string         `xml:"name,attr"`
-	ClassName string         `xml:"classname,attr"`
-	Failures  []junitFailure `xml:"failure"`
+	XMLName       xml.Name       `xml:"testcase"`
+	CWE           string         `xml:"cwe,attr,omitempty"`
+	Name          string         `xml:"name,attr"`
+	ClassName     string         `xml:"classname,attr"`
+	BaselineState string         `xml:"baseline_state,attr,omitempty"`
+	Failures      []junitFailure `xml:"failure"`
 }
 
 type junitFailure struct {
@@ -67,10 +68,11 @@ func (jUnit *junitTestSuites) GenerateTestEntry(query *model.QueryResult) {
 
 	for idx := range query.Files {
 		failedTestCase := junitTestCase{
-			Name:      fmt.Sprintf("%s: %s file in line %d", query.QueryName, query.Files[idx].FileName, query.Files[idx].Line),
-			ClassName: query.Platform,
-			CWE:       query.CWE,
-			Failures:  []junitFailure{},
+			Name:          fmt.Sprintf("%s: %s file in line %d", query.QueryName, query.Files[idx].FileName, query.Files[idx].Line),
+			ClassName:     query.Platform,
+			CWE:           query.CWE,
+			BaselineState: query.Files[idx].BaselineState,
+			Failures:      []junitFailure{},
 		}
 
 		failedTest := junitFailure{
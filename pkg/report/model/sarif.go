@@ -22,6 +22,13 @@ var severityLevelEquivalence = map[model.Severity]string{
 	"CRITICAL": "error",
 }
 
+// baselineStateEquivalence maps the KICS baseline state to the SARIF result baselineState
+var baselineStateEquivalence = map[string]string{
+	model.BaselineStateNew:       "new",
+	model.BaselineStateUnchanged: "unchanged",
+	model.BaselineStateFixed:     "absent",
+}
+
 var targetTemplate = sarifDescriptorReference{
 	ToolComponent: sarifComponentReference{
 		ComponentReferenceGUID:  "58cdcc6f-fe41-4724-bfb3-131a93df4c3f",
@@ -145,11 +152,12 @@ type sarifLocation struct {
 }
 
 type sarifResult struct {
-	ResultRuleID    string          `json:"ruleId"`
-	ResultRuleIndex int             `json:"ruleIndex"`
-	ResultKind      string          `json:"kind"`
-	ResultMessage   sarifMessage    `json:"message"`
-	ResultLocations []sarifLocation `json:"locations"`
+	ResultRuleID        string          `json:"ruleId"`
+	ResultRuleIndex     int             `json:"ruleIndex"`
+	ResultKind          string          `json:"kind"`
+	ResultMessage       sarifMessage    `json:"message"`
+	ResultLocations     []sarifLocation `json:"locations"`
+	ResultBaselineState string          `json:"baselineState,omitempty"`
 }
 
 type taxonomyDefinitions struct {
@@ -647,6 +655,7 @@ func (sr *sarifReport) BuildSarifIssue(issue *model.QueryResult) string {
 						},
 					},
 				},
+				ResultBaselineState: baselineStateEquivalence[issue.Files[idx].BaselineState],
 			}
 			sr.Runs[0].Results = append(sr.Runs[0].Results, result)
 		}
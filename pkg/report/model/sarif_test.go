@@ -345,6 +345,28 @@ func TestBuildSarifIssue(t *testing.T) {
 	}
 }
 
+func TestBuildSarifIssue_BaselineState(t *testing.T) {
+	query := model.QueryResult{
+		QueryName: "test",
+		QueryID:   "1",
+		Severity:  model.SeverityHigh,
+		Files: []model.VulnerableFile{
+			{KeyActualValue: "new", BaselineState: model.BaselineStateNew},
+			{KeyActualValue: "unchanged", BaselineState: model.BaselineStateUnchanged},
+			{KeyActualValue: "fixed", BaselineState: model.BaselineStateFixed},
+			{KeyActualValue: "no baseline"},
+		},
+	}
+	result := NewSarifReport().(*sarifReport)
+	result.BuildSarifIssue(&query)
+
+	require.Len(t, result.Runs[0].Results, 4)
+	require.Equal(t, "new", result.Runs[0].Results[0].ResultBaselineState)
+	require.Equal(t, "unchanged", result.Runs[0].Results[1].ResultBaselineState)
+	require.Equal(t, "absent", result.Runs[0].Results[2].ResultBaselineState)
+	require.Empty(t, result.Runs[0].Results[3].ResultBaselineState)
+}
+
 func TestInitCweCategories(t *testing.T) {
 	cweIDs := []string{"22", "41", "203", "1188"}
 	guids := map[string]string{"22": "1489b0c4-d7ce-4d31-af66-6382a01202e3", "41": "1489b0c4-d7ce-4d31-af66-6382a01202e4", "203": "1489b0c4-d7ce-4d31-af66-6382a01202e5", "1188": "1489b0c4-d7ce-4d31-af66-6382a01202e6"}
@@ -56,9 +56,10 @@ type Issue struct {
 
 // Location is the location for the vulnerability in the SonarQube Report
 type Location struct {
-	Message   string `json:"message"`
-	FilePath  string `json:"filePath"`
-	TextRange *Range `json:"textRange"`
+	Message       string `json:"message"`
+	FilePath      string `json:"filePath"`
+	TextRange     *Range `json:"textRange"`
+	BaselineState string `json:"baselineState,omitempty"`
 }
 
 // Range is the range for the vulnerability in the SonarQube Report
@@ -119,5 +120,6 @@ func buildLocation(index int, query *model.QueryResult) *Location {
 		TextRange: &Range{
 			StartLine: query.Files[index].Line,
 		},
+		BaselineState: query.Files[index].BaselineState,
 	}
 }
@@ -218,6 +218,9 @@ func createResultsTable(m pdf.Maroto, query *model.QueryResult) {
 
 		filePath := query.Files[idx].FileName
 		fileLine := fmt.Sprintf("%s:%s", filePath, fmt.Sprint(query.Files[idx].Line))
+		if query.Files[idx].BaselineState != "" {
+			fileLine = fmt.Sprintf("%s (%s)", fileLine, query.Files[idx].BaselineState)
+		}
 		m.Row(colFive, func() {
 			m.Col(colFullPage, func() {
 				m.Text(fileLine, props.Text{
@@ -430,10 +433,35 @@ func createSummaryArea(m pdf.Maroto, summary *model.Summary) {
 	})
 }
 
+func createBaselineArea(m pdf.Maroto, summary *model.Summary) {
+	if summary.Baseline == nil {
+		return
+	}
+	baseline := fmt.Sprintf("%s (new: %d, unchanged: %d, fixed: %d)", summary.Baseline.Path,
+		summary.Baseline.NewCounter, summary.Baseline.UnchangedCounter, summary.Baseline.FixedCounter)
+	m.Row(rowSmall, func() {
+		m.Col(colTwo, func() {
+			m.Text("BASELINE", props.Text{
+				Size:        defaultTextSize,
+				Align:       consts.Left,
+				Extrapolate: false,
+			})
+		})
+		m.Col(colTen, func() {
+			m.Text(baseline, props.Text{
+				Size:        defaultTextSize,
+				Align:       consts.Left,
+				Extrapolate: false,
+			})
+		})
+	})
+}
+
 func createFirstPageHeader(m pdf.Maroto, summary *model.Summary) {
 	createSummaryArea(m, summary)
 	createPlatformsArea(m, summary)
 	createDateArea(m, summary)
+	createBaselineArea(m, summary)
 	m.Row(rowSmall, func() {
 		m.Col(colTwo, func() {
 			m.Text("SCANNED PATHS:", props.Text{
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/internal/constants"
+	"github.com/Checkmarx/kics/v2/pkg/querytest"
+)
+
+type queryTestsJUnitSuites struct {
+	XMLName    xml.Name               `xml:"testsuites"`
+	Name       string                 `xml:"name,attr"`
+	Tests      int                    `xml:"tests,attr"`
+	Failures   int                    `xml:"failures,attr"`
+	TestSuites []queryTestsJUnitSuite `xml:"testsuite"`
+}
+
+type queryTestsJUnitSuite struct {
+	XMLName   xml.Name                  `xml:"testsuite"`
+	Name      string                    `xml:"name,attr"`
+	ID        string                    `xml:"id,attr,omitempty"`
+	Tests     int                       `xml:"tests,attr"`
+	Failures  int                       `xml:"failures,attr"`
+	TestCases []queryTestsJUnitTestCase `xml:"testcase"`
+}
+
+type queryTestsJUnitTestCase struct {
+	XMLName   xml.Name                `xml:"testcase"`
+	Name      string                  `xml:"name,attr"`
+	ClassName string                  `xml:"classname,attr"`
+	Failure   *queryTestsJUnitFailure `xml:"failure,omitempty"`
+}
+
+type queryTestsJUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// PrintQueryTestsJSONReport creates a report file on JSON format with the outcome of the query tests
+func PrintQueryTestsJSONReport(path, filename string, body *querytest.Report) error {
+	if !strings.HasSuffix(filename, jsonExtension) {
+		filename += jsonExtension
+	}
+	return ExportJSONReport(path, filename, body)
+}
+
+// PrintQueryTestsJUnitReport creates a report file on JUnit format with a test suite for each query and a test
+// case for its positive and negative samples
+func PrintQueryTestsJUnitReport(path, filename string, body *querytest.Report) error {
+	if !strings.HasPrefix(filename, "junit-") {
+		filename = "junit-" + filename
+	}
+
+	report := queryTestsJUnitSuites{
+		Name:       fmt.Sprintf("KICS %s query tests", constants.Version),
+		TestSuites: make([]queryTestsJUnitSuite, 0, len(body.Queries)),
+	}
+	for i := range body.Queries {
+		query := &body.Queries[i]
+		suite := queryTestsJUnitSuite{
+			Name:      query.Dir,
+			ID:        query.QueryID,
+			TestCases: make([]queryTestsJUnitTestCase, 0, len(query.Cases)),
+		}
+		for j := range query.Cases {
+			testCase := queryTestsJUnitTestCase{
+				Name:      query.Cases[j].Name,
+				ClassName: query.Platform,
+			}
+			if !query.Cases[j].Passed {
+				testCase.Failure = getQueryTestFailure(&query.Cases[j])
+				suite.Failures++
+			}
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.TestSuites = append(report.TestSuites, suite)
+	}
+
+	return exportXMLReport(path, filename, report)
+}
+
+func getQueryTestFailure(testCase *querytest.CaseReport) *queryTestsJUnitFailure {
+	if testCase.Error != "" {
+		return &queryTestsJUnitFailure{Message: testCase.Error}
+	}
+
+	lines := make([]string, 0, len(testCase.Missing)+len(testCase.Unexpected))
+	for _, result := range testCase.Missing {
+		lines = append(lines, "missing: "+result.String())
+	}
+	for _, result := range testCase.Unexpected {
+		lines = append(lines, "unexpected: "+result.String())
+	}
+	return &queryTestsJUnitFailure{
+		Message: fmt.Sprintf("%d missing and %d unexpected results", len(testCase.Missing), len(testCase.Unexpected)),
+		Text:    strings.Join(lines, "\n"),
+	}
+}
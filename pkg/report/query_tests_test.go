@@ -0,0 +1,40 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/querytest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrintQueryTestsReports tests the functions [PrintQueryTestsJSONReport()] and [PrintQueryTestsJUnitReport()]
+func TestPrintQueryTestsReports(t *testing.T) {
+	path := t.TempDir()
+	report := &querytest.Report{}
+	report.Add(&querytest.QueryReport{
+		QueryName: "Query",
+		QueryID:   "a-b-c",
+		Platform:  "Terraform",
+		Dir:       "queries/query",
+		Cases: []querytest.CaseReport{
+			{
+				Name:    "positive",
+				Missing: []querytest.Result{{QueryName: "Query", Severity: model.SeverityHigh, Line: 3}},
+			},
+			{Name: "negative", Passed: true},
+		},
+	})
+
+	require.NoError(t, PrintQueryTestsJSONReport(path, "query-tests", report))
+	require.FileExists(t, filepath.Join(path, "query-tests.json"))
+
+	require.NoError(t, PrintQueryTestsJUnitReport(path, "query-tests", report))
+	content, err := os.ReadFile(filepath.Join(path, "junit-query-tests.xml"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `<testsuite name="queries/query" id="a-b-c" tests="2" failures="1">`)
+	require.Contains(t, string(content), `message="1 missing and 0 unexpected results"`)
+	require.Contains(t, string(content), "missing: Query [HIGH] in any file line 3")
+}
@@ -1,8 +1,10 @@
 package report
 
 import (
+	"io"
 	"strings"
 
+	"github.com/Checkmarx/kics/v2/pkg/model"
 	reportModel "github.com/Checkmarx/kics/v2/pkg/report/model"
 )
 
@@ -11,26 +13,75 @@ func PrintSarifReport(path, filename string, body interface{}) error {
 	if !strings.HasSuffix(filename, ".sarif") {
 		filename += ".sarif"
 	}
-	if body != "" {
-		summary, err := getSummary(body)
-		if err != nil {
-			return err
+	report, err := buildSarifReport(body)
+	if err != nil {
+		return err
+	}
+
+	return ExportJSONReport(path, filename, report)
+}
+
+// WriteSarifReport writes the sarif report of the summary results to the writer
+func WriteSarifReport(w io.Writer, body interface{}) error {
+	report, err := buildSarifReport(body)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, report)
+}
+
+func buildSarifReport(body interface{}) (interface{}, error) {
+	if body == "" {
+		return body, nil
+	}
+
+	summary, err := getSummary(body)
+	if err != nil {
+		return nil, err
+	}
+
+	sarifReport := reportModel.NewSarifReport()
+	auxID := []string{}
+	auxGUID := map[string]string{}
+	queries := withFixedFindings(&summary)
+	for idx := range queries {
+		x := sarifReport.BuildSarifIssue(&queries[idx])
+		if x != "" {
+			auxID = append(auxID, x)
+			guid := sarifReport.GetGUIDFromRelationships(idx, x)
+			auxGUID[x] = guid
 		}
+	}
+	sarifReport.RebuildTaxonomies(auxID, auxGUID)
+	return sarifReport, nil
+}
+
+// withFixedFindings merges the findings fixed since the baseline into the summary queries,
+// so they are reported as absent results
+func withFixedFindings(summary *model.Summary) model.QueryResultSlice {
+	if summary.Baseline == nil || len(summary.Baseline.Fixed) == 0 {
+		return summary.Queries
+	}
+
+	queries := make(model.QueryResultSlice, len(summary.Queries))
+	copy(queries, summary.Queries)
+	queryIndexes := make(map[string]int, len(queries))
+	for idx := range queries {
+		queryIndexes[queries[idx].QueryID] = idx
+	}
 
-		sarifReport := reportModel.NewSarifReport()
-		auxID := []string{}
-		auxGUID := map[string]string{}
-		for idx := range summary.Queries {
-			x := sarifReport.BuildSarifIssue(&summary.Queries[idx])
-			if x != "" {
-				auxID = append(auxID, x)
-				guid := sarifReport.GetGUIDFromRelationships(idx, x)
-				auxGUID[x] = guid
-			}
+	for idx := range summary.Baseline.Fixed {
+		fixed := summary.Baseline.Fixed[idx]
+		if queryIdx, ok := queryIndexes[fixed.QueryID]; ok {
+			files := make([]model.VulnerableFile, 0, len(queries[queryIdx].Files)+len(fixed.Files))
+			files = append(files, queries[queryIdx].Files...)
+			queries[queryIdx].Files = append(files, fixed.Files...)
+			continue
 		}
-		sarifReport.RebuildTaxonomies(auxID, auxGUID)
-		body = sarifReport
+		queryIndexes[fixed.QueryID] = len(queries)
+		queries = append(queries, fixed)
 	}
 
-	return ExportJSONReport(path, filename, body)
+	return queries
 }
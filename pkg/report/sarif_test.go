@@ -1,6 +1,7 @@
 package report
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -67,7 +68,52 @@ func TestPrintSarifReport(t *testing.T) {
 	}
 }
 
+// TestWriteSarifReport tests the function [WriteSarifReport()]
+func TestWriteSarifReport(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSarifReport(&buf, test.SummaryMock)
+	require.NoError(t, err)
+
+	var resultSarif sarifReport
+	err = json.Unmarshal(buf.Bytes(), &resultSarif)
+	require.NoError(t, err)
+	require.Equal(t, "2.1.0", resultSarif.SarifVersion)
+	require.Len(t, resultSarif.Runs, len(test.SummaryMock.Queries))
+}
+
 func checkFileExists(t *testing.T, err error, tc *reportTestCase, extension string) {
 	require.NoError(t, err)
 	require.FileExists(t, filepath.Join(tc.caseTest.path, tc.caseTest.filename+fmt.Sprintf(".%s", extension)))
 }
+
+// TestWithFixedFindings tests the function [withFixedFindings()]
+func TestWithFixedFindings(t *testing.T) {
+	summary := model.Summary{
+		Queries: model.QueryResultSlice{
+			{
+				QueryID: "query-1",
+				Files:   []model.VulnerableFile{{SimilarityID: "new", BaselineState: model.BaselineStateNew}},
+			},
+		},
+	}
+	require.Equal(t, summary.Queries, withFixedFindings(&summary))
+
+	summary.Baseline = &model.BaselineSummary{
+		Fixed: model.QueryResultSlice{
+			{
+				QueryID: "query-1",
+				Files:   []model.VulnerableFile{{SimilarityID: "fixed-1", BaselineState: model.BaselineStateFixed}},
+			},
+			{
+				QueryID: "query-2",
+				Files:   []model.VulnerableFile{{SimilarityID: "fixed-2", BaselineState: model.BaselineStateFixed}},
+			},
+		},
+	}
+	queries := withFixedFindings(&summary)
+	require.Len(t, queries, 2)
+	require.Len(t, queries[0].Files, 2)
+	require.Equal(t, "fixed-1", queries[0].Files[1].SimilarityID)
+	require.Equal(t, "query-2", queries[1].QueryID)
+	require.Len(t, summary.Queries[0].Files, 1)
+}
@@ -4,14 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/Checkmarx/kics/v2/pkg/analyzer"
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/Checkmarx/kics/v2/pkg/utils"
 	"github.com/rs/zerolog/log"
@@ -28,6 +27,7 @@ type ResolvedFile struct {
 type Resolver struct {
 	unmarshler    func(fileContent []byte, v any) error
 	marshler      func(v any) ([]byte, error)
+	fileSystem    filesystem.FileSystem
 	ResolvedFiles map[string]model.ResolvedFile
 	Extension     []string
 }
@@ -37,14 +37,29 @@ func NewResolver(
 	unmarshler func(fileContent []byte, v any) error,
 	marshler func(v any) ([]byte, error),
 	ext []string) *Resolver {
+	return NewResolverWithFileSystem(unmarshler, marshler, ext, nil)
+}
+
+// NewResolverWithFileSystem returns a new Resolver reading the referenced files from the given FileSystem
+func NewResolverWithFileSystem(
+	unmarshler func(fileContent []byte, v any) error,
+	marshler func(v any) ([]byte, error),
+	ext []string,
+	fileSystem filesystem.FileSystem) *Resolver {
 	return &Resolver{
 		unmarshler:    unmarshler,
 		marshler:      marshler,
+		fileSystem:    fileSystem,
 		ResolvedFiles: make(map[string]model.ResolvedFile),
 		Extension:     ext,
 	}
 }
 
+// getFileSystem returns the FileSystem the referenced files are read from, the disk by default
+func (r *Resolver) getFileSystem() filesystem.FileSystem {
+	return filesystem.Get(r.fileSystem)
+}
+
 func isOpenAPI(fileContent []byte) bool {
 	regexToRun :=
 		[]*regexp.Regexp{analyzer.OpenAPIRegexInfo,
@@ -279,7 +294,7 @@ func (r *Resolver) resolveYamlPath(
 	} else { // external file resolve
 		value = checkServerlessFileReference(value)
 
-		exists, path, onlyFilePath, filename := findFilePath(filepath.Dir(filePath), value, ansibleVars, r.Extension)
+		exists, path, onlyFilePath, filename := r.findFilePath(filepath.Dir(filePath), value, ansibleVars, r.Extension)
 		if !exists {
 			return *v, false
 		}
@@ -346,21 +361,12 @@ func (r *Resolver) resolveFile(
 	resolveCount, maxResolverDepth int,
 	resolvedFilesCache map[string]ResolvedFile,
 	yamlResolve, resolveReferences bool) (any, bool) {
-	// open the file with the content to replace
-	file, err := os.Open(filepath.Clean(filePath))
+	// read the file with the content to replace
+	fileContent, err := r.getFileSystem().ReadFile(filePath)
 	if err != nil {
 		return value, true
 	}
 
-	defer func(file *os.File) {
-		err = file.Close()
-		if err != nil {
-			log.Err(err).Msgf("failed to close resolved file: %s", filePath)
-		}
-	}(file)
-	// read the content
-	fileContent, _ := io.ReadAll(file)
-
 	resolvedFile := r.Resolve(fileContent, filePath, resolveCount+1, maxResolverDepth, resolvedFilesCache, resolveReferences)
 
 	if yamlResolve {
@@ -422,7 +428,7 @@ func (r *Resolver) resolvePath(
 
 		// index 0 contains the path of the file while the other indexes contain the sections (e.g. path = "./definitions.json#User/schema")
 		onlyFilePath := splitPath[0]
-		_, err := os.Stat(onlyFilePath)
+		_, err := r.getFileSystem().Stat(onlyFilePath)
 
 		if err != nil || !contains(filepath.Ext(onlyFilePath), r.Extension) {
 			return value, false
@@ -583,18 +589,18 @@ func checkServerlessFileReference(value string) string {
 	return value
 }
 
-func findFilePath(
+func (r *Resolver) findFilePath(
 	folderPath, filename string,
 	ansibleVars bool,
 	extensions []string) (exists bool, path, onlyFilePath, cleanFilePath string) {
 	path = filepath.Join(folderPath, filename)
 	if ansibleVars {
-		if exists, ansibleVarsPath := findAnsibleVarsPath(folderPath, filename); !exists {
+		if exists, ansibleVarsPath := r.findAnsibleVarsPath(folderPath, filename); !exists {
 			return false, "", "", ""
 		} else {
 			path = ansibleVarsPath
 		}
-	} else if _, err := os.Stat(path); err != nil {
+	} else if _, err := r.getFileSystem().Stat(path); err != nil {
 		return false, "", "", ""
 	}
 
@@ -606,14 +612,14 @@ func findFilePath(
 	return true, path, onlyFilePath, filepath.Clean(onlyFilePath)
 }
 
-func findAnsibleVarsPath(folderPath, filename string) (exists bool, ansibleVarsPath string) {
+func (r *Resolver) findAnsibleVarsPath(folderPath, filename string) (exists bool, ansibleVarsPath string) {
 	possiblePaths := []string{
 		filepath.Join(folderPath, "vars", filename),
 		filepath.Join(folderPath, filename),
 	}
 
 	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
+		if _, err := r.getFileSystem().Stat(path); err == nil {
 			return true, path
 		}
 	}
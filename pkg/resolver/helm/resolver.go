@@ -1,21 +1,31 @@
 package helm
 
 import (
+	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	masterUtils "github.com/Checkmarx/kics/v2/pkg/utils"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/release"
 )
 
 // Resolver is an instance of the helm resolver
 type Resolver struct {
+	// ValuesFiles are the values files merged into the values of the charts, relative to the chart directory
+	ValuesFiles []string
+	// Values are the values set on the charts, with the syntax of helm --set
+	Values []string
+	// Environments are the values files, relative to the chart directory, the charts are rendered with one at a time
+	Environments []string
 }
 
 // splitManifest keeps the information of the manifest splitted by source
@@ -29,6 +39,11 @@ type splitManifest struct {
 
 const (
 	kicsHelmID = "# KICS_HELM_ID_"
+
+	// chart annotations setting the values of the chart, in addition to the ones of the resolver
+	valuesFilesAnnotation  = "kics-scan/values"
+	valuesAnnotation       = "kics-scan/set"
+	environmentsAnnotation = "kics-scan/environments"
 )
 
 // Resolve will render the passed helm chart and return its content ready for parsing
@@ -40,30 +55,106 @@ func (r *Resolver) Resolve(filePath string) (model.ResolvedFiles, error) {
 			masterUtils.HandlePanic(r, errMessage)
 		}
 	}()
-	splits, excluded, err := renderHelm(filePath)
-	if err != nil { // return error to be logged
-		return model.ResolvedFiles{}, errors.New("failed to render helm chart")
+	var rfiles = model.ResolvedFiles{}
+	for _, environment := range r.getEnvironments(filePath) {
+		splits, excluded, err := renderHelm(filePath, r.getValuesOptions(filePath, environment))
+		if err != nil { // return error to be logged
+			return model.ResolvedFiles{}, errors.New("failed to render helm chart")
+		}
+		rfiles.Excluded = append(rfiles.Excluded, excluded...)
+		for _, split := range *splits {
+			subFolder := filepath.Base(filePath)
+
+			splitPath := strings.Split(split.path, getPathSeparator(split.path))
+
+			splited := filepath.Join(splitPath[1:]...)
+
+			origpath := filepath.Join(filepath.Dir(filePath), subFolder, splited)
+			rfiles.File = append(rfiles.File, model.ResolvedHelm{
+				FileName:     origpath,
+				Content:      split.content,
+				OriginalData: split.original,
+				SplitID:      split.splitID,
+				IDInfo:       split.splitIDMap,
+				Environment:  environment,
+			})
+		}
 	}
-	var rfiles = model.ResolvedFiles{
-		Excluded: excluded,
+	return rfiles, nil
+}
+
+// getAnnotation returns the comma separated values of a Chart.yaml annotation
+func getAnnotation(chartPath, annotation string) []string {
+	metadata, err := chartutil.LoadChartfile(filepath.Join(chartPath, "Chart.yaml"))
+	if err != nil || metadata.Annotations[annotation] == "" {
+		return []string{}
+	}
+	values := make([]string, 0)
+	for _, value := range strings.Split(metadata.Annotations[annotation], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
 	}
-	for _, split := range *splits {
-		subFolder := filepath.Base(filePath)
+	return values
+}
 
-		splitPath := strings.Split(split.path, getPathSeparator(split.path))
+// getEnvironments returns the values files the chart is rendered with one at a time, relative to the chart
+// directory, the environments of the chart annotation take precedence over the ones of the resolver,
+// a chart without environments is rendered once with an empty environment
+func (r *Resolver) getEnvironments(chartPath string) []string {
+	patterns := getAnnotation(chartPath, environmentsAnnotation)
+	if len(patterns) == 0 {
+		patterns = r.Environments
+	}
 
-		splited := filepath.Join(splitPath[1:]...)
+	environments := make([]string, 0)
+	found := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(chartPath, pattern))
+		if err != nil {
+			log.Warn().Msgf("Invalid helm environment pattern %s", pattern)
+			continue
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			environment, errRel := filepath.Rel(chartPath, match)
+			if errRel != nil || found[environment] {
+				continue
+			}
+			found[environment] = true
+			environments = append(environments, environment)
+		}
+	}
+	if len(environments) == 0 {
+		return []string{""}
+	}
+	return environments
+}
 
-		origpath := filepath.Join(filepath.Dir(filePath), subFolder, splited)
-		rfiles.File = append(rfiles.File, model.ResolvedHelm{
-			FileName:     origpath,
-			Content:      split.content,
-			OriginalData: split.original,
-			SplitID:      split.splitID,
-			IDInfo:       split.splitIDMap,
-		})
+// getValuesOptions returns the values of the chart, the values files and values of the resolver followed
+// by the ones of the chart annotations and the values file of the environment, values files not found
+// in the chart directory are skipped
+func (r *Resolver) getValuesOptions(chartPath, environment string) *values.Options {
+	valueOpts := &values.Options{
+		ValueFiles: make([]string, 0),
+		Values:     make([]string, 0),
 	}
-	return rfiles, nil
+
+	valuesFiles := append(append([]string{}, r.ValuesFiles...), getAnnotation(chartPath, valuesFilesAnnotation)...)
+	if environment != "" {
+		valuesFiles = append(valuesFiles, environment)
+	}
+	for _, valuesFile := range valuesFiles {
+		valuesPath := filepath.Join(chartPath, valuesFile)
+		if _, err := os.Stat(valuesPath); err != nil {
+			log.Debug().Msgf("Helm values file %s not found in chart %s", valuesFile, chartPath)
+			continue
+		}
+		valueOpts.ValueFiles = append(valueOpts.ValueFiles, valuesPath)
+	}
+
+	valueOpts.Values = append(append(valueOpts.Values, r.Values...), getAnnotation(chartPath, valuesAnnotation)...)
+	return valueOpts
 }
 
 // SupportedTypes returns the supported fileKinds for this resolver
@@ -72,9 +163,9 @@ func (r *Resolver) SupportedTypes() []model.FileKind {
 }
 
 // renderHelm will use helm library to render helm charts
-func renderHelm(path string) (*[]splitManifest, []string, error) {
+func renderHelm(path string, valueOpts *values.Options) (*[]splitManifest, []string, error) {
 	client := newClient()
-	manifest, excluded, err := runInstall([]string{path}, client, &values.Options{})
+	manifest, excluded, err := runInstall([]string{path}, client, valueOpts)
 	if err != nil {
 		return nil, []string{}, err
 	}
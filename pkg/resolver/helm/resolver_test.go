@@ -206,3 +206,38 @@ spec:
 		})
 	}
 }
+
+// TestResolver_Resolve_Environments tests that the chart is rendered once for each environment values file
+func TestResolver_Resolve_Environments(t *testing.T) {
+	res := &Resolver{
+		Values: []string{"image.repository=httpd"},
+	}
+	got, err := res.Resolve(filepath.FromSlash("../../../test/fixtures/test_helm_environments"))
+	require.NoError(t, err)
+	require.Len(t, got.File, 2)
+
+	require.Equal(t, "values-dev.yaml", got.File[0].Environment)
+	require.Contains(t, string(got.File[0].Content), `image: "httpd:1.25-dev"`)
+	require.Contains(t, string(got.File[0].Content), "privileged: false")
+
+	require.Equal(t, "values-prod.yaml", got.File[1].Environment)
+	require.Contains(t, string(got.File[1].Content), `image: "httpd:1.25"`)
+	require.Contains(t, string(got.File[1].Content), "privileged: true")
+}
+
+// TestResolver_getValuesOptions tests the values of the charts set by the resolver and the chart annotations
+func TestResolver_getValuesOptions(t *testing.T) {
+	chartPath := filepath.FromSlash("../../../test/fixtures/test_helm")
+	res := &Resolver{
+		ValuesFiles:  []string{"values.yaml", "values-missing.yaml"},
+		Values:       []string{"service.type=LoadBalancer"},
+		Environments: []string{"values*.yaml"},
+	}
+
+	require.Equal(t, []string{"values.yaml"}, res.getEnvironments(chartPath))
+	got := res.getValuesOptions(chartPath, "values.yaml")
+	require.Equal(t, []string{filepath.Join(chartPath, "values.yaml"), filepath.Join(chartPath, "values.yaml")}, got.ValueFiles)
+	require.Equal(t, []string{"service.type=LoadBalancer"}, got.Values)
+
+	require.Equal(t, []string{""}, (&Resolver{}).getEnvironments(chartPath))
+}
@@ -0,0 +1,137 @@
+package kustomize
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// kustomization keeps the fields of a kustomization file that reference other files
+type kustomization struct {
+	Resources             []string `yaml:"resources"`
+	Bases                 []string `yaml:"bases"`
+	Components            []string `yaml:"components"`
+	PatchesStrategicMerge []string `yaml:"patchesStrategicMerge"`
+	Patches               []struct {
+		Path string `yaml:"path"`
+	} `yaml:"patches"`
+}
+
+// referenceFields are the fields of a kustomization file that reference resources, bases and components
+var referenceFields = []string{"resources", "bases", "components"}
+
+// fileSystem is the file system on disk used to render a kustomization, it enables the origin annotations
+// of the kustomization files loaded, drops their references to remote resources and keeps the files read
+type fileSystem struct {
+	filesys.FileSystem
+	read           []string
+	kustomizations map[string]kustomization
+}
+
+func newFileSystem() *fileSystem {
+	return &fileSystem{
+		FileSystem:     filesys.MakeFsOnDisk(),
+		read:           make([]string, 0),
+		kustomizations: make(map[string]kustomization),
+	}
+}
+
+// ReadFile reads a file from disk, updating the content of kustomization files
+func (f *fileSystem) ReadFile(path string) ([]byte, error) {
+	content, err := f.FileSystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f.read = append(f.read, path)
+	if !isKustomizationFile(path) {
+		return content, nil
+	}
+	return f.updateKustomization(path, content), nil
+}
+
+// updateKustomization adds the origin annotations to the build metadata of a kustomization file,
+// used to know the file each rendered resource comes from, and removes the references to
+// resources that are not on disk, since remote resources are not fetched
+func (f *fileSystem) updateKustomization(path string, content []byte) []byte {
+	var parsed kustomization
+	var fields map[string]interface{}
+	if yaml.Unmarshal(content, &parsed) != nil || yaml.Unmarshal(content, &fields) != nil || fields == nil {
+		// let kustomize report the invalid kustomization
+		return content
+	}
+	f.kustomizations[path] = parsed
+
+	for _, field := range referenceFields {
+		references, ok := fields[field].([]interface{})
+		if !ok {
+			continue
+		}
+		local := make([]interface{}, 0, len(references))
+		for _, reference := range references {
+			if name, isString := reference.(string); isString && !f.Exists(filepath.Join(filepath.Dir(path), name)) {
+				continue
+			}
+			local = append(local, reference)
+		}
+		fields[field] = local
+	}
+
+	buildMetadata, _ := fields["buildMetadata"].([]interface{})
+	fields["buildMetadata"] = append(buildMetadata, types.OriginAnnotations)
+
+	updated, err := yaml.Marshal(fields)
+	if err != nil {
+		return content
+	}
+	return updated
+}
+
+// isKustomizationFile returns true if the file name is one of the names recognized for kustomization files
+func isKustomizationFile(path string) bool {
+	name := filepath.Base(path)
+	for _, kustomizationName := range konfig.RecognizedKustomizationFileNames() {
+		if name == kustomizationName {
+			return true
+		}
+	}
+	return false
+}
+
+// renderKustomize will use kustomize library to render the kustomization in the directory path
+func renderKustomize(fSys *fileSystem, path string) (resmap.ResMap, error) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	return krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, path)
+}
+
+// patchFiles returns the files of the strategic merge patches of the kustomizations loaded
+func (f *fileSystem) patchFiles() []string {
+	patches := make([]string, 0)
+	for path, parsed := range f.kustomizations {
+		names := make([]string, 0, len(parsed.PatchesStrategicMerge)+len(parsed.Patches))
+		names = append(names, parsed.PatchesStrategicMerge...)
+		for _, patch := range parsed.Patches {
+			names = append(names, patch.Path)
+		}
+		for _, name := range names {
+			// inline patches are not files
+			if name == "" || strings.Contains(name, "\n") {
+				continue
+			}
+			patches = append(patches, filepath.Join(filepath.Dir(path), name))
+		}
+	}
+	sort.Strings(patches)
+	return patches
+}
@@ -0,0 +1,251 @@
+package kustomize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	masterUtils "github.com/Checkmarx/kics/v2/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Resolver is an instance of the kustomize resolver
+type Resolver struct {
+}
+
+// sourceFile keeps the original data of a file the rendered resources come from,
+// with an auxiliary line added at the start of each of its documents
+type sourceFile struct {
+	original  []byte
+	documents []sourceDocument
+	idMap     map[int]interface{}
+}
+
+// sourceDocument keeps the auxiliary line id, the kind and the name of a document of a source file
+type sourceDocument struct {
+	id   string
+	kind string
+	name string
+	used bool
+}
+
+const (
+	// kicsHelmID is the auxiliary line also used by the helm resolver, so the helm line detection can be reused
+	kicsHelmID = "# KICS_HELM_ID_"
+)
+
+// manifestExtensions are the extensions of the source files that can be parsed
+var manifestExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+// Resolve will render the kustomization in the passed directory and return its resources ready for parsing
+func (r *Resolver) Resolve(filePath string) (model.ResolvedFiles, error) {
+	// handle panic during resolve process
+	defer func() {
+		if r := recover(); r != nil {
+			errMessage := "Recovered from panic during resolve of file " + filePath
+			masterUtils.HandlePanic(r, errMessage)
+		}
+	}()
+	fSys := newFileSystem()
+	resources, err := renderKustomize(fSys, filePath)
+	if err != nil { // return error to be logged
+		return model.ResolvedFiles{}, errors.Wrap(err, "failed to render kustomization")
+	}
+	root, _, err := fSys.CleanedAbs(filePath)
+	if err != nil {
+		return model.ResolvedFiles{}, err
+	}
+
+	origins := make([]*resource.Origin, 0, resources.Size())
+	for _, res := range resources.Resources() {
+		origin, errOrigin := res.GetOrigin()
+		if errOrigin != nil {
+			return model.ResolvedFiles{}, errOrigin
+		}
+		origins = append(origins, origin)
+	}
+	if err = resources.RemoveOriginAnnotations(); err != nil {
+		return model.ResolvedFiles{}, err
+	}
+
+	sources := make(map[string]*sourceFile)
+	patches := fSys.patchFiles()
+	var rfiles = model.ResolvedFiles{
+		Excluded: fSys.read,
+	}
+	for i, res := range resources.Resources() {
+		sourcePath := getSourcePath(root.String(), origins[i])
+		if sourcePath == "" || !manifestExtensions[strings.ToLower(filepath.Ext(sourcePath))] {
+			log.Debug().Msgf("Kustomize resource %s has no source file to be scanned", res.CurId())
+			continue
+		}
+		source, errSource := getSourceFile(sources, sourcePath)
+		if errSource != nil {
+			return model.ResolvedFiles{}, errSource
+		}
+		content, errYAML := res.AsYAML()
+		if errYAML != nil {
+			return model.ResolvedFiles{}, errYAML
+		}
+
+		document := source.document(res.GetKind(), res.GetName())
+		resolved := model.ResolvedHelm{
+			FileName:     getResolvedPath(filePath, root.String(), sourcePath),
+			Content:      content,
+			OriginalData: source.original,
+			SplitID:      document.id,
+			IDInfo:       source.idMap,
+		}
+		for _, patchPath := range patches {
+			patch, errPatch := getSourceFile(sources, patchPath)
+			if errPatch != nil {
+				return model.ResolvedFiles{}, errPatch
+			}
+			for _, patchDocument := range patch.documents {
+				if patchDocument.kind != res.GetKind() ||
+					(patchDocument.name != document.name && patchDocument.name != res.GetName()) {
+					continue
+				}
+				resolved.Patches = append(resolved.Patches, model.ResolvedHelm{
+					FileName:     getResolvedPath(filePath, root.String(), patchPath),
+					OriginalData: patch.original,
+					SplitID:      patchDocument.id,
+					IDInfo:       patch.idMap,
+				})
+			}
+		}
+		rfiles.File = append(rfiles.File, resolved)
+	}
+	return rfiles, nil
+}
+
+// SupportedTypes returns the supported fileKinds for this resolver
+func (r *Resolver) SupportedTypes() []model.FileKind {
+	return []model.FileKind{model.KindKUSTOMIZE}
+}
+
+// getSourcePath returns the path of the file a rendered resource comes from, which is the file it was
+// loaded from or, for generated resources, the kustomization file of the generator
+func getSourcePath(root string, origin *resource.Origin) string {
+	switch {
+	case origin == nil || origin.Repo != "":
+		return ""
+	case origin.Path != "":
+		return filepath.Join(root, origin.Path)
+	case origin.ConfiguredIn != "":
+		return filepath.Join(root, origin.ConfiguredIn)
+	default:
+		return ""
+	}
+}
+
+// getResolvedPath returns the path of a source file relative to the directory passed to the resolver
+func getResolvedPath(filePath, root, sourcePath string) string {
+	rel, err := filepath.Rel(root, sourcePath)
+	if err != nil {
+		return sourcePath
+	}
+	return filepath.Join(filePath, rel)
+}
+
+// getSourceFile returns the source file in path, loading it the first time it is needed
+func getSourceFile(sources map[string]*sourceFile, path string) (*sourceFile, error) {
+	if source, ok := sources[path]; ok {
+		return source, nil
+	}
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	source := newSourceFile(content)
+	sources[path] = source
+	return source, nil
+}
+
+// newSourceFile adds an auxiliary line at the start of each document of the content, where the id
+// is the line, and builds the map with the ids and the corresponding lines used in the detector
+func newSourceFile(content []byte) *sourceFile {
+	source := &sourceFile{
+		documents: make([]sourceDocument, 0),
+		idMap:     make(map[int]interface{}),
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r", ""), "\n")
+	original := make([]string, 0, len(lines)+1)
+	documents := make([][]string, 0)
+	var mapLines map[int]int
+	start := true
+	for _, line := range lines {
+		if isDocumentSeparator(line) {
+			start = true
+		} else if start {
+			id := len(original)
+			original = append(original, fmt.Sprintf("%s%d:", kicsHelmID, id))
+			mapLines = map[int]int{id: id}
+			source.idMap[id] = mapLines
+			source.documents = append(source.documents, sourceDocument{id: original[id]})
+			documents = append(documents, make([]string, 0))
+			start = false
+		}
+		original = append(original, line)
+		if mapLines != nil {
+			mapLines[len(original)-1] = len(original) - 1
+		}
+		if !start {
+			documents[len(documents)-1] = append(documents[len(documents)-1], line)
+		}
+	}
+	source.original = []byte(strings.Join(original, "\n"))
+
+	for i := range documents {
+		var metadata struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(documents[i], "\n")), &metadata); err == nil {
+			source.documents[i].kind = metadata.Kind
+			source.documents[i].name = metadata.Metadata.Name
+		}
+	}
+	return source
+}
+
+// document returns the document of the source file a rendered resource comes from, the documents
+// are matched by kind and by the name, which transformations like namePrefix keep in the rendered name,
+// in the order they are declared
+func (s *sourceFile) document(kind, name string) *sourceDocument {
+	var candidate *sourceDocument
+	for i := range s.documents {
+		document := &s.documents[i]
+		if document.used || document.kind != kind {
+			continue
+		}
+		if strings.Contains(name, document.name) {
+			candidate = document
+			break
+		}
+		if candidate == nil {
+			candidate = document
+		}
+	}
+	if candidate == nil {
+		// generated resources come from a kustomization file without documents of their kind
+		return &s.documents[0]
+	}
+	candidate.used = true
+	return candidate
+}
+
+func isDocumentSeparator(line string) bool {
+	return line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t")
+}
@@ -0,0 +1,87 @@
+package kustomize
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var kustomizeFixturePath = filepath.FromSlash("../../../test/fixtures/test_kustomize")
+
+// TestResolver_Resolve tests the functions [Resolve()] and all the methods called by them
+func TestResolver_Resolve(t *testing.T) {
+	res := &Resolver{}
+	overlay := filepath.Join(kustomizeFixturePath, "overlays", "prod")
+
+	got, err := res.Resolve(overlay)
+	require.NoError(t, err)
+	require.Len(t, got.File, 3)
+
+	deployment := filepath.Join(kustomizeFixturePath, "base", "deployment.yaml")
+	require.Equal(t, deployment, got.File[0].FileName)
+	require.Equal(t, "# KICS_HELM_ID_0:", got.File[0].SplitID)
+	require.Contains(t, string(got.File[0].Content), "name: prod-web")
+	require.Empty(t, got.File[0].Patches)
+
+	require.Equal(t, deployment, got.File[1].FileName)
+	require.Equal(t, "# KICS_HELM_ID_11:", got.File[1].SplitID)
+	require.Contains(t, string(got.File[1].Content), "image: nginx:1.25")
+	require.NotContains(t, string(got.File[1].Content), "config.kubernetes.io/origin")
+	require.Equal(t, []model.ResolvedHelm{
+		{
+			FileName: filepath.Join(overlay, "patch.yaml"),
+			OriginalData: []byte(`# KICS_HELM_ID_0:
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          securityContext:
+            privileged: true
+`),
+			SplitID: "# KICS_HELM_ID_0:",
+			IDInfo: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6,
+				7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12}},
+		},
+	}, got.File[1].Patches)
+
+	require.Equal(t, filepath.Join(overlay, "kustomization.yaml"), got.File[2].FileName)
+	require.Contains(t, string(got.File[2].Content), "kind: ConfigMap")
+
+	require.Len(t, got.Excluded, 4)
+	require.Contains(t, got.Excluded[3], "patch.yaml")
+}
+
+// TestResolver_Resolve_Error tests that directories without a kustomization file are not rendered
+func TestResolver_Resolve_Error(t *testing.T) {
+	res := &Resolver{}
+	_, err := res.Resolve(filepath.FromSlash("../../../test/fixtures/all_auth_users_get_read_access"))
+	require.Error(t, err)
+}
+
+// TestNewSourceFile tests that the auxiliary lines are added at the start of each document
+func TestNewSourceFile(t *testing.T) {
+	source := newSourceFile([]byte("# comment\n---\nkind: Service\nmetadata:\n  name: web\n---\nkind: Pod\n"))
+
+	require.Equal(t, "# KICS_HELM_ID_0:\n# comment\n---\n# KICS_HELM_ID_3:\nkind: Service\nmetadata:\n  name: web\n"+
+		"---\n# KICS_HELM_ID_8:\nkind: Pod\n", string(source.original))
+	require.Equal(t, []sourceDocument{
+		{id: "# KICS_HELM_ID_0:"},
+		{id: "# KICS_HELM_ID_3:", kind: "Service", name: "web"},
+		{id: "# KICS_HELM_ID_8:", kind: "Pod"},
+	}, source.documents)
+	require.Equal(t, map[int]interface{}{
+		0: map[int]int{0: 0, 1: 1, 2: 2},
+		3: map[int]int{3: 3, 4: 4, 5: 5, 6: 6, 7: 7},
+		8: map[int]int{8: 8, 9: 9, 10: 10},
+	}, source.idMap)
+
+	require.Equal(t, "# KICS_HELM_ID_3:", source.document("Service", "prod-web").id)
+	require.Equal(t, "# KICS_HELM_ID_0:", source.document("ConfigMap", "config").id)
+}
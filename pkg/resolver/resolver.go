@@ -6,6 +6,7 @@ import (
 
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/rs/zerolog/log"
+	"sigs.k8s.io/kustomize/api/konfig"
 )
 
 // kindResolver is a type of resolver interface (ex: helm resolver)
@@ -74,5 +75,10 @@ func (r *Resolver) GetType(filePath string) model.FileKind {
 	if err == nil {
 		return model.KindHELM
 	}
+	for _, kustomizationName := range konfig.RecognizedKustomizationFileNames() {
+		if info, err := os.Stat(filepath.Join(filePath, kustomizationName)); err == nil && !info.IsDir() {
+			return model.KindKUSTOMIZE
+		}
+	}
 	return model.KindCOMMON
 }
@@ -34,6 +34,13 @@ func TestGetType(t *testing.T) {
 			},
 			want: model.KindHELM,
 		},
+		{
+			name: "get_kustomize_type",
+			args: args{
+				filepath: filepath.FromSlash("../../test/fixtures/test_kustomize/overlays/prod"),
+			},
+			want: model.KindKUSTOMIZE,
+		},
 		{
 			name: "get_no_type",
 			args: args{
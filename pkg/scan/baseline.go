@@ -0,0 +1,28 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// loadBaseline reads the JSON results file of a previous scan to be used as baseline
+func loadBaseline(baselinePath string) (*model.Summary, error) {
+	log.Debug().Msgf("Loading baseline from %s", baselinePath)
+
+	content, err := os.ReadFile(filepath.Clean(baselinePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file %s: %w", baselinePath, err)
+	}
+
+	var baseline model.Summary
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline file %s: %w", baselinePath, err)
+	}
+
+	return &baseline, nil
+}
@@ -0,0 +1,30 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadBaseline(t *testing.T) {
+	dir := t.TempDir()
+
+	baselinePath := filepath.Join(dir, "results.json")
+	content := `{"queries": [{"query_id": "query-1", "severity": "HIGH", "files": [{"file_name": "main.tf", "similarity_id": "sim-1"}]}]}`
+	require.NoError(t, os.WriteFile(baselinePath, []byte(content), 0600))
+
+	baseline, err := loadBaseline(baselinePath)
+	require.NoError(t, err)
+	require.Len(t, baseline.Queries, 1)
+	require.Equal(t, "sim-1", baseline.Queries[0].Files[0].SimilarityID)
+
+	invalidPath := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("not json"), 0600))
+	_, err = loadBaseline(invalidPath)
+	require.Error(t, err)
+
+	_, err = loadBaseline(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
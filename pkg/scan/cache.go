@@ -0,0 +1,74 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/v2/internal/constants"
+	"github.com/Checkmarx/kics/v2/pkg/cache"
+	"github.com/Checkmarx/kics/v2/pkg/engine"
+	"github.com/Checkmarx/kics/v2/pkg/kics"
+	"github.com/rs/zerolog/log"
+)
+
+// newResultsCache initializes the results cache, its namespace includes everything that changes the results of
+// an unchanged file: KICS version, queries, libraries and their input data, secrets rules, Terraform variables,
+// CloudFormation parameters and the scan parameters affecting the results
+func (c *Client) newResultsCache(inspector *engine.Inspector, secretsRegexRulesContent string) (*cache.Cache, error) {
+	terraformVars := ""
+	if c.ScanParams.TerraformVarsPath != "" {
+		content, err := os.ReadFile(filepath.Clean(c.ScanParams.TerraformVarsPath))
+		if err != nil {
+			return nil, err
+		}
+		terraformVars = string(content)
+	}
+	cfnParameters := ""
+	if c.ScanParams.CloudFormationParamsPath != "" {
+		content, err := os.ReadFile(filepath.Clean(c.ScanParams.CloudFormationParamsPath))
+		if err != nil {
+			return nil, err
+		}
+		cfnParameters = string(content)
+	}
+
+	excludeResults := make([]string, len(c.ScanParams.ExcludeResults))
+	copy(excludeResults, c.ScanParams.ExcludeResults)
+	sort.Strings(excludeResults)
+
+	return cache.NewCache(
+		c.ScanParams.CacheDir,
+		constants.Version,
+		inspector.QueriesHash(),
+		secretsRegexRulesContent,
+		terraformVars,
+		cfnParameters,
+		strings.Join(excludeResults, ","),
+		fmt.Sprintf("%t,%t,%t,%t,%d,%d,%d",
+			c.ScanParams.DisableSecrets,
+			c.ScanParams.UseOldSeverities,
+			c.ScanParams.KicsComputeNewSimID,
+			c.ScanParams.OpenAPIResolveReferences,
+			c.ScanParams.MaxResolverDepth,
+			c.ScanParams.MaxFileSizeFlag,
+			c.ScanParams.PreviewLines),
+	)
+}
+
+// saveResultsCache stores the results of the parsed files, unless some query failed since the results
+// of the scan are incomplete
+func (c *Client) saveResultsCache(services []*kics.Service, failedQueries map[string]error) {
+	hits, misses := c.resultsCache.Stats()
+	log.Info().Msgf("Results cache hits: %d, misses: %d", hits, misses)
+
+	if len(failedQueries) > 0 {
+		log.Warn().Msg("Results cache not updated since some queries failed to execute")
+		return
+	}
+	for _, service := range services {
+		service.SaveCache()
+	}
+}
@@ -6,7 +6,10 @@ import (
 
 	"github.com/Checkmarx/kics/v2/internal/storage"
 	"github.com/Checkmarx/kics/v2/internal/tracker"
+	"github.com/Checkmarx/kics/v2/pkg/cache"
 	"github.com/Checkmarx/kics/v2/pkg/descriptions"
+	"github.com/Checkmarx/kics/v2/pkg/gitdiff"
+	"github.com/Checkmarx/kics/v2/pkg/model"
 	consolePrinter "github.com/Checkmarx/kics/v2/pkg/printer"
 	"github.com/Checkmarx/kics/v2/pkg/progress"
 	"github.com/rs/zerolog/log"
@@ -35,6 +38,7 @@ type Parameters struct {
 	Platform                    []string
 	ExcludePlatform             []string
 	TerraformVarsPath           string
+	CloudFormationParamsPath    string
 	QueryExecTimeout            int
 	LineInfoPayload             bool
 	DisableSecrets              bool
@@ -50,6 +54,13 @@ type Parameters struct {
 	UseOldSeverities            bool
 	MaxResolverDepth            int
 	KicsComputeNewSimID         bool
+	BaselinePath                string
+	GitDiffBase                 string
+	CacheDir                    string
+	CoveragePath                string
+	HelmValues                  []string
+	HelmSet                     []string
+	HelmEnvironments            []string
 }
 
 // Client represents a scan client
@@ -61,6 +72,9 @@ type Client struct {
 	ExcludeResultsMap map[string]bool
 	Printer           *consolePrinter.Printer
 	ProBarBuilder     *progress.PbBuilder
+	baseline          *model.Summary
+	gitChanges        *gitdiff.Changes
+	resultsCache      *cache.Cache
 }
 
 // NewClient initializes the client with all the required parameters
@@ -91,6 +105,24 @@ func NewClient(params *Parameters, proBarBuilder *progress.PbBuilder, customPrin
 func (c *Client) PerformScan(ctx context.Context) error {
 	c.ScanStartTime = time.Now()
 
+	if c.ScanParams.BaselinePath != "" {
+		baseline, err := loadBaseline(c.ScanParams.BaselinePath)
+		if err != nil {
+			log.Err(err)
+			return err
+		}
+		c.baseline = baseline
+	}
+
+	if c.ScanParams.GitDiffBase != "" {
+		gitChanges, err := gitdiff.GetChanges(ctx, c.ScanParams.GitDiffBase, c.ScanParams.Path)
+		if err != nil {
+			log.Err(err)
+			return err
+		}
+		c.gitChanges = gitChanges
+	}
+
 	scanResults, err := c.executeScan(ctx)
 
 	if err != nil {
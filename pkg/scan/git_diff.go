@@ -0,0 +1,40 @@
+package scan
+
+import (
+	"github.com/Checkmarx/kics/v2/pkg/gitdiff"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// filterChangedLines keeps the results located inside the lines changed since the git base ref,
+// results without line information are kept when their file was changed and the results of a Terraform
+// module instance are kept when the module block calling it was changed
+func filterChangedLines(results []model.Vulnerability, changes *gitdiff.Changes) []model.Vulnerability {
+	filtered := make([]model.Vulnerability, 0, len(results))
+	for i := range results {
+		if changedModuleCall(results[i].ModuleCall, changes) {
+			filtered = append(filtered, results[i])
+			continue
+		}
+		if results[i].Line < 1 {
+			if changes.HasFile(results[i].FileName) {
+				filtered = append(filtered, results[i])
+			}
+			continue
+		}
+		if changes.Contains(results[i].FileName, results[i].Line) {
+			filtered = append(filtered, results[i])
+		}
+	}
+
+	log.Info().Msgf("Results outside the lines changed since %s: %d", changes.BaseRef, len(results)-len(filtered))
+	return filtered
+}
+
+// changedModuleCall checks if any line of the module block calling a module instance was changed
+func changedModuleCall(call *model.ModuleCall, changes *gitdiff.Changes) bool {
+	if call == nil {
+		return false
+	}
+	return changes.Overlaps(call.FileName, call.Line, max(call.Line, call.EndLine))
+}
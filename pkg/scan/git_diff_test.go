@@ -0,0 +1,70 @@
+package scan
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/gitdiff"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_filterChangedLines(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	changedFile := filepath.Join(dir, "main.tf")
+	otherFile := filepath.Join(dir, "other.tf")
+
+	changes := &gitdiff.Changes{
+		BaseRef: "main",
+		Files: map[string][]gitdiff.LineRange{
+			changedFile: {{Start: 3, End: 5}},
+		},
+	}
+
+	results := []model.Vulnerability{
+		{SimilarityID: "inside", FileName: changedFile, Line: 4},
+		{SimilarityID: "outside", FileName: changedFile, Line: 10},
+		{SimilarityID: "no-line", FileName: changedFile, Line: -1},
+		{SimilarityID: "other-file", FileName: otherFile, Line: 4},
+	}
+
+	filtered := filterChangedLines(results, changes)
+
+	ids := make([]string, 0, len(filtered))
+	for i := range filtered {
+		ids = append(ids, filtered[i].SimilarityID)
+	}
+	require.Equal(t, []string{"inside", "no-line"}, ids)
+}
+
+func Test_filterChangedLines_ModuleCall(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	callerFile := filepath.Join(dir, "main.tf")
+	moduleFile := filepath.Join(dir, "modules", "bucket", "main.tf")
+
+	changes := &gitdiff.Changes{
+		BaseRef: "main",
+		Files: map[string][]gitdiff.LineRange{
+			callerFile: {{Start: 7, End: 7}},
+		},
+	}
+
+	changedCall := &model.ModuleCall{Path: "module.changed", ModuleFile: moduleFile, FileName: callerFile, Line: 5, EndLine: 8}
+	otherCall := &model.ModuleCall{Path: "module.other", ModuleFile: moduleFile, FileName: callerFile, Line: 10, EndLine: 13}
+	results := []model.Vulnerability{
+		{SimilarityID: "changed-call", FileName: moduleFile, Line: 3, ModuleCall: changedCall},
+		{SimilarityID: "changed-call-no-line", FileName: moduleFile, Line: -1, ModuleCall: changedCall},
+		{SimilarityID: "other-call", FileName: moduleFile, Line: 3, ModuleCall: otherCall},
+		{SimilarityID: "module-file", FileName: moduleFile, Line: 3},
+	}
+
+	filtered := filterChangedLines(results, changes)
+
+	ids := make([]string, 0, len(filtered))
+	for i := range filtered {
+		ids = append(ids, filtered[i].SimilarityID)
+	}
+	require.Equal(t, []string{"changed-call", "changed-call-no-line"}, ids)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	consoleHelpers "github.com/Checkmarx/kics/v2/internal/console/helpers"
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
 	"github.com/Checkmarx/kics/v2/pkg/descriptions"
 	"github.com/Checkmarx/kics/v2/pkg/engine/provider"
 	"github.com/Checkmarx/kics/v2/pkg/model"
@@ -52,6 +53,7 @@ func (c *Client) getSummary(results []model.Vulnerability, end time.Time, pathPa
 func (c *Client) resolveOutputs(
 	summary *model.Summary,
 	documents model.Documents,
+	coverageReport *coverage.Report,
 	printer *consolePrinter.Printer,
 	proBarBuilder progress.PbBuilder,
 ) error {
@@ -61,6 +63,11 @@ func (c *Client) resolveOutputs(
 	if err := consolePrinter.PrintResult(summary, printer, usingCustomQueries); err != nil {
 		return err
 	}
+	if c.ScanParams.CoveragePath != "" && coverageReport != nil {
+		if err := consoleHelpers.GenerateCoverageReport(c.ScanParams.CoveragePath, coverageReport); err != nil {
+			return err
+		}
+	}
 	if c.ScanParams.PayloadPath != "" {
 		if err := report.ExportJSONReport(
 			filepath.Dir(c.ScanParams.PayloadPath),
@@ -114,15 +121,24 @@ func (c *Client) postScan(scanResults *Results) error {
 			return err
 		}
 	}
+	if c.gitChanges != nil {
+		scanResults.Results = filterChangedLines(scanResults.Results, c.gitChanges)
+	}
+
 	sort.Strings(c.ScanParams.Path)
 	summary := c.getSummary(scanResults.Results, time.Now(), model.PathParameters{
 		ScannedPaths:      c.ScanParams.Path,
 		PathExtractionMap: scanResults.ExtractedPaths.ExtractionMap,
 	})
 
+	if c.baseline != nil {
+		summary.ApplyBaseline(c.baseline, c.ScanParams.BaselinePath)
+	}
+
 	if err := c.resolveOutputs(
 		&summary,
 		scanResults.Files.Combine(c.ScanParams.LineInfoPayload),
+		scanResults.Coverage,
 		c.Printer,
 		*c.ProBarBuilder); err != nil {
 		log.Err(err)
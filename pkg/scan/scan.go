@@ -6,10 +6,12 @@ import (
 	"os"
 
 	"github.com/Checkmarx/kics/v2/assets"
+	"github.com/Checkmarx/kics/v2/pkg/coverage"
 	"github.com/Checkmarx/kics/v2/pkg/engine"
 	"github.com/Checkmarx/kics/v2/pkg/engine/provider"
 	"github.com/Checkmarx/kics/v2/pkg/engine/secrets"
 	"github.com/Checkmarx/kics/v2/pkg/engine/source"
+	"github.com/Checkmarx/kics/v2/pkg/filesystem"
 	"github.com/Checkmarx/kics/v2/pkg/kics"
 	"github.com/Checkmarx/kics/v2/pkg/model"
 	"github.com/Checkmarx/kics/v2/pkg/parser"
@@ -17,6 +19,7 @@ import (
 	ansibleHostsParser "github.com/Checkmarx/kics/v2/pkg/parser/ansible/ini/hosts"
 	bicepParser "github.com/Checkmarx/kics/v2/pkg/parser/bicep"
 	buildahParser "github.com/Checkmarx/kics/v2/pkg/parser/buildah"
+	"github.com/Checkmarx/kics/v2/pkg/parser/cloudformation"
 	dockerParser "github.com/Checkmarx/kics/v2/pkg/parser/docker"
 	protoParser "github.com/Checkmarx/kics/v2/pkg/parser/grpc"
 	jsonParser "github.com/Checkmarx/kics/v2/pkg/parser/json"
@@ -24,6 +27,7 @@ import (
 	yamlParser "github.com/Checkmarx/kics/v2/pkg/parser/yaml"
 	"github.com/Checkmarx/kics/v2/pkg/resolver"
 	"github.com/Checkmarx/kics/v2/pkg/resolver/helm"
+	"github.com/Checkmarx/kics/v2/pkg/resolver/kustomize"
 	"github.com/Checkmarx/kics/v2/pkg/scanner"
 	"github.com/rs/zerolog/log"
 )
@@ -34,6 +38,7 @@ type Results struct {
 	ExtractedPaths provider.ExtractedPath
 	Files          model.FileMetadatas
 	FailedQueries  map[string]error
+	Coverage       *coverage.Report
 }
 
 type executeScanParameters struct {
@@ -83,6 +88,9 @@ func (c *Client) initScan(ctx context.Context) (*executeScanParameters, error) {
 	if err != nil {
 		return nil, err
 	}
+	if c.ScanParams.CoveragePath != "" {
+		inspector.EnableCoverageReport()
+	}
 
 	secretsRegexRulesContent, err := getSecretsRegexRules(c.ScanParams.SecretsRegexesPath)
 	if err != nil {
@@ -106,6 +114,17 @@ func (c *Client) initScan(ctx context.Context) (*executeScanParameters, error) {
 		return nil, err
 	}
 
+	// the queries are not evaluated against cached files, so their coverage would be incomplete
+	if c.ScanParams.CacheDir != "" && c.ScanParams.CoveragePath != "" {
+		log.Warn().Msg("Results cache is disabled when reporting the queries coverage")
+	} else if c.ScanParams.CacheDir != "" {
+		c.resultsCache, err = c.newResultsCache(inspector, secretsRegexRulesContent)
+		if err != nil {
+			log.Err(err)
+			return nil, err
+		}
+	}
+
 	services, err := c.createService(
 		inspector,
 		secretsInspector,
@@ -152,6 +171,10 @@ func (c *Client) executeScan(ctx context.Context) (*Results, error) {
 
 	failedQueries := executeScanParameters.inspector.GetFailedQueries()
 
+	if c.resultsCache != nil {
+		c.saveResultsCache(executeScanParameters.services, failedQueries)
+	}
+
 	results, err := c.Storage.GetVulnerabilities(ctx, c.ScanParams.ScanID)
 	if err != nil {
 		log.Err(err)
@@ -164,11 +187,17 @@ func (c *Client) executeScan(ctx context.Context) (*Results, error) {
 		return nil, err
 	}
 
+	var coverageReport *coverage.Report
+	if c.ScanParams.CoveragePath != "" {
+		coverageReport = executeScanParameters.inspector.GetQueriesCoverageReport()
+	}
+
 	return &Results{
 		Results:        results,
 		ExtractedPaths: executeScanParameters.extractedPaths,
 		Files:          files,
 		FailedQueries:  failedQueries,
+		Coverage:       coverageReport,
 	}, nil
 }
 
@@ -248,9 +277,15 @@ func (c *Client) createService(
 		return nil, err
 	}
 
+	cfnParameters, err := cloudformation.LoadParameters(nil, c.ScanParams.CloudFormationParamsPath)
+	if err != nil {
+		return nil, err
+	}
+
 	combinedParser, err := parser.NewBuilder().
-		Add(&jsonParser.Parser{}).
-		Add(&yamlParser.Parser{}).
+		WithFileSystem(filesystem.NewOS(paths)).
+		Add(jsonParser.NewWithCloudFormationParameters(cfnParameters)).
+		Add(yamlParser.NewWithCloudFormationParameters(cfnParameters)).
 		Add(terraformParser.NewDefaultWithVarsPath(c.ScanParams.TerraformVarsPath)).
 		Add(&bicepParser.Parser{}).
 		Add(&dockerParser.Parser{}).
@@ -265,7 +300,12 @@ func (c *Client) createService(
 
 	// combinedResolver to be used to resolve files and templates
 	combinedResolver, err := resolver.NewBuilder().
-		Add(&helm.Resolver{}).
+		Add(&helm.Resolver{
+			ValuesFiles:  c.ScanParams.HelmValues,
+			Values:       c.ScanParams.HelmSet,
+			Environments: c.ScanParams.HelmEnvironments,
+		}).
+		Add(&kustomize.Resolver{}).
 		Build()
 	if err != nil {
 		return nil, err
@@ -284,6 +324,7 @@ func (c *Client) createService(
 				SecretsInspector: secretsInspector,
 				Tracker:          t,
 				Resolver:         combinedResolver,
+				Cache:            c.resultsCache,
 				MaxFileSize:      c.ScanParams.MaxFileSizeFlag,
 			},
 		)
@@ -305,5 +346,9 @@ func (c *Client) getFileSystemSourceProvider(paths []string) (*provider.FileSyst
 	if err != nil {
 		return nil, err
 	}
+
+	if c.gitChanges != nil {
+		filesSource.SetIncludedFiles(c.gitChanges.Paths())
+	}
 	return filesSource, nil
 }
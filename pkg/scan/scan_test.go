@@ -67,6 +67,27 @@ func Test_ExecuteScan(t *testing.T) {
 	}
 }
 
+func Test_ExecuteScanCoverage(t *testing.T) {
+	scanParams := Parameters{
+		Path:                    []string{"./../../test/fixtures/test_scan_cloudfront_logging_disabled/test/positive1.yaml"},
+		QueriesPath:             []string{"./../../test/fixtures/test_scan_cloudfront_logging_disabled"},
+		PreviewLines:            3,
+		Platform:                []string{"CloudFormation"},
+		ChangedDefaultQueryPath: true,
+		MaxFileSizeFlag:         100,
+		QueryExecTimeout:        60,
+		CoveragePath:            t.TempDir(),
+	}
+	c, err := NewClient(&scanParams, &progress.PbBuilder{}, &consolePrinter.Printer{})
+	require.NoError(t, err)
+
+	r, err := c.executeScan(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, r.Coverage)
+	require.Len(t, r.Coverage.Queries, 1)
+	require.Positive(t, r.Coverage.Queries[0].CoveredLines)
+}
+
 func Test_GetSecretsRegexRules(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -20,6 +20,7 @@ import (
 
 var (
 	kuberneterRegex = regexp.MustCompile(`^kuberneter::`)
+	ociRegex        = regexp.MustCompile(`^oci::`)
 )
 
 func (c *Client) prepareAndAnalyzePaths(ctx context.Context) (provider.ExtractedPath, error) {
@@ -28,19 +29,24 @@ func (c *Client) prepareAndAnalyzePaths(ctx context.Context) (provider.Extracted
 		return provider.ExtractedPath{}, err
 	}
 
-	regularPaths, kuberneterPaths := extractPathType(c.ScanParams.Path)
+	regularPaths, kuberneterPaths, ociPaths := extractPathType(c.ScanParams.Path)
 
 	kuberneterExPaths, err := provider.GetKuberneterSources(ctx, kuberneterPaths, c.ScanParams.OutputPath)
 	if err != nil {
 		return provider.ExtractedPath{}, err
 	}
 
+	ociExPaths, err := provider.GetOCISources(ctx, ociPaths)
+	if err != nil {
+		return provider.ExtractedPath{}, err
+	}
+
 	regularExPaths, err := provider.GetSources(regularPaths)
 	if err != nil {
 		return provider.ExtractedPath{}, err
 	}
 
-	allPaths := combinePaths(kuberneterExPaths, regularExPaths, queryExPaths, libExPaths)
+	allPaths := combinePaths(kuberneterExPaths, ociExPaths, regularExPaths, queryExPaths, libExPaths)
 	if len(allPaths.Path) == 0 {
 		return provider.ExtractedPath{}, nil
 	}
@@ -72,11 +78,12 @@ func (c *Client) prepareAndAnalyzePaths(ctx context.Context) (provider.Extracted
 	return allPaths, nil
 }
 
-func combinePaths(kuberneter, regular, query, library provider.ExtractedPath) provider.ExtractedPath {
+func combinePaths(kuberneter, oci, regular, query, library provider.ExtractedPath) provider.ExtractedPath {
 	var combinedPaths provider.ExtractedPath
 	paths := make([]string, 0)
 	combinedPathsEx := make(map[string]model.ExtractedPathObject)
 	paths = append(paths, kuberneter.Path...)
+	paths = append(paths, oci.Path...)
 	paths = append(paths, regular.Path...)
 	combinedPaths.Path = paths
 	for k, v := range regular.ExtractionMap {
@@ -85,6 +92,9 @@ func combinePaths(kuberneter, regular, query, library provider.ExtractedPath) pr
 	for k, v := range kuberneter.ExtractionMap {
 		combinedPathsEx[k] = v
 	}
+	for k, v := range oci.ExtractionMap {
+		combinedPathsEx[k] = v
+	}
 	for k, v := range query.ExtractionMap {
 		combinedPathsEx[k] = v
 	}
@@ -198,10 +208,12 @@ func logLoadingQueriesType(types []string) {
 	log.Info().Msgf("Loading queries of type: %s", strings.Join(types, ", "))
 }
 
-func extractPathType(paths []string) (regular, kuberneter []string) {
+func extractPathType(paths []string) (regular, kuberneter, oci []string) {
 	for _, path := range paths {
 		if kuberneterRegex.MatchString(path) {
 			kuberneter = append(kuberneter, kuberneterRegex.ReplaceAllString(path, ""))
+		} else if ociRegex.MatchString(path) {
+			oci = append(oci, ociRegex.ReplaceAllString(path, ""))
 		} else {
 			regular = append(regular, path)
 		}
@@ -243,6 +243,7 @@ func Test_ExtractPathType(t *testing.T) {
 		name               string
 		paths              []string
 		expectedKuberneter []string
+		expectedOCI        []string
 		expectedPaths      []string
 	}{
 		{
@@ -251,6 +252,13 @@ func Test_ExtractPathType(t *testing.T) {
 			expectedKuberneter: []string{"*:*:*"},
 			expectedPaths:      []string(nil),
 		},
+		{
+			name:               "oci",
+			paths:              []string{"oci::image.tar", "main.tf"},
+			expectedKuberneter: []string(nil),
+			expectedOCI:        []string{"image.tar"},
+			expectedPaths:      []string{"main.tf"},
+		},
 		{
 			name:               "count progress and utils folder files",
 			paths:              []string{filepath.Join("..", "..", "pkg", "progress"), filepath.Join("..", "..", "pkg", "utils")},
@@ -267,9 +275,10 @@ func Test_ExtractPathType(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			vPaths, vKuberneter := extractPathType(tt.paths)
+			vPaths, vKuberneter, vOCI := extractPathType(tt.paths)
 
 			require.Equal(t, tt.expectedKuberneter, vKuberneter)
+			require.Equal(t, tt.expectedOCI, vOCI)
 			require.Equal(t, tt.expectedPaths, vPaths)
 
 		})
@@ -371,7 +380,7 @@ func Test_CombinePaths(t *testing.T) {
 				Path:          []string{},
 				ExtractionMap: make(map[string]model.ExtractedPathObject),
 			}
-			v := combinePaths(tt.kuberneter, tt.regular, extPath, extPath)
+			v := combinePaths(tt.kuberneter, provider.ExtractedPath{}, tt.regular, extPath, extPath)
 
 			require.Equal(t, tt.expectedOutput, v)
 		})
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// metrics counts the scan requests handled by the server, exposed in the Prometheus text format
+type metrics struct {
+	mu              sync.Mutex
+	scans           int
+	failedScans     int
+	inFlight        int
+	durationSeconds float64
+}
+
+func (m *metrics) startScan() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+}
+
+func (m *metrics) endScan(duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	m.scans++
+	if failed {
+		m.failedScans++
+	}
+	m.durationSeconds += duration.Seconds()
+}
+
+func (m *metrics) write(w io.Writer, loadedQueries int) error {
+	m.mu.Lock()
+	scans, failedScans, inFlight, durationSeconds := m.scans, m.failedScans, m.inFlight, m.durationSeconds
+	m.mu.Unlock()
+
+	_, err := fmt.Fprintf(w, `# HELP kics_scans_total Number of scan requests handled.
+# TYPE kics_scans_total counter
+kics_scans_total %d
+# HELP kics_scan_errors_total Number of scan requests that failed.
+# TYPE kics_scan_errors_total counter
+kics_scan_errors_total %d
+# HELP kics_scans_in_flight Number of scan requests being handled.
+# TYPE kics_scans_in_flight gauge
+kics_scans_in_flight %d
+# HELP kics_scan_duration_seconds Time taken to handle scan requests.
+# TYPE kics_scan_duration_seconds summary
+kics_scan_duration_seconds_sum %g
+kics_scan_duration_seconds_count %d
+# HELP kics_loaded_queries Number of queries loaded by the server.
+# TYPE kics_loaded_queries gauge
+kics_loaded_queries %d
+`, scans, failedScans, inFlight, durationSeconds, scans, loadedQueries)
+	return err
+}
@@ -0,0 +1,274 @@
+// Package server implements an HTTP API scanning files with queries loaded and compiled once, so short scans,
+// such as pre-commit hooks and IDE checks, do not pay the cost of loading the queries on every run
+package server
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Checkmarx/kics/v2/pkg/api"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/Checkmarx/kics/v2/pkg/report"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// archiveField is the multipart form field holding the tarball to scan
+	archiveField = "archive"
+	// maxArchiveRatio is how many times the max request size the files extracted from an archive can take
+	maxArchiveRatio = 10
+	// maxMemoryForm is the memory used to parse multipart forms, the rest is stored in temporary files
+	maxMemoryForm = 32 << 20
+
+	formatJSON  = "json"
+	formatSarif = "sarif"
+)
+
+var (
+	errRequestTooLarge = errors.New("request body too large")
+	errArchiveTooLarge = errors.New("archive content too large")
+)
+
+// Scanner scans files held in memory
+type Scanner interface {
+	Scan(ctx context.Context, files []api.InMemoryFile) (model.Summary, error)
+	LoadedQueries() int
+}
+
+// Server handles scan requests over HTTP, each request is scanned in isolation so requests can run concurrently
+type Server struct {
+	scanner        Scanner
+	maxRequestSize int64
+	metrics        *metrics
+	mux            *http.ServeMux
+}
+
+// scanRequest is the JSON body of a scan request, mapping file paths to their contents
+type scanRequest struct {
+	Files map[string]string `json:"files"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type healthResponse struct {
+	Status        string `json:"status"`
+	LoadedQueries int    `json:"loaded_queries"`
+}
+
+// New creates a Server scanning requests of at most maxRequestSize bytes with the given scanner
+func New(scanner Scanner, maxRequestSize int64) *Server {
+	s := &Server{
+		scanner:        scanner,
+		maxRequestSize: maxRequestSize,
+		metrics:        &metrics{},
+		mux:            http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /scan", s.handleScan)
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	s.metrics.startScan()
+	failed := true
+	defer func() {
+		s.metrics.endScan(time.Since(start), failed)
+	}()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatJSON
+	}
+	if format != formatJSON && format != formatSarif {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported format %q, expected json or sarif", format))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxRequestSize)
+	files, err := s.readFiles(r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			writeError(w, http.StatusRequestEntityTooLarge, errRequestTooLarge)
+		case errors.Is(err, errArchiveTooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+		default:
+			writeError(w, http.StatusBadRequest, err)
+		}
+		return
+	}
+
+	summary, err := s.scanner.Scan(r.Context(), files)
+	if err != nil {
+		log.Err(err).Msg("Failed to scan request files")
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if format == formatSarif {
+		err = report.WriteSarifReport(&buf, &summary)
+	} else {
+		err = report.WriteJSONReport(&buf, &summary)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	failed = false
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Debug().Msgf("Failed to write scan response: %s", err)
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, healthResponse{
+		Status:        "ok",
+		LoadedQueries: s.scanner.LoadedQueries(),
+	})
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := s.metrics.write(w, s.scanner.LoadedQueries()); err != nil {
+		log.Debug().Msgf("Failed to write metrics response: %s", err)
+	}
+}
+
+// readFiles reads the files to scan from a JSON body or from a tarball sent in a multipart form
+func (s *Server) readFiles(r *http.Request) ([]api.InMemoryFile, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type: %w", err)
+	}
+
+	switch mediaType {
+	case "application/json":
+		return readJSONFiles(r.Body)
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxMemoryForm); err != nil {
+			return nil, err
+		}
+		archive, _, err := r.FormFile(archiveField)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read form field %s: %w", archiveField, err)
+		}
+		defer archive.Close()
+		return readArchiveFiles(archive, s.maxRequestSize*maxArchiveRatio)
+	default:
+		return nil, fmt.Errorf("unsupported content type %s, expected application/json or multipart/form-data", mediaType)
+	}
+}
+
+func readJSONFiles(body io.Reader) ([]api.InMemoryFile, error) {
+	var request scanRequest
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	files := make([]api.InMemoryFile, 0, len(request.Files))
+	for filePath, content := range request.Files {
+		cleaned, err := cleanPath(filePath)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, api.InMemoryFile{Path: cleaned, Content: []byte(content)})
+	}
+	return files, nil
+}
+
+// readArchiveFiles reads the regular files of a tarball, gzip compressed or not, up to maxSize bytes
+func readArchiveFiles(archive io.Reader, maxSize int64) ([]api.InMemoryFile, error) {
+	reader := bufio.NewReader(archive)
+	var tarReader *tar.Reader
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer gzipReader.Close()
+		tarReader = tar.NewReader(gzipReader)
+	} else {
+		tarReader = tar.NewReader(reader)
+	}
+
+	files := make([]api.InMemoryFile, 0)
+	remaining := maxSize
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		filePath, err := cleanPath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if header.Size > remaining {
+			return nil, errArchiveTooLarge
+		}
+		content, err := io.ReadAll(io.LimitReader(tarReader, header.Size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive file %s: %w", header.Name, err)
+		}
+		remaining -= int64(len(content))
+		files = append(files, api.InMemoryFile{Path: filePath, Content: content})
+	}
+}
+
+// cleanPath returns the path relative to the root of the scanned files, paths escaping it are rejected
+func cleanPath(filePath string) (string, error) {
+	slashed := strings.ReplaceAll(filePath, "\\", "/")
+	for _, part := range strings.Split(slashed, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("invalid file path %q", filePath)
+		}
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+slashed), "/")
+	if cleaned == "" || strings.Contains(cleaned, "\x00") {
+		return "", fmt.Errorf("invalid file path %q", filePath)
+	}
+	return cleaned, nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Debug().Msgf("Failed to write response: %s", err)
+	}
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Checkmarx/kics/v2/pkg/api"
+	"github.com/Checkmarx/kics/v2/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeScanner struct {
+	mu    sync.Mutex
+	files map[string]string
+	err   error
+}
+
+func (f *fakeScanner) Scan(_ context.Context, files []api.InMemoryFile) (model.Summary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return model.Summary{}, f.err
+	}
+	f.files = make(map[string]string, len(files))
+	paths := make([]string, 0, len(files))
+	for i := range files {
+		f.files[files[i].Path] = string(files[i].Content)
+		paths = append(paths, files[i].Path)
+	}
+	sort.Strings(paths)
+	return model.Summary{
+		ScannedPaths: paths,
+		Counters:     model.Counters{ScannedFiles: len(files)},
+	}, nil
+}
+
+func (f *fakeScanner) LoadedQueries() int {
+	return 42
+}
+
+func newArchive(t *testing.T, compress bool, files map[string]string) []byte {
+	var buf bytes.Buffer
+	var gzipWriter *gzip.Writer
+	tarWriter := tar.NewWriter(&buf)
+	if compress {
+		gzipWriter = gzip.NewWriter(&buf)
+		tarWriter = tar.NewWriter(gzipWriter)
+	}
+
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "infra/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for name, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+		}))
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	if gzipWriter != nil {
+		require.NoError(t, gzipWriter.Close())
+	}
+	return buf.Bytes()
+}
+
+func newMultipartRequest(t *testing.T, target string, archive []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(archiveField, "files.tar")
+	require.NoError(t, err)
+	_, err = part.Write(archive)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func newJSONRequest(target, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestServer_Scan(t *testing.T) {
+	files := map[string]string{
+		"infra/main.tf":          `resource "aws_s3_bucket" "b" {}`,
+		"infra/terraform.tfvars": `acl = "private"`,
+	}
+	jsonBody, err := json.Marshal(scanRequest{Files: map[string]string{
+		"./infra/main.tf":        files["infra/main.tf"],
+		"infra/terraform.tfvars": files["infra/terraform.tfvars"],
+	}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{
+			name: "should scan files of a json request",
+			req:  newJSONRequest("/scan", string(jsonBody)),
+		},
+		{
+			name: "should scan files of a tarball",
+			req:  newMultipartRequest(t, "/scan", newArchive(t, false, files)),
+		},
+		{
+			name: "should scan files of a gzip compressed tarball",
+			req:  newMultipartRequest(t, "/scan", newArchive(t, true, files)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &fakeScanner{}
+			rec := httptest.NewRecorder()
+			New(scanner, 1<<20).ServeHTTP(rec, tt.req)
+
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			require.Equal(t, files, scanner.files)
+
+			var summary model.Summary
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+			require.Equal(t, 2, summary.ScannedFiles)
+			require.Equal(t, []string{"infra/main.tf", "infra/terraform.tfvars"}, summary.ScannedPaths)
+		})
+	}
+}
+
+func TestServer_ScanSarif(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(&fakeScanner{}, 1<<20).ServeHTTP(rec, newJSONRequest("/scan?format=sarif", `{"files":{"main.tf":""}}`))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var sarif struct {
+		Version string `json:"version"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sarif))
+	require.Equal(t, "2.1.0", sarif.Version)
+}
+
+func TestServer_ScanErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *http.Request
+		scanErr    error
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "should reject unsupported formats",
+			req:        newJSONRequest("/scan?format=html", `{"files":{}}`),
+			wantStatus: http.StatusBadRequest,
+			wantError:  `unsupported format "html"`,
+		},
+		{
+			name:       "should reject invalid json",
+			req:        newJSONRequest("/scan", `{"files":`),
+			wantStatus: http.StatusBadRequest,
+			wantError:  "failed to decode request",
+		},
+		{
+			name:       "should reject paths escaping the scanned files",
+			req:        newJSONRequest("/scan", `{"files":{"../etc/passwd":""}}`),
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid file path",
+		},
+		{
+			name:       "should reject unsupported content types",
+			req:        httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader("")),
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid content type",
+		},
+		{
+			name:       "should reject requests larger than the max request size",
+			req:        newJSONRequest("/scan", `{"files":{"main.tf":"`+strings.Repeat("a", 2048)+`"}}`),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantError:  errRequestTooLarge.Error(),
+		},
+		{
+			name:       "should reject archives larger than the max request size",
+			req:        newMultipartRequest(t, "/scan", newArchive(t, true, map[string]string{"main.tf": strings.Repeat("a", 20480)})),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantError:  errArchiveTooLarge.Error(),
+		},
+		{
+			name:       "should report scan failures",
+			req:        newJSONRequest("/scan", `{"files":{"main.tf":""}}`),
+			scanErr:    errors.New("scan failed"),
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "scan failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			New(&fakeScanner{err: tt.scanErr}, 1024).ServeHTTP(rec, tt.req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			var got errorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			require.Contains(t, got.Error, tt.wantError)
+		})
+	}
+}
+
+func TestServer_HealthAndMetrics(t *testing.T) {
+	s := New(&fakeScanner{}, 1<<20)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"ok","loaded_queries":42}`, rec.Body.String())
+
+	s.ServeHTTP(httptest.NewRecorder(), newJSONRequest("/scan", `{"files":{"main.tf":""}}`))
+	s.ServeHTTP(httptest.NewRecorder(), newJSONRequest("/scan", `{"files":`))
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "kics_scans_total 2\n")
+	require.Contains(t, rec.Body.String(), "kics_scan_errors_total 1\n")
+	require.Contains(t, rec.Body.String(), "kics_scans_in_flight 0\n")
+	require.Contains(t, rec.Body.String(), "kics_scan_duration_seconds_count 2\n")
+	require.Contains(t, rec.Body.String(), "kics_loaded_queries 42\n")
+}
+
+func TestServer_ScanNoDiskReads(t *testing.T) {
+	queries, err := filepath.Abs(filepath.Join("..", "..", "assets", "queries"))
+	require.NoError(t, err)
+	scanner, err := api.NewScanner(context.Background(), api.Options{
+		QueriesPath:    []string{queries},
+		Platforms:      []string{"DockerCompose"},
+		IncludeQueries: []string{"ae5b6871-7f45-42e0-bb4c-ab300c4d2026"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, scanner.LoadedQueries())
+
+	// the request extends a privileged service of a file of the server outside of the scan root
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "etc"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "root"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "etc", "common.yml"),
+		[]byte("services:\n  base:\n    image: nginx\n    privileged: true\n"), 0o600))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(filepath.Join(dir, "root")))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+	}()
+
+	body, err := json.Marshal(scanRequest{Files: map[string]string{
+		"compose/docker-compose.yml": "services:\n  web:\n    extends:\n      file: ../../etc/common.yml\n" +
+			"      service: base\n",
+	}})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	New(scanner, 1<<20).ServeHTTP(rec, newJSONRequest("/scan", string(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var summary model.Summary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	require.Equal(t, 1, summary.ScannedFiles)
+	require.Equal(t, 0, summary.TotalCounter)
+}
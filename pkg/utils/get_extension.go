@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,10 +13,10 @@ import (
 	"golang.org/x/tools/godoc/util"
 )
 
+var extensionTargets = []string{"Dockerfile", "tfvars"}
+
 // GetExtension gets the extension of a file path
 func GetExtension(path string) (string, error) {
-	targets := []string{"Dockerfile", "tfvars"}
-
 	// Get file information
 	fileInfo, err := os.Stat(path)
 	if err != nil {
@@ -30,7 +31,7 @@ func GetExtension(path string) (string, error) {
 	if ext == "" {
 		base := filepath.Base(path)
 
-		if Contains(base, targets) {
+		if Contains(base, extensionTargets) {
 			ext = base
 		} else {
 			isText, err := isTextFile(path)
@@ -50,6 +51,27 @@ func GetExtension(path string) (string, error) {
 	return ext, nil
 }
 
+// GetExtensionFromContent gets the extension of a file path using its content, instead of reading the file,
+// to detect Dockerfiles without extension
+func GetExtensionFromContent(path string, content []byte) string {
+	ext := filepath.Ext(path)
+	if ext != "" {
+		return ext
+	}
+
+	base := filepath.Base(path)
+	if Contains(base, extensionTargets) {
+		return base
+	}
+
+	if util.IsText(bytes.ReplaceAll(content, []byte("\r"), []byte(""))) &&
+		(strings.HasSuffix(path, "gitignore") || isPossibleDockerFile(bytes.NewReader(content))) {
+		return "possibleDockerfile"
+	}
+
+	return ""
+}
+
 func readPossibleDockerFile(path string) bool {
 	path = filepath.Clean(path)
 	if strings.HasSuffix(path, "gitignore") {
@@ -60,8 +82,12 @@ func readPossibleDockerFile(path string) bool {
 		return false
 	}
 	defer file.Close()
+	return isPossibleDockerFile(file)
+}
+
+func isPossibleDockerFile(reader io.Reader) bool {
 	// Create a scanner to read the file line by line
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(reader)
 	// Read lines from the file
 	for scanner.Scan() {
 		if strings.HasPrefix(scanner.Text(), "FROM") {
@@ -73,3 +73,48 @@ func TestGetExtension(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExtensionFromContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		content  []byte
+		want     string
+	}{
+		{
+			name:     "Get extension from a file with extension defined ('main.tf')",
+			filePath: "infra/main.tf",
+			content:  []byte(`resource "aws_s3_bucket" "b" {}`),
+			want:     ".tf",
+		},
+		{
+			name:     "Get extension from a file named as Dockerfile and without extension defined ('Dockerfile')",
+			filePath: "Dockerfile",
+			want:     "Dockerfile",
+		},
+		{
+			name:     "Get extension from a file content starting with FROM and without extension defined",
+			filePath: "images/base",
+			content:  []byte("# base image\nFROM alpine:3.18\nRUN apk add curl\n"),
+			want:     "possibleDockerfile",
+		},
+		{
+			name:     "Get empty extension from a file content not starting with FROM and without extension defined",
+			filePath: "images/notes",
+			content:  []byte("some notes\nFROM here\n"),
+			want:     "",
+		},
+		{
+			name:     "Get empty extension from a binary content without extension defined",
+			filePath: "images/binary",
+			content:  []byte{0x00, 0x01, 0x02, 0xff},
+			want:     "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, GetExtensionFromContent(test.filePath, test.content))
+		})
+	}
+}
@@ -0,0 +1,6 @@
+locals {
+  name          = "${local.prefix}-data"
+  encrypted     = var.environment == "prod"
+  ingress_cidrs = [var.vpc_cidr]
+  kms_key_id    = aws_kms_key.data.arn
+}
@@ -0,0 +1,23 @@
+locals {
+  prefix = upper(var.environment)
+}
+
+resource "aws_ebs_volume" "data" {
+  availability_zone = "us-west-2a"
+  size              = 40
+  encrypted         = local.encrypted
+  kms_key_id        = local.kms_key_id
+
+  tags = {
+    Name = local.name
+  }
+}
+
+resource "aws_security_group" "data" {
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = local.ingress_cidrs
+  }
+}
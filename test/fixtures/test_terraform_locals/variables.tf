@@ -0,0 +1,7 @@
+variable "environment" {
+  default = "prod"
+}
+
+variable "vpc_cidr" {
+  default = "10.0.0.0/16"
+}
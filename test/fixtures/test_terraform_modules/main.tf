@@ -0,0 +1,14 @@
+variable "volume_encrypted" {
+  default = false
+}
+
+module "ebs" {
+  source    = "./modules/ebs"
+  encrypted = var.volume_encrypted
+  size      = 40
+}
+
+module "remote" {
+  source  = "terraform-aws-modules/ebs/aws"
+  version = "1.0.0"
+}
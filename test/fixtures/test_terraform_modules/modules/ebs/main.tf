@@ -0,0 +1,5 @@
+resource "aws_ebs_volume" "this" {
+  availability_zone = "us-west-2a"
+  size              = var.size
+  encrypted         = var.encrypted
+}
@@ -0,0 +1,9 @@
+variable "encrypted" {
+  type    = bool
+  default = true
+}
+
+variable "size" {
+  type    = number
+  default = 20
+}
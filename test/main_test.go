@@ -16,9 +16,9 @@ import (
 	"github.com/Checkmarx/kics/v2/pkg/parser"
 	ansibleConfigParser "github.com/Checkmarx/kics/v2/pkg/parser/ansible/ini/config"
 	ansibleHostsParser "github.com/Checkmarx/kics/v2/pkg/parser/ansible/ini/hosts"
+	bicepParser "github.com/Checkmarx/kics/v2/pkg/parser/bicep"
 	buildahParser "github.com/Checkmarx/kics/v2/pkg/parser/buildah"
-    bicepParser "github.com/Checkmarx/kics/v2/pkg/parser/bicep"
-	dockerParser "github.com/Checkmarx/kics/v2/pkg/parser/docker" 
+	dockerParser "github.com/Checkmarx/kics/v2/pkg/parser/docker"
 	protoParser "github.com/Checkmarx/kics/v2/pkg/parser/grpc"
 	jsonParser "github.com/Checkmarx/kics/v2/pkg/parser/json"
 	terraformParser "github.com/Checkmarx/kics/v2/pkg/parser/terraform"
@@ -73,6 +73,9 @@ var (
 		"../assets/queries/serverlessFW":                    {FileKind: []model.FileKind{model.KindYAML, model.KindYML}, Platform: "serverlessFW"},
 		"../assets/queries/knative":                         {FileKind: []model.FileKind{model.KindYAML}, Platform: "knative"},
 		"../assets/queries/cicd/github":                     {FileKind: []model.FileKind{model.KindYAML}, Platform: "cicd"},
+		"../assets/queries/cicd/gitlab":                     {FileKind: []model.FileKind{model.KindYAML}, Platform: "cicd"},
+		"../assets/queries/cicd/azure":                      {FileKind: []model.FileKind{model.KindYAML}, Platform: "cicd"},
+		"../assets/queries/cicd/bitbucket":                  {FileKind: []model.FileKind{model.KindYAML}, Platform: "cicd"},
 	}
 
 	issueTypes = map[string]string{
@@ -303,4 +306,4 @@ func getQueryFilter() *source.QueryInspectorParameters {
 		ExcludeQueries: source.ExcludeQueries{ByIDs: []string{}, ByCategories: []string{}},
 		InputDataPath:  "",
 	}
-}
\ No newline at end of file
+}
@@ -57,6 +57,9 @@ var (
 		"../assets/queries/openAPI/general/response_code_missing",
 		"../assets/queries/cicd/github/run_block_injection",
 		"../assets/queries/cicd/github/script_block_injection",
+		"../assets/queries/cicd/gitlab/script_injection",
+		"../assets/queries/cicd/azure/script_injection",
+		"../assets/queries/cicd/bitbucket/script_injection",
 		"../assets/queries/azureResourceManager/key_vault_not_recoverable",
 	}
 